@@ -0,0 +1,117 @@
+// migrate 升级已有数据库：将旧版 artworks/artwork_instances 数据迁移到统一的 assets/asset_instances 藏品目录，
+// 并补建搜索所需的全文索引、藏品属性表和实例稀有度字段、
+// 衍生作品授权和上游版税分账表、盲盒分成和逐次熵字段、所有权变更记录和每日Merkle快照表，
+// 并为历史社区事件补建哈希链、为历史交易补发交易单号、为历史藏品实例登记上链任务，
+// 以及将平台账户金额字段升级为 decimal(30,8)、补建每日平台指标快照表和管理员角色权限表
+// 用法：在 backend 目录下执行 go run ./cmd/migrate，可重复执行
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/joho/godotenv"
+	"hoho-miniapp/backend/config"
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/services"
+	"hoho-miniapp/backend/utils"
+)
+
+func main() {
+	// 加载环境变量
+	if err := godotenv.Load(".env"); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	// 补发单号使用雪花ID，需与运行中的服务使用不同的 NODE_ID
+	config.InitConfig()
+	if err := utils.InitIDGenerator(int64(config.AppConfig.NodeID)); err != nil {
+		log.Fatalf("Failed to initialize ID generator: %v", err)
+	}
+
+	if err := database.InitDatabase(); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.CloseDatabase()
+
+	report, err := services.NewCatalogMigrationService().Run()
+	if err != nil {
+		log.Fatalf("Catalog migration failed: %v", err)
+	}
+
+	fmt.Printf("✅ Catalog migrated: %d assets, %d instances created\n", report.Assets, report.Instances)
+	for column, rows := range report.References {
+		fmt.Printf("   %s: %d rows rewritten\n", column, rows)
+	}
+	if len(report.Flattened) > 0 {
+		fmt.Printf("⚠️  %d listed/locked legacy instances migrated as in_wallet, please review:\n", len(report.Flattened))
+		for _, f := range report.Flattened {
+			fmt.Printf("   asset_instance %d (legacy %d, owner %d, was %s)\n", f.AssetInstanceID, f.LegacyInstanceID, f.OwnerID, f.LegacyStatus)
+		}
+	}
+
+	if err := services.NewSearchService().EnsureIndexes(); err != nil {
+		log.Fatalf("Failed to create search indexes: %v", err)
+	}
+	fmt.Println("✅ Search indexes ready")
+
+	if err := services.NewTraitService().EnsureSchema(); err != nil {
+		log.Fatalf("Failed to create trait schema: %v", err)
+	}
+	fmt.Println("✅ Trait and rarity schema ready")
+
+	if err := services.NewDerivativeService().EnsureSchema(); err != nil {
+		log.Fatalf("Failed to create derivative schema: %v", err)
+	}
+	fmt.Println("✅ Derivative license and royalty schema ready")
+
+	if err := services.NewBlindBoxService().EnsureSchema(); err != nil {
+		log.Fatalf("Failed to upgrade blind box schema: %v", err)
+	}
+	fmt.Println("✅ Blind box revenue split and draw entropy ready")
+
+	if err := services.NewLotteryService().EnsureSchema(); err != nil {
+		log.Fatalf("Failed to upgrade lottery schema: %v", err)
+	}
+	fmt.Println("✅ Lottery entries hash ready")
+
+	if err := services.NewProvenanceService().EnsureSchema(); err != nil {
+		log.Fatalf("Failed to create provenance schema: %v", err)
+	}
+	fmt.Println("✅ Ownership provenance schema ready")
+
+	if err := services.NewOwnershipSnapshotService().EnsureSchema(); err != nil {
+		log.Fatalf("Failed to create ownership snapshot schema: %v", err)
+	}
+	fmt.Println("✅ Ownership snapshot schema ready")
+
+	if err := services.NewEventService().EnsureChain(); err != nil {
+		log.Fatalf("Failed to build event hash chain: %v", err)
+	}
+	fmt.Println("✅ Event hash chain ready")
+
+	if err := services.NewTradeService().EnsureSchema(); err != nil {
+		log.Fatalf("Failed to backfill trade numbers: %v", err)
+	}
+	fmt.Println("✅ Trade numbers ready")
+
+	if err := services.NewChainAnchorService(nil).EnsureSchema(); err != nil {
+		log.Fatalf("Failed to create chain anchor schema: %v", err)
+	}
+	fmt.Println("✅ Chain anchor schema ready")
+
+	if err := services.NewPlatformAccountService().EnsureSchema(); err != nil {
+		log.Fatalf("Failed to upgrade platform account schema: %v", err)
+	}
+	fmt.Println("✅ Platform account schema ready")
+
+	if err := services.NewMetricsService().EnsureSchema(); err != nil {
+		log.Fatalf("Failed to create metrics schema: %v", err)
+	}
+	fmt.Println("✅ Metrics schema ready")
+
+	if err := services.NewAdminRoleService().EnsureSchema(); err != nil {
+		log.Fatalf("Failed to create admin role schema: %v", err)
+	}
+	fmt.Println("✅ Admin roles ready")
+}
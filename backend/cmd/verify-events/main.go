@@ -0,0 +1,43 @@
+// verify-events 校验社区事件哈希链，逐条重算哈希并报告被修改、删除或插入的事件
+// 用法：在 backend 目录下执行 go run ./cmd/verify-events，链完整时退出码为0，存在断点时为1
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/services"
+)
+
+func main() {
+	// 加载环境变量
+	if err := godotenv.Load(".env"); err != nil {
+		log.Println("No .env file found, using environment variables")
+	}
+
+	if err := database.InitDatabase(); err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.CloseDatabase()
+
+	report, err := services.NewEventService().VerifyChain()
+	if err != nil {
+		log.Fatalf("Event chain verification failed: %v", err)
+	}
+
+	fmt.Printf("Checked %d events, head seq %d, head hash %s\n", report.Checked, report.HeadSeq, report.HeadHash)
+	if report.Valid {
+		fmt.Println("✅ Event chain intact")
+		return
+	}
+
+	fmt.Printf("❌ Event chain broken at %d point(s)\n", len(report.Breaks))
+	for _, b := range report.Breaks {
+		fmt.Printf("   seq %d (event %d): %s\n", b.Seq, b.EventID, b.Reason)
+	}
+	database.CloseDatabase()
+	os.Exit(1)
+}
@@ -18,6 +18,7 @@ type Config struct {
 
 	// 系统配置
 	DecimalPrecision int32 // 积分精度（默认8位小数）
+	NodeID           int   // 雪花ID节点号（0-1023），多实例部署时每个实例必须不同
 }
 
 var AppConfig *Config
@@ -29,6 +30,7 @@ func InitConfig() {
 		CreatorRoyaltyRate: getDecimalEnv("CREATOR_ROYALTY_RATE", "0.025"), // 2.5%
 		InitialPoints:      getDecimalEnv("INITIAL_POINTS", "100.00000000"),
 		DecimalPrecision:   8,
+		NodeID:             getIntEnv("NODE_ID", 0),
 	}
 }
 
@@ -23,8 +23,8 @@ func InitDatabase() error {
 		os.Getenv("DB_NAME"),
 	)
 
-	// GORM配置
-	gormConfig := &gorm.Config{}
+	// GORM配置，TranslateError 将唯一索引冲突统一转换为 gorm.ErrDuplicatedKey
+	gormConfig := &gorm.Config{TranslateError: true}
 	if os.Getenv("ENV") == "development" {
 		gormConfig.Logger = logger.Default.LogMode(logger.Info)
 	} else {
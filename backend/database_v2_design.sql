@@ -1,5 +1,5 @@
 -- HOHO Park 数据库设计 V2.0
--- 统一术语：藏品(asset) → 作品(artwork)
+-- 藏品目录：统一使用 init.sql 中的 assets/asset_instances，旧 artworks/artwork_instances 已废弃（cmd/migrate 迁移）
 -- 新增功能：创作、任务、公告、出价、阳光账户
 
 -- ============================================
@@ -59,15 +59,17 @@ CREATE TABLE IF NOT EXISTS `point_transactions` (
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='积分交易记录表';
 
 -- ============================================
--- 2. 作品相关表（术语统一：asset → artwork）
+-- 2. 旧版作品相关表（已废弃，仅保留供 cmd/migrate 迁移数据）
 -- ============================================
 
--- 作品表（原assets表）
+-- 作品表（已废弃，使用 assets）
 CREATE TABLE IF NOT EXISTS `artworks` (
   `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '作品ID',
   `title` varchar(100) NOT NULL COMMENT '作品标题',
   `description` text COMMENT '作品描述',
   `creator_name` varchar(50) DEFAULT NULL COMMENT '创作者名称',
+  `creation_id` bigint unsigned DEFAULT NULL COMMENT '来源创作ID',
+  `creator_id` bigint unsigned DEFAULT NULL COMMENT '创作者用户ID',
   `media_type` enum('image','video','audio','3d') NOT NULL DEFAULT 'image' COMMENT '媒体类型',
   `media_url` varchar(255) NOT NULL COMMENT '媒体URL',
   `thumbnail_url` varchar(255) DEFAULT NULL COMMENT '缩略图URL',
@@ -84,10 +86,12 @@ CREATE TABLE IF NOT EXISTS `artworks` (
   KEY `idx_source` (`source`),
   KEY `idx_status` (`status`),
   KEY `idx_release_date` (`release_date`),
-  KEY `idx_series` (`series`)
+  KEY `idx_series` (`series`),
+  KEY `idx_creation_id` (`creation_id`),
+  KEY `idx_creator_id` (`creator_id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='作品表';
 
--- 作品实例表（原asset_instances表）
+-- 作品实例表（已废弃，使用 asset_instances）
 CREATE TABLE IF NOT EXISTS `artwork_instances` (
   `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '实例ID',
   `artwork_id` bigint unsigned NOT NULL COMMENT '作品ID',
@@ -121,6 +125,7 @@ CREATE TABLE IF NOT EXISTS `creations` (
   `total_supply` int unsigned NOT NULL COMMENT '发行数量',
   `price` decimal(20,8) NOT NULL COMMENT '售价（积分）',
   `commission_rate` decimal(5,2) NOT NULL DEFAULT '40.00' COMMENT '平台分成比例（%）',
+  `parent_asset_id` bigint unsigned DEFAULT NULL COMMENT '声明的上游藏品ID（衍生作品）',
   `status` enum('pending','approved','rejected','published') NOT NULL DEFAULT 'pending' COMMENT '状态',
   `reject_reason` varchar(255) DEFAULT NULL COMMENT '拒绝原因',
   `审核员_id` bigint unsigned DEFAULT NULL COMMENT '审核员ID',
@@ -132,6 +137,7 @@ CREATE TABLE IF NOT EXISTS `creations` (
   KEY `idx_user_id` (`user_id`),
   KEY `idx_status` (`status`),
   KEY `idx_created_at` (`created_at`),
+  KEY `idx_creations_parent_asset_id` (`parent_asset_id`),
   CONSTRAINT `fk_creations_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='创作表';
 
@@ -139,21 +145,21 @@ CREATE TABLE IF NOT EXISTS `creations` (
 -- 4. 交易相关表
 -- ============================================
 
--- 挂单表（保持不变，但关联改为artworks）
+-- 挂单表（关联藏品实例）
 CREATE TABLE IF NOT EXISTS `listings` (
   `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '挂单ID',
   `seller_id` bigint unsigned NOT NULL COMMENT '卖家ID',
-  `artwork_instance_id` bigint unsigned NOT NULL COMMENT '作品实例ID',
+  `asset_instance_id` bigint unsigned NOT NULL COMMENT '藏品实例ID',
   `price` decimal(20,8) NOT NULL COMMENT '价格',
   `status` enum('active','sold','cancelled') NOT NULL DEFAULT 'active' COMMENT '状态',
   `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   PRIMARY KEY (`id`),
   KEY `idx_seller_id` (`seller_id`),
-  KEY `idx_artwork_instance_id` (`artwork_instance_id`),
+  KEY `idx_asset_instance_id` (`asset_instance_id`),
   KEY `idx_status` (`status`),
   CONSTRAINT `fk_listings_seller` FOREIGN KEY (`seller_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
-  CONSTRAINT `fk_listings_artwork_instance` FOREIGN KEY (`artwork_instance_id`) REFERENCES `artwork_instances` (`id`) ON DELETE CASCADE
+  CONSTRAINT `fk_listings_asset_instance` FOREIGN KEY (`asset_instance_id`) REFERENCES `asset_instances` (`id`) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='挂单表';
 
 -- 交易表（保持不变）
@@ -161,7 +167,7 @@ CREATE TABLE IF NOT EXISTS `trades` (
   `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '交易ID',
   `seller_id` bigint unsigned NOT NULL COMMENT '卖家ID',
   `buyer_id` bigint unsigned NOT NULL COMMENT '买家ID',
-  `artwork_instance_id` bigint unsigned NOT NULL COMMENT '作品实例ID',
+  `asset_instance_id` bigint unsigned NOT NULL COMMENT '藏品实例ID',
   `price` decimal(20,8) NOT NULL COMMENT '成交价格',
   `fee` decimal(20,8) NOT NULL DEFAULT '0.00000000' COMMENT '手续费',
   `type` enum('direct','listing','offer') NOT NULL DEFAULT 'listing' COMMENT '交易类型',
@@ -169,18 +175,18 @@ CREATE TABLE IF NOT EXISTS `trades` (
   PRIMARY KEY (`id`),
   KEY `idx_seller_id` (`seller_id`),
   KEY `idx_buyer_id` (`buyer_id`),
-  KEY `idx_artwork_instance_id` (`artwork_instance_id`),
+  KEY `idx_asset_instance_id` (`asset_instance_id`),
   KEY `idx_created_at` (`created_at`),
   CONSTRAINT `fk_trades_seller` FOREIGN KEY (`seller_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
   CONSTRAINT `fk_trades_buyer` FOREIGN KEY (`buyer_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
-  CONSTRAINT `fk_trades_artwork_instance` FOREIGN KEY (`artwork_instance_id`) REFERENCES `artwork_instances` (`id`) ON DELETE CASCADE
+  CONSTRAINT `fk_trades_asset_instance` FOREIGN KEY (`asset_instance_id`) REFERENCES `asset_instances` (`id`) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='交易表';
 
 -- 出价表（新增 - 心愿单功能）
 CREATE TABLE IF NOT EXISTS `offers` (
   `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '出价ID',
   `buyer_id` bigint unsigned NOT NULL COMMENT '买家ID',
-  `artwork_instance_id` bigint unsigned NOT NULL COMMENT '作品实例ID',
+  `asset_instance_id` bigint unsigned NOT NULL COMMENT '藏品实例ID',
   `price` decimal(20,8) NOT NULL COMMENT '出价',
   `status` enum('pending','accepted','rejected','cancelled','expired') NOT NULL DEFAULT 'pending' COMMENT '状态',
   `expires_at` timestamp NULL DEFAULT NULL COMMENT '过期时间',
@@ -189,11 +195,11 @@ CREATE TABLE IF NOT EXISTS `offers` (
   `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   PRIMARY KEY (`id`),
   KEY `idx_buyer_id` (`buyer_id`),
-  KEY `idx_artwork_instance_id` (`artwork_instance_id`),
+  KEY `idx_asset_instance_id` (`asset_instance_id`),
   KEY `idx_status` (`status`),
   KEY `idx_expires_at` (`expires_at`),
   CONSTRAINT `fk_offers_buyer` FOREIGN KEY (`buyer_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
-  CONSTRAINT `fk_offers_artwork_instance` FOREIGN KEY (`artwork_instance_id`) REFERENCES `artwork_instances` (`id`) ON DELETE CASCADE
+  CONSTRAINT `fk_offers_asset_instance` FOREIGN KEY (`asset_instance_id`) REFERENCES `asset_instances` (`id`) ON DELETE CASCADE
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='出价表';
 
 -- ============================================
@@ -287,10 +293,10 @@ CREATE TABLE IF NOT EXISTS `notifications` (
 -- 平台账户表
 CREATE TABLE IF NOT EXISTS `platform_account` (
   `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT 'ID',
-  `total_balance` decimal(20,8) NOT NULL DEFAULT '0.00000000' COMMENT '总余额',
-  `commission_income` decimal(20,8) NOT NULL DEFAULT '0.00000000' COMMENT '分成收入',
-  `fee_income` decimal(20,8) NOT NULL DEFAULT '0.00000000' COMMENT '手续费收入',
-  `total_expense` decimal(20,8) NOT NULL DEFAULT '0.00000000' COMMENT '总支出',
+  `total_balance` decimal(30,8) NOT NULL DEFAULT '0.00000000' COMMENT '总余额',
+  `commission_income` decimal(30,8) NOT NULL DEFAULT '0.00000000' COMMENT '分成收入',
+  `fee_income` decimal(30,8) NOT NULL DEFAULT '0.00000000' COMMENT '手续费收入',
+  `total_expense` decimal(30,8) NOT NULL DEFAULT '0.00000000' COMMENT '总支出',
   `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
   PRIMARY KEY (`id`)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='平台账户表';
@@ -299,10 +305,11 @@ CREATE TABLE IF NOT EXISTS `platform_account` (
 CREATE TABLE IF NOT EXISTS `platform_transactions` (
   `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '交易ID',
   `type` enum('commission','fee','expense','adjustment') NOT NULL COMMENT '交易类型',
-  `amount` decimal(20,8) NOT NULL COMMENT '金额',
-  `balance_after` decimal(20,8) NOT NULL COMMENT '交易后余额',
+  `amount` decimal(30,8) NOT NULL COMMENT '金额',
+  `balance_after` decimal(30,8) NOT NULL COMMENT '交易后余额',
   `description` varchar(255) NOT NULL COMMENT '描述',
   `related_id` bigint unsigned DEFAULT NULL COMMENT '关联ID',
+  `related_type` varchar(50) DEFAULT NULL COMMENT '关联类型',
   `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
   PRIMARY KEY (`id`),
   KEY `idx_type` (`type`),
@@ -369,7 +376,216 @@ CREATE TABLE IF NOT EXISTS `third_party_accounts` (
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='第三方账户表';
 
 -- ============================================
--- 12. 初始化数据
+-- 12. 首发相关表（新增）
+-- ============================================
+
+-- 首发配置表
+CREATE TABLE IF NOT EXISTS `primary_sales` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '首发ID',
+  `asset_id` bigint unsigned NOT NULL COMMENT '藏品ID',
+  `creation_id` bigint unsigned NOT NULL DEFAULT '0' COMMENT '来源创作ID（平台藏品为0）',
+  `creator_id` bigint unsigned NOT NULL DEFAULT '0' COMMENT '创作者ID（平台藏品为0）',
+  `price` decimal(30,8) NOT NULL COMMENT '首发价格（积分）',
+  `commission_rate` decimal(5,2) NOT NULL COMMENT '平台分成比例（%）',
+  `total_stock` int NOT NULL COMMENT '首发库存',
+  `sold_count` int NOT NULL DEFAULT '0' COMMENT '已售数量',
+  `start_at` timestamp NOT NULL COMMENT '开售时间',
+  `end_at` timestamp NULL DEFAULT NULL COMMENT '结束时间',
+  `per_user_limit` int NOT NULL DEFAULT '0' COMMENT '每人限购数量（0=不限）',
+  `require_real_name` tinyint(1) NOT NULL DEFAULT '1' COMMENT '是否要求实名',
+  `queue_enabled` tinyint(1) NOT NULL DEFAULT '0' COMMENT '是否开启排队（热门首发）',
+  `priority_start_at` timestamp NULL DEFAULT NULL COMMENT '优先购开始时间',
+  `priority_transferable` tinyint(1) NOT NULL DEFAULT '0' COMMENT '优先购权益是否可转让',
+  `status` enum('on_sale','sold_out','closed') NOT NULL DEFAULT 'on_sale' COMMENT '状态',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_primary_sales_asset_id` (`asset_id`),
+  KEY `idx_creator_id` (`creator_id`),
+  KEY `idx_status_start` (`status`,`start_at`),
+  CONSTRAINT `fk_primary_sales_asset` FOREIGN KEY (`asset_id`) REFERENCES `assets` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='首发配置表';
+
+-- 首发订单表
+CREATE TABLE IF NOT EXISTS `primary_sale_orders` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '订单ID',
+  `order_no` varchar(32) NOT NULL COMMENT '订单号',
+  `sale_id` bigint unsigned NOT NULL COMMENT '首发ID',
+  `user_id` bigint unsigned NOT NULL COMMENT '买家ID',
+  `asset_instance_id` bigint unsigned NOT NULL COMMENT '铸造的藏品实例ID',
+  `price` decimal(30,8) NOT NULL COMMENT '成交价格',
+  `creator_income` decimal(30,8) NOT NULL COMMENT '创作者分成',
+  `platform_income` decimal(30,8) NOT NULL COMMENT '平台分成',
+  `parent_royalty` decimal(30,8) NOT NULL DEFAULT '0.00000000' COMMENT '分给上游的版税（衍生作品）',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_order_no` (`order_no`),
+  KEY `idx_sale_user` (`sale_id`,`user_id`),
+  KEY `idx_user_id` (`user_id`),
+  KEY `idx_primary_sale_orders_asset_instance_id` (`asset_instance_id`),
+  CONSTRAINT `fk_primary_sale_orders_sale` FOREIGN KEY (`sale_id`) REFERENCES `primary_sales` (`id`),
+  CONSTRAINT `fk_primary_sale_orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`),
+  CONSTRAINT `fk_primary_sale_orders_asset_instance` FOREIGN KEY (`asset_instance_id`) REFERENCES `asset_instances` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='首发订单表';
+
+-- ============================================
+-- 13. 首发抽签相关表（新增）
+-- ============================================
+
+-- 首发抽签表
+CREATE TABLE IF NOT EXISTS `lotteries` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '抽签ID',
+  `sale_id` bigint unsigned NOT NULL COMMENT '首发ID',
+  `winner_count` int NOT NULL COMMENT '中签名额',
+  `register_start_at` timestamp NOT NULL COMMENT '报名开始时间',
+  `register_end_at` timestamp NOT NULL COMMENT '报名截止时间',
+  `purchase_start_at` timestamp NOT NULL COMMENT '中签购买开始时间',
+  `purchase_end_at` timestamp NOT NULL COMMENT '中签购买截止时间',
+  `bonus_asset_id` bigint unsigned DEFAULT NULL COMMENT '加签藏品ID（持有可增加签数）',
+  `tickets_per_holding` int NOT NULL DEFAULT '0' COMMENT '每持有一份增加的签数',
+  `max_tickets_per_user` int NOT NULL DEFAULT '1' COMMENT '每人签数上限',
+  `seed` varchar(64) DEFAULT NULL COMMENT '随机种子（开奖前保密）',
+  `seed_hash` char(64) DEFAULT NULL COMMENT '种子SHA-256承诺',
+  `revealed_seed` varchar(64) DEFAULT NULL COMMENT '开奖后公开的种子',
+  `entries_hash` char(64) DEFAULT NULL COMMENT '报名截止后报名名单的SHA-256，与种子共同决定开奖结果',
+  `entry_count` int NOT NULL DEFAULT '0' COMMENT '报名人数',
+  `total_tickets` int NOT NULL DEFAULT '0' COMMENT '总签数',
+  `status` enum('registering','drawn','finished','cancelled') NOT NULL DEFAULT 'registering' COMMENT '状态',
+  `drawn_at` timestamp NULL DEFAULT NULL COMMENT '开奖时间',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_sale_id` (`sale_id`),
+  KEY `idx_status_register_end` (`status`,`register_end_at`),
+  CONSTRAINT `fk_lotteries_sale` FOREIGN KEY (`sale_id`) REFERENCES `primary_sales` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='首发抽签表';
+
+-- 抽签报名表
+CREATE TABLE IF NOT EXISTS `lottery_entries` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '报名ID',
+  `lottery_id` bigint unsigned NOT NULL COMMENT '抽签ID',
+  `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+  `tickets` int NOT NULL COMMENT '签数',
+  `frozen_amount` decimal(30,8) NOT NULL COMMENT '报名冻结积分',
+  `win_rank` int NOT NULL DEFAULT '0' COMMENT '中签顺序（0=未中签）',
+  `status` enum('registered','won','lost','purchased','expired') NOT NULL DEFAULT 'registered' COMMENT '状态',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_lottery_user` (`lottery_id`,`user_id`),
+  KEY `idx_user_id` (`user_id`),
+  CONSTRAINT `fk_lottery_entries_lottery` FOREIGN KEY (`lottery_id`) REFERENCES `lotteries` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_lottery_entries_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='抽签报名表';
+
+-- ============================================
+-- 14. 优先购相关表（新增）
+-- ============================================
+
+-- 优先购权益表
+CREATE TABLE IF NOT EXISTS `priority_rights` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '权益ID',
+  `sale_id` bigint unsigned NOT NULL COMMENT '首发ID',
+  `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+  `quantity` int NOT NULL COMMENT '可优先购买份数',
+  `used_quantity` int NOT NULL DEFAULT '0' COMMENT '已使用份数',
+  `source` enum('csv','snapshot','transfer') NOT NULL COMMENT '首次发放来源',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_sale_user` (`sale_id`,`user_id`),
+  KEY `idx_user_id` (`user_id`),
+  CONSTRAINT `fk_priority_rights_sale` FOREIGN KEY (`sale_id`) REFERENCES `primary_sales` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_priority_rights_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='优先购权益表';
+
+-- 持仓快照发放任务表
+CREATE TABLE IF NOT EXISTS `priority_snapshots` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '任务ID',
+  `sale_id` bigint unsigned NOT NULL COMMENT '首发ID',
+  `asset_ids` varchar(500) DEFAULT NULL COMMENT '藏品ID（逗号分隔）',
+  `jingtan_names` varchar(1000) DEFAULT NULL COMMENT '鲸探资产名称（逗号分隔）',
+  `quantity_per_holding` int NOT NULL DEFAULT '1' COMMENT '每持有一份发放的份数',
+  `max_per_user` int NOT NULL DEFAULT '0' COMMENT '每人最多发放份数（0=不限）',
+  `snapshot_at` timestamp NOT NULL COMMENT '快照时间',
+  `status` enum('pending','done') NOT NULL DEFAULT 'pending' COMMENT '状态',
+  `granted_users` int NOT NULL DEFAULT '0' COMMENT '发放人数',
+  `executed_at` timestamp NULL DEFAULT NULL COMMENT '执行时间',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_sale_id` (`sale_id`),
+  KEY `idx_status_snapshot` (`status`,`snapshot_at`),
+  CONSTRAINT `fk_priority_snapshots_sale` FOREIGN KEY (`sale_id`) REFERENCES `primary_sales` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='持仓快照发放任务表';
+
+-- ============================================
+-- 15. 发售日历相关表（新增）
+-- ============================================
+
+-- 开售提醒订阅表
+CREATE TABLE IF NOT EXISTS `release_reminders` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '订阅ID',
+  `user_id` bigint unsigned NOT NULL COMMENT '用户ID',
+  `asset_id` bigint unsigned NOT NULL COMMENT '藏品ID',
+  `notified_at` timestamp NULL DEFAULT NULL COMMENT '提醒发送时间',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_user_asset` (`user_id`,`asset_id`),
+  KEY `idx_release_reminders_asset_id` (`asset_id`),
+  CONSTRAINT `fk_release_reminders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`) ON DELETE CASCADE,
+  CONSTRAINT `fk_release_reminders_asset` FOREIGN KEY (`asset_id`) REFERENCES `assets` (`id`) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='开售提醒订阅表';
+
+-- ============================================
+-- 16. 衍生作品相关表（新增）
+-- ============================================
+
+-- 衍生作品授权表
+CREATE TABLE IF NOT EXISTS `derivative_licenses` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '授权ID',
+  `creation_id` bigint unsigned NOT NULL COMMENT '衍生创作ID',
+  `applicant_id` bigint unsigned NOT NULL COMMENT '衍生作品创作者ID',
+  `parent_asset_id` bigint unsigned NOT NULL COMMENT '上游藏品ID',
+  `parent_creator_id` bigint unsigned NOT NULL COMMENT '上游创作者ID（平台藏品为0）',
+  `royalty_rate` decimal(5,2) NOT NULL DEFAULT '0.00' COMMENT '上游分成比例（%）',
+  `status` enum('pending','approved','rejected') NOT NULL DEFAULT 'pending' COMMENT '状态',
+  `approved_by` varchar(20) DEFAULT NULL COMMENT '审批方：creator/admin',
+  `reviewer_id` bigint unsigned DEFAULT NULL COMMENT '审批人ID',
+  `reject_reason` varchar(255) DEFAULT NULL COMMENT '拒绝原因',
+  `responded_at` timestamp NULL DEFAULT NULL COMMENT '审批时间',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  `updated_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `idx_derivative_licenses_creation_id` (`creation_id`),
+  KEY `idx_derivative_licenses_applicant_id` (`applicant_id`),
+  KEY `idx_derivative_licenses_parent_asset_id` (`parent_asset_id`),
+  KEY `idx_derivative_licenses_parent_creator_id` (`parent_creator_id`),
+  CONSTRAINT `fk_derivative_licenses_creation` FOREIGN KEY (`creation_id`) REFERENCES `creations` (`id`),
+  CONSTRAINT `fk_derivative_licenses_parent_asset` FOREIGN KEY (`parent_asset_id`) REFERENCES `assets` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='衍生作品授权表';
+
+-- 上游版税分账明细表
+CREATE TABLE IF NOT EXISTS `royalty_payouts` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT COMMENT '分账ID',
+  `source_type` varchar(30) NOT NULL COMMENT '来源：primary_sale_order/trade/blind_box_draw',
+  `source_id` bigint unsigned NOT NULL COMMENT '来源订单/交易ID',
+  `asset_id` bigint unsigned NOT NULL COMMENT '成交的衍生藏品ID',
+  `ancestor_asset_id` bigint unsigned NOT NULL COMMENT '收取分成的上游藏品ID',
+  `recipient_id` bigint unsigned NOT NULL COMMENT '上游创作者ID（平台藏品为0，计入平台账户）',
+  `depth` int NOT NULL COMMENT '上游层级（1为直接上游）',
+  `amount` decimal(30,8) NOT NULL COMMENT '分成金额',
+  `status` enum('pending','paid') NOT NULL DEFAULT 'pending' COMMENT '入账状态',
+  `created_at` timestamp NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+  PRIMARY KEY (`id`),
+  KEY `idx_royalty_payouts_source` (`source_type`,`source_id`),
+  KEY `idx_royalty_payouts_asset_id` (`asset_id`),
+  KEY `idx_royalty_payouts_ancestor_asset_id` (`ancestor_asset_id`),
+  KEY `idx_royalty_payouts_recipient_id` (`recipient_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='上游版税分账明细表';
+
+-- ============================================
+-- 17. 初始化数据
 -- ============================================
 
 -- 初始化平台账户
@@ -382,6 +598,18 @@ INSERT INTO `system_configs` (`key`, `value`, `description`) VALUES
 ('default_commission_rate', '40.00', '默认平台分成比例（%）'),
 ('trade_fee_rate', '2.00', '交易手续费比例（%）'),
 ('offer_expire_days', '7', '出价有效期（天）'),
+('primary_sale_per_user_limit', '5', '首发默认每人限购数量（0=不限）'),
+('drop_ticket_ttl_seconds', '300', '首发排队购买凭证有效期（秒）'),
+('address_max_count', '20', '每个用户最多保存的收货地址数量'),
+('release_reminder_minutes', '15', '开售提醒提前分钟数'),
+('catalog_cache_seconds', '30', '藏品目录缓存时长（秒，0=不缓存）'),
+('dashboard_cache_seconds', '30', '管理后台仪表盘统计缓存时长（秒，0=不缓存）'),
+('trade_pending_alert_minutes', '30', '交易待支付超过此分钟数在仪表盘中标记为异常'),
+('derivative_royalty_rate', '5.00', '衍生作品默认上游分成比例（%，上限50）'),
+('asset_transfer_enabled', '0', '藏品转赠功能开关（0=关闭，1=开启）'),
+('chain_confirm_seconds', '3', '模拟账本交易确认延迟（秒）'),
+('chain_confirm_timeout_minutes', '30', '上链交易确认超时（分钟），超时后重新提交'),
+('chain_anchor_max_attempts', '10', '上链任务最大尝试次数，超过后需管理员重试'),
 ('daily_signin_points', '0.00001000', '每日签到积分'),
 ('first_creation_points', '10.00000000', '首次创作奖励积分'),
 ('first_purchase_points', '5.00000000', '首次购买奖励积分'),
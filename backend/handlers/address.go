@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/regions"
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AddressHandler 定义收货地址相关的HTTP处理函数
+type AddressHandler struct {
+	AddressService *services.AddressService
+}
+
+// NewAddressHandler 创建一个新的AddressHandler实例
+func NewAddressHandler(addressService *services.AddressService) *AddressHandler {
+	return &AddressHandler{AddressService: addressService}
+}
+
+// ListAddresses 获取我的收货地址列表
+// GET /api/v1/addresses
+func (h *AddressHandler) ListAddresses(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	addresses, err := h.AddressService.ListAddresses(userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取收货地址失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list": addresses,
+		},
+	})
+}
+
+// GetAddress 获取收货地址详情
+// GET /api/v1/addresses/:id
+func (h *AddressHandler) GetAddress(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	addressID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "地址ID格式错误"})
+		return
+	}
+
+	address, err := h.AddressService.GetAddress(userID.(uint64), addressID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    address,
+	})
+}
+
+// CreateAddress 新增收货地址
+// POST /api/v1/addresses
+func (h *AddressHandler) CreateAddress(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req services.AddressInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	address, err := h.AddressService.CreateAddress(userID.(uint64), req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "新增收货地址失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "新增成功",
+		"data":    address,
+	})
+}
+
+// UpdateAddress 修改收货地址
+// PUT /api/v1/addresses/:id
+func (h *AddressHandler) UpdateAddress(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	addressID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "地址ID格式错误"})
+		return
+	}
+
+	var req services.AddressInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	address, err := h.AddressService.UpdateAddress(userID.(uint64), addressID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "修改收货地址失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "修改成功",
+		"data":    address,
+	})
+}
+
+// SetDefaultAddress 设为默认收货地址
+// PUT /api/v1/addresses/:id/default
+func (h *AddressHandler) SetDefaultAddress(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	addressID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "地址ID格式错误"})
+		return
+	}
+
+	if err := h.AddressService.SetDefaultAddress(userID.(uint64), addressID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "设置默认地址失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "设置成功"})
+}
+
+// DeleteAddress 删除收货地址
+// DELETE /api/v1/addresses/:id
+func (h *AddressHandler) DeleteAddress(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	addressID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "地址ID格式错误"})
+		return
+	}
+
+	if err := h.AddressService.DeleteAddress(userID.(uint64), addressID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "删除收货地址失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "删除成功"})
+}
+
+// ListRegions 获取下级行政区划，parent_code为空时返回省级列表
+// GET /api/v1/regions
+func (h *AddressHandler) ListRegions(c *gin.Context) {
+	list, err := regions.Children(c.Query("parent_code"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "获取地区列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list": list,
+		},
+	})
+}
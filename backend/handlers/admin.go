@@ -67,7 +67,7 @@ func (h *AdminHandler) Login(c *gin.Context) {
 	})
 }
 
-// GetProfile 处理获取管理员资料请求
+// GetProfile 处理获取管理员资料请求，包含角色拥有的权限
 func (h *AdminHandler) GetProfile(c *gin.Context) {
 	adminID, exists := c.Get("admin_id")
 	if !exists {
@@ -75,7 +75,7 @@ func (h *AdminHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	admin, err := h.AdminService.GetAdminByID(adminID.(uint64))
+	admin, err := h.AdminService.GetAdminWithPermissions(adminID.(uint64))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "管理员不存在"})
 		return
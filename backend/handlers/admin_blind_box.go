@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/models"
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// AdminBlindBoxHandler 定义盲盒管理的HTTP处理函数
+type AdminBlindBoxHandler struct {
+	BlindBoxService *services.BlindBoxService
+}
+
+// NewAdminBlindBoxHandler 创建一个新的AdminBlindBoxHandler实例
+func NewAdminBlindBoxHandler(blindBoxService *services.BlindBoxService) *AdminBlindBoxHandler {
+	return &AdminBlindBoxHandler{BlindBoxService: blindBoxService}
+}
+
+// ListBoxes 获取全部盲盒
+func (h *AdminBlindBoxHandler) ListBoxes(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	var statuses []string
+	if status := c.Query("status"); status != "" {
+		statuses = []string{status}
+	}
+
+	boxes, total, err := h.BlindBoxService.ListBoxes(statuses, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取盲盒列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      boxes,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// CreateBox 创建盲盒及档位
+func (h *AdminBlindBoxHandler) CreateBox(c *gin.Context) {
+	var req struct {
+		Name           string               `json:"name" binding:"required"`
+		Description    string               `json:"description"`
+		CoverImage     string               `json:"cover_image"`
+		Price          string               `json:"price" binding:"required"`
+		CommissionRate string               `json:"commission_rate"` // 平台分成比例（%），为空时使用系统默认值
+		StartAt        time.Time            `json:"start_at" binding:"required"`
+		EndAt          *time.Time           `json:"end_at"`
+		Tiers          []services.TierInput `json:"tiers" binding:"required,min=1,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	price, err := decimal.NewFromString(req.Price)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "价格格式错误"})
+		return
+	}
+	commissionRate := h.BlindBoxService.DefaultCommissionRate()
+	if req.CommissionRate != "" {
+		if commissionRate, err = decimal.NewFromString(req.CommissionRate); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "平台分成比例格式错误"})
+			return
+		}
+	}
+
+	box := &models.BlindBox{
+		Name:           req.Name,
+		Description:    req.Description,
+		CoverImage:     req.CoverImage,
+		Price:          price,
+		CommissionRate: commissionRate,
+		StartAt:        req.StartAt,
+		EndAt:          req.EndAt,
+	}
+	if err := h.BlindBoxService.CreateBox(box, req.Tiers); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "创建盲盒失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "创建成功",
+		"data":    box,
+	})
+}
+
+// CommitSeed 提交种子承诺并开售
+func (h *AdminBlindBoxHandler) CommitSeed(c *gin.Context) {
+	boxID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "盲盒ID格式错误"})
+		return
+	}
+
+	box, err := h.BlindBoxService.CommitSeed(boxID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "提交种子承诺失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "种子承诺已公布",
+		"data":    box,
+	})
+}
+
+// CloseBox 结束盲盒销售
+func (h *AdminBlindBoxHandler) CloseBox(c *gin.Context) {
+	boxID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "盲盒ID格式错误"})
+		return
+	}
+
+	if err := h.BlindBoxService.CloseBox(boxID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "结束盲盒失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "盲盒已结束"})
+}
+
+// RevealSeed 公开随机种子
+func (h *AdminBlindBoxHandler) RevealSeed(c *gin.Context) {
+	boxID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "盲盒ID格式错误"})
+		return
+	}
+
+	box, err := h.BlindBoxService.RevealSeed(boxID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "公开种子失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "种子已公开",
+		"data":    box,
+	})
+}
@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminChainHandler 定义上链任务管理的HTTP处理函数
+type AdminChainHandler struct {
+	ChainAnchorService *services.ChainAnchorService
+}
+
+// NewAdminChainHandler 创建一个新的AdminChainHandler实例
+func NewAdminChainHandler(chainAnchorService *services.ChainAnchorService) *AdminChainHandler {
+	return &AdminChainHandler{ChainAnchorService: chainAnchorService}
+}
+
+// ListAnchors 获取上链任务列表，可按状态筛选
+// GET /admin/chain-anchors
+func (h *AdminChainHandler) ListAnchors(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	anchors, total, err := h.ChainAnchorService.ListAnchors(c.Query("status"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取上链任务失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      anchors,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// RetryAnchor 重试失败的上链任务
+// POST /admin/chain-anchors/:id/retry
+func (h *AdminChainHandler) RetryAnchor(c *gin.Context) {
+	anchorID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "任务ID格式错误"})
+		return
+	}
+
+	if err := h.ChainAnchorService.RetryAnchor(anchorID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "重试失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "已重新排队"})
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/models"
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminCollectionHandler 定义藏品集合管理的HTTP处理函数
+type AdminCollectionHandler struct {
+	CollectionService *services.CollectionService
+}
+
+// NewAdminCollectionHandler 创建一个新的AdminCollectionHandler实例
+func NewAdminCollectionHandler(collectionService *services.CollectionService) *AdminCollectionHandler {
+	return &AdminCollectionHandler{CollectionService: collectionService}
+}
+
+// ListCollections 获取全部集合
+func (h *AdminCollectionHandler) ListCollections(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	collections, total, err := h.CollectionService.ListCollections(c.Query("status"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取集合列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      collections,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// CreateCollection 创建集合
+func (h *AdminCollectionHandler) CreateCollection(c *gin.Context) {
+	var req struct {
+		Name        string `json:"name" binding:"required,max=100"`
+		Description string `json:"description" binding:"max=500"`
+		CoverImage  string `json:"cover_image"`
+		BannerImage string `json:"banner_image"`
+		CreatorID   uint64 `json:"creator_id"`
+		CreatorName string `json:"creator_name" binding:"max=50"`
+		SortOrder   int    `json:"sort_order"`
+		Status      string `json:"status" binding:"omitempty,oneof=active inactive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	collection := &models.Collection{
+		Name:        req.Name,
+		Description: req.Description,
+		CoverImage:  req.CoverImage,
+		BannerImage: req.BannerImage,
+		CreatorID:   req.CreatorID,
+		CreatorName: req.CreatorName,
+		SortOrder:   req.SortOrder,
+		Status:      req.Status,
+	}
+	if err := h.CollectionService.CreateCollection(collection); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "创建集合失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "创建成功",
+		"data":    collection,
+	})
+}
+
+// UpdateCollection 更新集合（信息、图片、创作者、展示顺序、上下架）
+func (h *AdminCollectionHandler) UpdateCollection(c *gin.Context) {
+	collectionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "集合ID格式错误"})
+		return
+	}
+
+	var req struct {
+		Name        *string `json:"name" binding:"omitempty,min=1,max=100"`
+		Description *string `json:"description" binding:"omitempty,max=500"`
+		CoverImage  *string `json:"cover_image"`
+		BannerImage *string `json:"banner_image"`
+		CreatorID   *uint64 `json:"creator_id"`
+		CreatorName *string `json:"creator_name" binding:"omitempty,max=50"`
+		SortOrder   *int    `json:"sort_order"`
+		Status      *string `json:"status" binding:"omitempty,oneof=active inactive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.Name != nil {
+		updates["name"] = *req.Name
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.CoverImage != nil {
+		updates["cover_image"] = *req.CoverImage
+	}
+	if req.BannerImage != nil {
+		updates["banner_image"] = *req.BannerImage
+	}
+	if req.CreatorID != nil {
+		updates["creator_id"] = *req.CreatorID
+	}
+	if req.CreatorName != nil {
+		updates["creator_name"] = *req.CreatorName
+	}
+	if req.SortOrder != nil {
+		updates["sort_order"] = *req.SortOrder
+	}
+	if req.Status != nil {
+		updates["status"] = *req.Status
+	}
+
+	collection, err := h.CollectionService.UpdateCollection(collectionID, updates)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "更新集合失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "更新成功",
+		"data":    collection,
+	})
+}
+
+// DeleteCollection 删除集合（集合下没有藏品时）
+func (h *AdminCollectionHandler) DeleteCollection(c *gin.Context) {
+	collectionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "集合ID格式错误"})
+		return
+	}
+
+	if err := h.CollectionService.DeleteCollection(collectionID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "删除集合失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "删除成功"})
+}
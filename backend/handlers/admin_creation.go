@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"hoho-miniapp/backend/services"
 
@@ -66,7 +68,7 @@ func (h *AdminCreationHandler) ApproveCreation(c *gin.Context) {
 	adminID, _ := c.Get("admin_id")
 	creationID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
 	
-	if err := h.creationService.ApproveCreation(uint(creationID), adminID.(uint)); err != nil {
+	if err := h.creationService.ApproveCreation(uint(creationID), uint(adminID.(uint64))); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -88,10 +90,31 @@ func (h *AdminCreationHandler) RejectCreation(c *gin.Context) {
 		return
 	}
 	
-	if err := h.creationService.RejectCreation(uint(creationID), adminID.(uint), req.Reason); err != nil {
+	if err := h.creationService.RejectCreation(uint(creationID), uint(adminID.(uint64)), req.Reason); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 	
 	c.JSON(http.StatusOK, gin.H{"message": "已拒绝"})
 }
+
+// PublishCreation 发布创作并开启首发
+func (h *AdminCreationHandler) PublishCreation(c *gin.Context) {
+	creationID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
+	
+	var req struct {
+		ReleaseDate *time.Time `json:"release_date"`
+	}
+	
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	
+	if err := h.creationService.PublishCreation(uint(creationID), req.ReleaseDate); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{"message": "发布成功"})
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminDashboardHandler 定义管理后台仪表盘的HTTP处理函数
+type AdminDashboardHandler struct {
+	DashboardService *services.DashboardService
+}
+
+// NewAdminDashboardHandler 创建一个新的AdminDashboardHandler实例
+func NewAdminDashboardHandler(dashboardService *services.DashboardService) *AdminDashboardHandler {
+	return &AdminDashboardHandler{DashboardService: dashboardService}
+}
+
+// DashboardPage 渲染仪表盘页面
+// GET /admin/dashboard?range=today|7d|30d
+func (h *AdminDashboardHandler) DashboardPage(c *gin.Context) {
+	stats, err := h.DashboardService.GetStats(c.Query("range"))
+	if err != nil {
+		c.String(http.StatusInternalServerError, "获取仪表盘统计失败: "+err.Error())
+		return
+	}
+
+	c.HTML(http.StatusOK, "admin_dashboard.html", gin.H{
+		"Title":      "仪表盘",
+		"ActiveMenu": "dashboard",
+		"Stats":      stats,
+	})
+}
+
+// GetStats 获取仪表盘统计（JSON，供管理后台前端使用）
+// GET /admin/dashboard/stats?range=today|7d|30d
+func (h *AdminDashboardHandler) GetStats(c *gin.Context) {
+	stats, err := h.DashboardService.GetStats(c.Query("range"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "获取仪表盘统计失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    stats,
+	})
+}
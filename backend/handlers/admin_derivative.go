@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminDerivativeHandler 定义衍生作品授权管理的HTTP处理函数
+type AdminDerivativeHandler struct {
+	DerivativeService *services.DerivativeService
+}
+
+// NewAdminDerivativeHandler 创建一个新的AdminDerivativeHandler实例
+func NewAdminDerivativeHandler(derivativeService *services.DerivativeService) *AdminDerivativeHandler {
+	return &AdminDerivativeHandler{DerivativeService: derivativeService}
+}
+
+// ListLicenses 获取衍生授权申请列表
+func (h *AdminDerivativeHandler) ListLicenses(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	licenses, total, err := h.DerivativeService.ListLicenses(c.Query("status"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取授权申请失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      licenses,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// ApproveLicense 管理员批准衍生授权
+func (h *AdminDerivativeHandler) ApproveLicense(c *gin.Context) {
+	adminID, _ := c.Get("admin_id")
+	licenseID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "授权ID格式错误"})
+		return
+	}
+	var req struct {
+		RoyaltyRate string `json:"royalty_rate"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	license, err := h.DerivativeService.ApproveByAdmin(licenseID, adminID.(uint64), req.RoyaltyRate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "授权失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "已授权",
+		"data":    license,
+	})
+}
+
+// RejectLicense 管理员拒绝衍生授权
+func (h *AdminDerivativeHandler) RejectLicense(c *gin.Context) {
+	adminID, _ := c.Get("admin_id")
+	licenseID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "授权ID格式错误"})
+		return
+	}
+	var req struct {
+		Reason string `json:"reason" binding:"required,max=255"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	license, err := h.DerivativeService.RejectByAdmin(licenseID, adminID.(uint64), req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "拒绝授权失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "已拒绝",
+		"data":    license,
+	})
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminEventHandler 定义社区事件管理的HTTP处理函数
+type AdminEventHandler struct {
+	EventService *services.EventService
+}
+
+// NewAdminEventHandler 创建一个新的AdminEventHandler实例
+func NewAdminEventHandler(eventService *services.EventService) *AdminEventHandler {
+	return &AdminEventHandler{EventService: eventService}
+}
+
+// VerifyChain 校验社区事件哈希链并报告断点
+// GET /admin/events/verify-chain
+func (h *AdminEventHandler) VerifyChain(c *gin.Context) {
+	report, err := h.EventService.VerifyChain()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "校验事件哈希链失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    report,
+	})
+}
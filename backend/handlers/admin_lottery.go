@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminLotteryHandler 定义首发抽签管理的HTTP处理函数
+type AdminLotteryHandler struct {
+	LotteryService *services.LotteryService
+}
+
+// NewAdminLotteryHandler 创建一个新的AdminLotteryHandler实例
+func NewAdminLotteryHandler(lotteryService *services.LotteryService) *AdminLotteryHandler {
+	return &AdminLotteryHandler{LotteryService: lotteryService}
+}
+
+// ListLotteries 获取全部抽签
+func (h *AdminLotteryHandler) ListLotteries(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	lotteries, total, err := h.LotteryService.ListLotteries(c.Query("status"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取抽签列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      lotteries,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// CreateLottery 为首发创建抽签
+func (h *AdminLotteryHandler) CreateLottery(c *gin.Context) {
+	var req services.LotteryInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	lottery, err := h.LotteryService.CreateLottery(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "创建抽签失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "创建成功",
+		"data":    lottery,
+	})
+}
+
+// Draw 立即开奖（报名截止后）
+func (h *AdminLotteryHandler) Draw(c *gin.Context) {
+	lotteryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "抽签ID格式错误"})
+		return
+	}
+
+	lottery, err := h.LotteryService.Draw(lotteryID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "开奖失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "开奖成功",
+		"data":    lottery,
+	})
+}
+
+// CancelLottery 取消抽签并解冻报名积分
+func (h *AdminLotteryHandler) CancelLottery(c *gin.Context) {
+	lotteryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "抽签ID格式错误"})
+		return
+	}
+
+	if err := h.LotteryService.CancelLottery(lotteryID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "取消抽签失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "抽签已取消"})
+}
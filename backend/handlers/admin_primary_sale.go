@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminPrimarySaleHandler 定义作品首发管理的HTTP处理函数
+type AdminPrimarySaleHandler struct {
+	PrimarySaleService *services.PrimarySaleService
+}
+
+// NewAdminPrimarySaleHandler 创建一个新的AdminPrimarySaleHandler实例
+func NewAdminPrimarySaleHandler(primarySaleService *services.PrimarySaleService) *AdminPrimarySaleHandler {
+	return &AdminPrimarySaleHandler{PrimarySaleService: primarySaleService}
+}
+
+// ListSales 获取全部首发
+func (h *AdminPrimarySaleHandler) ListSales(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	sales, total, err := h.PrimarySaleService.ListSales(c.Query("status"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取首发列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      sales,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// UpdateSale 修改首发时间、限购、实名要求、排队开关和优先购设置
+func (h *AdminPrimarySaleHandler) UpdateSale(c *gin.Context) {
+	saleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "首发ID格式错误"})
+		return
+	}
+
+	var req struct {
+		StartAt              *time.Time `json:"start_at"`
+		EndAt                *time.Time `json:"end_at"`
+		PerUserLimit         *int       `json:"per_user_limit" binding:"omitempty,min=0"`
+		RequireRealName      *bool      `json:"require_real_name"`
+		QueueEnabled         *bool      `json:"queue_enabled"`
+		PriorityStartAt      *time.Time `json:"priority_start_at"`
+		PriorityTransferable *bool      `json:"priority_transferable"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.StartAt != nil {
+		updates["start_at"] = *req.StartAt
+	}
+	if req.EndAt != nil {
+		updates["end_at"] = *req.EndAt
+	}
+	if req.PerUserLimit != nil {
+		updates["per_user_limit"] = *req.PerUserLimit
+	}
+	if req.RequireRealName != nil {
+		updates["require_real_name"] = *req.RequireRealName
+	}
+	if req.QueueEnabled != nil {
+		updates["queue_enabled"] = *req.QueueEnabled
+	}
+	if req.PriorityStartAt != nil {
+		updates["priority_start_at"] = *req.PriorityStartAt
+	}
+	if req.PriorityTransferable != nil {
+		updates["priority_transferable"] = *req.PriorityTransferable
+	}
+
+	sale, err := h.PrimarySaleService.UpdateSale(saleID, updates)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "修改首发失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "修改成功",
+		"data":    sale,
+	})
+}
+
+// CloseSale 提前结束首发
+func (h *AdminPrimarySaleHandler) CloseSale(c *gin.Context) {
+	saleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "首发ID格式错误"})
+		return
+	}
+
+	if err := h.PrimarySaleService.CloseSale(saleID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "结束首发失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "首发已结束"})
+}
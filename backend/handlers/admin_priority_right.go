@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminPriorityRightHandler 定义优先购名单管理的HTTP处理函数
+type AdminPriorityRightHandler struct {
+	PriorityRightService *services.PriorityRightService
+}
+
+// NewAdminPriorityRightHandler 创建一个新的AdminPriorityRightHandler实例
+func NewAdminPriorityRightHandler(priorityRightService *services.PriorityRightService) *AdminPriorityRightHandler {
+	return &AdminPriorityRightHandler{PriorityRightService: priorityRightService}
+}
+
+// ListRights 获取首发的优先购名单
+func (h *AdminPriorityRightHandler) ListRights(c *gin.Context) {
+	saleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "首发ID格式错误"})
+		return
+	}
+	page, pageSize := parsePagination(c)
+
+	rights, total, err := h.PriorityRightService.ListSaleRights(saleID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取优先购名单失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      rights,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// ImportCSV 上传CSV导入优先购名单
+func (h *AdminPriorityRightHandler) ImportCSV(c *gin.Context) {
+	saleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "首发ID格式错误"})
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请上传CSV文件"})
+		return
+	}
+	if file.Size > 5*1024*1024 {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "文件大小不能超过5MB"})
+		return
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "文件读取失败"})
+		return
+	}
+	defer src.Close()
+
+	result, err := h.PriorityRightService.ImportCSV(saleID, src)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "导入失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "导入完成",
+		"data":    result,
+	})
+}
+
+// ListSnapshots 获取首发的持仓快照任务
+func (h *AdminPriorityRightHandler) ListSnapshots(c *gin.Context) {
+	saleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "首发ID格式错误"})
+		return
+	}
+
+	snapshots, err := h.PriorityRightService.ListSnapshots(saleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取快照任务失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    snapshots,
+	})
+}
+
+// CreateSnapshot 创建持仓快照发放任务
+func (h *AdminPriorityRightHandler) CreateSnapshot(c *gin.Context) {
+	saleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "首发ID格式错误"})
+		return
+	}
+
+	var req services.SnapshotInput
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	snapshot, err := h.PriorityRightService.CreateSnapshot(saleID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "创建快照任务失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "创建成功",
+		"data":    snapshot,
+	})
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/models"
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminRedemptionHandler 定义实物兑换管理的HTTP处理函数
+type AdminRedemptionHandler struct {
+	RedemptionService *services.RedemptionService
+}
+
+// NewAdminRedemptionHandler 创建一个新的AdminRedemptionHandler实例
+func NewAdminRedemptionHandler(redemptionService *services.RedemptionService) *AdminRedemptionHandler {
+	return &AdminRedemptionHandler{RedemptionService: redemptionService}
+}
+
+// ListItems 获取全部兑换配置
+func (h *AdminRedemptionHandler) ListItems(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	items, total, err := h.RedemptionService.ListRedeemableItems(false, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取兑换配置失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      items,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// CreateItem 将藏品设置为可兑换实物
+func (h *AdminRedemptionHandler) CreateItem(c *gin.Context) {
+	var req struct {
+		AssetID      uint64     `json:"asset_id" binding:"required"`
+		ItemName     string     `json:"item_name" binding:"required"`
+		ItemImage    string     `json:"item_image"`
+		Description  string     `json:"description"`
+		Stock        int        `json:"stock" binding:"required,min=1"`
+		StartAt      *time.Time `json:"start_at"`
+		EndAt        *time.Time `json:"end_at"`
+		BurnOnRedeem bool       `json:"burn_on_redeem"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	item := &models.RedeemableItem{
+		AssetID:      req.AssetID,
+		ItemName:     req.ItemName,
+		ItemImage:    req.ItemImage,
+		Description:  req.Description,
+		Stock:        req.Stock,
+		StartAt:      req.StartAt,
+		EndAt:        req.EndAt,
+		BurnOnRedeem: req.BurnOnRedeem,
+	}
+	if err := h.RedemptionService.CreateRedeemableItem(item); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "创建兑换配置失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "创建成功",
+		"data":    item,
+	})
+}
+
+// UpdateItem 更新兑换配置（库存、时间窗口、上下架）
+func (h *AdminRedemptionHandler) UpdateItem(c *gin.Context) {
+	itemID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "兑换配置ID格式错误"})
+		return
+	}
+
+	var req struct {
+		ItemName    *string    `json:"item_name"`
+		ItemImage   *string    `json:"item_image"`
+		Description *string    `json:"description"`
+		Stock       *int       `json:"stock" binding:"omitempty,min=1"`
+		StartAt     *time.Time `json:"start_at"`
+		EndAt       *time.Time `json:"end_at"`
+		Status      *string    `json:"status" binding:"omitempty,oneof=active inactive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if req.ItemName != nil {
+		updates["item_name"] = *req.ItemName
+	}
+	if req.ItemImage != nil {
+		updates["item_image"] = *req.ItemImage
+	}
+	if req.Description != nil {
+		updates["description"] = *req.Description
+	}
+	if req.Stock != nil {
+		updates["stock"] = *req.Stock
+	}
+	if req.StartAt != nil {
+		updates["start_at"] = *req.StartAt
+	}
+	if req.EndAt != nil {
+		updates["end_at"] = *req.EndAt
+	}
+	if req.Status != nil {
+		updates["status"] = *req.Status
+	}
+
+	item, err := h.RedemptionService.UpdateRedeemableItem(itemID, updates)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "更新兑换配置失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "更新成功",
+		"data":    item,
+	})
+}
+
+// ListOrders 获取兑换订单列表
+func (h *AdminRedemptionHandler) ListOrders(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	orders, total, err := h.RedemptionService.ListOrders(c.Query("status"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取兑换订单失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      orders,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// UpdateOrderStatus 推进兑换订单履约状态（发货、签收、售后、关闭）
+func (h *AdminRedemptionHandler) UpdateOrderStatus(c *gin.Context) {
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "订单ID格式错误"})
+		return
+	}
+
+	var req struct {
+		Status     string `json:"status" binding:"required,oneof=shipped delivered after_sale closed"`
+		Carrier    string `json:"carrier"`
+		TrackingNo string `json:"tracking_no"`
+		Remark     string `json:"remark"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	order, err := h.RedemptionService.UpdateOrderStatus(orderID, req.Status, req.Carrier, req.TrackingNo, req.Remark)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "更新订单状态失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "更新成功",
+		"data":    order,
+	})
+}
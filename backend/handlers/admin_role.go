@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminRoleHandler 定义管理员及角色管理的HTTP处理函数（仅超级管理员）
+type AdminRoleHandler struct {
+	AdminService     *services.AdminService
+	AdminRoleService *services.AdminRoleService
+}
+
+// NewAdminRoleHandler 创建一个新的AdminRoleHandler实例
+func NewAdminRoleHandler(adminService *services.AdminService, adminRoleService *services.AdminRoleService) *AdminRoleHandler {
+	return &AdminRoleHandler{
+		AdminService:     adminService,
+		AdminRoleService: adminRoleService,
+	}
+}
+
+// ListAdmins 获取管理员列表
+// GET /admin/admins
+func (h *AdminRoleHandler) ListAdmins(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	admins, total, err := h.AdminService.ListAdmins(page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取管理员列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      admins,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// CreateAdmin 创建管理员
+// POST /admin/admins
+func (h *AdminRoleHandler) CreateAdmin(c *gin.Context) {
+	var req struct {
+		Username string `json:"username" binding:"required"`
+		Password string `json:"password" binding:"required"`
+		Email    string `json:"email"`
+		Role     string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	admin, err := h.AdminService.CreateAdmin(req.Username, req.Password, req.Email, req.Role)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "创建管理员失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "创建成功",
+		"data":    admin,
+	})
+}
+
+// UpdateAdmin 修改管理员的邮箱、角色、状态或密码
+// PUT /admin/admins/:id
+func (h *AdminRoleHandler) UpdateAdmin(c *gin.Context) {
+	adminID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "管理员ID格式错误"})
+		return
+	}
+
+	var req services.UpdateAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	operatorID, _ := c.Get("admin_id")
+	admin, err := h.AdminService.UpdateAdmin(operatorID.(uint64), adminID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "修改管理员失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "修改成功",
+		"data":    admin,
+	})
+}
+
+// ListRoles 获取角色列表及全部可用权限
+// GET /admin/roles
+func (h *AdminRoleHandler) ListRoles(c *gin.Context) {
+	roles, err := h.AdminRoleService.ListRoles()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取角色列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":        roles,
+			"permissions": services.AdminPermissions,
+		},
+	})
+}
+
+// roleRequest 创建或修改角色的请求体
+type roleRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+// CreateRole 创建自定义角色
+// POST /admin/roles
+func (h *AdminRoleHandler) CreateRole(c *gin.Context) {
+	var req roleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	role, err := h.AdminRoleService.CreateRole(req.Name, req.Description, req.Permissions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "创建角色失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "创建成功",
+		"data":    role,
+	})
+}
+
+// UpdateRole 修改角色说明和权限
+// PUT /admin/roles/:name
+func (h *AdminRoleHandler) UpdateRole(c *gin.Context) {
+	var req roleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	role, err := h.AdminRoleService.UpdateRole(c.Param("name"), req.Description, req.Permissions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "修改角色失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "修改成功",
+		"data":    role,
+	})
+}
+
+// DeleteRole 删除自定义角色
+// DELETE /admin/roles/:name
+func (h *AdminRoleHandler) DeleteRole(c *gin.Context) {
+	if err := h.AdminRoleService.DeleteRole(c.Param("name")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "删除角色失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "删除成功",
+	})
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminSynthesisHandler 定义合成配方管理的HTTP处理函数
+type AdminSynthesisHandler struct {
+	SynthesisService *services.SynthesisService
+}
+
+// NewAdminSynthesisHandler 创建一个新的AdminSynthesisHandler实例
+func NewAdminSynthesisHandler(synthesisService *services.SynthesisService) *AdminSynthesisHandler {
+	return &AdminSynthesisHandler{SynthesisService: synthesisService}
+}
+
+// ListRecipes 获取全部合成配方（管理员）
+func (h *AdminSynthesisHandler) ListRecipes(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	recipes, total, err := h.SynthesisService.ListRecipes(false, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取合成配方失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      recipes,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// CreateRecipe 创建合成配方
+func (h *AdminSynthesisHandler) CreateRecipe(c *gin.Context) {
+	var req struct {
+		Name          string                 `json:"name" binding:"required"`
+		Description   string                 `json:"description"`
+		OutputAssetID uint64                 `json:"output_asset_id" binding:"required"`
+		OutputCount   int                    `json:"output_count" binding:"required,min=1"`
+		StartAt       *time.Time             `json:"start_at"`
+		EndAt         *time.Time             `json:"end_at"`
+		MaxTimes      int                    `json:"max_times" binding:"min=0"`
+		Inputs        []services.RecipeInput `json:"inputs" binding:"required,min=1,dive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	recipe, err := h.SynthesisService.CreateRecipe(req.Name, req.Description, req.OutputAssetID, req.OutputCount, req.StartAt, req.EndAt, req.MaxTimes, req.Inputs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "创建合成配方失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "创建成功",
+		"data":    recipe,
+	})
+}
+
+// UpdateRecipeStatus 启用/停用合成配方
+func (h *AdminSynthesisHandler) UpdateRecipeStatus(c *gin.Context) {
+	recipeID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "配方ID格式错误"})
+		return
+	}
+
+	var req struct {
+		Status string `json:"status" binding:"required,oneof=active inactive"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	if err := h.SynthesisService.UpdateRecipeStatus(recipeID, req.Status); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "更新配方状态失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "更新成功"})
+}
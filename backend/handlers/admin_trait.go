@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminTraitHandler 定义藏品属性管理的HTTP处理函数
+type AdminTraitHandler struct {
+	TraitService *services.TraitService
+}
+
+// NewAdminTraitHandler 创建一个新的AdminTraitHandler实例
+func NewAdminTraitHandler(traitService *services.TraitService) *AdminTraitHandler {
+	return &AdminTraitHandler{TraitService: traitService}
+}
+
+// setTraitsRequest 替换属性的请求体，traits为空数组时清空属性
+type setTraitsRequest struct {
+	Traits []services.TraitInput `json:"traits"`
+}
+
+// SetAssetTraits 替换藏品级属性（对该藏品全部实例生效）
+func (h *AdminTraitHandler) SetAssetTraits(c *gin.Context) {
+	assetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "藏品ID格式错误"})
+		return
+	}
+	var req setTraitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	traits, err := h.TraitService.SetAssetTraits(assetID, req.Traits)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "设置藏品属性失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "设置成功",
+		"data":    traits,
+	})
+}
+
+// SetInstanceTraits 替换实例级属性（覆盖同类型的藏品级属性，如特殊编号）
+func (h *AdminTraitHandler) SetInstanceTraits(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "实例ID格式错误"})
+		return
+	}
+	var req setTraitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	traits, err := h.TraitService.SetInstanceTraits(instanceID, req.Traits)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "设置实例属性失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "设置成功",
+		"data":    traits,
+	})
+}
+
+// RecomputeRarity 手动重算集合稀有度
+func (h *AdminTraitHandler) RecomputeRarity(c *gin.Context) {
+	collectionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "集合ID格式错误"})
+		return
+	}
+
+	if err := h.TraitService.RecomputeCollectionRarity(collectionID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "重算稀有度失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "重算完成",
+	})
+}
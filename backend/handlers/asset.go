@@ -10,12 +10,13 @@ import (
 
 // AssetHandler 定义藏品相关的HTTP处理函数
 type AssetHandler struct {
-	AssetService *services.AssetService
+	AssetService   *services.AssetService
+	CatalogService *services.CatalogService
 }
 
 // NewAssetHandler 创建一个新的AssetHandler实例
-func NewAssetHandler(assetService *services.AssetService) *AssetHandler {
-	return &AssetHandler{AssetService: assetService}
+func NewAssetHandler(assetService *services.AssetService, catalogService *services.CatalogService) *AssetHandler {
+	return &AssetHandler{AssetService: assetService, CatalogService: catalogService}
 }
 
 // SubmitMintRequest 提交铸造请求
@@ -50,9 +51,36 @@ func (h *AssetHandler) SubmitMintRequest(c *gin.Context) {
 }
 
 // ListAssets 处理获取藏品列表请求
+// GET /api/v1/assets?source=&collection_id=&sort=recommended|trending|new&cursor=&limit=
 func (h *AssetHandler) ListAssets(c *gin.Context) {
-	// TODO: 实现分页、筛选、排序逻辑
-	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "藏品列表 - TODO"})
+	var collectionID uint64
+	if value := c.Query("collection_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "集合ID格式错误"})
+			return
+		}
+		collectionID = id
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	page, err := h.CatalogService.ListAssets(services.CatalogQuery{
+		Source:       c.Query("source"),
+		CollectionID: collectionID,
+		Sort:         c.Query("sort"),
+		Cursor:       c.Query("cursor"),
+		Limit:        limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "获取藏品列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    page,
+	})
 }
 
 // GetAssetDetail 处理获取藏品详情请求
@@ -92,6 +120,7 @@ func (h *AssetHandler) GetMyAssets(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
 	status := c.Query("status") // 可选: "in_wallet", "on_sale", "pending_trade"
+	sortBy := c.Query("sort")   // 可选: "rarity"
 
 	if page < 1 {
 		page = 1
@@ -100,8 +129,19 @@ func (h *AssetHandler) GetMyAssets(c *gin.Context) {
 		pageSize = 20
 	}
 
+	// 属性筛选，如 trait=背景:金色
+	traits, err := services.ParseTraitFilters(c.QueryArray("trait"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"code":    400,
+			"message": "请求参数错误",
+			"details": err.Error(),
+		})
+		return
+	}
+
 	// 调用service获取用户的作品实例
-	instances, total, err := h.AssetService.GetUserAssetInstances(userID.(uint64), page, pageSize, status)
+	instances, total, err := h.AssetService.GetUserAssetInstances(userID.(uint64), page, pageSize, status, traits, sortBy)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"code":    500,
@@ -122,3 +162,33 @@ func (h *AssetHandler) GetMyAssets(c *gin.Context) {
 		},
 	})
 }
+
+// TransferInstance 转赠藏品实例
+// POST /api/v1/asset-instances/:id/transfer
+func (h *AssetHandler) TransferInstance(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "实例ID格式错误"})
+		return
+	}
+	var req struct {
+		ToUID string `json:"to_uid" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	instance, err := h.AssetService.TransferInstance(userID.(uint64), instanceID, req.ToUID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "转赠失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "转赠成功",
+		"data":    instance,
+	})
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BlindBoxHandler 定义盲盒相关的HTTP处理函数
+type BlindBoxHandler struct {
+	BlindBoxService *services.BlindBoxService
+}
+
+// NewBlindBoxHandler 创建一个新的BlindBoxHandler实例
+func NewBlindBoxHandler(blindBoxService *services.BlindBoxService) *BlindBoxHandler {
+	return &BlindBoxHandler{BlindBoxService: blindBoxService}
+}
+
+// ListBoxes 获取盲盒列表（不含草稿）
+// GET /api/v1/blind-boxes
+func (h *BlindBoxHandler) ListBoxes(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	boxes, total, err := h.BlindBoxService.ListBoxes([]string{"on_sale", "closed", "revealed"}, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取盲盒列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      boxes,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// GetBox 获取盲盒详情（含档位和种子承诺）
+// GET /api/v1/blind-boxes/:id
+func (h *BlindBoxHandler) GetBox(c *gin.Context) {
+	boxID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "盲盒ID格式错误"})
+		return
+	}
+
+	box, err := h.BlindBoxService.GetBox(boxID)
+	if err != nil || box.Status == "draft" {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "盲盒不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    box,
+	})
+}
+
+// VerifyBox 验证盲盒抽取结果
+// GET /api/v1/blind-boxes/:id/verify
+func (h *BlindBoxHandler) VerifyBox(c *gin.Context) {
+	boxID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "盲盒ID格式错误"})
+		return
+	}
+
+	result, err := h.BlindBoxService.VerifyBox(boxID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "验证失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    result,
+	})
+}
+
+// OpenBox 购买并开启盲盒
+// POST /api/v1/blind-boxes/:id/open
+func (h *BlindBoxHandler) OpenBox(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	boxID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "盲盒ID格式错误"})
+		return
+	}
+
+	draw, err := h.BlindBoxService.OpenBox(userID.(uint64), boxID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "开启盲盒失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "开启成功",
+		"data":    draw,
+	})
+}
+
+// GetMyDraws 获取我的盲盒抽取记录
+// GET /api/v1/my/blind-box-draws
+func (h *BlindBoxHandler) GetMyDraws(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	page, pageSize := parsePagination(c)
+
+	draws, total, err := h.BlindBoxService.GetUserDraws(userID.(uint64), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取抽取记录失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      draws,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CalendarHandler 定义发售日历相关的HTTP处理函数
+type CalendarHandler struct {
+	CalendarService *services.CalendarService
+}
+
+// NewCalendarHandler 创建一个新的CalendarHandler实例
+func NewCalendarHandler(calendarService *services.CalendarService) *CalendarHandler {
+	return &CalendarHandler{CalendarService: calendarService}
+}
+
+// GetCalendar 获取发售日历（按天分组）
+// GET /api/v1/calendar?from=2006-01-02&to=2006-01-02
+func (h *CalendarHandler) GetCalendar(c *gin.Context) {
+	h.renderCalendar(c, 0)
+}
+
+// GetMyCalendar 获取发售日历，并标记我已订阅提醒的发售
+// GET /api/v1/my/calendar
+func (h *CalendarHandler) GetMyCalendar(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	h.renderCalendar(c, userID.(uint64))
+}
+
+// ExportICS 导出未来60天的发售日历（iCalendar格式）
+// GET /api/v1/calendar/ics
+func (h *CalendarHandler) ExportICS(c *gin.Context) {
+	items, err := h.CalendarService.GetUpcoming(60)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "导出日历失败", "details": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="hoho-releases.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", h.CalendarService.ExportICS(items))
+}
+
+// ExportMyICS 导出我订阅提醒的发售（iCalendar格式）
+// GET /api/v1/my/calendar/ics
+func (h *CalendarHandler) ExportMyICS(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	items, err := h.CalendarService.GetUserReminders(userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "导出日历失败", "details": err.Error()})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="hoho-my-releases.ics"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", h.CalendarService.ExportICS(items))
+}
+
+// GetMyReminders 获取我订阅的开售提醒
+// GET /api/v1/my/release-reminders
+func (h *CalendarHandler) GetMyReminders(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	items, err := h.CalendarService.GetUserReminders(userID.(uint64))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取开售提醒失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    items,
+	})
+}
+
+// Subscribe 订阅开售提醒
+// POST /api/v1/calendar/:asset_id/reminder
+func (h *CalendarHandler) Subscribe(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	assetID, err := strconv.ParseUint(c.Param("asset_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "藏品ID格式错误"})
+		return
+	}
+
+	reminder, err := h.CalendarService.Subscribe(userID.(uint64), assetID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "订阅提醒失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "订阅成功",
+		"data":    reminder,
+	})
+}
+
+// Unsubscribe 取消开售提醒
+// DELETE /api/v1/calendar/:asset_id/reminder
+func (h *CalendarHandler) Unsubscribe(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	assetID, err := strconv.ParseUint(c.Param("asset_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "藏品ID格式错误"})
+		return
+	}
+
+	if err := h.CalendarService.Unsubscribe(userID.(uint64), assetID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "取消提醒失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "已取消提醒"})
+}
+
+// renderCalendar 解析日期范围并返回日历
+func (h *CalendarHandler) renderCalendar(c *gin.Context, userID uint64) {
+	from, to, err := parseCalendarRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "日期格式错误", "details": err.Error()})
+		return
+	}
+
+	days, err := h.CalendarService.GetCalendar(userID, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "获取发售日历失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    days,
+	})
+}
+
+// parseCalendarRange 解析日期范围（含首尾两天），默认为过去7天至未来30天
+func parseCalendarRange(c *gin.Context) (time.Time, time.Time, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+
+	from := today.AddDate(0, 0, -7)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("from 应为 YYYY-MM-DD 格式")
+		}
+		from = parsed
+	}
+
+	to := today.AddDate(0, 0, 30)
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("to 应为 YYYY-MM-DD 格式")
+		}
+		to = parsed
+	}
+
+	return from, to.AddDate(0, 0, 1), nil
+}
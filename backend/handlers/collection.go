@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CollectionHandler 定义藏品集合相关的HTTP处理函数
+type CollectionHandler struct {
+	CollectionService *services.CollectionService
+}
+
+// NewCollectionHandler 创建一个新的CollectionHandler实例
+func NewCollectionHandler(collectionService *services.CollectionService) *CollectionHandler {
+	return &CollectionHandler{CollectionService: collectionService}
+}
+
+// ListCollections 获取已上架的集合列表
+// GET /api/v1/collections
+func (h *CollectionHandler) ListCollections(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	collections, total, err := h.CollectionService.ListCollections("active", page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取集合列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      collections,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// GetCollectionDetail 获取集合详情（成员藏品、持有人数、总发行量、地板价、成交额）
+// GET /api/v1/collections/:id
+func (h *CollectionHandler) GetCollectionDetail(c *gin.Context) {
+	collectionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "集合ID格式错误"})
+		return
+	}
+
+	detail, err := h.CollectionService.GetCollectionDetail(collectionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "获取集合详情失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    detail,
+	})
+}
@@ -23,12 +23,13 @@ func (h *CreationHandler) SubmitCreation(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	
 	var req struct {
-		Title        string `json:"title" binding:"required"`
-		Description  string `json:"description"`
-		MediaURL     string `json:"media_url" binding:"required"`
-		ThumbnailURL string `json:"thumbnail_url"`
-		TotalSupply  uint   `json:"total_supply" binding:"required,min=1"`
-		Price        string `json:"price" binding:"required"`
+		Title         string  `json:"title" binding:"required"`
+		Description   string  `json:"description"`
+		MediaURL      string  `json:"media_url" binding:"required"`
+		ThumbnailURL  string  `json:"thumbnail_url"`
+		TotalSupply   uint    `json:"total_supply" binding:"required,min=1"`
+		Price         string  `json:"price" binding:"required"`
+		ParentAssetID *uint64 `json:"parent_asset_id"` // 衍生作品的上游藏品
 	}
 	
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -37,13 +38,14 @@ func (h *CreationHandler) SubmitCreation(c *gin.Context) {
 	}
 	
 	creation, err := h.creationService.SubmitCreation(
-		userID.(uint),
+		uint(userID.(uint64)),
 		req.Title,
 		req.Description,
 		req.MediaURL,
 		req.ThumbnailURL,
 		req.TotalSupply,
 		req.Price,
+		req.ParentAssetID,
 	)
 	
 	if err != nil {
@@ -65,7 +67,7 @@ func (h *CreationHandler) GetMyCreations(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
 	
-	creations, total, err := h.creationService.GetUserCreations(userID.(uint), status, page, pageSize)
+	creations, total, err := h.creationService.GetUserCreations(uint(userID.(uint64)), status, page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -89,5 +91,11 @@ func (h *CreationHandler) GetCreationDetail(c *gin.Context) {
 		return
 	}
 	
-	c.JSON(http.StatusOK, gin.H{"creation": creation})
+	license, err := h.creationService.GetDerivativeLicense(creation.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	
+	c.JSON(http.StatusOK, gin.H{"creation": creation, "derivative_license": license})
 }
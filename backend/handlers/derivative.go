@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DerivativeHandler 定义衍生作品授权与血缘相关的HTTP处理函数
+type DerivativeHandler struct {
+	DerivativeService *services.DerivativeService
+}
+
+// NewDerivativeHandler 创建一个新的DerivativeHandler实例
+func NewDerivativeHandler(derivativeService *services.DerivativeService) *DerivativeHandler {
+	return &DerivativeHandler{DerivativeService: derivativeService}
+}
+
+// GetLineage 获取藏品的上游链和直接衍生作品
+// GET /api/v1/assets/:id/lineage
+func (h *DerivativeHandler) GetLineage(c *gin.Context) {
+	assetID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "藏品ID格式错误"})
+		return
+	}
+
+	lineage, err := h.DerivativeService.GetLineage(assetID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "获取藏品血缘失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    lineage,
+	})
+}
+
+// GetReceivedLicenses 获取我作为上游创作者收到的授权申请
+// GET /api/v1/my/derivative-licenses
+func (h *DerivativeHandler) GetReceivedLicenses(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	page, pageSize := parsePagination(c)
+
+	licenses, total, err := h.DerivativeService.ListReceivedLicenses(userID.(uint64), c.Query("status"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取授权申请失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      licenses,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// ApproveLicense 上游创作者批准衍生授权
+// POST /api/v1/derivative-licenses/:id/approve
+func (h *DerivativeHandler) ApproveLicense(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	licenseID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "授权ID格式错误"})
+		return
+	}
+	var req struct {
+		RoyaltyRate string `json:"royalty_rate"` // 上游分成比例（%），为空时使用默认比例
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	license, err := h.DerivativeService.ApproveByCreator(licenseID, userID.(uint64), req.RoyaltyRate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "授权失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "已授权",
+		"data":    license,
+	})
+}
+
+// RejectLicense 上游创作者拒绝衍生授权
+// POST /api/v1/derivative-licenses/:id/reject
+func (h *DerivativeHandler) RejectLicense(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	licenseID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "授权ID格式错误"})
+		return
+	}
+	var req struct {
+		Reason string `json:"reason" binding:"required,max=255"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	license, err := h.DerivativeService.RejectByCreator(licenseID, userID.(uint64), req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "拒绝授权失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "已拒绝",
+		"data":    license,
+	})
+}
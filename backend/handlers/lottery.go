@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LotteryHandler 定义首发抽签相关的HTTP处理函数
+type LotteryHandler struct {
+	LotteryService *services.LotteryService
+}
+
+// NewLotteryHandler 创建一个新的LotteryHandler实例
+func NewLotteryHandler(lotteryService *services.LotteryService) *LotteryHandler {
+	return &LotteryHandler{LotteryService: lotteryService}
+}
+
+// ListLotteries 获取抽签列表
+// GET /api/v1/lotteries
+func (h *LotteryHandler) ListLotteries(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	lotteries, total, err := h.LotteryService.ListLotteries(c.Query("status"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取抽签列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      lotteries,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// GetLottery 获取抽签详情（含种子承诺，开奖后含公开种子）
+// GET /api/v1/lotteries/:id
+func (h *LotteryHandler) GetLottery(c *gin.Context) {
+	lotteryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "抽签ID格式错误"})
+		return
+	}
+
+	lottery, err := h.LotteryService.GetLottery(lotteryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "抽签不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    lottery,
+	})
+}
+
+// GetEntries 获取开奖后的报名名单及中签顺序
+// GET /api/v1/lotteries/:id/entries
+func (h *LotteryHandler) GetEntries(c *gin.Context) {
+	lotteryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "抽签ID格式错误"})
+		return
+	}
+	page, pageSize := parsePagination(c)
+
+	entries, total, err := h.LotteryService.GetEntries(lotteryID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "获取抽签结果失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      entries,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// VerifyLottery 验证抽签结果
+// GET /api/v1/lotteries/:id/verify
+func (h *LotteryHandler) VerifyLottery(c *gin.Context) {
+	lotteryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "抽签ID格式错误"})
+		return
+	}
+
+	result, err := h.LotteryService.VerifyLottery(lotteryID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "验证失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    result,
+	})
+}
+
+// Register 报名抽签
+// POST /api/v1/lotteries/:id/register
+func (h *LotteryHandler) Register(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	lotteryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "抽签ID格式错误"})
+		return
+	}
+
+	entry, err := h.LotteryService.Register(userID.(uint64), lotteryID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "报名失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "报名成功",
+		"data":    entry,
+	})
+}
+
+// GetMyEntry 获取我的报名记录
+// GET /api/v1/lotteries/:id/my-entry
+func (h *LotteryHandler) GetMyEntry(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	lotteryID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "抽签ID格式错误"})
+		return
+	}
+
+	entry, err := h.LotteryService.GetUserEntry(userID.(uint64), lotteryID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    entry,
+	})
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hoho-miniapp/backend/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// metadataMaxAge 元数据的浏览器及CDN缓存时长（秒）
+const metadataMaxAge = 300
+
+// MetadataHandler 定义藏品元数据相关的HTTP处理函数
+type MetadataHandler struct {
+	MetadataService *services.MetadataService
+}
+
+// NewMetadataHandler 创建一个新的MetadataHandler实例
+func NewMetadataHandler(metadataService *services.MetadataService) *MetadataHandler {
+	return &MetadataHandler{MetadataService: metadataService}
+}
+
+// GetTokenMetadata 返回ERC-721风格的元数据JSON，支持 ETag 协商缓存
+// GET /metadata/:token_id
+func (h *MetadataHandler) GetTokenMetadata(c *gin.Context) {
+	metadata, err := h.MetadataService.GetTokenMetadata(c.Param("token_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "获取元数据失败", "details": err.Error()})
+		return
+	}
+
+	body, err := json.Marshal(metadata)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "生成元数据失败", "details": err.Error()})
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", metadataMaxAge))
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MetricsHandler 定义平台指标公示相关的HTTP处理函数
+type MetricsHandler struct {
+	MetricsService *services.MetricsService
+}
+
+// NewMetricsHandler 创建一个新的MetricsHandler实例
+func NewMetricsHandler(metricsService *services.MetricsService) *MetricsHandler {
+	return &MetricsHandler{MetricsService: metricsService}
+}
+
+// ListMetrics 获取每日指标时间序列，from/to 缺省为截至昨天的最近30天
+// GET /api/v1/transparency/metrics?from=2026-01-01&to=2026-01-31
+func (h *MetricsHandler) ListMetrics(c *gin.Context) {
+	snapshots, from, to, err := h.MetricsService.ListMetrics(c.Query("from"), c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "获取指标失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list": snapshots,
+			"from": from,
+			"to":   to,
+		},
+	})
+}
+
+// GetLatestMetrics 获取最新一天的指标快照
+// GET /api/v1/transparency/metrics/latest
+func (h *MetricsHandler) GetLatestMetrics(c *gin.Context) {
+	snapshot, err := h.MetricsService.GetLatestMetrics()
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "获取指标失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    snapshot,
+	})
+}
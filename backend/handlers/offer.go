@@ -23,8 +23,8 @@ func (h *OfferHandler) CreateOffer(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	
 	var req struct {
-		ArtworkInstanceID uint   `json:"artwork_instance_id" binding:"required"`
-		Price             string `json:"price" binding:"required"`
+		AssetInstanceID uint64 `json:"asset_instance_id" binding:"required"`
+		Price           string `json:"price" binding:"required"`
 	}
 	
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -32,7 +32,7 @@ func (h *OfferHandler) CreateOffer(c *gin.Context) {
 		return
 	}
 	
-	offer, err := h.offerService.CreateOffer(userID.(uint), req.ArtworkInstanceID, req.Price)
+	offer, err := h.offerService.CreateOffer(uint(userID.(uint64)), req.AssetInstanceID, req.Price)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -52,7 +52,7 @@ func (h *OfferHandler) GetMyOffers(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
 	
-	offers, total, err := h.offerService.GetUserOffers(userID.(uint), status, page, pageSize)
+	offers, total, err := h.offerService.GetUserOffers(uint(userID.(uint64)), status, page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -71,7 +71,7 @@ func (h *OfferHandler) CancelOffer(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	offerID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
 	
-	if err := h.offerService.CancelOffer(uint(offerID), userID.(uint)); err != nil {
+	if err := h.offerService.CancelOffer(uint(offerID), uint(userID.(uint64))); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -84,7 +84,7 @@ func (h *OfferHandler) AcceptOffer(c *gin.Context) {
 	userID, _ := c.Get("user_id")
 	offerID, _ := strconv.ParseUint(c.Param("id"), 10, 32)
 	
-	if err := h.offerService.AcceptOffer(uint(offerID), userID.(uint)); err != nil {
+	if err := h.offerService.AcceptOffer(uint(offerID), uint(userID.(uint64))); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OwnershipSnapshotHandler 定义所有权快照相关的HTTP处理函数
+type OwnershipSnapshotHandler struct {
+	OwnershipSnapshotService *services.OwnershipSnapshotService
+}
+
+// NewOwnershipSnapshotHandler 创建一个新的OwnershipSnapshotHandler实例
+func NewOwnershipSnapshotHandler(snapshotService *services.OwnershipSnapshotService) *OwnershipSnapshotHandler {
+	return &OwnershipSnapshotHandler{OwnershipSnapshotService: snapshotService}
+}
+
+// ListSnapshots 获取已公示的所有权快照列表
+// GET /api/v1/ownership-snapshots
+func (h *OwnershipSnapshotHandler) ListSnapshots(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	snapshots, total, err := h.OwnershipSnapshotService.ListSnapshots(page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取快照列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      snapshots,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// GetSnapshot 获取所有权快照详情
+// GET /api/v1/ownership-snapshots/:id
+func (h *OwnershipSnapshotHandler) GetSnapshot(c *gin.Context) {
+	snapshotID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "快照ID格式错误"})
+		return
+	}
+
+	snapshot, err := h.OwnershipSnapshotService.GetSnapshot(snapshotID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "获取快照失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    snapshot,
+	})
+}
+
+// GetPublicKey 获取平台公布的快照验签公钥，该值来自部署配置而非快照记录
+// GET /api/v1/ownership-snapshots/public-key
+func (h *OwnershipSnapshotHandler) GetPublicKey(c *gin.Context) {
+	publicKey, err := services.SnapshotPublicKey()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"code": 503, "message": "快照验签公钥未配置"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"algorithm":  "ed25519",
+			"public_key": hex.EncodeToString(publicKey),
+		},
+	})
+}
+
+// GetMyProof 获取当前用户持有某藏品实例的包含证明，snapshot_id 缺省时使用最新快照
+// GET /api/v1/my/ownership-proofs/:token_id
+func (h *OwnershipSnapshotHandler) GetMyProof(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	var snapshotID uint64
+	if raw := c.Query("snapshot_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "快照ID格式错误"})
+			return
+		}
+		snapshotID = id
+	}
+
+	proof, err := h.OwnershipSnapshotService.GetUserProof(userID.(uint64), snapshotID, c.Param("token_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "获取包含证明失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    proof,
+	})
+}
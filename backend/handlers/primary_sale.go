@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrimarySaleHandler 定义作品首发相关的HTTP处理函数
+type PrimarySaleHandler struct {
+	PrimarySaleService *services.PrimarySaleService
+}
+
+// NewPrimarySaleHandler 创建一个新的PrimarySaleHandler实例
+func NewPrimarySaleHandler(primarySaleService *services.PrimarySaleService) *PrimarySaleHandler {
+	return &PrimarySaleHandler{PrimarySaleService: primarySaleService}
+}
+
+// ListSales 获取在售首发列表（含即将开售）
+// GET /api/v1/primary-sales
+func (h *PrimarySaleHandler) ListSales(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	sales, total, err := h.PrimarySaleService.ListSales("on_sale", page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取首发列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      sales,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// GetSale 获取首发详情
+// GET /api/v1/primary-sales/:id
+func (h *PrimarySaleHandler) GetSale(c *gin.Context) {
+	saleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "首发ID格式错误"})
+		return
+	}
+
+	sale, err := h.PrimarySaleService.GetSale(saleID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    sale,
+	})
+}
+
+// Purchase 首发购买
+// POST /api/v1/primary-sales/:id/purchase
+func (h *PrimarySaleHandler) Purchase(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	saleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "首发ID格式错误"})
+		return
+	}
+
+	// 开启排队的首发需携带购买凭证
+	var req struct {
+		Ticket string `json:"ticket"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	order, err := h.PrimarySaleService.Purchase(userID.(uint64), saleID, req.Ticket)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "购买失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "购买成功",
+		"data":    order,
+	})
+}
+
+// GetMyOrders 获取我的首发订单
+// GET /api/v1/my/primary-orders
+func (h *PrimarySaleHandler) GetMyOrders(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	page, pageSize := parsePagination(c)
+
+	orders, total, err := h.PrimarySaleService.GetUserOrders(userID.(uint64), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取首发订单失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      orders,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// JoinQueue 加入热门首发排队
+// POST /api/v1/primary-sales/:id/queue
+func (h *PrimarySaleHandler) JoinQueue(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	saleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "首发ID格式错误"})
+		return
+	}
+
+	state, err := h.PrimarySaleService.JoinQueue(userID.(uint64), saleID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "排队失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    state,
+	})
+}
+
+// GetQueueState 查询排队位置和购买凭证
+// GET /api/v1/primary-sales/:id/queue
+func (h *PrimarySaleHandler) GetQueueState(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	saleID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "首发ID格式错误"})
+		return
+	}
+
+	state, err := h.PrimarySaleService.GetQueueState(userID.(uint64), saleID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "查询排队状态失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    state,
+	})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PriorityRightHandler 定义优先购权益相关的HTTP处理函数
+type PriorityRightHandler struct {
+	PriorityRightService *services.PriorityRightService
+}
+
+// NewPriorityRightHandler 创建一个新的PriorityRightHandler实例
+func NewPriorityRightHandler(priorityRightService *services.PriorityRightService) *PriorityRightHandler {
+	return &PriorityRightHandler{PriorityRightService: priorityRightService}
+}
+
+// GetMyRights 获取我的优先购权益
+// GET /api/v1/my/priority-rights
+func (h *PriorityRightHandler) GetMyRights(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	page, pageSize := parsePagination(c)
+
+	rights, total, err := h.PriorityRightService.GetUserRights(userID.(uint64), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取优先购权益失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      rights,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// Transfer 转让优先购权益
+// POST /api/v1/priority-rights/:id/transfer
+func (h *PriorityRightHandler) Transfer(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	rightID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "权益ID格式错误"})
+		return
+	}
+
+	var req struct {
+		ToUID    string `json:"to_uid" binding:"required"`
+		Quantity int    `json:"quantity" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	if err := h.PriorityRightService.Transfer(userID.(uint64), rightID, req.ToUID, req.Quantity); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "转让失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "转让成功"})
+}
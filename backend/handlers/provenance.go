@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProvenanceHandler 定义藏品溯源相关的HTTP处理函数
+type ProvenanceHandler struct {
+	ProvenanceService *services.ProvenanceService
+}
+
+// NewProvenanceHandler 创建一个新的ProvenanceHandler实例
+func NewProvenanceHandler(provenanceService *services.ProvenanceService) *ProvenanceHandler {
+	return &ProvenanceHandler{ProvenanceService: provenanceService}
+}
+
+// GetProvenance 按TokenID查询藏品实例的所有权变更时间线
+// GET /api/v1/provenance/:token_id
+func (h *ProvenanceHandler) GetProvenance(c *gin.Context) {
+	provenance, err := h.ProvenanceService.GetProvenance(c.Param("token_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "获取溯源信息失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    provenance,
+	})
+}
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RedemptionHandler 定义实物兑换相关的HTTP处理函数
+type RedemptionHandler struct {
+	RedemptionService *services.RedemptionService
+}
+
+// NewRedemptionHandler 创建一个新的RedemptionHandler实例
+func NewRedemptionHandler(redemptionService *services.RedemptionService) *RedemptionHandler {
+	return &RedemptionHandler{RedemptionService: redemptionService}
+}
+
+// ListRedeemableItems 获取可兑换实物列表
+// GET /api/v1/redemptions/items
+func (h *RedemptionHandler) ListRedeemableItems(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	items, total, err := h.RedemptionService.ListRedeemableItems(true, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取兑换列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      items,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// Redeem 兑换实物
+// POST /api/v1/redemptions
+func (h *RedemptionHandler) Redeem(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	var req struct {
+		AssetInstanceID uint64 `json:"asset_instance_id" binding:"required"`
+		AddressID       uint64 `json:"address_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	order, err := h.RedemptionService.Redeem(userID.(uint64), req.AssetInstanceID, req.AddressID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "兑换失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "兑换成功",
+		"data":    order,
+	})
+}
+
+// GetMyOrders 获取我的兑换订单
+// GET /api/v1/redemptions
+func (h *RedemptionHandler) GetMyOrders(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	page, pageSize := parsePagination(c)
+
+	orders, total, err := h.RedemptionService.GetUserOrders(userID.(uint64), c.Query("status"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取兑换订单失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      orders,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// GetOrderDetail 获取兑换订单详情
+// GET /api/v1/redemptions/:id
+func (h *RedemptionHandler) GetOrderDetail(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "订单ID格式错误"})
+		return
+	}
+
+	order, err := h.RedemptionService.GetUserOrder(userID.(uint64), orderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    order,
+	})
+}
+
+// RequestAfterSale 申请售后
+// POST /api/v1/redemptions/:id/after-sale
+func (h *RedemptionHandler) RequestAfterSale(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	orderID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "订单ID格式错误"})
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	if err := h.RedemptionService.RequestAfterSale(userID.(uint64), orderID, req.Reason); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "申请售后失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"code": 0, "message": "售后申请已提交"})
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler 定义全站搜索相关的HTTP处理函数
+type SearchHandler struct {
+	SearchService *services.SearchService
+}
+
+// NewSearchHandler 创建一个新的SearchHandler实例
+func NewSearchHandler(searchService *services.SearchService) *SearchHandler {
+	return &SearchHandler{SearchService: searchService}
+}
+
+// Search 搜索藏品、集合、创作者和用户
+// GET /api/v1/search?q=&type=asset,collection,creator,user&limit=
+func (h *SearchHandler) Search(c *gin.Context) {
+	var types []string
+	if value := c.Query("type"); value != "" {
+		types = strings.Split(value, ",")
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	result, err := h.SearchService.Search(c.Query("q"), types, limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "搜索失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    result,
+	})
+}
+
+// Suggest 输入联想
+// GET /api/v1/search/suggest?q=&limit=
+func (h *SearchHandler) Suggest(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	suggestions, err := h.SearchService.Suggest(c.Query("q"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取联想词失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    suggestions,
+	})
+}
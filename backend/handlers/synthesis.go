@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SynthesisHandler 定义合成相关的HTTP处理函数
+type SynthesisHandler struct {
+	SynthesisService *services.SynthesisService
+}
+
+// NewSynthesisHandler 创建一个新的SynthesisHandler实例
+func NewSynthesisHandler(synthesisService *services.SynthesisService) *SynthesisHandler {
+	return &SynthesisHandler{SynthesisService: synthesisService}
+}
+
+// ListRecipes 获取当前可合成的配方列表
+// GET /api/v1/synthesis/recipes
+func (h *SynthesisHandler) ListRecipes(c *gin.Context) {
+	page, pageSize := parsePagination(c)
+
+	recipes, total, err := h.SynthesisService.ListRecipes(true, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取合成配方失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      recipes,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// GetRecipe 获取合成配方详情
+// GET /api/v1/synthesis/recipes/:id
+func (h *SynthesisHandler) GetRecipe(c *gin.Context) {
+	recipeID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "配方ID格式错误"})
+		return
+	}
+
+	recipe, err := h.SynthesisService.GetRecipe(recipeID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    recipe,
+	})
+}
+
+// Synthesize 执行合成
+// POST /api/v1/synthesis/recipes/:id/synthesize
+func (h *SynthesisHandler) Synthesize(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	recipeID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "配方ID格式错误"})
+		return
+	}
+
+	var req struct {
+		InstanceIDs []uint64 `json:"instance_ids" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+
+	record, outputs, err := h.SynthesisService.Synthesize(userID.(uint64), recipeID, req.InstanceIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "合成失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "合成成功",
+		"data": gin.H{
+			"record":  record,
+			"outputs": outputs,
+		},
+	})
+}
+
+// GetMyRecords 获取我的合成记录
+// GET /api/v1/my/synthesis-records
+func (h *SynthesisHandler) GetMyRecords(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+
+	page, pageSize := parsePagination(c)
+
+	records, total, err := h.SynthesisService.GetUserRecords(userID.(uint64), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取合成记录失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      records,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
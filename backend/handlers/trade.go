@@ -74,6 +74,7 @@ func (h *TradeHandler) ListListings(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
 	status := c.DefaultQuery("status", "active") // active, sold, cancelled
+	sortBy := c.Query("sort")                    // 可选: rarity
 
 	if page < 1 {
 		page = 1
@@ -82,8 +83,15 @@ func (h *TradeHandler) ListListings(c *gin.Context) {
 		pageSize = 20
 	}
 
+	// 属性筛选，如 trait=背景:金色&trait=背景:银色&trait=编号:#1
+	traits, err := services.ParseTraitFilters(c.QueryArray("trait"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// 获取挂售列表
-	listings, total, err := h.tradeService.ListListings(status, page, pageSize)
+	listings, total, err := h.tradeService.ListListings(status, traits, sortBy, page, pageSize)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"hoho-miniapp/backend/services"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TraitHandler 定义藏品属性与稀有度相关的HTTP处理函数
+type TraitHandler struct {
+	TraitService *services.TraitService
+}
+
+// NewTraitHandler 创建一个新的TraitHandler实例
+func NewTraitHandler(traitService *services.TraitService) *TraitHandler {
+	return &TraitHandler{TraitService: traitService}
+}
+
+// GetCollectionTraits 获取集合内各属性值的分布
+// GET /api/v1/collections/:id/traits
+func (h *TraitHandler) GetCollectionTraits(c *gin.Context) {
+	collectionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "集合ID格式错误"})
+		return
+	}
+
+	stats, err := h.TraitService.GetCollectionTraits(collectionID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "获取属性分布失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    stats,
+	})
+}
+
+// ListCollectionInstances 获取集合内的藏品实例，支持属性筛选（trait=类型:值，可重复）和稀有度排序（sort=rarity）
+// GET /api/v1/collections/:id/instances
+func (h *TraitHandler) ListCollectionInstances(c *gin.Context) {
+	collectionID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "集合ID格式错误"})
+		return
+	}
+	traits, err := services.ParseTraitFilters(c.QueryArray("trait"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "请求参数错误", "details": err.Error()})
+		return
+	}
+	page, pageSize := parsePagination(c)
+
+	instances, total, err := h.TraitService.ListCollectionInstances(collectionID, traits, c.Query("sort"), page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "获取实例列表失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": gin.H{
+			"list":      instances,
+			"total":     total,
+			"page":      page,
+			"page_size": pageSize,
+		},
+	})
+}
+
+// GetInstanceTraits 获取实例的生效属性及稀有度
+// GET /api/v1/asset-instances/:id/traits
+func (h *TraitHandler) GetInstanceTraits(c *gin.Context) {
+	instanceID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"code": 400, "message": "实例ID格式错误"})
+		return
+	}
+
+	traits, err := h.TraitService.GetInstanceTraits(instanceID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"code": 404, "message": "获取实例属性失败", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    traits,
+	})
+}
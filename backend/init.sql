@@ -21,7 +21,8 @@ CREATE TABLE IF NOT EXISTS users (
     deleted_at TIMESTAMP NULL,
     INDEX idx_phone (phone),
     INDEX idx_uid (uid),
-    INDEX idx_status (status)
+    INDEX idx_status (status),
+    FULLTEXT INDEX ft_users_nickname (nickname) WITH PARSER ngram
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户表';
 
 -- 2. 用户积分表
@@ -63,33 +64,53 @@ CREATE TABLE IF NOT EXISTS collections (
     name VARCHAR(100) NOT NULL COMMENT '集合名称',
     description VARCHAR(500) COMMENT '集合描述',
     cover_image VARCHAR(500) COMMENT '封面图片',
+    banner_image VARCHAR(500) COMMENT '横幅图片',
+    creator_id BIGINT UNSIGNED DEFAULT 0 COMMENT '创作者ID（平台集合为0）',
+    creator_name VARCHAR(50) COMMENT '创作者名称',
+    sort_order INT DEFAULT 0 COMMENT '展示顺序（越小越靠前）',
     status ENUM('active', 'inactive') DEFAULT 'active' COMMENT '状态',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP NULL,
-    INDEX idx_status (status)
+    INDEX idx_creator (creator_id),
+    INDEX idx_status_sort (status, sort_order),
+    FULLTEXT INDEX ft_collections_name (name) WITH PARSER ngram
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='藏品集合表';
 
--- 5. 藏品表 (SKU)
+-- 5. 藏品表 (SKU)，平台唯一的藏品目录（旧 artworks 表数据通过 cmd/migrate 迁移至此）
 CREATE TABLE IF NOT EXISTS assets (
     id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
     collection_id BIGINT UNSIGNED NOT NULL COMMENT '所属集合ID',
     name VARCHAR(100) NOT NULL COMMENT '藏品名称',
     description VARCHAR(500) COMMENT '藏品描述',
     media_url VARCHAR(500) NOT NULL COMMENT '媒体URL',
-    media_type ENUM('image', 'video', 'audio') NOT NULL COMMENT '媒体类型',
+    media_type ENUM('image', 'video', 'audio', '3d') NOT NULL COMMENT '媒体类型',
+    thumbnail_url VARCHAR(500) COMMENT '缩略图URL',
     total_supply INT NOT NULL COMMENT '总发行量',
     minted_count INT DEFAULT 0 COMMENT '已铸造数量',
-    creator_id BIGINT UNSIGNED NOT NULL COMMENT '创作者ID',
+    creator_id BIGINT UNSIGNED NOT NULL COMMENT '创作者ID（平台藏品为0）',
+    creator_name VARCHAR(50) COMMENT '创作者名称',
+    price DECIMAL(30,8) DEFAULT 0 COMMENT '发行价（积分）',
+    source ENUM('platform', 'community', 'jingtan', 'waveup') DEFAULT 'platform' COMMENT '来源',
+    series VARCHAR(50) COMMENT '系列名称',
+    release_date TIMESTAMP NULL COMMENT '发售时间',
+    creation_id BIGINT UNSIGNED NULL COMMENT '来源创作ID（社区藏品）',
+    legacy_artwork_id BIGINT UNSIGNED NULL COMMENT '迁移前 artworks 表的ID',
+    parent_asset_id BIGINT UNSIGNED NULL COMMENT '上游藏品ID（衍生作品）',
+    parent_royalty_rate DECIMAL(5, 2) DEFAULT 0 COMMENT '成交时分给上游创作者的比例（%）',
     status ENUM('pending_review', 'approved', 'rejected', 'active', 'inactive') DEFAULT 'pending_review' COMMENT '状态',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP NULL,
     FOREIGN KEY (collection_id) REFERENCES collections(id),
-    FOREIGN KEY (creator_id) REFERENCES users(id),
+    UNIQUE INDEX idx_assets_legacy_artwork_id (legacy_artwork_id),
     INDEX idx_collection (collection_id),
     INDEX idx_creator (creator_id),
-    INDEX idx_status (status)
+    INDEX idx_assets_release_date (release_date),
+    INDEX idx_assets_creation_id (creation_id),
+    INDEX idx_assets_parent_asset_id (parent_asset_id),
+    INDEX idx_status (status),
+    FULLTEXT INDEX ft_assets_name_description (name, description) WITH PARSER ngram
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='藏品表';
 
 -- 6. 藏品实例表
@@ -100,6 +121,13 @@ CREATE TABLE IF NOT EXISTS asset_instances (
     owner_id BIGINT UNSIGNED NOT NULL COMMENT '持有者ID',
     token_id VARCHAR(255) UNIQUE NOT NULL COMMENT '唯一TokenID',
     status ENUM('in_wallet', 'on_sale', 'pending_trade', 'burned') DEFAULT 'in_wallet' COMMENT '状态',
+    redeemed_at TIMESTAMP NULL COMMENT '兑换实物时间',
+    legacy_instance_id BIGINT UNSIGNED NULL COMMENT '迁移前 artwork_instances 表的ID',
+    rarity_score DOUBLE DEFAULT 0 COMMENT '集合内稀有度得分',
+    rarity_rank INT DEFAULT 0 COMMENT '集合内稀有度排名，0为未计算',
+    mint_tx_hash VARCHAR(66) COMMENT '铸造上链交易哈希',
+    last_tx_hash VARCHAR(66) COMMENT '最近一次已确认的上链交易哈希',
+    chain_status VARCHAR(20) DEFAULT 'pending' COMMENT '上链状态：pending, anchored, failed',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP NULL,
@@ -107,8 +135,10 @@ CREATE TABLE IF NOT EXISTS asset_instances (
     FOREIGN KEY (owner_id) REFERENCES users(id),
     INDEX idx_asset (asset_id),
     INDEX idx_owner (owner_id),
+    UNIQUE INDEX idx_asset_instances_legacy_instance_id (legacy_instance_id),
     INDEX idx_token (token_id),
-    INDEX idx_status (status)
+    INDEX idx_status (status),
+    INDEX idx_asset_instances_rarity_rank (rarity_rank)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='藏品实例表';
 
 -- 7. 交易挂单表
@@ -133,12 +163,15 @@ CREATE TABLE IF NOT EXISTS listings (
 -- 8. 交易记录表
 CREATE TABLE IF NOT EXISTS trades (
     id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    trade_no VARCHAR(32) UNIQUE COMMENT '交易单号（带校验位）',
     listing_id BIGINT UNSIGNED NOT NULL COMMENT '挂单ID',
     instance_id BIGINT UNSIGNED NOT NULL COMMENT '藏品实例ID',
     seller_id BIGINT UNSIGNED NOT NULL COMMENT '卖家ID',
     buyer_id BIGINT UNSIGNED NOT NULL COMMENT '买家ID',
     price DECIMAL(30,8) NOT NULL COMMENT '成交价格',
+    parent_royalty DECIMAL(30,8) NOT NULL DEFAULT 0 COMMENT '上游版税（衍生作品）',
     status ENUM('pending', 'completed', 'failed', 'cancelled') DEFAULT 'pending' COMMENT '状态',
+    completed_at TIMESTAMP NULL COMMENT '成交时间',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP NULL,
@@ -150,6 +183,7 @@ CREATE TABLE IF NOT EXISTS trades (
     INDEX idx_seller (seller_id),
     INDEX idx_buyer (buyer_id),
     INDEX idx_status (status),
+    INDEX idx_completed_at (completed_at),
     INDEX idx_created_at (created_at)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='交易记录表';
 
@@ -200,6 +234,9 @@ CREATE TABLE IF NOT EXISTS community_events (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP NULL,
+    chain_seq BIGINT UNSIGNED NULL UNIQUE COMMENT '哈希链序号',
+    prev_hash VARCHAR(64) COMMENT '上一事件哈希',
+    hash VARCHAR(64) COMMENT '本事件哈希',
     INDEX idx_event_type (event_type),
     INDEX idx_user (user_id),
     INDEX idx_related (related_id, related_type),
@@ -212,16 +249,344 @@ CREATE TABLE IF NOT EXISTS admins (
     username VARCHAR(50) UNIQUE NOT NULL COMMENT '用户名',
     password_hash VARCHAR(255) NOT NULL COMMENT '密码哈希',
     email VARCHAR(100) COMMENT '邮箱',
-    role ENUM('super_admin', 'admin', 'operator') DEFAULT 'operator' COMMENT '角色',
-    status ENUM('active', 'suspended') DEFAULT 'active' COMMENT '状态',
+    role VARCHAR(30) DEFAULT 'admin' COMMENT '角色，对应 admin_roles.name',
+    status ENUM('active', 'inactive') DEFAULT 'active' COMMENT '状态',
     last_login_at TIMESTAMP NULL COMMENT '最后登录时间',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP NULL,
     INDEX idx_username (username),
+    INDEX idx_role (role),
     INDEX idx_status (status)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='管理员表';
 
+-- 13. 合成配方表
+CREATE TABLE IF NOT EXISTS synthesis_recipes (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL COMMENT '配方名称',
+    description VARCHAR(500) COMMENT '配方描述',
+    output_asset_id BIGINT UNSIGNED NOT NULL COMMENT '产出藏品ID',
+    output_count INT NOT NULL DEFAULT 1 COMMENT '每次产出数量',
+    start_at TIMESTAMP NULL COMMENT '开始时间',
+    end_at TIMESTAMP NULL COMMENT '结束时间',
+    max_times INT NOT NULL DEFAULT 0 COMMENT '全局合成次数上限（0为不限）',
+    used_times INT NOT NULL DEFAULT 0 COMMENT '已合成次数',
+    status ENUM('active', 'inactive') DEFAULT 'active' COMMENT '状态',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (output_asset_id) REFERENCES assets(id),
+    INDEX idx_status (status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='合成配方表';
+
+-- 14. 合成配方材料表
+CREATE TABLE IF NOT EXISTS synthesis_recipe_inputs (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    recipe_id BIGINT UNSIGNED NOT NULL COMMENT '配方ID',
+    asset_id BIGINT UNSIGNED NOT NULL COMMENT '材料藏品ID',
+    quantity INT NOT NULL COMMENT '所需数量',
+    FOREIGN KEY (recipe_id) REFERENCES synthesis_recipes(id) ON DELETE CASCADE,
+    FOREIGN KEY (asset_id) REFERENCES assets(id),
+    UNIQUE KEY uk_recipe_asset (recipe_id, asset_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='合成配方材料表';
+
+-- 15. 合成记录表
+CREATE TABLE IF NOT EXISTS synthesis_records (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    recipe_id BIGINT UNSIGNED NOT NULL COMMENT '配方ID',
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    burned_instance_ids TEXT NOT NULL COMMENT '销毁的实例ID',
+    output_instance_ids TEXT NOT NULL COMMENT '产出的实例ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (recipe_id) REFERENCES synthesis_recipes(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    INDEX idx_recipe (recipe_id),
+    INDEX idx_user (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='合成记录表';
+
+-- 16. 可兑换实物表
+CREATE TABLE IF NOT EXISTS redeemable_items (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    asset_id BIGINT UNSIGNED UNIQUE NOT NULL COMMENT '藏品ID',
+    item_name VARCHAR(100) NOT NULL COMMENT '实物名称',
+    item_image VARCHAR(500) COMMENT '实物图片',
+    description VARCHAR(1000) COMMENT '实物描述',
+    stock INT NOT NULL COMMENT '实物库存',
+    redeemed_count INT NOT NULL DEFAULT 0 COMMENT '已兑换数量',
+    start_at TIMESTAMP NULL COMMENT '兑换开始时间',
+    end_at TIMESTAMP NULL COMMENT '兑换结束时间',
+    burn_on_redeem BOOLEAN NOT NULL DEFAULT FALSE COMMENT '兑换时是否销毁藏品',
+    status ENUM('active', 'inactive') DEFAULT 'active' COMMENT '状态',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (asset_id) REFERENCES assets(id),
+    INDEX idx_status (status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='可兑换实物表';
+
+-- 17. 实物兑换订单表
+CREATE TABLE IF NOT EXISTS redemption_orders (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    order_no VARCHAR(32) UNIQUE NOT NULL COMMENT '订单号',
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    redeemable_item_id BIGINT UNSIGNED NOT NULL COMMENT '兑换配置ID',
+    asset_instance_id BIGINT UNSIGNED NOT NULL COMMENT '藏品实例ID',
+    receiver_name VARCHAR(50) NOT NULL COMMENT '收货人',
+    receiver_phone VARCHAR(20) NOT NULL COMMENT '收货人手机号',
+    shipping_address VARCHAR(500) NOT NULL COMMENT '收货地址快照',
+    status ENUM('pending', 'shipped', 'delivered', 'after_sale', 'closed') DEFAULT 'pending' COMMENT '履约状态',
+    carrier VARCHAR(50) COMMENT '物流公司',
+    tracking_no VARCHAR(100) COMMENT '物流单号',
+    after_sale_reason VARCHAR(500) COMMENT '售后原因',
+    remark VARCHAR(500) COMMENT '管理员备注',
+    shipped_at TIMESTAMP NULL COMMENT '发货时间',
+    delivered_at TIMESTAMP NULL COMMENT '签收时间',
+    closed_at TIMESTAMP NULL COMMENT '关闭时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (redeemable_item_id) REFERENCES redeemable_items(id),
+    FOREIGN KEY (asset_instance_id) REFERENCES asset_instances(id),
+    INDEX idx_user (user_id),
+    INDEX idx_status (status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='实物兑换订单表';
+
+-- 18. 用户收货地址表
+CREATE TABLE IF NOT EXISTS user_addresses (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    receiver_name VARCHAR(50) NOT NULL COMMENT '收货人',
+    phone VARCHAR(20) NOT NULL COMMENT '收货人手机号',
+    province_code CHAR(6) NOT NULL COMMENT '省级编码',
+    province VARCHAR(50) NOT NULL COMMENT '省',
+    city_code CHAR(6) NOT NULL COMMENT '市级编码',
+    city VARCHAR(50) NOT NULL COMMENT '市',
+    district_code CHAR(6) NOT NULL COMMENT '区县编码',
+    district VARCHAR(50) NOT NULL COMMENT '区/县',
+    detail VARCHAR(200) NOT NULL COMMENT '详细地址',
+    is_default BOOLEAN NOT NULL DEFAULT FALSE COMMENT '是否默认地址',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    INDEX idx_user (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户收货地址表';
+
+-- 19. 盲盒表
+CREATE TABLE IF NOT EXISTS blind_boxes (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL COMMENT '盲盒名称',
+    description VARCHAR(1000) COMMENT '盲盒描述',
+    cover_image VARCHAR(500) COMMENT '封面图',
+    price DECIMAL(30,8) NOT NULL COMMENT '价格（积分）',
+    commission_rate DECIMAL(5,2) NOT NULL DEFAULT 40.00 COMMENT '平台分成比例（%），平台藏品全部计入平台',
+    total_supply INT NOT NULL COMMENT '总供应量（各档位之和）',
+    sold_count INT NOT NULL DEFAULT 0 COMMENT '已售数量',
+    start_at TIMESTAMP NOT NULL COMMENT '开售时间',
+    end_at TIMESTAMP NULL COMMENT '结束时间',
+    seed VARCHAR(64) COMMENT '随机种子（揭晓前保密）',
+    seed_hash CHAR(64) COMMENT '种子SHA-256承诺',
+    revealed_at TIMESTAMP NULL COMMENT '种子公开时间',
+    status ENUM('draft', 'on_sale', 'closed', 'revealed') DEFAULT 'draft' COMMENT '状态',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_status (status)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='盲盒表';
+
+-- 20. 盲盒档位表
+CREATE TABLE IF NOT EXISTS blind_box_tiers (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    box_id BIGINT UNSIGNED NOT NULL COMMENT '盲盒ID',
+    name VARCHAR(50) NOT NULL COMMENT '档位名称',
+    asset_id BIGINT UNSIGNED NOT NULL COMMENT '藏品ID',
+    weight INT NOT NULL COMMENT '抽取权重',
+    supply INT NOT NULL COMMENT '档位供应量',
+    drawn_count INT NOT NULL DEFAULT 0 COMMENT '已抽出数量',
+    FOREIGN KEY (box_id) REFERENCES blind_boxes(id) ON DELETE CASCADE,
+    FOREIGN KEY (asset_id) REFERENCES assets(id),
+    INDEX idx_box (box_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='盲盒档位表';
+
+-- 21. 盲盒抽取记录表
+CREATE TABLE IF NOT EXISTS blind_box_draws (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    box_id BIGINT UNSIGNED NOT NULL COMMENT '盲盒ID',
+    draw_index INT NOT NULL COMMENT '抽取序号（从0开始）',
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    tier_id BIGINT UNSIGNED NOT NULL COMMENT '抽中档位ID',
+    asset_instance_id BIGINT UNSIGNED NOT NULL COMMENT '铸造的藏品实例ID',
+    price DECIMAL(30,8) NOT NULL COMMENT '成交价格',
+    creator_income DECIMAL(30,8) NOT NULL DEFAULT 0 COMMENT '创作者收入',
+    platform_income DECIMAL(30,8) NOT NULL DEFAULT 0 COMMENT '平台收入',
+    parent_royalty DECIMAL(30,8) NOT NULL DEFAULT 0 COMMENT '从创作者收入中分给上游的部分（衍生作品）',
+    entropy_seq BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '抽取时事件哈希链链头序号',
+    entropy_hash CHAR(64) COMMENT '抽取时事件哈希链链头哈希，与种子共同决定档位',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (box_id) REFERENCES blind_boxes(id),
+    FOREIGN KEY (user_id) REFERENCES users(id),
+    FOREIGN KEY (tier_id) REFERENCES blind_box_tiers(id),
+    FOREIGN KEY (asset_instance_id) REFERENCES asset_instances(id),
+    UNIQUE KEY idx_box_draw (box_id, draw_index),
+    INDEX idx_user (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='盲盒抽取记录表';
+
+-- 22. 藏品属性表
+CREATE TABLE IF NOT EXISTS asset_traits (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    asset_id BIGINT UNSIGNED NOT NULL COMMENT '藏品ID',
+    asset_instance_id BIGINT UNSIGNED NULL COMMENT '实例ID，为空时对藏品全部实例生效',
+    trait_type VARCHAR(50) NOT NULL COMMENT '属性类型',
+    value VARCHAR(100) NOT NULL COMMENT '属性值',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (asset_id) REFERENCES assets(id),
+    FOREIGN KEY (asset_instance_id) REFERENCES asset_instances(id),
+    INDEX idx_asset_traits_asset_id (asset_id),
+    INDEX idx_asset_traits_asset_instance_id (asset_instance_id),
+    INDEX idx_asset_traits_type_value (trait_type, value)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='藏品属性表';
+
+-- 23. 所有权变更记录表（只追加，用于藏品溯源）
+CREATE TABLE IF NOT EXISTS ownership_records (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    asset_instance_id BIGINT UNSIGNED NOT NULL COMMENT '实例ID',
+    token_id VARCHAR(255) NOT NULL COMMENT '实例TokenID',
+    event_type VARCHAR(20) NOT NULL COMMENT 'mint, airdrop, trade, offer, transfer, burn, redeem',
+    from_user_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '转出用户，铸造/空投时为0',
+    to_user_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '转入用户，销毁时为0',
+    price DECIMAL(30,8) NULL COMMENT '成交价，非交易为空',
+    event_id BIGINT UNSIGNED NULL COMMENT '对应的社区事件ID',
+    related_id BIGINT UNSIGNED,
+    related_type VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (asset_instance_id) REFERENCES asset_instances(id),
+    INDEX idx_ownership_records_asset_instance_id (asset_instance_id),
+    INDEX idx_ownership_records_token_id (token_id),
+    INDEX idx_ownership_records_from_user_id (from_user_id),
+    INDEX idx_ownership_records_to_user_id (to_user_id),
+    INDEX idx_ownership_records_event_id (event_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='所有权变更记录表';
+
+-- 24. 所有权快照表（每日Merkle根，签名后公示）
+CREATE TABLE IF NOT EXISTS ownership_snapshots (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    snapshot_date VARCHAR(10) NOT NULL UNIQUE COMMENT '快照日期',
+    merkle_root VARCHAR(64) NOT NULL COMMENT 'Merkle根（hex）',
+    leaf_count INT NOT NULL COMMENT '叶子数量',
+    signature VARCHAR(128) NOT NULL COMMENT 'Ed25519签名（hex）',
+    public_key VARCHAR(64) NOT NULL COMMENT '签名时使用的公钥（hex），仅供内部核对，验签以公布的公钥为准',
+    event_id BIGINT UNSIGNED NULL COMMENT '公示根哈希的社区事件ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_ownership_snapshots_event_id (event_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='所有权快照表';
+
+-- 25. 所有权快照叶子表
+CREATE TABLE IF NOT EXISTS ownership_snapshot_leaves (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    snapshot_id BIGINT UNSIGNED NOT NULL COMMENT '快照ID',
+    leaf_index INT NOT NULL COMMENT '叶子序号',
+    token_id VARCHAR(255) NOT NULL COMMENT '实例TokenID',
+    owner_uid VARCHAR(20) NOT NULL COMMENT '快照时持有者UID',
+    status VARCHAR(20) NOT NULL COMMENT '快照时实例状态',
+    leaf_hash VARCHAR(64) NOT NULL COMMENT '叶子哈希（hex）',
+    FOREIGN KEY (snapshot_id) REFERENCES ownership_snapshots(id),
+    UNIQUE KEY idx_snapshot_leaf (snapshot_id, leaf_index),
+    INDEX idx_snapshot_token (snapshot_id, token_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='所有权快照叶子表';
+
+-- 26. 社区事件哈希链链头表（仅一行）
+CREATE TABLE IF NOT EXISTS event_chain_heads (
+    id BIGINT UNSIGNED PRIMARY KEY,
+    last_seq BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '最后一个事件的序号',
+    last_hash VARCHAR(64) NOT NULL DEFAULT '' COMMENT '最后一个事件的哈希',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='社区事件哈希链链头表';
+
+INSERT IGNORE INTO event_chain_heads (id, last_seq, last_hash) VALUES (1, 0, '');
+
+-- 27. 上链任务表（发件箱，异步提交并确认）
+CREATE TABLE IF NOT EXISTS chain_anchors (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    asset_instance_id BIGINT UNSIGNED NOT NULL COMMENT '实例ID',
+    token_id VARCHAR(255) NOT NULL COMMENT '实例TokenID',
+    operation VARCHAR(20) NOT NULL COMMENT 'mint, transfer, burn',
+    from_user_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '转出用户，铸造时为0',
+    to_user_id BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '接收用户，销毁时为0',
+    status ENUM('pending', 'submitting', 'submitted', 'confirmed', 'failed') DEFAULT 'pending' COMMENT '状态',
+    tx_hash VARCHAR(66) COMMENT '交易哈希',
+    attempts INT NOT NULL DEFAULT 0 COMMENT '失败次数',
+    last_error VARCHAR(500) COMMENT '最近一次失败原因',
+    next_attempt_at TIMESTAMP NULL COMMENT '下次提交时间',
+    submitted_at TIMESTAMP NULL,
+    confirmed_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    FOREIGN KEY (asset_instance_id) REFERENCES asset_instances(id),
+    INDEX idx_chain_anchors_asset_instance_id (asset_instance_id),
+    INDEX idx_chain_anchor_status (status, next_attempt_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='上链任务表';
+
+-- 28. 本地模拟账本表（只追加）
+CREATE TABLE IF NOT EXISTS simulated_ledger_entries (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '区块高度',
+    anchor_id BIGINT UNSIGNED NOT NULL UNIQUE COMMENT '上链任务ID（幂等键）',
+    tx_hash VARCHAR(66) NOT NULL UNIQUE COMMENT '交易哈希',
+    prev_hash VARCHAR(66) NOT NULL COMMENT '上一交易哈希',
+    payload TEXT NOT NULL COMMENT '操作内容JSON',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='本地模拟账本表';
+
+-- 29. 每日平台指标快照表（透明公示，生成后不再修改）
+CREATE TABLE IF NOT EXISTS metric_snapshots (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    snapshot_date VARCHAR(10) NOT NULL UNIQUE COMMENT '统计日期',
+    points_held DECIMAL(30,8) NULL COMMENT '用户持有积分总额（含冻结），仅统计日期刚结束时采集',
+    points_circulating DECIMAL(30,8) NULL COMMENT '可用积分总额',
+    points_frozen DECIMAL(30,8) NULL COMMENT '冻结积分总额',
+    active_holders BIGINT NULL COMMENT '持有藏品的用户数',
+    stock_captured_at DATETIME NULL COMMENT '积分与持有人数的采集时间',
+    platform_balance DECIMAL(30,8) NOT NULL DEFAULT 0 COMMENT '统计日期结束时的平台账户余额',
+    trade_volume DECIMAL(30,8) NOT NULL DEFAULT 0 COMMENT '当日完成交易总额',
+    trade_count BIGINT NOT NULL DEFAULT 0 COMMENT '当日完成交易笔数',
+    fees_collected DECIMAL(30,8) NOT NULL DEFAULT 0 COMMENT '当日手续费收入',
+    royalties_paid DECIMAL(30,8) NOT NULL DEFAULT 0 COMMENT '当日支付版税',
+    airdrop_points DECIMAL(30,8) NOT NULL DEFAULT 0 COMMENT '当日空投积分',
+    airdrop_assets BIGINT NOT NULL DEFAULT 0 COMMENT '当日空投藏品数量',
+    new_users BIGINT NOT NULL DEFAULT 0 COMMENT '当日新注册用户',
+    hash VARCHAR(64) NOT NULL COMMENT '指标规范化内容的SHA256',
+    event_id BIGINT UNSIGNED NULL COMMENT '公示哈希的社区事件ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_metric_snapshots_event_id (event_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='每日平台指标快照表';
+
+-- 30. 管理员角色表（super_admin 始终拥有全部权限）
+CREATE TABLE IF NOT EXISTS admin_roles (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(30) NOT NULL UNIQUE COMMENT '角色名',
+    description VARCHAR(255) COMMENT '说明',
+    is_system BOOLEAN NOT NULL DEFAULT FALSE COMMENT '是否内置角色',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='管理员角色表';
+
+-- 31. 角色权限表
+CREATE TABLE IF NOT EXISTS admin_role_permissions (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    role_id BIGINT UNSIGNED NOT NULL COMMENT '角色ID',
+    permission VARCHAR(50) NOT NULL COMMENT '权限标识',
+    FOREIGN KEY (role_id) REFERENCES admin_roles(id),
+    UNIQUE KEY idx_role_permission (role_id, permission)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='角色权限表';
+
+INSERT IGNORE INTO admin_roles (id, name, description, is_system) VALUES
+(1, 'super_admin', '超级管理员，拥有全部权限', TRUE),
+(2, 'admin', '管理员', TRUE),
+(3, 'reviewer', '审核员', TRUE),
+(4, 'customer_service', '客服', TRUE);
+
+INSERT IGNORE INTO admin_role_permissions (role_id, permission) VALUES
+(2, 'dashboard:view'), (2, 'user:view'), (2, 'user:status'), (2, 'review'), (2, 'airdrop'), (2, 'config'),
+(2, 'announcement'), (2, 'task'), (2, 'catalog'), (2, 'sale'), (2, 'redemption'), (2, 'chain'),
+(3, 'dashboard:view'), (3, 'user:view'), (3, 'review'),
+(4, 'dashboard:view'), (4, 'user:view'), (4, 'user:status'), (4, 'redemption');
+
 -- 插入默认管理员账户 (密码: Admin@123456)
 -- 密码哈希使用bcrypt生成，需要在应用层生成
 -- INSERT INTO admins (username, password_hash, email, role, status) 
@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
@@ -14,6 +15,7 @@ import (
 	"hoho-miniapp/backend/handlers"
 	"hoho-miniapp/backend/middleware"
 	"hoho-miniapp/backend/services"
+	"hoho-miniapp/backend/utils"
 )
 
 func main() {
@@ -24,6 +26,9 @@ func main() {
 
 	// 初始化配置
 	config.InitConfig()
+	if err := utils.InitIDGenerator(int64(config.AppConfig.NodeID)); err != nil {
+		log.Fatalf("Failed to initialize ID generator: %v", err)
+	}
 	fmt.Println("✅ Configuration initialized")
 
 	// 初始化数据库
@@ -36,6 +41,15 @@ func main() {
 		log.Fatalf("Failed to initialize Redis: %v", err)
 	}
 
+	// 初始化链适配器（默认本地模拟账本）
+	chainAdapter, err := services.NewChainAdapter(os.Getenv("CHAIN_ADAPTER"))
+	if err != nil {
+		log.Fatalf("Failed to initialize chain adapter: %v", err)
+	}
+
+	// 启动后台定时任务
+	startBackgroundJobs(chainAdapter)
+
 	// 创建Gin引擎
 	router := gin.Default()
 
@@ -44,7 +58,7 @@ func main() {
 	router.Use(loggerMiddleware())
 
 	// 注册路由
-	registerRoutes(router)
+	registerRoutes(router, chainAdapter)
 
 	// 启动服务器
 	port := os.Getenv("PORT")
@@ -58,6 +72,47 @@ func main() {
 	}
 }
 
+// startBackgroundJobs 启动后台定时任务
+func startBackgroundJobs(chainAdapter services.ChainAdapter) {
+	dropQueueService := services.NewDropQueueService()
+	runEvery("回收过期首发购买凭证", 5*time.Second, dropQueueService.ReleaseExpiredTickets)
+
+	lotteryService := services.NewLotteryService()
+	runEvery("首发抽签开奖及收尾", time.Minute, lotteryService.RunDueLotteries)
+
+	priorityRightService := services.NewPriorityRightService()
+	runEvery("执行优先购持仓快照", time.Minute, priorityRightService.RunDueSnapshots)
+
+	calendarService := services.NewCalendarService()
+	runEvery("发送开售提醒", time.Minute, calendarService.SendDueReminders)
+
+	traitService := services.NewTraitService()
+	runEvery("重算新铸造实例的稀有度", 10*time.Minute, traitService.RecomputeStaleRarity)
+
+	ownershipSnapshotService := services.NewOwnershipSnapshotService()
+	runEvery("生成每日所有权Merkle快照", time.Hour, ownershipSnapshotService.RunDailySnapshot)
+
+	metricsService := services.NewMetricsService()
+	runEvery("生成每日平台指标快照", time.Hour, metricsService.RunDailyMetrics)
+
+	chainAnchorService := services.NewChainAnchorService(chainAdapter)
+	runEvery("提交待上链任务", 5*time.Second, chainAnchorService.SubmitPending)
+	runEvery("确认上链交易", 5*time.Second, chainAnchorService.ConfirmSubmitted)
+}
+
+// runEvery 按固定间隔在后台执行任务，任务出错时仅记录日志
+func runEvery(name string, interval time.Duration, job func() error) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := job(); err != nil {
+				log.Printf("定时任务[%s]执行失败: %v", name, err)
+			}
+		}
+	}()
+}
+
 func initDatabase() error {
 	return database.InitDatabase()
 }
@@ -89,12 +144,13 @@ func loggerMiddleware() gin.HandlerFunc {
 	}
 }
 
-func registerRoutes(router *gin.Engine) {
+func registerRoutes(router *gin.Engine, chainAdapter services.ChainAdapter) {
 	// 初始化服务和处理器
 	userService := services.NewUserService()
 	userHandler := handlers.NewUserHandler(userService)
 	assetService := services.NewAssetService()
-	assetHandler := handlers.NewAssetHandler(assetService)
+	catalogService := services.NewCatalogService()
+	assetHandler := handlers.NewAssetHandler(assetService, catalogService)
 	eventService := services.NewEventService()
 	eventHandler := handlers.NewEventHandler(eventService)
 	jingtanService := services.NewJingtanService()
@@ -123,6 +179,54 @@ func registerRoutes(router *gin.Engine) {
 	adminTaskHandler := handlers.NewAdminTaskHandler(taskService)
 	adminAnnouncementHandler := handlers.NewAdminAnnouncementHandler(announcementService)
 	adminConfigHandler := handlers.NewAdminConfigHandler()
+	synthesisService := services.NewSynthesisService()
+	synthesisHandler := handlers.NewSynthesisHandler(synthesisService)
+	adminSynthesisHandler := handlers.NewAdminSynthesisHandler(synthesisService)
+	redemptionService := services.NewRedemptionService()
+	redemptionHandler := handlers.NewRedemptionHandler(redemptionService)
+	adminRedemptionHandler := handlers.NewAdminRedemptionHandler(redemptionService)
+	addressService := services.NewAddressService()
+	addressHandler := handlers.NewAddressHandler(addressService)
+	primarySaleService := services.NewPrimarySaleService()
+	primarySaleHandler := handlers.NewPrimarySaleHandler(primarySaleService)
+	adminPrimarySaleHandler := handlers.NewAdminPrimarySaleHandler(primarySaleService)
+	priorityRightService := services.NewPriorityRightService()
+	priorityRightHandler := handlers.NewPriorityRightHandler(priorityRightService)
+	adminPriorityRightHandler := handlers.NewAdminPriorityRightHandler(priorityRightService)
+	calendarService := services.NewCalendarService()
+	calendarHandler := handlers.NewCalendarHandler(calendarService)
+	blindBoxService := services.NewBlindBoxService()
+	blindBoxHandler := handlers.NewBlindBoxHandler(blindBoxService)
+	adminBlindBoxHandler := handlers.NewAdminBlindBoxHandler(blindBoxService)
+	lotteryService := services.NewLotteryService()
+	lotteryHandler := handlers.NewLotteryHandler(lotteryService)
+	adminLotteryHandler := handlers.NewAdminLotteryHandler(lotteryService)
+	collectionService := services.NewCollectionService()
+	collectionHandler := handlers.NewCollectionHandler(collectionService)
+	adminCollectionHandler := handlers.NewAdminCollectionHandler(collectionService)
+	searchService := services.NewSearchService()
+	searchHandler := handlers.NewSearchHandler(searchService)
+	traitService := services.NewTraitService()
+	traitHandler := handlers.NewTraitHandler(traitService)
+	adminTraitHandler := handlers.NewAdminTraitHandler(traitService)
+	derivativeService := services.NewDerivativeService()
+	derivativeHandler := handlers.NewDerivativeHandler(derivativeService)
+	adminDerivativeHandler := handlers.NewAdminDerivativeHandler(derivativeService)
+	provenanceService := services.NewProvenanceService()
+	provenanceHandler := handlers.NewProvenanceHandler(provenanceService)
+	ownershipSnapshotService := services.NewOwnershipSnapshotService()
+	ownershipSnapshotHandler := handlers.NewOwnershipSnapshotHandler(ownershipSnapshotService)
+	adminEventHandler := handlers.NewAdminEventHandler(eventService)
+	chainAnchorService := services.NewChainAnchorService(chainAdapter)
+	adminChainHandler := handlers.NewAdminChainHandler(chainAnchorService)
+	metadataService := services.NewMetadataService()
+	metadataHandler := handlers.NewMetadataHandler(metadataService)
+	metricsService := services.NewMetricsService()
+	metricsHandler := handlers.NewMetricsHandler(metricsService)
+	dashboardService := services.NewDashboardService()
+	adminDashboardHandler := handlers.NewAdminDashboardHandler(dashboardService)
+	adminRoleService := services.NewAdminRoleService()
+	adminRoleHandler := handlers.NewAdminRoleHandler(adminService, adminRoleService)
 
 	// 健康检查
 	router.GET("/health", func(c *gin.Context) {
@@ -132,6 +236,9 @@ func registerRoutes(router *gin.Engine) {
 		})
 	})
 
+	// 藏品实例元数据（公开，ERC-721风格，供合作平台和钱包使用，路径不随API版本变化）
+	router.GET("/metadata/:token_id", metadataHandler.GetTokenMetadata)
+
 	// API v1 路由组
 	v1 := router.Group("/api/v1")
 	{
@@ -144,7 +251,7 @@ func registerRoutes(router *gin.Engine) {
 
 		// 需要认证的路由
 		auth := v1.Group("/")
-		auth.Use(middleware.AuthMiddleware())
+		auth.Use(middleware.AuthMiddleware(), middleware.ActivityMiddleware())
 		{
 			// 用户相关路由
 			auth.GET("/users/profile", userHandler.GetProfile)
@@ -158,6 +265,12 @@ func registerRoutes(router *gin.Engine) {
 				assets.POST("", assetHandler.SubmitMintRequest) // 提交铸造请求
 			}
 
+			// 藏品实例路由
+			assetInstances := auth.Group("/asset-instances")
+			{
+				assetInstances.POST("/:id/transfer", assetHandler.TransferInstance) // 转赠
+			}
+
 			// 交易相关路由
 			trades := auth.Group("/trades")
 			{
@@ -178,6 +291,15 @@ func registerRoutes(router *gin.Engine) {
 			{
 				my.GET("/listings", tradeHandler.GetMyListings)
 				my.GET("/assets", assetHandler.GetMyAssets)
+				my.GET("/synthesis-records", synthesisHandler.GetMyRecords)
+				my.GET("/primary-orders", primarySaleHandler.GetMyOrders)
+				my.GET("/priority-rights", priorityRightHandler.GetMyRights)
+				my.GET("/calendar", calendarHandler.GetMyCalendar)
+				my.GET("/calendar/ics", calendarHandler.ExportMyICS)
+				my.GET("/release-reminders", calendarHandler.GetMyReminders)
+				my.GET("/blind-box-draws", blindBoxHandler.GetMyDraws)
+				my.GET("/derivative-licenses", derivativeHandler.GetReceivedLicenses)
+				my.GET("/ownership-proofs/:token_id", ownershipSnapshotHandler.GetMyProof)
 			}
 
 			// 上传相关
@@ -235,6 +357,78 @@ func registerRoutes(router *gin.Engine) {
 					offers.DELETE("/:id", offerHandler.CancelOffer)
 					offers.POST("/:id/accept", offerHandler.AcceptOffer)
 				}
+
+				// 合成相关路由
+				synthesis := auth.Group("/synthesis")
+				{
+					synthesis.GET("/recipes", synthesisHandler.ListRecipes)
+					synthesis.GET("/recipes/:id", synthesisHandler.GetRecipe)
+					synthesis.POST("/recipes/:id/synthesize", synthesisHandler.Synthesize)
+				}
+
+				// 实物兑换相关路由
+				redemptions := auth.Group("/redemptions")
+				{
+					redemptions.GET("/items", redemptionHandler.ListRedeemableItems)
+					redemptions.POST("", redemptionHandler.Redeem)
+					redemptions.GET("", redemptionHandler.GetMyOrders)
+					redemptions.GET("/:id", redemptionHandler.GetOrderDetail)
+					redemptions.POST("/:id/after-sale", redemptionHandler.RequestAfterSale)
+				}
+
+				// 收货地址相关路由
+				addresses := auth.Group("/addresses")
+				{
+					addresses.GET("", addressHandler.ListAddresses)
+					addresses.POST("", addressHandler.CreateAddress)
+					addresses.GET("/:id", addressHandler.GetAddress)
+					addresses.PUT("/:id", addressHandler.UpdateAddress)
+					addresses.DELETE("/:id", addressHandler.DeleteAddress)
+					addresses.PUT("/:id/default", addressHandler.SetDefaultAddress)
+				}
+
+				// 作品首发相关路由
+				primarySales := auth.Group("/primary-sales")
+				{
+					primarySales.GET("", primarySaleHandler.ListSales)
+					primarySales.GET("/:id", primarySaleHandler.GetSale)
+					primarySales.POST("/:id/purchase", primarySaleHandler.Purchase)
+					primarySales.POST("/:id/queue", primarySaleHandler.JoinQueue)
+					primarySales.GET("/:id/queue", primarySaleHandler.GetQueueState)
+				}
+
+				// 优先购权益相关路由
+				priorityRights := auth.Group("/priority-rights")
+				{
+					priorityRights.POST("/:id/transfer", priorityRightHandler.Transfer)
+				}
+
+				// 发售提醒相关路由
+				calendar := auth.Group("/calendar")
+				{
+					calendar.POST("/:asset_id/reminder", calendarHandler.Subscribe)
+					calendar.DELETE("/:asset_id/reminder", calendarHandler.Unsubscribe)
+				}
+
+				// 盲盒相关路由
+				blindBoxes := auth.Group("/blind-boxes")
+				{
+					blindBoxes.POST("/:id/open", blindBoxHandler.OpenBox)
+				}
+
+				// 衍生作品授权路由（上游创作者审批）
+				derivativeLicenses := auth.Group("/derivative-licenses")
+				{
+					derivativeLicenses.POST("/:id/approve", derivativeHandler.ApproveLicense)
+					derivativeLicenses.POST("/:id/reject", derivativeHandler.RejectLicense)
+				}
+
+				// 首发抽签相关路由
+				lotteries := auth.Group("/lotteries")
+				{
+					lotteries.POST("/:id/register", lotteryHandler.Register)
+					lotteries.GET("/:id/my-entry", lotteryHandler.GetMyEntry)
+				}
 			}
 
 		// 公开的藏品路由
@@ -242,6 +436,44 @@ func registerRoutes(router *gin.Engine) {
 		{
 			assetsPublic.GET("", assetHandler.ListAssets)
 			assetsPublic.GET("/:id", assetHandler.GetAssetDetail)
+			assetsPublic.GET("/:id/lineage", derivativeHandler.GetLineage)
+		}
+
+		// 公开的藏品集合路由
+		collectionsPublic := v1.Group("/collections")
+		{
+			collectionsPublic.GET("", collectionHandler.ListCollections)
+			collectionsPublic.GET("/:id", collectionHandler.GetCollectionDetail)
+			collectionsPublic.GET("/:id/traits", traitHandler.GetCollectionTraits)
+			collectionsPublic.GET("/:id/instances", traitHandler.ListCollectionInstances)
+		}
+
+		// 藏品实例属性与稀有度（公开）
+		instancesPublic := v1.Group("/asset-instances")
+		{
+			instancesPublic.GET("/:id/traits", traitHandler.GetInstanceTraits)
+		}
+
+		// 藏品溯源（公开）
+		v1.GET("/provenance/:token_id", provenanceHandler.GetProvenance)
+
+		// 所有权Merkle快照（公开）
+		v1.GET("/ownership-snapshots", ownershipSnapshotHandler.ListSnapshots)
+		v1.GET("/ownership-snapshots/public-key", ownershipSnapshotHandler.GetPublicKey)
+		v1.GET("/ownership-snapshots/:id", ownershipSnapshotHandler.GetSnapshot)
+
+		// 平台指标每日快照（公开）
+		transparencyPublic := v1.Group("/transparency")
+		{
+			transparencyPublic.GET("/metrics", metricsHandler.ListMetrics)
+			transparencyPublic.GET("/metrics/latest", metricsHandler.GetLatestMetrics)
+		}
+
+		// 公开的搜索路由
+		searchPublic := v1.Group("/search")
+		{
+			searchPublic.GET("", searchHandler.Search)
+			searchPublic.GET("/suggest", searchHandler.Suggest)
 		}
 
 		// 公开的集换路由
@@ -271,6 +503,33 @@ func registerRoutes(router *gin.Engine) {
 				platformAccountPublic.GET("", platformAccountHandler.GetAccountInfo)
 				platformAccountPublic.GET("/transactions", platformAccountHandler.GetTransactions)
 			}
+
+			// 公开的行政区划路由
+			v1.GET("/regions", addressHandler.ListRegions)
+
+			// 公开的盲盒路由（任何人都可以验证抽取结果）
+			blindBoxesPublic := v1.Group("/blind-boxes")
+			{
+				blindBoxesPublic.GET("", blindBoxHandler.ListBoxes)
+				blindBoxesPublic.GET("/:id", blindBoxHandler.GetBox)
+				blindBoxesPublic.GET("/:id/verify", blindBoxHandler.VerifyBox)
+			}
+
+			// 公开的发售日历路由
+			calendarPublic := v1.Group("/calendar")
+			{
+				calendarPublic.GET("", calendarHandler.GetCalendar)
+				calendarPublic.GET("/ics", calendarHandler.ExportICS)
+			}
+
+			// 公开的首发抽签路由（开奖后任何人都可以验证结果）
+			lotteriesPublic := v1.Group("/lotteries")
+			{
+				lotteriesPublic.GET("", lotteryHandler.ListLotteries)
+				lotteriesPublic.GET("/:id", lotteryHandler.GetLottery)
+				lotteriesPublic.GET("/:id/entries", lotteryHandler.GetEntries)
+				lotteriesPublic.GET("/:id/verify", lotteryHandler.VerifyLottery)
+			}
 		}
 
 	// 注册自定义模板函数
@@ -298,55 +557,46 @@ func registerRoutes(router *gin.Engine) {
 
 		// 需要认证的路由
 		authAdmin := admin.Group("/")
-		authAdmin.Use(middleware.AdminAuthMiddleware())
+		authAdmin.Use(middleware.AdminAuthMiddleware(), middleware.AdminPermissionMiddleware())
 		{
 			authAdmin.GET("/profile", adminHandler.GetProfile)
-			authAdmin.GET("/dashboard", func(c *gin.Context) {
-				// 模拟数据
-				data := gin.H{
-					"Title":            "仪表盘",
-					"ActiveMenu":       "dashboard",
-					"TotalUsers":       1234,
-					"TotalAssets":      567,
-					"PendingReviews":   12,
-					"TodayTradeVolume": "12,345.67890123",
-				}
-				c.HTML(http.StatusOK, "admin_dashboard.html", data)
-			})
+			authAdmin.GET("/dashboard", middleware.RequirePermission(services.PermDashboardView), adminDashboardHandler.DashboardPage)
+			authAdmin.GET("/dashboard/stats", middleware.RequirePermission(services.PermDashboardView), adminDashboardHandler.GetStats)
 
 			// 用户管理路由
 			users := authAdmin.Group("/users")
 			{
-				users.GET("", adminHandler.ListUsersPage)
+				users.GET("", middleware.RequirePermission(services.PermUserView), adminHandler.ListUsersPage)
 				// users.GET("/:id", adminHandler.GetUserDetailPage) // 用户详情页
-				users.PUT("/:id/status", adminHandler.UpdateUserStatus) // 禁用/解禁 API
+				users.PUT("/:id/status", middleware.RequirePermission(services.PermUserStatus), adminHandler.UpdateUserStatus) // 禁用/解禁 API
 			}
 
 			// 藏品审核路由
-			assetsReview := authAdmin.Group("/review/assets")
+			assetsReview := authAdmin.Group("/review/assets", middleware.RequirePermission(services.PermReview))
 			{
 				assetsReview.GET("", adminHandler.ListAssetReviewPage)
 				assetsReview.PUT("/:id", adminHandler.ReviewAsset)
 			}
 
 				// 空投管理路由
-				airdrop := authAdmin.Group("/airdrop")
+				airdrop := authAdmin.Group("/airdrop", middleware.RequirePermission(services.PermAirdrop))
 				{
 					airdrop.POST("/points", adminHandler.AirdropPoints)
 					airdrop.POST("/asset", adminHandler.AirdropAsset)
 				}
 				
 				// 创作审核管理路由
-				creationsAdmin := authAdmin.Group("/creations")
+				creationsAdmin := authAdmin.Group("/creations", middleware.RequirePermission(services.PermReview))
 				{
 					creationsAdmin.GET("", adminCreationHandler.GetCreationList)
 					creationsAdmin.GET("/:id", adminCreationHandler.GetCreationDetail)
 					creationsAdmin.POST("/:id/approve", adminCreationHandler.ApproveCreation)
 					creationsAdmin.POST("/:id/reject", adminCreationHandler.RejectCreation)
+					creationsAdmin.POST("/:id/publish", adminCreationHandler.PublishCreation)
 				}
 				
 				// 任务管理路由
-				tasksAdmin := authAdmin.Group("/tasks")
+				tasksAdmin := authAdmin.Group("/tasks", middleware.RequirePermission(services.PermTask))
 				{
 					tasksAdmin.GET("", adminTaskHandler.GetTaskList)
 					tasksAdmin.POST("", adminTaskHandler.CreateTask)
@@ -356,7 +606,7 @@ func registerRoutes(router *gin.Engine) {
 				}
 				
 				// 公告管理路由
-				announcementsAdmin := authAdmin.Group("/announcements")
+				announcementsAdmin := authAdmin.Group("/announcements", middleware.RequirePermission(services.PermAnnouncement))
 				{
 					announcementsAdmin.GET("", adminAnnouncementHandler.GetAnnouncementList)
 					announcementsAdmin.POST("", adminAnnouncementHandler.CreateAnnouncement)
@@ -365,12 +615,117 @@ func registerRoutes(router *gin.Engine) {
 					announcementsAdmin.POST("/:id/toggle-pin", adminAnnouncementHandler.TogglePin)
 				}
 				
+				// 社区事件哈希链校验路由
+				eventsAdmin := authAdmin.Group("/events", middleware.RequirePermission(services.PermChain))
+				{
+					eventsAdmin.GET("/verify-chain", adminEventHandler.VerifyChain)
+				}
+
+				// 上链任务管理路由
+				chainAnchorsAdmin := authAdmin.Group("/chain-anchors", middleware.RequirePermission(services.PermChain))
+				{
+					chainAnchorsAdmin.GET("", adminChainHandler.ListAnchors)
+					chainAnchorsAdmin.POST("/:id/retry", adminChainHandler.RetryAnchor)
+				}
+
 				// 系统配置管理路由
-				configAdmin := authAdmin.Group("/config")
+				configAdmin := authAdmin.Group("/config", middleware.RequirePermission(services.PermConfig))
 				{
 					configAdmin.GET("", adminConfigHandler.GetConfig)
 					configAdmin.PUT("", adminConfigHandler.UpdateConfig)
 				}
+
+				// 合成配方管理路由
+				synthesisAdmin := authAdmin.Group("/synthesis/recipes", middleware.RequirePermission(services.PermCatalog))
+				{
+					synthesisAdmin.GET("", adminSynthesisHandler.ListRecipes)
+					synthesisAdmin.POST("", adminSynthesisHandler.CreateRecipe)
+					synthesisAdmin.PUT("/:id/status", adminSynthesisHandler.UpdateRecipeStatus)
+				}
+
+				// 实物兑换管理路由
+				redemptionAdmin := authAdmin.Group("/redemptions", middleware.RequirePermission(services.PermRedemption))
+				{
+					redemptionAdmin.GET("/items", adminRedemptionHandler.ListItems)
+					redemptionAdmin.POST("/items", adminRedemptionHandler.CreateItem)
+					redemptionAdmin.PUT("/items/:id", adminRedemptionHandler.UpdateItem)
+					redemptionAdmin.GET("/orders", adminRedemptionHandler.ListOrders)
+					redemptionAdmin.PUT("/orders/:id/status", adminRedemptionHandler.UpdateOrderStatus)
+				}
+
+				// 作品首发管理路由
+				primarySalesAdmin := authAdmin.Group("/primary-sales", middleware.RequirePermission(services.PermSale))
+				{
+					primarySalesAdmin.GET("", adminPrimarySaleHandler.ListSales)
+					primarySalesAdmin.PUT("/:id", adminPrimarySaleHandler.UpdateSale)
+					primarySalesAdmin.POST("/:id/close", adminPrimarySaleHandler.CloseSale)
+					primarySalesAdmin.GET("/:id/priority-rights", adminPriorityRightHandler.ListRights)
+					primarySalesAdmin.POST("/:id/priority-rights/import", adminPriorityRightHandler.ImportCSV)
+					primarySalesAdmin.GET("/:id/priority-snapshots", adminPriorityRightHandler.ListSnapshots)
+					primarySalesAdmin.POST("/:id/priority-snapshots", adminPriorityRightHandler.CreateSnapshot)
+				}
+
+				// 盲盒管理路由
+				blindBoxAdmin := authAdmin.Group("/blind-boxes", middleware.RequirePermission(services.PermSale))
+				{
+					blindBoxAdmin.GET("", adminBlindBoxHandler.ListBoxes)
+					blindBoxAdmin.POST("", adminBlindBoxHandler.CreateBox)
+					blindBoxAdmin.POST("/:id/commit", adminBlindBoxHandler.CommitSeed)
+					blindBoxAdmin.POST("/:id/close", adminBlindBoxHandler.CloseBox)
+					blindBoxAdmin.POST("/:id/reveal", adminBlindBoxHandler.RevealSeed)
+				}
+
+				// 首发抽签管理路由
+				lotteryAdmin := authAdmin.Group("/lotteries", middleware.RequirePermission(services.PermSale))
+				{
+					lotteryAdmin.GET("", adminLotteryHandler.ListLotteries)
+					lotteryAdmin.POST("", adminLotteryHandler.CreateLottery)
+					lotteryAdmin.POST("/:id/draw", adminLotteryHandler.Draw)
+					lotteryAdmin.POST("/:id/cancel", adminLotteryHandler.CancelLottery)
+				}
+
+				// 藏品集合管理路由
+				collectionAdmin := authAdmin.Group("/collections", middleware.RequirePermission(services.PermCatalog))
+				{
+					collectionAdmin.GET("", adminCollectionHandler.ListCollections)
+					collectionAdmin.POST("", adminCollectionHandler.CreateCollection)
+					collectionAdmin.PUT("/:id", adminCollectionHandler.UpdateCollection)
+					collectionAdmin.DELETE("/:id", adminCollectionHandler.DeleteCollection)
+					collectionAdmin.POST("/:id/rarity", adminTraitHandler.RecomputeRarity)
+				}
+
+				// 衍生作品授权管理
+				derivativeAdmin := authAdmin.Group("/derivative-licenses", middleware.RequirePermission(services.PermReview))
+				{
+					derivativeAdmin.GET("", adminDerivativeHandler.ListLicenses)
+					derivativeAdmin.POST("/:id/approve", adminDerivativeHandler.ApproveLicense)
+					derivativeAdmin.POST("/:id/reject", adminDerivativeHandler.RejectLicense)
+				}
+
+				// 藏品属性管理
+				assetTraitAdmin := authAdmin.Group("/assets", middleware.RequirePermission(services.PermCatalog))
+				{
+					assetTraitAdmin.PUT("/:id/traits", adminTraitHandler.SetAssetTraits)
+				}
+				instanceTraitAdmin := authAdmin.Group("/asset-instances", middleware.RequirePermission(services.PermCatalog))
+				{
+					instanceTraitAdmin.PUT("/:id/traits", adminTraitHandler.SetInstanceTraits)
+				}
+
+				// 管理员及角色管理路由（仅超级管理员）
+				adminsAdmin := authAdmin.Group("/admins", middleware.RequirePermission(services.PermAdminManage))
+				{
+					adminsAdmin.GET("", adminRoleHandler.ListAdmins)
+					adminsAdmin.POST("", adminRoleHandler.CreateAdmin)
+					adminsAdmin.PUT("/:id", adminRoleHandler.UpdateAdmin)
+				}
+				rolesAdmin := authAdmin.Group("/roles", middleware.RequirePermission(services.PermAdminManage))
+				{
+					rolesAdmin.GET("", adminRoleHandler.ListRoles)
+					rolesAdmin.POST("", adminRoleHandler.CreateRole)
+					rolesAdmin.PUT("/:name", adminRoleHandler.UpdateRole)
+					rolesAdmin.DELETE("/:name", adminRoleHandler.DeleteRole)
+				}
 			}
 		}
 		
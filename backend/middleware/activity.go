@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"hoho-miniapp/backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ActivityMiddleware 记录已登录用户的日活（供管理后台仪表盘统计），需放在 AuthMiddleware 之后
+func ActivityMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if userID, ok := c.Get("user_id"); ok {
+			if id, ok := userID.(uint64); ok {
+				services.TrackDailyActive(id)
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+
+	"hoho-miniapp/backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminPermissionMiddleware 加载管理员角色权限并拒绝已停用的管理员，需放在 AdminAuthMiddleware 之后
+func AdminPermissionMiddleware() gin.HandlerFunc {
+	adminService := services.NewAdminService()
+	return func(c *gin.Context) {
+		adminID, _ := c.Get("admin_id")
+		id, ok := adminID.(uint64)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"code": 401, "message": "未授权"})
+			c.Abort()
+			return
+		}
+
+		admin, err := adminService.GetAdminWithPermissions(id)
+		if err != nil {
+			c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "无权限：" + err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Set("admin_role", admin.Role)
+		c.Set("admin_permissions", admin.Permissions)
+		c.Next()
+	}
+}
+
+// RequirePermission 要求当前管理员拥有指定权限，需放在 AdminPermissionMiddleware 之后
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("admin_permissions")
+		permissions, _ := value.([]string)
+		if !services.HasPermission(permissions, permission) {
+			c.JSON(http.StatusForbidden, gin.H{"code": 403, "message": "无权限：需要 " + permission + " 权限"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
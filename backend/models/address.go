@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// UserAddress 用户收货地址
+type UserAddress struct {
+	ID           uint64    `gorm:"primaryKey" json:"id"`
+	UserID       uint64    `gorm:"index;not null" json:"user_id"`
+	ReceiverName string    `gorm:"type:varchar(50);not null" json:"receiver_name"`
+	Phone        string    `gorm:"type:varchar(20);not null" json:"phone"`
+	ProvinceCode string    `gorm:"type:char(6);not null" json:"province_code"` // GB/T 2260 行政区划编码
+	Province     string    `gorm:"type:varchar(50);not null" json:"province"`
+	CityCode     string    `gorm:"type:char(6);not null" json:"city_code"`
+	City         string    `gorm:"type:varchar(50);not null" json:"city"`
+	DistrictCode string    `gorm:"type:char(6);not null" json:"district_code"`
+	District     string    `gorm:"type:varchar(50);not null" json:"district"`
+	Detail       string    `gorm:"type:varchar(200);not null" json:"detail"` // 详细地址
+	IsDefault    bool      `gorm:"not null;default:false" json:"is_default"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (UserAddress) TableName() string {
+	return "user_addresses"
+}
+
+// FullAddress 返回完整地址文本
+func (a *UserAddress) FullAddress() string {
+	return a.Province + a.City + a.District + a.Detail
+}
@@ -13,11 +13,13 @@ type Admin struct {
 	Username     string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"username"`
 	PasswordHash string    `gorm:"type:varchar(255);not null" json:"-"`
 	Email        string    `gorm:"type:varchar(100);uniqueIndex" json:"email"`
-	Role         string    `gorm:"type:enum('super_admin', 'admin', 'reviewer', 'customer_service');default:'admin'" json:"role"`
+	Role         string    `gorm:"type:varchar(30);index;default:'admin'" json:"role"` // 对应 admin_roles.name，内置 super_admin, admin, reviewer, customer_service
 	Status       string    `gorm:"type:enum('active', 'inactive');default:'active'" json:"status"`
 	LastLoginAt  time.Time `json:"last_login_at"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
+
+	Permissions []string `gorm:"-" json:"permissions,omitempty"` // 角色拥有的权限（查询时填充）
 }
 
 // TableName 为 Admin 指定表名
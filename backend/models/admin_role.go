@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// AdminRole 管理员角色，内置角色不可删除，super_admin 始终拥有全部权限
+type AdminRole struct {
+	ID          uint64    `gorm:"primaryKey" json:"id"`
+	Name        string    `gorm:"type:varchar(30);uniqueIndex;not null" json:"name"`
+	Description string    `gorm:"type:varchar(255)" json:"description"`
+	IsSystem    bool      `gorm:"not null;default:false" json:"is_system"` // 内置角色
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	Permissions []string `gorm:"-" json:"permissions"`
+}
+
+// AdminRolePermission 角色与权限的对应关系
+type AdminRolePermission struct {
+	ID         uint64 `gorm:"primaryKey" json:"id"`
+	RoleID     uint64 `gorm:"uniqueIndex:idx_role_permission;not null" json:"role_id"`
+	Permission string `gorm:"type:varchar(50);uniqueIndex:idx_role_permission;not null" json:"permission"`
+}
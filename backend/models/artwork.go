@@ -3,6 +3,7 @@ package models
 import (
 	"time"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
 )
 
@@ -13,39 +14,69 @@ type Collection struct {
 	Name        string    `gorm:"type:varchar(100);not null" json:"name"`
 	Description string    `gorm:"type:varchar(500)" json:"description"`
 	CoverImage  string    `gorm:"type:varchar(500)" json:"cover_image"`
+	BannerImage string    `gorm:"type:varchar(500)" json:"banner_image"` // 详情页顶部横幅
+	CreatorID   uint64    `gorm:"index;default:0" json:"creator_id"`     // 创作者ID，平台集合为0
+	CreatorName string    `gorm:"type:varchar(50)" json:"creator_name"`
+	SortOrder   int       `gorm:"default:0" json:"sort_order"` // 展示顺序，越小越靠前
 	Status      string    `gorm:"type:enum('active', 'inactive');default:'active'" json:"status"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
-// Asset 藏品（SKU）
+// Asset 藏品（SKU），平台唯一的藏品目录模型
+// 社区创作发布、首发、交易、出价均使用 Asset/AssetInstance，旧的 artworks 表数据通过 cmd/migrate 迁移
 type Asset struct {
 	gorm.Model
-	ID           uint64    `gorm:"primaryKey" json:"id"`
-	CollectionID uint64    `gorm:"index;not null" json:"collection_id"`
-	Name         string    `gorm:"type:varchar(100);not null" json:"name"`
-	Description  string    `gorm:"type:varchar(500)" json:"description"`
-	MediaURL     string    `gorm:"type:varchar(500);not null" json:"media_url"`
-	MediaType    string    `gorm:"type:enum('image', 'video', 'audio');not null" json:"media_type"`
-	TotalSupply  int       `gorm:"not null" json:"total_supply"`     // 总发行量
-	MintedCount  int       `gorm:"default:0" json:"minted_count"`    // 已铸造数量
-	CreatorID    uint64    `gorm:"index;not null" json:"creator_id"` // 创作者ID
-	Status       string    `gorm:"type:enum('pending_review', 'approved', 'rejected', 'active', 'inactive');default:'pending_review'" json:"status"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID                uint64          `gorm:"primaryKey" json:"id"`
+	CollectionID      uint64          `gorm:"index;not null" json:"collection_id"`
+	Name              string          `gorm:"type:varchar(100);not null" json:"name"`
+	Description       string          `gorm:"type:varchar(500)" json:"description"`
+	MediaURL          string          `gorm:"type:varchar(500);not null" json:"media_url"`
+	MediaType         string          `gorm:"type:enum('image', 'video', 'audio', '3d');not null" json:"media_type"`
+	ThumbnailURL      string          `gorm:"type:varchar(500)" json:"thumbnail_url"`
+	TotalSupply       int             `gorm:"not null" json:"total_supply"`     // 总发行量
+	MintedCount       int             `gorm:"default:0" json:"minted_count"`    // 已铸造数量
+	CreatorID         uint64          `gorm:"index;not null" json:"creator_id"` // 创作者ID，平台藏品为0
+	CreatorName       string          `gorm:"type:varchar(50)" json:"creator_name"`
+	Price             decimal.Decimal `gorm:"type:decimal(30,8);default:0" json:"price"` // 发行价（积分）
+	Source            string          `gorm:"type:enum('platform', 'community', 'jingtan', 'waveup');default:'platform'" json:"source"`
+	Series            string          `gorm:"type:varchar(50)" json:"series"`
+	ReleaseDate       *time.Time      `gorm:"index" json:"release_date"`                              // 发售时间
+	CreationID        *uint64         `gorm:"index" json:"creation_id"`                               // 来源创作ID（社区作品）
+	ParentAssetID     *uint64         `gorm:"index" json:"parent_asset_id"`                           // 上游藏品（衍生作品）
+	ParentRoyaltyRate decimal.Decimal `gorm:"type:decimal(5,2);default:0" json:"parent_royalty_rate"` // 成交时分给上游创作者的比例（%）
+	LegacyArtworkID   *uint64         `gorm:"uniqueIndex" json:"-"`                                   // 迁移前 artworks 表的ID
+	Status            string          `gorm:"type:enum('pending_review', 'approved', 'rejected', 'active', 'inactive');default:'pending_review'" json:"status"`
+	CreatedAt         time.Time       `json:"created_at"`
+	UpdatedAt         time.Time       `json:"updated_at"`
+}
+
+// SoldOut 是否已全部铸造
+func (a *Asset) SoldOut() bool {
+	return a.MintedCount >= a.TotalSupply
 }
 
 // AssetInstance 藏品实例（具体编号）
 type AssetInstance struct {
 	gorm.Model
-	ID         uint64    `gorm:"primaryKey" json:"id"`
-	AssetID    uint64    `gorm:"index;not null" json:"asset_id"`
-	InstanceNo int       `gorm:"not null" json:"instance_no"`                            // 实例编号（#1, #2, #3...）
-	OwnerID    uint64    `gorm:"index;not null" json:"owner_id"`                         // 当前持有者ID
-	TokenID    string    `gorm:"type:varchar(255);uniqueIndex;not null" json:"token_id"` // 唯一标识符，模拟链上TokenID
-	Status     string    `gorm:"type:enum('in_wallet', 'on_sale', 'pending_trade', 'burned');default:'in_wallet'" json:"status"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID               uint64     `gorm:"primaryKey" json:"id"`
+	AssetID          uint64     `gorm:"index;not null" json:"asset_id"`
+	InstanceNo       int        `gorm:"not null" json:"instance_no"`                            // 实例编号（#1, #2, #3...）
+	OwnerID          uint64     `gorm:"index;not null" json:"owner_id"`                         // 当前持有者ID
+	TokenID          string     `gorm:"type:varchar(255);uniqueIndex;not null" json:"token_id"` // 唯一标识符，模拟链上TokenID
+	Status           string     `gorm:"type:enum('in_wallet', 'on_sale', 'pending_trade', 'burned');default:'in_wallet'" json:"status"`
+	RedeemedAt       *time.Time `json:"redeemed_at"`                                            // 已兑换实物的时间（兑换后不销毁时标记）
+	LegacyInstanceID *uint64    `gorm:"uniqueIndex" json:"-"`                                   // 迁移前 artwork_instances 表的ID
+	RarityScore      float64    `gorm:"default:0" json:"rarity_score"`                          // 集合内稀有度得分
+	RarityRank       int        `gorm:"index;default:0" json:"rarity_rank"`                     // 集合内稀有度排名，1为最稀有，0为未计算
+	MintTxHash       string     `gorm:"type:varchar(66)" json:"mint_tx_hash"`                   // 铸造上链交易哈希
+	LastTxHash       string     `gorm:"type:varchar(66)" json:"last_tx_hash"`                   // 最近一次已确认的上链交易哈希
+	ChainStatus      string     `gorm:"type:varchar(20);default:'pending'" json:"chain_status"` // pending, anchored, failed
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	// 关联
+	Asset *Asset `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
 }
 
 // JingtanAsset 鲸探资产映射表
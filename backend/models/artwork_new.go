@@ -4,12 +4,15 @@ import (
 	"time"
 )
 
-// Artwork 作品模型（统一术语：asset → artwork）
+// Artwork 旧版作品模型
+// Deprecated: 藏品目录已统一为 Asset/AssetInstance，本模型仅供 cmd/migrate 读取 artworks 表迁移数据
 type Artwork struct {
 	ID           uint       `gorm:"primaryKey" json:"id"`
 	Title        string     `gorm:"size:100;not null" json:"title"`
 	Description  string     `gorm:"type:text" json:"description"`
 	CreatorName  string     `gorm:"size:50" json:"creator_name"`
+	CreationID   *uint      `gorm:"index" json:"creation_id"` // 来源创作ID（社区作品）
+	CreatorID    *uint      `gorm:"index" json:"creator_id"`  // 创作者用户ID（社区作品）
 	MediaType    string     `gorm:"type:enum('image','video','audio','3d');default:'image'" json:"media_type"`
 	MediaURL     string     `gorm:"size:255;not null" json:"media_url"`
 	ThumbnailURL string     `gorm:"size:255" json:"thumbnail_url"`
@@ -24,7 +27,8 @@ type Artwork struct {
 	UpdatedAt    time.Time  `json:"updated_at"`
 }
 
-// ArtworkInstance 作品实例模型
+// ArtworkInstance 旧版作品实例模型
+// Deprecated: 使用 AssetInstance，本模型仅供 cmd/migrate 读取 artwork_instances 表迁移数据
 type ArtworkInstance struct {
 	ID           uint      `gorm:"primaryKey" json:"id"`
 	ArtworkID    uint      `gorm:"not null;index" json:"artwork_id"`
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// AssetTrait 藏品属性（特征键值对）
+// AssetInstanceID 为空时对该藏品的全部实例生效；不为空时仅对该实例生效，
+// 并覆盖同一属性类型的藏品级属性（如特殊编号、隐藏款标记）
+type AssetTrait struct {
+	ID              uint64    `gorm:"primaryKey" json:"id"`
+	AssetID         uint64    `gorm:"index;not null" json:"asset_id"`
+	AssetInstanceID *uint64   `gorm:"index" json:"asset_instance_id"`
+	TraitType       string    `gorm:"type:varchar(50);not null;index:idx_asset_traits_type_value" json:"trait_type"`
+	Value           string    `gorm:"type:varchar(100);not null;index:idx_asset_traits_type_value" json:"value"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
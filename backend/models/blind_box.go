@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// BlindBox 盲盒
+// 开售前生成随机种子并公布其SHA-256承诺（commit），售罄或结束后公开种子（reveal），
+// 每次抽取另外混入抽取时的事件哈希链链头，任何人都可以用公开的种子和各次抽取的链头重放全部抽取结果进行验证
+type BlindBox struct {
+	ID             uint64          `gorm:"primaryKey" json:"id"`
+	Name           string          `gorm:"type:varchar(100);not null" json:"name"`
+	Description    string          `gorm:"type:varchar(1000)" json:"description"`
+	CoverImage     string          `gorm:"type:varchar(500)" json:"cover_image"`
+	Price          decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"price"`
+	CommissionRate decimal.Decimal `gorm:"type:decimal(5,2);not null;default:40.00" json:"commission_rate"` // 平台分成比例（%），平台藏品全部计入平台
+	TotalSupply    int             `gorm:"not null" json:"total_supply"`                                    // 各档位供应量之和
+	SoldCount      int             `gorm:"not null;default:0" json:"sold_count"`
+	StartAt        time.Time       `gorm:"not null" json:"start_at"`
+	EndAt          *time.Time      `json:"end_at"`
+	Seed           string          `gorm:"type:varchar(64)" json:"-"`      // 随机种子，揭晓前保密
+	SeedHash       string          `gorm:"type:char(64)" json:"seed_hash"` // 种子的SHA-256承诺
+	RevealedAt     *time.Time      `json:"revealed_at"`                    // 种子公开时间
+	Status         string          `gorm:"type:enum('draft', 'on_sale', 'closed', 'revealed');default:'draft'" json:"status"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+
+	// 关联
+	Tiers []BlindBoxTier `gorm:"foreignKey:BoxID" json:"tiers,omitempty"`
+}
+
+// BlindBoxTier 盲盒档位（如普通/稀有/隐藏款）
+type BlindBoxTier struct {
+	ID         uint64 `gorm:"primaryKey" json:"id"`
+	BoxID      uint64 `gorm:"index;not null" json:"box_id"`
+	Name       string `gorm:"type:varchar(50);not null" json:"name"`
+	AssetID    uint64 `gorm:"not null" json:"asset_id"`
+	Weight     int    `gorm:"not null" json:"weight"` // 抽取权重
+	Supply     int    `gorm:"not null" json:"supply"` // 档位供应量
+	DrawnCount int    `gorm:"not null;default:0" json:"drawn_count"`
+
+	// 关联
+	Asset *Asset `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
+}
+
+// BlindBoxDraw 盲盒抽取记录
+type BlindBoxDraw struct {
+	ID              uint64          `gorm:"primaryKey" json:"id"`
+	BoxID           uint64          `gorm:"uniqueIndex:idx_box_draw;not null" json:"box_id"`
+	DrawIndex       int             `gorm:"uniqueIndex:idx_box_draw;not null" json:"draw_index"` // 抽取序号，从0开始
+	UserID          uint64          `gorm:"index;not null" json:"user_id"`
+	TierID          uint64          `gorm:"not null" json:"tier_id"`
+	AssetInstanceID uint64          `gorm:"not null" json:"asset_instance_id"`
+	Price           decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"price"`
+	CreatorIncome   decimal.Decimal `gorm:"type:decimal(30,8);not null;default:0" json:"creator_income"`
+	PlatformIncome  decimal.Decimal `gorm:"type:decimal(30,8);not null;default:0" json:"platform_income"`
+	ParentRoyalty   decimal.Decimal `gorm:"type:decimal(30,8);not null;default:0" json:"parent_royalty"` // 从创作者收入中分给上游的部分（衍生作品）
+	EntropySeq      uint64          `gorm:"not null;default:0" json:"entropy_seq"`                       // 抽取时事件哈希链链头序号
+	EntropyHash     string          `gorm:"type:char(64)" json:"entropy_hash"`                           // 抽取时事件哈希链链头哈希，与种子共同决定档位
+	CreatedAt       time.Time       `json:"created_at"`
+
+	// 关联
+	Tier          *BlindBoxTier  `gorm:"foreignKey:TierID" json:"tier,omitempty"`
+	AssetInstance *AssetInstance `gorm:"foreignKey:AssetInstanceID" json:"asset_instance,omitempty"`
+}
+
+// TableName 指定表名
+func (BlindBox) TableName() string {
+	return "blind_boxes"
+}
+
+// TableName 指定表名
+func (BlindBoxTier) TableName() string {
+	return "blind_box_tiers"
+}
+
+// TableName 指定表名
+func (BlindBoxDraw) TableName() string {
+	return "blind_box_draws"
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// ChainAnchor 上链任务（发件箱）
+// 铸造、转移、销毁时与业务变更在同一事务中写入，由后台任务异步提交到 ChainAdapter 并等待确认，失败按退避重试
+type ChainAnchor struct {
+	ID              uint64     `gorm:"primaryKey" json:"id"`
+	AssetInstanceID uint64     `gorm:"index;not null" json:"asset_instance_id"`
+	TokenID         string     `gorm:"type:varchar(255);not null" json:"token_id"`
+	Operation       string     `gorm:"type:varchar(20);not null" json:"operation"` // mint, transfer, burn
+	FromUserID      uint64     `gorm:"not null;default:0" json:"from_user_id"`     // 铸造时为0
+	ToUserID        uint64     `gorm:"not null;default:0" json:"to_user_id"`       // 销毁时为0
+	Status          string     `gorm:"type:enum('pending', 'submitting', 'submitted', 'confirmed', 'failed');default:'pending';index:idx_chain_anchor_status" json:"status"`
+	TxHash          string     `gorm:"type:varchar(66)" json:"tx_hash"`
+	Attempts        int        `gorm:"not null;default:0" json:"attempts"`
+	LastError       string     `gorm:"type:varchar(500)" json:"last_error"`
+	NextAttemptAt   time.Time  `gorm:"index:idx_chain_anchor_status" json:"next_attempt_at"`
+	SubmittedAt     *time.Time `json:"submitted_at"`
+	ConfirmedAt     *time.Time `json:"confirmed_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// SimulatedLedgerEntry 本地模拟账本中的一笔交易（只追加），交易哈希链接上一笔交易
+type SimulatedLedgerEntry struct {
+	ID        uint64    `gorm:"primaryKey" json:"height"`              // 区块高度
+	AnchorID  uint64    `gorm:"uniqueIndex;not null" json:"anchor_id"` // 幂等键
+	TxHash    string    `gorm:"type:varchar(66);uniqueIndex;not null" json:"tx_hash"`
+	PrevHash  string    `gorm:"type:varchar(66);not null" json:"prev_hash"`
+	Payload   string    `gorm:"type:text;not null" json:"payload"`
+	CreatedAt time.Time `json:"created_at"`
+}
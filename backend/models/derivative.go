@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DerivativeLicense 衍生作品授权
+// 创作提交时声明上游藏品后生成，由上游创作者或管理员审批（平台藏品仅管理员可审批）；
+// 授权通过后创作才能发布，发布的藏品按授权比例将收入分给上游创作者
+type DerivativeLicense struct {
+	ID              uint64          `gorm:"primaryKey" json:"id"`
+	CreationID      uint64          `gorm:"uniqueIndex;not null" json:"creation_id"`
+	ApplicantID     uint64          `gorm:"index;not null" json:"applicant_id"` // 衍生作品创作者
+	ParentAssetID   uint64          `gorm:"index;not null" json:"parent_asset_id"`
+	ParentCreatorID uint64          `gorm:"index;not null" json:"parent_creator_id"`                  // 上游创作者，平台藏品为0
+	RoyaltyRate     decimal.Decimal `gorm:"type:decimal(5,2);not null;default:0" json:"royalty_rate"` // 上游分成比例（%）
+	Status          string          `gorm:"type:enum('pending', 'approved', 'rejected');default:'pending'" json:"status"`
+	ApprovedBy      string          `gorm:"type:varchar(20)" json:"approved_by"` // creator 或 admin
+	ReviewerID      *uint64         `json:"reviewer_id"`
+	RejectReason    string          `gorm:"type:varchar(255)" json:"reject_reason"`
+	RespondedAt     *time.Time      `json:"responded_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+
+	// 关联
+	Creation    *Creation `gorm:"foreignKey:CreationID" json:"creation,omitempty"`
+	ParentAsset *Asset    `gorm:"foreignKey:ParentAssetID" json:"parent_asset,omitempty"`
+}
+
+// RoyaltyPayout 衍生作品的上游版税分账明细，每笔成交按血缘链逐级生成
+type RoyaltyPayout struct {
+	ID              uint64          `gorm:"primaryKey" json:"id"`
+	SourceType      string          `gorm:"type:varchar(30);not null;index:idx_royalty_payouts_source" json:"source_type"` // primary_sale_order、trade 或 blind_box_draw
+	SourceID        uint64          `gorm:"not null;index:idx_royalty_payouts_source" json:"source_id"`
+	AssetID         uint64          `gorm:"index;not null" json:"asset_id"`          // 成交的衍生藏品
+	AncestorAssetID uint64          `gorm:"index;not null" json:"ancestor_asset_id"` // 收取分成的上游藏品
+	RecipientID     uint64          `gorm:"index;not null" json:"recipient_id"`      // 上游创作者，平台藏品为0（计入平台账户）
+	Depth           int             `gorm:"not null" json:"depth"`                   // 1为直接上游
+	Amount          decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"amount"`
+	Status          string          `gorm:"type:enum('pending', 'paid');default:'pending'" json:"status"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
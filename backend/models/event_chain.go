@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// EventChainHead 社区事件哈希链的链头（仅一行，ID固定为1）
+// 追加事件时加行锁串行化，保证序号连续；校验时用于发现链尾被截断
+type EventChainHead struct {
+	ID        uint64    `gorm:"primaryKey" json:"id"`
+	LastSeq   uint64    `gorm:"not null;default:0" json:"last_seq"`
+	LastHash  string    `gorm:"type:varchar(64);not null;default:''" json:"last_hash"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Lottery 首发抽签
+// 创建时公布随机种子的SHA-256承诺，报名截止后用种子和最终报名名单的哈希开奖并公开种子，
+// 中签者在购买窗口内按首发价格购买，任何人都可以用公开的种子和报名名单重放开奖结果
+type Lottery struct {
+	ID                uint64     `gorm:"primaryKey" json:"id"`
+	SaleID            uint64     `gorm:"uniqueIndex;not null" json:"sale_id"` // 关联的首发
+	WinnerCount       int        `gorm:"not null" json:"winner_count"`        // 中签名额
+	RegisterStartAt   time.Time  `gorm:"not null" json:"register_start_at"`
+	RegisterEndAt     time.Time  `gorm:"not null" json:"register_end_at"`
+	PurchaseStartAt   time.Time  `gorm:"not null" json:"purchase_start_at"`
+	PurchaseEndAt     time.Time  `gorm:"not null" json:"purchase_end_at"`
+	BonusAssetID      *uint64    `json:"bonus_asset_id"`                                 // 持有该藏品可获得额外签数
+	TicketsPerHolding int        `gorm:"not null;default:0" json:"tickets_per_holding"`  // 每持有一份增加的签数
+	MaxTicketsPerUser int        `gorm:"not null;default:1" json:"max_tickets_per_user"` // 每人签数上限
+	Seed              string     `gorm:"type:varchar(64)" json:"-"`                      // 随机种子，开奖前保密
+	SeedHash          string     `gorm:"type:char(64)" json:"seed_hash"`
+	RevealedSeed      string     `gorm:"type:varchar(64)" json:"revealed_seed"` // 开奖后公开的种子
+	EntriesHash       string     `gorm:"type:char(64)" json:"entries_hash"`     // 报名截止后报名名单的SHA-256，与种子共同决定开奖结果
+	EntryCount        int        `gorm:"not null;default:0" json:"entry_count"`
+	TotalTickets      int        `gorm:"not null;default:0" json:"total_tickets"`
+	Status            string     `gorm:"type:enum('registering', 'drawn', 'finished', 'cancelled');default:'registering'" json:"status"`
+	DrawnAt           *time.Time `json:"drawn_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	// 关联
+	Sale *PrimarySale `gorm:"foreignKey:SaleID" json:"sale,omitempty"`
+}
+
+// LotteryEntry 抽签报名记录
+type LotteryEntry struct {
+	ID           uint64          `gorm:"primaryKey" json:"id"`
+	LotteryID    uint64          `gorm:"uniqueIndex:idx_lottery_user;not null" json:"lottery_id"`
+	UserID       uint64          `gorm:"uniqueIndex:idx_lottery_user;not null" json:"user_id"`
+	Tickets      int             `gorm:"not null" json:"tickets"`                          // 签数（抽中权重）
+	FrozenAmount decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"frozen_amount"` // 报名时冻结的积分
+	WinRank      int             `gorm:"not null;default:0" json:"win_rank"`               // 中签顺序，从1开始，未中签为0
+	Status       string          `gorm:"type:enum('registered', 'won', 'lost', 'purchased', 'expired');default:'registered'" json:"status"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Lottery) TableName() string {
+	return "lotteries"
+}
+
+// TableName 指定表名
+func (LotteryEntry) TableName() string {
+	return "lottery_entries"
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// MetricSnapshot 每日平台指标快照（透明公示）
+// 当日流量指标按统计日期 [00:00, 次日00:00) 汇总，平台余额为统计日期结束时的账户余额
+// 积分与持有人数无法按历史时点回溯，仅在统计日期刚结束时采集（StockCapturedAt 为采集时间），补生成的历史快照中为空
+// 快照生成后不再修改，公示数据以此表为准
+type MetricSnapshot struct {
+	ID                uint64              `gorm:"primaryKey" json:"id"`
+	SnapshotDate      string              `gorm:"type:varchar(10);uniqueIndex;not null" json:"snapshot_date"`    // 统计日期，如 2026-01-02
+	PointsHeld        decimal.NullDecimal `gorm:"type:decimal(30,8)" json:"points_held"`                         // 用户持有积分总额（含冻结）
+	PointsCirculating decimal.NullDecimal `gorm:"type:decimal(30,8)" json:"points_circulating"`                  // 可用积分总额
+	PointsFrozen      decimal.NullDecimal `gorm:"type:decimal(30,8)" json:"points_frozen"`                       // 冻结积分总额
+	ActiveHolders     *int64              `json:"active_holders"`                                                // 持有至少一个未销毁藏品的用户数
+	StockCapturedAt   *time.Time          `json:"stock_captured_at"`                                             // 积分与持有人数的采集时间
+	PlatformBalance   decimal.Decimal     `gorm:"type:decimal(30,8);not null;default:0" json:"platform_balance"` // 统计日期结束时的平台账户（阳光账户）余额
+	TradeVolume       decimal.Decimal     `gorm:"type:decimal(30,8);not null;default:0" json:"trade_volume"`     // 当日完成交易总额
+	TradeCount        int64               `gorm:"not null;default:0" json:"trade_count"`                         // 当日完成交易笔数
+	FeesCollected     decimal.Decimal     `gorm:"type:decimal(30,8);not null;default:0" json:"fees_collected"`   // 当日平台手续费收入
+	RoyaltiesPaid     decimal.Decimal     `gorm:"type:decimal(30,8);not null;default:0" json:"royalties_paid"`   // 当日支付给创作者的版税
+	AirdropPoints     decimal.Decimal     `gorm:"type:decimal(30,8);not null;default:0" json:"airdrop_points"`   // 当日空投积分
+	AirdropAssets     int64               `gorm:"not null;default:0" json:"airdrop_assets"`                      // 当日空投藏品数量
+	NewUsers          int64               `gorm:"not null;default:0" json:"new_users"`                           // 当日新注册用户
+	Hash              string              `gorm:"type:varchar(64);not null" json:"hash"`                         // 指标规范化内容的SHA256
+	EventID           *uint64             `gorm:"index" json:"event_id"`                                         // 公示哈希的社区事件
+	CreatedAt         time.Time           `json:"created_at"`
+}
@@ -8,7 +8,7 @@ import (
 type Offer struct {
 	ID                 uint       `gorm:"primaryKey" json:"id"`
 	BuyerID            uint       `gorm:"not null;index" json:"buyer_id"`
-	ArtworkInstanceID  uint       `gorm:"not null;index" json:"artwork_instance_id"`
+	AssetInstanceID    uint64     `gorm:"not null;index" json:"asset_instance_id"`
 	Price              string     `gorm:"type:decimal(20,8);not null" json:"price"`
 	Status             string     `gorm:"type:enum('pending','accepted','rejected','cancelled','expired');default:'pending'" json:"status"`
 	ExpiresAt          *time.Time `json:"expires_at"`
@@ -18,7 +18,7 @@ type Offer struct {
 	
 	// 关联
 	Buyer           *User             `gorm:"foreignKey:BuyerID" json:"buyer,omitempty"`
-	ArtworkInstance *ArtworkInstance  `gorm:"foreignKey:ArtworkInstanceID" json:"artwork_instance,omitempty"`
+	AssetInstance   *AssetInstance    `gorm:"foreignKey:AssetInstanceID" json:"asset_instance,omitempty"`
 }
 
 // TableName 指定表名
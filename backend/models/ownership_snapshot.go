@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// OwnershipSnapshot 每日所有权Merkle快照
+// 对全部藏品实例的 (TokenID, 持有者UID, 状态) 构建Merkle树，根哈希经平台密钥签名后作为社区事件公示
+type OwnershipSnapshot struct {
+	ID           uint64    `gorm:"primaryKey" json:"id"`
+	SnapshotDate string    `gorm:"type:varchar(10);uniqueIndex;not null" json:"snapshot_date"` // 快照日期，如 2026-01-02
+	MerkleRoot   string    `gorm:"type:varchar(64);not null" json:"merkle_root"`
+	LeafCount    int       `gorm:"not null" json:"leaf_count"`
+	Signature    string    `gorm:"type:varchar(128);not null" json:"signature"` // Ed25519签名（hex）
+	PublicKey    string    `gorm:"type:varchar(64);not null" json:"-"`          // 签名时使用的公钥（hex），仅供内部核对，验签以公布的公钥为准
+	EventID      *uint64   `gorm:"index" json:"event_id"`                       // 公示根哈希的社区事件
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// OwnershipSnapshotLeaf 快照中的叶子，按 LeafIndex 顺序组成Merkle树
+type OwnershipSnapshotLeaf struct {
+	ID         uint64 `gorm:"primaryKey" json:"id"`
+	SnapshotID uint64 `gorm:"uniqueIndex:idx_snapshot_leaf;index:idx_snapshot_token;not null" json:"snapshot_id"`
+	LeafIndex  int    `gorm:"uniqueIndex:idx_snapshot_leaf;not null" json:"leaf_index"`
+	TokenID    string `gorm:"type:varchar(255);index:idx_snapshot_token;not null" json:"token_id"`
+	OwnerUID   string `gorm:"type:varchar(20);not null" json:"owner_uid"`
+	Status     string `gorm:"type:varchar(20);not null" json:"status"`
+	LeafHash   string `gorm:"type:varchar(64);not null" json:"leaf_hash"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PrimarySale 藏品首发配置（创作发布后生成）
+type PrimarySale struct {
+	ID                   uint64          `gorm:"primaryKey" json:"id"`
+	AssetID              uint64          `gorm:"uniqueIndex;not null" json:"asset_id"`
+	CreationID           uint64          `gorm:"index" json:"creation_id"` // 平台藏品为0
+	CreatorID            uint64          `gorm:"index" json:"creator_id"`  // 平台藏品为0
+	Price                decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"price"`
+	CommissionRate       decimal.Decimal `gorm:"type:decimal(5,2);not null" json:"commission_rate"` // 平台分成比例（%）
+	TotalStock           int             `gorm:"not null" json:"total_stock"`
+	SoldCount            int             `gorm:"not null;default:0" json:"sold_count"`
+	StartAt              time.Time       `gorm:"not null" json:"start_at"`
+	EndAt                *time.Time      `json:"end_at"`
+	PerUserLimit         int             `gorm:"not null;default:0" json:"per_user_limit"` // 每人限购数量，0为不限
+	RequireRealName      bool            `gorm:"not null;default:true" json:"require_real_name"`
+	QueueEnabled         bool            `gorm:"not null;default:false" json:"queue_enabled"`         // 热门首发开启排队，凭购买凭证下单
+	PriorityStartAt      *time.Time      `json:"priority_start_at"`                                   // 优先购开始时间，至StartAt前仅持有优先购权益的用户可购买
+	PriorityTransferable bool            `gorm:"not null;default:false" json:"priority_transferable"` // 是否允许转让优先购权益
+	Status               string          `gorm:"type:enum('on_sale', 'sold_out', 'closed');default:'on_sale'" json:"status"`
+	CreatedAt            time.Time       `json:"created_at"`
+	UpdatedAt            time.Time       `json:"updated_at"`
+
+	// 关联
+	Asset *Asset `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
+}
+
+// PrimarySaleOrder 首发购买订单
+type PrimarySaleOrder struct {
+	ID              uint64          `gorm:"primaryKey" json:"id"`
+	OrderNo         string          `gorm:"type:varchar(32);uniqueIndex;not null" json:"order_no"`
+	SaleID          uint64          `gorm:"index;not null" json:"sale_id"`
+	UserID          uint64          `gorm:"index;not null" json:"user_id"`
+	AssetInstanceID uint64          `gorm:"index;not null" json:"asset_instance_id"`
+	Price           decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"price"`
+	CreatorIncome   decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"creator_income"`
+	PlatformIncome  decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"platform_income"`
+	ParentRoyalty   decimal.Decimal `gorm:"type:decimal(30,8);not null;default:0" json:"parent_royalty"` // 从创作者收入中分给上游的部分（衍生作品）
+	CreatedAt       time.Time       `json:"created_at"`
+
+	// 关联
+	Sale          *PrimarySale   `gorm:"foreignKey:SaleID" json:"sale,omitempty"`
+	AssetInstance *AssetInstance `gorm:"foreignKey:AssetInstanceID" json:"asset_instance,omitempty"`
+}
+
+// TableName 指定表名
+func (PrimarySale) TableName() string {
+	return "primary_sales"
+}
+
+// TableName 指定表名
+func (PrimarySaleOrder) TableName() string {
+	return "primary_sale_orders"
+}
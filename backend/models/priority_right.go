@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// PriorityRight 首发优先购权益
+// 持有权益的用户可在优先购时段（PriorityStartAt 至 StartAt）内购买，每购买一份消耗一次
+type PriorityRight struct {
+	ID           uint64    `gorm:"primaryKey" json:"id"`
+	SaleID       uint64    `gorm:"uniqueIndex:idx_sale_user;not null" json:"sale_id"`
+	UserID       uint64    `gorm:"uniqueIndex:idx_sale_user;index;not null" json:"user_id"`
+	Quantity     int       `gorm:"not null" json:"quantity"`                                        // 可优先购买的总份数
+	UsedQuantity int       `gorm:"not null;default:0" json:"used_quantity"`                         // 已使用份数
+	Source       string    `gorm:"type:enum('csv', 'snapshot', 'transfer');not null" json:"source"` // 首次发放来源
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+
+	// 关联
+	Sale *PrimarySale `gorm:"foreignKey:SaleID" json:"sale,omitempty"`
+}
+
+// PrioritySnapshot 持仓快照发放任务
+// 到达快照时间后统计用户持有的指定藏品（含已同步的鲸探资产）数量并发放优先购权益
+type PrioritySnapshot struct {
+	ID                 uint64     `gorm:"primaryKey" json:"id"`
+	SaleID             uint64     `gorm:"index;not null" json:"sale_id"`
+	AssetIDs           string     `gorm:"type:varchar(500)" json:"asset_ids"`      // 藏品ID，逗号分隔
+	JingtanNames       string     `gorm:"type:varchar(1000)" json:"jingtan_names"` // 鲸探资产名称，逗号分隔
+	QuantityPerHolding int        `gorm:"not null;default:1" json:"quantity_per_holding"`
+	MaxPerUser         int        `gorm:"not null;default:0" json:"max_per_user"` // 每人最多发放份数，0为不限
+	SnapshotAt         time.Time  `gorm:"not null" json:"snapshot_at"`
+	Status             string     `gorm:"type:enum('pending', 'done');default:'pending'" json:"status"`
+	GrantedUsers       int        `gorm:"not null;default:0" json:"granted_users"`
+	ExecutedAt         *time.Time `json:"executed_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (PriorityRight) TableName() string {
+	return "priority_rights"
+}
+
+// TableName 指定表名
+func (PrioritySnapshot) TableName() string {
+	return "priority_snapshots"
+}
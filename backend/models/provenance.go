@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// OwnershipRecord 藏品实例的所有权变更记录（只追加，不修改不删除）
+// 铸造、空投、交易结算、接受出价、转赠、销毁和兑换实物时写入，用于公开溯源
+type OwnershipRecord struct {
+	ID              uint64           `gorm:"primaryKey" json:"id"`
+	AssetInstanceID uint64           `gorm:"index;not null" json:"asset_instance_id"`
+	TokenID         string           `gorm:"type:varchar(255);index;not null" json:"token_id"`
+	EventType       string           `gorm:"type:varchar(20);not null" json:"event_type"`  // mint, airdrop, trade, offer, transfer, burn, redeem
+	FromUserID      uint64           `gorm:"index;not null;default:0" json:"from_user_id"` // 铸造/空投时为0
+	ToUserID        uint64           `gorm:"index;not null;default:0" json:"to_user_id"`   // 销毁时为0
+	Price           *decimal.Decimal `gorm:"type:decimal(30,8)" json:"price"`              // 交易成交价，非交易为空
+	EventID         *uint64          `gorm:"index" json:"event_id"`                        // 对应的社区事件
+	RelatedID       uint64           `json:"related_id"`
+	RelatedType     string           `gorm:"type:varchar(50)" json:"related_type"`
+	CreatedAt       time.Time        `json:"created_at"`
+}
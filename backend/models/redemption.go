@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+)
+
+// RedeemableItem 可兑换实物配置（一个Asset对应一个实物）
+type RedeemableItem struct {
+	ID            uint64     `gorm:"primaryKey" json:"id"`
+	AssetID       uint64     `gorm:"uniqueIndex;not null" json:"asset_id"`
+	ItemName      string     `gorm:"type:varchar(100);not null" json:"item_name"` // 实物名称
+	ItemImage     string     `gorm:"type:varchar(500)" json:"item_image"`
+	Description   string     `gorm:"type:varchar(1000)" json:"description"`
+	Stock         int        `gorm:"not null" json:"stock"`                        // 实物库存
+	RedeemedCount int        `gorm:"not null;default:0" json:"redeemed_count"`     // 已兑换数量
+	StartAt       *time.Time `json:"start_at"`                                     // 兑换开始时间
+	EndAt         *time.Time `json:"end_at"`                                       // 兑换结束时间
+	BurnOnRedeem  bool       `gorm:"not null;default:false" json:"burn_on_redeem"` // 兑换时是否销毁藏品实例
+	Status        string     `gorm:"type:enum('active', 'inactive');default:'active'" json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// 关联
+	Asset *Asset `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
+}
+
+// RedemptionOrder 实物兑换订单
+type RedemptionOrder struct {
+	ID               uint64     `gorm:"primaryKey" json:"id"`
+	OrderNo          string     `gorm:"type:varchar(32);uniqueIndex;not null" json:"order_no"`
+	UserID           uint64     `gorm:"index;not null" json:"user_id"`
+	RedeemableItemID uint64     `gorm:"index;not null" json:"redeemable_item_id"`
+	AssetInstanceID  uint64     `gorm:"index;not null" json:"asset_instance_id"`
+	ReceiverName     string     `gorm:"type:varchar(50);not null" json:"receiver_name"`
+	ReceiverPhone    string     `gorm:"type:varchar(20);not null" json:"receiver_phone"`
+	ShippingAddress  string     `gorm:"type:varchar(500);not null" json:"shipping_address"` // 下单时的收货地址快照
+	Status           string     `gorm:"type:enum('pending', 'shipped', 'delivered', 'after_sale', 'closed');default:'pending'" json:"status"`
+	Carrier          string     `gorm:"type:varchar(50)" json:"carrier"`      // 物流公司
+	TrackingNo       string     `gorm:"type:varchar(100)" json:"tracking_no"` // 物流单号
+	AfterSaleReason  string     `gorm:"type:varchar(500)" json:"after_sale_reason"`
+	Remark           string     `gorm:"type:varchar(500)" json:"remark"` // 管理员备注
+	ShippedAt        *time.Time `json:"shipped_at"`
+	DeliveredAt      *time.Time `json:"delivered_at"`
+	ClosedAt         *time.Time `json:"closed_at"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+
+	// 关联
+	RedeemableItem *RedeemableItem `gorm:"foreignKey:RedeemableItemID" json:"redeemable_item,omitempty"`
+	AssetInstance  *AssetInstance  `gorm:"foreignKey:AssetInstanceID" json:"asset_instance,omitempty"`
+}
+
+// TableName 指定表名
+func (RedeemableItem) TableName() string {
+	return "redeemable_items"
+}
+
+// TableName 指定表名
+func (RedemptionOrder) TableName() string {
+	return "redemption_orders"
+}
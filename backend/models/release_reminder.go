@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ReleaseReminder 发售提醒订阅
+// 藏品开售前 N 分钟（系统配置 release_reminder_minutes）向订阅用户发送通知
+type ReleaseReminder struct {
+	ID         uint64     `gorm:"primaryKey" json:"id"`
+	UserID     uint64     `gorm:"uniqueIndex:idx_user_asset;not null" json:"user_id"`
+	AssetID    uint64     `gorm:"uniqueIndex:idx_user_asset;index;not null" json:"asset_id"`
+	NotifiedAt *time.Time `json:"notified_at"` // 已发送提醒的时间
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// 关联
+	Asset *Asset `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
+}
+
+// TableName 指定表名
+func (ReleaseReminder) TableName() string {
+	return "release_reminders"
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"time"
+)
+
+// SynthesisRecipe 合成配方
+type SynthesisRecipe struct {
+	ID            uint64     `gorm:"primaryKey" json:"id"`
+	Name          string     `gorm:"type:varchar(100);not null" json:"name"`
+	Description   string     `gorm:"type:varchar(500)" json:"description"`
+	OutputAssetID uint64     `gorm:"index;not null" json:"output_asset_id"`  // 产出藏品ID
+	OutputCount   int        `gorm:"not null;default:1" json:"output_count"` // 每次合成产出数量
+	StartAt       *time.Time `json:"start_at"`                               // 开始时间，为空表示立即开始
+	EndAt         *time.Time `json:"end_at"`                                 // 结束时间，为空表示不限
+	MaxTimes      int        `gorm:"not null;default:0" json:"max_times"`    // 全局可合成次数上限，0表示不限
+	UsedTimes     int        `gorm:"not null;default:0" json:"used_times"`   // 已合成次数
+	Status        string     `gorm:"type:enum('active', 'inactive');default:'active'" json:"status"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+
+	// 关联
+	Inputs      []SynthesisRecipeInput `gorm:"foreignKey:RecipeID" json:"inputs,omitempty"`
+	OutputAsset *Asset                 `gorm:"foreignKey:OutputAssetID" json:"output_asset,omitempty"`
+}
+
+// SynthesisRecipeInput 合成配方所需材料
+type SynthesisRecipeInput struct {
+	ID       uint64 `gorm:"primaryKey" json:"id"`
+	RecipeID uint64 `gorm:"index;not null" json:"recipe_id"`
+	AssetID  uint64 `gorm:"not null" json:"asset_id"` // 材料藏品ID
+	Quantity int    `gorm:"not null" json:"quantity"` // 需要销毁的数量
+
+	// 关联
+	Asset *Asset `gorm:"foreignKey:AssetID" json:"asset,omitempty"`
+}
+
+// SynthesisRecord 合成记录
+type SynthesisRecord struct {
+	ID                uint64    `gorm:"primaryKey" json:"id"`
+	RecipeID          uint64    `gorm:"index;not null" json:"recipe_id"`
+	UserID            uint64    `gorm:"index;not null" json:"user_id"`
+	BurnedInstanceIDs string    `gorm:"type:text;not null" json:"burned_instance_ids"` // 销毁的实例ID，逗号分隔
+	OutputInstanceIDs string    `gorm:"type:text;not null" json:"output_instance_ids"` // 产出的实例ID，逗号分隔
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+// TableName 指定表名
+func (SynthesisRecipe) TableName() string {
+	return "synthesis_recipes"
+}
+
+// TableName 指定表名
+func (SynthesisRecipeInput) TableName() string {
+	return "synthesis_recipe_inputs"
+}
+
+// TableName 指定表名
+func (SynthesisRecord) TableName() string {
+	return "synthesis_records"
+}
@@ -23,15 +23,18 @@ type Listing struct {
 type Trade struct {
 	gorm.Model
 	ID              uint64          `gorm:"primaryKey" json:"id"`
+	TradeNo         string          `gorm:"type:varchar(32);uniqueIndex" json:"trade_no"` // 交易单号（带校验位）
 	ListingID       uint64          `gorm:"index;not null" json:"listing_id"`
 	AssetInstanceID uint64          `gorm:"index;not null" json:"asset_instance_id"`
 	BuyerID         uint64          `gorm:"index;not null" json:"buyer_id"`
 	SellerID        uint64          `gorm:"index;not null" json:"seller_id"`
 	Price           decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"price"`
-	PlatformFee     decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"platform_fee"`    // 平台手续费（2.5%）
-	CreatorRoyalty  decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"creator_royalty"` // 创作者版税（2.5%）
-	SellerReceived  decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"seller_received"` // 卖家实际收到
+	PlatformFee     decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"platform_fee"`             // 平台手续费（2.5%）
+	CreatorRoyalty  decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"creator_royalty"`          // 创作者版税（2.5%）
+	ParentRoyalty   decimal.Decimal `gorm:"type:decimal(30,8);not null;default:0" json:"parent_royalty"` // 上游版税（衍生作品），明细见 royalty_payouts
+	SellerReceived  decimal.Decimal `gorm:"type:decimal(30,8);not null" json:"seller_received"`          // 卖家实际收到
 	Status          string          `gorm:"type:enum('pending', 'completed', 'failed', 'canceled');default:'pending'" json:"status"`
+	CompletedAt     *time.Time      `gorm:"index" json:"completed_at"` // 成交时间，交易完成时写入
 	CreatedAt       time.Time       `json:"created_at"`
 	UpdatedAt       time.Time       `json:"updated_at"`
 }
@@ -41,9 +44,13 @@ type CommunityEvent struct {
 	gorm.Model
 	ID          uint64    `gorm:"primaryKey" json:"id"`
 	EventType   string    `gorm:"type:varchar(50);index;not null" json:"event_type"` // 事件类型：mint, airdrop, trade, burn, etc.
-	UserID      uint64    `gorm:"index" json:"user_id"`
+	UserID      uint64    `gorm:"index" json:"-"`                                    // 内部用户ID，参与哈希计算但不对外公开
+	UserUID     string    `gorm:"-" json:"user_uid"`                                 // 对外展示的脱敏UID
 	Description string    `gorm:"type:varchar(500)" json:"description"`
 	RelatedID   uint64    `gorm:"index" json:"related_id"`              // 关联ID（如Trade ID, Asset ID）
 	RelatedType string    `gorm:"type:varchar(50)" json:"related_type"` // 关联类型
+	ChainSeq    uint64    `gorm:"uniqueIndex" json:"chain_seq"`         // 哈希链序号，从1开始连续递增
+	PrevHash    string    `gorm:"type:varchar(64)" json:"prev_hash"`    // 上一事件的哈希，首个事件为空
+	Hash        string    `gorm:"type:varchar(64)" json:"hash"`         // SHA256(上一哈希与本事件规范化内容)
 	CreatedAt   time.Time `json:"created_at"`
 }
@@ -0,0 +1,161 @@
+// Package regions 提供省/市/区三级行政区划数据（GB/T 2260编码）的查询与校验
+//
+// 内置数据为民政部2023年版县级以上行政区划代码，包含全部省、地、县三级。
+// 区划调整后可通过环境变量 REGION_DATA_FILE 指定新版数据文件（结构与 regions.json 相同）。
+// 校验只接受数据中存在的编码，不设下级区划的地区（如东莞市、中山市及港澳台）下级编码沿用上级编码。
+package regions
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"os"
+	"regexp"
+	"sync"
+)
+
+//go:embed regions.json
+var bundledData []byte
+
+// Region 行政区划节点
+type Region struct {
+	Code     string    `json:"code"`
+	Name     string    `json:"name"`
+	Children []*Region `json:"children,omitempty"`
+}
+
+var (
+	loadOnce  sync.Once
+	loadErr   error
+	provinces []*Region
+	index     map[string]*Region
+)
+
+var codePattern = regexp.MustCompile(`^\d{6}$`)
+
+// load 加载区划数据，优先使用 REGION_DATA_FILE 指定的文件
+func load() error {
+	loadOnce.Do(func() {
+		data := bundledData
+		if path := os.Getenv("REGION_DATA_FILE"); path != "" {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				loadErr = err
+				return
+			}
+			data = content
+		}
+
+		var list []*Region
+		if err := json.Unmarshal(data, &list); err != nil {
+			loadErr = err
+			return
+		}
+
+		provinces = list
+		index = make(map[string]*Region)
+		var walk func(nodes []*Region)
+		walk = func(nodes []*Region) {
+			for _, node := range nodes {
+				index[node.Code] = node
+				walk(node.Children)
+			}
+		}
+		walk(list)
+	})
+	return loadErr
+}
+
+// Children 返回下级区划列表（不含孙级），parentCode为空时返回省级列表
+func Children(parentCode string) ([]Region, error) {
+	if err := load(); err != nil {
+		return nil, err
+	}
+
+	nodes := provinces
+	if parentCode != "" {
+		parent, ok := index[parentCode]
+		if !ok {
+			return nil, errors.New("地区编码不存在")
+		}
+		nodes = parent.Children
+	}
+
+	result := make([]Region, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, Region{Code: node.Code, Name: node.Name})
+	}
+	return result, nil
+}
+
+// Resolve 按区划数据校验省/市/区编码的层级关系，返回对应名称
+// 上级不设下级区划时，下级编码须与上级编码相同，对应名称为空
+func Resolve(provinceCode, cityCode, districtCode string) ([3]string, error) {
+	var resolved [3]string
+	if err := load(); err != nil {
+		return resolved, err
+	}
+
+	codes := [3]string{provinceCode, cityCode, districtCode}
+	for _, code := range codes {
+		if !codePattern.MatchString(code) {
+			return resolved, errors.New("地区编码格式不正确")
+		}
+	}
+	if !matchLevel(provinceCode, 0) {
+		return resolved, errors.New("地区编码层级不正确")
+	}
+
+	province, ok := index[provinceCode]
+	if !ok {
+		return resolved, errors.New("省份不存在")
+	}
+	resolved[0] = province.Name
+
+	parent := province
+	for i := 1; i < 3; i++ {
+		code := codes[i]
+		if len(parent.Children) == 0 {
+			if code != parent.Code {
+				return resolved, errors.New("该地区不设下级区划，下级编码应与上级编码相同")
+			}
+			continue
+		}
+		if !matchLevel(code, i) {
+			return resolved, errors.New("地区编码层级不正确")
+		}
+		if code[:2*i] != parent.Code[:2*i] {
+			return resolved, errors.New("地区编码上下级不匹配")
+		}
+
+		var found *Region
+		for _, child := range parent.Children {
+			if child.Code == code {
+				found = child
+				break
+			}
+		}
+		if found == nil {
+			if i == 1 {
+				return resolved, errors.New("城市不存在")
+			}
+			return resolved, errors.New("区县不存在")
+		}
+		resolved[i] = found.Name
+		parent = found
+	}
+
+	return resolved, nil
+}
+
+// matchLevel 检查编码是否符合对应层级：省 XX0000，市 XXXX00（非00），区 XXXXXX（末两位非00）
+func matchLevel(code string, level int) bool {
+	switch level {
+	case 0:
+		return code[2:] == "0000"
+	case 1:
+		return code[2:4] != "00" && code[4:] == "00"
+	default:
+		return code[2:4] != "00" && code[4:] != "00"
+	}
+}
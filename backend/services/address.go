@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+	"hoho-miniapp/backend/regions"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultAddressLimit 每个用户最多保存的收货地址数量（可通过系统配置 address_max_count 覆盖）
+const defaultAddressLimit = 20
+
+var mobilePattern = regexp.MustCompile(`^1[3-9]\d{9}$`)
+
+// AddressService 定义收货地址服务接口
+type AddressService struct{}
+
+// NewAddressService 创建一个新的AddressService实例
+func NewAddressService() *AddressService {
+	return &AddressService{}
+}
+
+// AddressInput 收货地址表单，地区名称由区划编码确定
+type AddressInput struct {
+	ReceiverName string `json:"receiver_name" binding:"required"`
+	Phone        string `json:"phone" binding:"required"`
+	ProvinceCode string `json:"province_code" binding:"required"`
+	CityCode     string `json:"city_code" binding:"required"`
+	DistrictCode string `json:"district_code" binding:"required"`
+	Detail       string `json:"detail" binding:"required"`
+	IsDefault    bool   `json:"is_default"`
+}
+
+// ListAddresses 获取用户的收货地址列表，默认地址排在最前
+func (s *AddressService) ListAddresses(userID uint64) ([]models.UserAddress, error) {
+	var addresses []models.UserAddress
+	err := database.DB.Where("user_id = ?", userID).
+		Order("is_default desc, updated_at desc").
+		Find(&addresses).Error
+	return addresses, err
+}
+
+// GetAddress 获取用户的单个收货地址
+func (s *AddressService) GetAddress(userID, addressID uint64) (*models.UserAddress, error) {
+	var address models.UserAddress
+	if err := database.DB.Where("id = ? AND user_id = ?", addressID, userID).First(&address).Error; err != nil {
+		return nil, errors.New("收货地址不存在")
+	}
+	return &address, nil
+}
+
+// CreateAddress 新增收货地址，首个地址自动设为默认
+func (s *AddressService) CreateAddress(userID uint64, input AddressInput) (*models.UserAddress, error) {
+	address := &models.UserAddress{UserID: userID}
+	if err := applyAddressInput(address, input); err != nil {
+		return nil, err
+	}
+
+	limit := s.getAddressLimit()
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 锁定用户行，避免并发新增突破数量上限或产生多个默认地址
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return errors.New("用户不存在")
+		}
+
+		var count int64
+		if err := tx.Model(&models.UserAddress{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(limit) {
+			return fmt.Errorf("收货地址最多保存%d个", limit)
+		}
+
+		if count == 0 {
+			address.IsDefault = true
+		}
+		if address.IsDefault {
+			if err := clearDefaultAddress(tx, userID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(address).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return address, nil
+}
+
+// UpdateAddress 修改收货地址
+func (s *AddressService) UpdateAddress(userID, addressID uint64, input AddressInput) (*models.UserAddress, error) {
+	var address models.UserAddress
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", addressID, userID).First(&address).Error; err != nil {
+			return errors.New("收货地址不存在")
+		}
+
+		wasDefault := address.IsDefault
+		if err := applyAddressInput(&address, input); err != nil {
+			return err
+		}
+		// 默认地址只能通过设置其他地址为默认来取消
+		if wasDefault {
+			address.IsDefault = true
+		}
+		if address.IsDefault && !wasDefault {
+			if err := clearDefaultAddress(tx, userID); err != nil {
+				return err
+			}
+		}
+
+		return tx.Save(&address).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &address, nil
+}
+
+// SetDefaultAddress 设置默认收货地址
+func (s *AddressService) SetDefaultAddress(userID, addressID uint64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var address models.UserAddress
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", addressID, userID).First(&address).Error; err != nil {
+			return errors.New("收货地址不存在")
+		}
+		if address.IsDefault {
+			return nil
+		}
+
+		if err := clearDefaultAddress(tx, userID); err != nil {
+			return err
+		}
+		return tx.Model(&address).Update("is_default", true).Error
+	})
+}
+
+// DeleteAddress 删除收货地址，删除默认地址时将最近更新的地址设为默认
+func (s *AddressService) DeleteAddress(userID, addressID uint64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var address models.UserAddress
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", addressID, userID).First(&address).Error; err != nil {
+			return errors.New("收货地址不存在")
+		}
+
+		if err := tx.Delete(&address).Error; err != nil {
+			return err
+		}
+
+		if !address.IsDefault {
+			return nil
+		}
+
+		var next models.UserAddress
+		err := tx.Where("user_id = ?", userID).Order("updated_at desc").First(&next).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return tx.Model(&next).Update("is_default", true).Error
+	})
+}
+
+// getAddressLimit 获取每个用户的收货地址数量上限
+func (s *AddressService) getAddressLimit() int {
+	limit := getConfigInt("address_max_count", defaultAddressLimit)
+	if limit < 1 {
+		limit = defaultAddressLimit
+	}
+	return limit
+}
+
+// clearDefaultAddress 取消用户当前的默认地址
+func clearDefaultAddress(tx *gorm.DB, userID uint64) error {
+	return tx.Model(&models.UserAddress{}).
+		Where("user_id = ? AND is_default = ?", userID, true).
+		Update("is_default", false).Error
+}
+
+// applyAddressInput 校验表单并写入地址，地区名称以区划数据为准
+func applyAddressInput(address *models.UserAddress, input AddressInput) error {
+	receiverName := strings.TrimSpace(input.ReceiverName)
+	detail := strings.TrimSpace(input.Detail)
+
+	if receiverName == "" || utf8.RuneCountInString(receiverName) > 20 {
+		return errors.New("收货人姓名长度应为1-20个字符")
+	}
+	if !isValidMobile(input.Phone) {
+		return errors.New("手机号格式不正确")
+	}
+	if utf8.RuneCountInString(detail) < 2 || utf8.RuneCountInString(detail) > 100 {
+		return errors.New("详细地址长度应为2-100个字符")
+	}
+
+	names, err := regions.Resolve(input.ProvinceCode, input.CityCode, input.DistrictCode)
+	if err != nil {
+		return err
+	}
+
+	address.ReceiverName = receiverName
+	address.Phone = input.Phone
+	address.ProvinceCode = input.ProvinceCode
+	address.Province = names[0]
+	address.CityCode = input.CityCode
+	address.City = names[1]
+	address.DistrictCode = input.DistrictCode
+	address.District = names[2]
+	address.Detail = detail
+	address.IsDefault = input.IsDefault
+	return nil
+}
+
+// isValidMobile 校验中国大陆手机号
+func isValidMobile(phone string) bool {
+	return mobilePattern.MatchString(phone)
+}
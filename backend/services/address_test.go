@@ -0,0 +1,152 @@
+package services
+
+import (
+	"testing"
+
+	"hoho-miniapp/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIsValidMobile 测试大陆手机号校验
+func TestIsValidMobile(t *testing.T) {
+	tests := []struct {
+		phone string
+		valid bool
+	}{
+		{"13800138000", true},
+		{"19912345678", true},
+		{"16612345678", true},
+		{"12345678901", false}, // 号段不合法
+		{"1380013800", false},  // 太短
+		{"138001380000", false},
+		{"+8613800138000", false},
+		{"1380013800a", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.phone, func(t *testing.T) {
+			assert.Equal(t, tt.valid, isValidMobile(tt.phone))
+		})
+	}
+}
+
+// TestApplyAddressInput 测试收货地址表单校验
+func TestApplyAddressInput(t *testing.T) {
+	base := AddressInput{
+		ReceiverName: "张三",
+		Phone:        "13800138000",
+		ProvinceCode: "440000",
+		CityCode:     "440300",
+		DistrictCode: "440305",
+		Detail:       "科技园南区1栋",
+	}
+
+	tests := []struct {
+		name     string
+		modify   func(in *AddressInput)
+		errorMsg string
+		district string
+	}{
+		{
+			name:     "正常地址",
+			modify:   func(in *AddressInput) {},
+			district: "南山区",
+		},
+		{
+			name: "其他城市的区县",
+			modify: func(in *AddressInput) {
+				in.CityCode = "440600"
+				in.DistrictCode = "440604"
+			},
+			district: "禅城区",
+		},
+		{
+			name: "不设区的地级市沿用城市编码",
+			modify: func(in *AddressInput) {
+				in.CityCode = "441900"
+				in.DistrictCode = "441900"
+			},
+			district: "",
+		},
+		{
+			name: "不设区的地级市不接受区县编码",
+			modify: func(in *AddressInput) {
+				in.CityCode = "441900"
+				in.DistrictCode = "441901"
+			},
+			errorMsg: "下级编码应与上级编码相同",
+		},
+		{
+			name: "城市不存在",
+			modify: func(in *AddressInput) {
+				in.CityCode = "449900"
+				in.DistrictCode = "449901"
+			},
+			errorMsg: "城市不存在",
+		},
+		{
+			name: "手机号错误",
+			modify: func(in *AddressInput) {
+				in.Phone = "12800138000"
+			},
+			errorMsg: "手机号格式不正确",
+		},
+		{
+			name: "区县不属于该城市",
+			modify: func(in *AddressInput) {
+				in.DistrictCode = "440106"
+			},
+			errorMsg: "地区编码上下级不匹配",
+		},
+		{
+			name: "区县不存在",
+			modify: func(in *AddressInput) {
+				in.DistrictCode = "440399"
+			},
+			errorMsg: "区县不存在",
+		},
+		{
+			name: "省份不存在",
+			modify: func(in *AddressInput) {
+				in.ProvinceCode = "990000"
+				in.CityCode = "990100"
+				in.DistrictCode = "990101"
+			},
+			errorMsg: "省份不存在",
+		},
+		{
+			name: "编码层级错误",
+			modify: func(in *AddressInput) {
+				in.CityCode = "440000"
+			},
+			errorMsg: "地区编码层级不正确",
+		},
+		{
+			name: "详细地址过短",
+			modify: func(in *AddressInput) {
+				in.Detail = " 1 "
+			},
+			errorMsg: "详细地址长度",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := base
+			tt.modify(&input)
+
+			var address models.UserAddress
+			err := applyAddressInput(&address, input)
+			if tt.errorMsg != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errorMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "广东省", address.Province)
+			assert.Equal(t, tt.district, address.District)
+		})
+	}
+}
@@ -6,12 +6,20 @@ import (
 	"hoho-miniapp/backend/models"
 	"hoho-miniapp/backend/utils"
 	"os"
+	"regexp"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// adminUsernamePattern 管理员用户名格式
+var adminUsernamePattern = regexp.MustCompile(`^[A-Za-z0-9_]{3,50}$`)
+
+// adminPasswordMinLength 管理员密码最小长度
+const adminPasswordMinLength = 8
+
 // AdminService 定义管理员服务接口
 type AdminService struct{}
 
@@ -141,3 +149,161 @@ func (s *AdminService) GenerateToken(adminID uint64) (string, error) {
 
 	return tokenString, nil
 }
+
+// GetAdminWithPermissions 获取管理员及其角色权限，管理员不存在或已停用时返回错误（供权限中间件使用）
+func (s *AdminService) GetAdminWithPermissions(adminID uint64) (*models.Admin, error) {
+	admin, err := s.GetAdminByID(adminID)
+	if err != nil {
+		return nil, err
+	}
+	if admin.Status != "active" {
+		return nil, errors.New("管理员已被禁用")
+	}
+	permissions, err := NewAdminRoleService().RolePermissions(admin.Role)
+	if err != nil {
+		return nil, err
+	}
+	admin.Permissions = permissions
+	return admin, nil
+}
+
+// ListAdmins 获取管理员列表
+func (s *AdminService) ListAdmins(page, pageSize int) ([]models.Admin, int64, error) {
+	var admins []models.Admin
+	var total int64
+
+	query := database.DB.Model(&models.Admin{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("id asc").Offset(offset).Limit(pageSize).Find(&admins).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return admins, total, nil
+}
+
+// CreateAdmin 创建管理员
+func (s *AdminService) CreateAdmin(username, password, email, role string) (*models.Admin, error) {
+	if !adminUsernamePattern.MatchString(username) {
+		return nil, errors.New("用户名只能包含字母、数字和下划线，长度3-50")
+	}
+	if len(password) < adminPasswordMinLength {
+		return nil, errors.New("密码长度不能少于8位")
+	}
+	passwordHash, err := utils.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	admin := models.Admin{
+		Username:     username,
+		PasswordHash: passwordHash,
+		Email:        email,
+		Role:         role,
+		Status:       "active",
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		exists, err := roleExistsTx(tx, role)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return errors.New("角色不存在")
+		}
+		if err := tx.Create(&admin).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return errors.New("用户名或邮箱已被使用")
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &admin, nil
+}
+
+// UpdateAdminRequest 管理员资料修改，字段为空表示不修改
+type UpdateAdminRequest struct {
+	Email    *string `json:"email"`
+	Role     *string `json:"role"`
+	Status   *string `json:"status" binding:"omitempty,oneof=active inactive"`
+	Password *string `json:"password"`
+}
+
+// UpdateAdmin 修改管理员的邮箱、角色、状态或密码
+// 不能修改自己的角色和状态，且必须保留至少一名启用的超级管理员
+func (s *AdminService) UpdateAdmin(operatorID, adminID uint64, req UpdateAdminRequest) (*models.Admin, error) {
+	if adminID == operatorID && (req.Role != nil || req.Status != nil) {
+		return nil, errors.New("不能修改自己的角色或状态")
+	}
+
+	updates := map[string]interface{}{}
+	if req.Email != nil {
+		updates["email"] = *req.Email
+	}
+	if req.Status != nil {
+		updates["status"] = *req.Status
+	}
+	if req.Password != nil {
+		if len(*req.Password) < adminPasswordMinLength {
+			return nil, errors.New("密码长度不能少于8位")
+		}
+		passwordHash, err := utils.HashPassword(*req.Password)
+		if err != nil {
+			return nil, err
+		}
+		updates["password_hash"] = passwordHash
+	}
+
+	var admin models.Admin
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 锁定启用的超级管理员，防止并发降级后无人可管理
+		var superAdminIDs []uint64
+		if err := tx.Model(&models.Admin{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("role = ? AND status = ?", RoleSuperAdmin, "active").
+			Pluck("id", &superAdminIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.First(&admin, adminID).Error; err != nil {
+			return errors.New("管理员不存在")
+		}
+		if req.Role != nil {
+			exists, err := roleExistsTx(tx, *req.Role)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return errors.New("角色不存在")
+			}
+			updates["role"] = *req.Role
+		}
+		if len(updates) == 0 {
+			return nil
+		}
+		if err := tx.Model(&admin).Updates(updates).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return errors.New("邮箱已被使用")
+			}
+			return err
+		}
+
+		var superAdmins int64
+		if err := tx.Model(&models.Admin{}).Where("role = ? AND status = ?", RoleSuperAdmin, "active").
+			Count(&superAdmins).Error; err != nil {
+			return err
+		}
+		if superAdmins == 0 {
+			return errors.New("必须保留至少一名启用的超级管理员")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &admin, nil
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"gorm.io/gorm"
+)
+
+// 管理后台权限
+const (
+	PermDashboardView = "dashboard:view"
+	PermUserView      = "user:view"
+	PermUserStatus    = "user:status"
+	PermReview        = "review"
+	PermAirdrop       = "airdrop"
+	PermConfig        = "config"
+	PermAnnouncement  = "announcement"
+	PermTask          = "task"
+	PermCatalog       = "catalog"
+	PermSale          = "sale"
+	PermRedemption    = "redemption"
+	PermChain         = "chain"
+	PermAdminManage   = "admin:manage" // 仅 super_admin 拥有，不可授予其他角色
+)
+
+// RoleSuperAdmin 超级管理员角色
+const RoleSuperAdmin = "super_admin"
+
+// roleNameMaxLength 角色名最大长度，与 admins.role 字段一致
+const roleNameMaxLength = 30
+
+// AdminPermissionInfo 权限说明
+type AdminPermissionInfo struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+// AdminPermissions 全部权限，按展示顺序排列
+var AdminPermissions = []AdminPermissionInfo{
+	{PermDashboardView, "查看仪表盘"},
+	{PermUserView, "查看用户"},
+	{PermUserStatus, "禁用/解禁用户"},
+	{PermReview, "审核藏品、创作及衍生授权"},
+	{PermAirdrop, "空投积分和藏品"},
+	{PermConfig, "修改系统配置"},
+	{PermAnnouncement, "管理公告"},
+	{PermTask, "管理任务"},
+	{PermCatalog, "管理藏品集合、属性及合成配方"},
+	{PermSale, "管理首发、优先购、盲盒及抽签"},
+	{PermRedemption, "管理实物兑换"},
+	{PermChain, "校验事件哈希链及管理上链任务"},
+	{PermAdminManage, "管理管理员和角色"},
+}
+
+// defaultRoles 内置角色及默认权限（super_admin 始终拥有全部权限，不在此列出）
+var defaultRoles = []struct {
+	Name        string
+	Description string
+	Permissions []string
+}{
+	{RoleSuperAdmin, "超级管理员，拥有全部权限", nil},
+	{"admin", "管理员", []string{PermDashboardView, PermUserView, PermUserStatus, PermReview, PermAirdrop, PermConfig,
+		PermAnnouncement, PermTask, PermCatalog, PermSale, PermRedemption, PermChain}},
+	{"reviewer", "审核员", []string{PermDashboardView, PermUserView, PermReview}},
+	{"customer_service", "客服", []string{PermDashboardView, PermUserView, PermUserStatus, PermRedemption}},
+}
+
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// AdminRoleService 定义管理员角色服务接口
+type AdminRoleService struct{}
+
+// NewAdminRoleService 创建一个新的AdminRoleService实例
+func NewAdminRoleService() *AdminRoleService {
+	return &AdminRoleService{}
+}
+
+// EnsureSchema 补建角色权限表，将 admins.role 由枚举改为字符串，并写入缺失的内置角色（由 cmd/migrate 调用）
+func (s *AdminRoleService) EnsureSchema() error {
+	m := database.DB.Migrator()
+	for _, model := range []interface{}{&models.AdminRole{}, &models.AdminRolePermission{}} {
+		if !m.HasTable(model) {
+			if err := m.CreateTable(model); err != nil {
+				return err
+			}
+		}
+	}
+	if err := m.AlterColumn(&models.Admin{}, "Role"); err != nil {
+		return err
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, def := range defaultRoles {
+			var count int64
+			if err := tx.Model(&models.AdminRole{}).Where("name = ?", def.Name).Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				continue
+			}
+			role := models.AdminRole{Name: def.Name, Description: def.Description, IsSystem: true}
+			if err := tx.Create(&role).Error; err != nil {
+				return err
+			}
+			if err := replaceRolePermissionsTx(tx, role.ID, def.Permissions); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RolePermissions 获取角色拥有的权限，super_admin 返回全部权限
+func (s *AdminRoleService) RolePermissions(roleName string) ([]string, error) {
+	if roleName == RoleSuperAdmin {
+		return allPermissionKeys(), nil
+	}
+	permissions := []string{}
+	err := database.DB.Model(&models.AdminRolePermission{}).
+		Joins("JOIN admin_roles ON admin_roles.id = admin_role_permissions.role_id").
+		Where("admin_roles.name = ?", roleName).
+		Order("admin_role_permissions.permission asc").
+		Pluck("admin_role_permissions.permission", &permissions).Error
+	return permissions, err
+}
+
+// ListRoles 获取全部角色及其权限
+func (s *AdminRoleService) ListRoles() ([]models.AdminRole, error) {
+	var roles []models.AdminRole
+	if err := database.DB.Order("id asc").Find(&roles).Error; err != nil {
+		return nil, err
+	}
+	for i := range roles {
+		permissions, err := s.RolePermissions(roles[i].Name)
+		if err != nil {
+			return nil, err
+		}
+		roles[i].Permissions = permissions
+	}
+	return roles, nil
+}
+
+// CreateRole 创建自定义角色
+func (s *AdminRoleService) CreateRole(name, description string, permissions []string) (*models.AdminRole, error) {
+	if len(name) > roleNameMaxLength || !roleNamePattern.MatchString(name) {
+		return nil, errors.New("角色名只能包含小写字母、数字和下划线，且以字母开头")
+	}
+	if err := validateGrantablePermissions(permissions); err != nil {
+		return nil, err
+	}
+
+	role := models.AdminRole{Name: name, Description: description}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&role).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return errors.New("角色已存在")
+			}
+			return err
+		}
+		return replaceRolePermissionsTx(tx, role.ID, permissions)
+	})
+	if err != nil {
+		return nil, err
+	}
+	role.Permissions = permissions
+	return &role, nil
+}
+
+// UpdateRole 更新角色说明和权限，super_admin 不可修改
+func (s *AdminRoleService) UpdateRole(name, description string, permissions []string) (*models.AdminRole, error) {
+	if name == RoleSuperAdmin {
+		return nil, errors.New("超级管理员角色不可修改")
+	}
+	if err := validateGrantablePermissions(permissions); err != nil {
+		return nil, err
+	}
+
+	var role models.AdminRole
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("name = ?", name).First(&role).Error; err != nil {
+			return errors.New("角色不存在")
+		}
+		if err := tx.Model(&role).Update("description", description).Error; err != nil {
+			return err
+		}
+		return replaceRolePermissionsTx(tx, role.ID, permissions)
+	})
+	if err != nil {
+		return nil, err
+	}
+	role.Permissions = permissions
+	return &role, nil
+}
+
+// DeleteRole 删除自定义角色，仍有管理员使用时不可删除
+func (s *AdminRoleService) DeleteRole(name string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var role models.AdminRole
+		if err := tx.Where("name = ?", name).First(&role).Error; err != nil {
+			return errors.New("角色不存在")
+		}
+		if role.IsSystem {
+			return errors.New("内置角色不可删除")
+		}
+
+		var count int64
+		if err := tx.Model(&models.Admin{}).Where("role = ?", name).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("仍有管理员使用该角色，无法删除")
+		}
+
+		if err := tx.Where("role_id = ?", role.ID).Delete(&models.AdminRolePermission{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&role).Error
+	})
+}
+
+// roleExistsTx 检查角色是否存在
+func roleExistsTx(tx *gorm.DB, name string) (bool, error) {
+	var count int64
+	err := tx.Model(&models.AdminRole{}).Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
+
+// replaceRolePermissionsTx 以给定权限替换角色的全部权限
+func replaceRolePermissionsTx(tx *gorm.DB, roleID uint64, permissions []string) error {
+	if err := tx.Where("role_id = ?", roleID).Delete(&models.AdminRolePermission{}).Error; err != nil {
+		return err
+	}
+	seen := make(map[string]bool)
+	var rows []models.AdminRolePermission
+	for _, permission := range permissions {
+		if seen[permission] {
+			continue
+		}
+		seen[permission] = true
+		rows = append(rows, models.AdminRolePermission{RoleID: roleID, Permission: permission})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return tx.Create(&rows).Error
+}
+
+// validateGrantablePermissions 校验权限是否存在且可授予，admin:manage 仅属于超级管理员
+func validateGrantablePermissions(permissions []string) error {
+	known := make(map[string]bool, len(AdminPermissions))
+	for _, info := range AdminPermissions {
+		known[info.Key] = true
+	}
+	for _, permission := range permissions {
+		if !known[permission] {
+			return errors.New("未知权限: " + permission)
+		}
+		if permission == PermAdminManage {
+			return errors.New("管理员管理权限仅属于超级管理员")
+		}
+	}
+	return nil
+}
+
+// allPermissionKeys 全部权限标识
+func allPermissionKeys() []string {
+	keys := make([]string, len(AdminPermissions))
+	for i, info := range AdminPermissions {
+		keys[i] = info.Key
+	}
+	return keys
+}
+
+// HasPermission 判断权限列表中是否包含指定权限
+func HasPermission(permissions []string, permission string) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
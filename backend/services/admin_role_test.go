@@ -0,0 +1,34 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestValidateGrantablePermissions 测试角色权限校验
+func TestValidateGrantablePermissions(t *testing.T) {
+	assert.NoError(t, validateGrantablePermissions([]string{PermReview, PermAirdrop}))
+	assert.NoError(t, validateGrantablePermissions(nil))
+	assert.Error(t, validateGrantablePermissions([]string{"unknown"}))
+	assert.Error(t, validateGrantablePermissions([]string{PermReview, PermAdminManage}), "管理员管理权限不可授予")
+}
+
+// TestDefaultRolePermissions 测试内置角色的默认权限均为可授予的有效权限
+func TestDefaultRolePermissions(t *testing.T) {
+	for _, role := range defaultRoles {
+		assert.NoError(t, validateGrantablePermissions(role.Permissions), role.Name)
+	}
+
+	all := allPermissionKeys()
+	assert.Len(t, all, len(AdminPermissions))
+	assert.True(t, HasPermission(all, PermAdminManage))
+
+	for _, role := range defaultRoles {
+		if role.Name == "reviewer" {
+			assert.True(t, HasPermission(role.Permissions, PermReview))
+			assert.False(t, HasPermission(role.Permissions, PermAirdrop))
+			assert.False(t, HasPermission(role.Permissions, PermConfig))
+		}
+	}
+}
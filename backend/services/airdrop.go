@@ -45,18 +45,9 @@ func (s *AirdropService) AirdropPoints(userID uint64, amount decimal.Decimal, re
 		}
 
 		// 3. 记录社区事件
-		event := models.CommunityEvent{
-			EventType:   "airdrop_points",
-			UserID:      userID,
-			Description: fmt.Sprintf("用户获得空投积分 %s，原因：%s", amount.String(), reason),
-			RelatedID:   transaction.ID,
-			RelatedType: "point_transaction",
-		}
-		if err := tx.Create(&event).Error; err != nil {
-			return err
-		}
-
-		return nil
+		description := fmt.Sprintf("用户获得空投积分 %s，原因：%s", amount.String(), reason)
+		_, err := recordEvent(tx, "airdrop_points", userID, description, transaction.ID, "point_transaction")
+		return err
 	})
 }
 
@@ -81,9 +72,20 @@ func (s *AirdropService) BatchAirdropPoints(userIDs []uint64, amount decimal.Dec
 
 // AirdropAsset 空投藏品给用户（基于已有的Asset）
 func (s *AirdropService) AirdropAsset(assetID uint64, targetUserID uint64, count int) ([]models.AssetInstance, error) {
-	// 复用AssetService的MintAndAirdrop方法
 	assetService := NewAssetService()
-	return assetService.MintAndAirdrop(assetID, targetUserID, count)
+	var instances []models.AssetInstance
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var events eventBatch
+		var err error
+		if instances, err = assetService.AirdropTx(tx, &events, assetID, targetUserID, count); err != nil {
+			return err
+		}
+		return events.recordTx(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return instances, nil
 }
 
 // GetAirdropHistory 获取空投历史记录
@@ -8,6 +8,7 @@ import (
 	"hoho-miniapp/backend/utils"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // AssetService 定义藏品服务接口
@@ -18,20 +19,6 @@ func NewAssetService() *AssetService {
 	return &AssetService{}
 }
 
-// CreateCollection 创建藏品集合
-func (s *AssetService) CreateCollection(name, description, coverImage string) (*models.Collection, error) {
-	collection := models.Collection{
-		Name:        name,
-		Description: description,
-		CoverImage:  coverImage,
-		Status:      "active",
-	}
-	if err := database.DB.Create(&collection).Error; err != nil {
-		return nil, err
-	}
-	return &collection, nil
-}
-
 // SubmitMintRequest 用户提交铸造请求
 func (s *AssetService) SubmitMintRequest(creatorID uint64, collectionID uint64, name, description, mediaURL, mediaType string, totalSupply int) (*models.Asset, error) {
 	// 1. 检查集合是否存在
@@ -95,8 +82,43 @@ func (s *AssetService) ReviewMintRequest(assetID uint64, status string) (*models
 
 // MintAndAirdrop 铸造藏品实例并空投给指定用户
 func (s *AssetService) MintAndAirdrop(assetID uint64, targetUserID uint64, count int) ([]models.AssetInstance, error) {
+	var instances []models.AssetInstance
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var events eventBatch
+		var err error
+		if instances, err = s.MintAndAirdropTx(tx, &events, assetID, targetUserID, count); err != nil {
+			return err
+		}
+		return events.recordTx(tx)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return instances, nil
+}
+
+// MintAndAirdropTx 在调用方事务中铸造藏品实例，供合成等需要与其他变更原子提交的流程使用
+// 铸造事件加入 events，由调用方在事务最后写入
+func (s *AssetService) MintAndAirdropTx(tx *gorm.DB, events *eventBatch, assetID uint64, targetUserID uint64, count int) ([]models.AssetInstance, error) {
+	return s.mintInstancesTx(tx, events, assetID, targetUserID, count, ProvenanceMint)
+}
+
+// AirdropTx 在调用方事务中铸造藏品实例并作为空投发放，溯源记录为airdrop
+func (s *AssetService) AirdropTx(tx *gorm.DB, events *eventBatch, assetID uint64, targetUserID uint64, count int) ([]models.AssetInstance, error) {
+	return s.mintInstancesTx(tx, events, assetID, targetUserID, count, ProvenanceAirdrop)
+}
+
+// mintInstancesTx 铸造藏品实例，将社区事件和每个实例的所有权来源（mint 或 airdrop）加入 events
+func (s *AssetService) mintInstancesTx(tx *gorm.DB, events *eventBatch, assetID uint64, targetUserID uint64, count int, provenanceType string) ([]models.AssetInstance, error) {
+	if count <= 0 {
+		return nil, errors.New("铸造数量必须大于0")
+	}
+
+	// 锁定Asset行，防止并发铸造导致编号重复或超发
 	var asset models.Asset
-	if err := database.DB.First(&asset, assetID).Error; err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&asset, assetID).Error; err != nil {
 		return nil, errors.New("藏品不存在")
 	}
 
@@ -109,39 +131,170 @@ func (s *AssetService) MintAndAirdrop(assetID uint64, targetUserID uint64, count
 	}
 
 	var instances []models.AssetInstance
-	err := database.DB.Transaction(func(tx *gorm.DB) error {
-		for i := 0; i < count; i++ {
-			// 实例编号从已铸造数量开始递增
-			instanceNo := asset.MintedCount + i + 1
-
-			instance := models.AssetInstance{
-				AssetID:    assetID,
-				InstanceNo: instanceNo,
-				OwnerID:    targetUserID,
-				TokenID:    utils.GenerateTokenID(assetID, uint64(instanceNo)), // 生成唯一TokenID
-				Status:     "in_wallet",
-			}
-			if err := tx.Create(&instance).Error; err != nil {
-				return err
-			}
-			instances = append(instances, instance)
-		}
-
-		// 更新Asset的已铸造数量
-		if err := tx.Model(&asset).Update("minted_count", asset.MintedCount+count).Error; err != nil {
+	var ownership []models.OwnershipRecord
+	for i := 0; i < count; i++ {
+		// 实例编号从已铸造数量开始递增
+		instanceNo := asset.MintedCount + i + 1
+
+		instance := models.AssetInstance{
+			AssetID:    assetID,
+			InstanceNo: instanceNo,
+			OwnerID:    targetUserID,
+			TokenID:    utils.GenerateTokenID(assetID, uint64(instanceNo)), // 生成唯一TokenID
+			Status:     "in_wallet",
+		}
+		if err := createWithRetry(tx, &instance, "token_id", func() {
+			instance.TokenID = utils.GenerateTokenID(assetID, uint64(instanceNo))
+		}); err != nil {
+			return nil, err
+		}
+		if err := enqueueAnchorTx(tx, &instance, ChainOpMint, 0, targetUserID); err != nil {
+			return nil, err
+		}
+		ownership = append(ownership, models.OwnershipRecord{
+			AssetInstanceID: instance.ID,
+			TokenID:         instance.TokenID,
+			EventType:       provenanceType,
+			ToUserID:        targetUserID,
+			RelatedID:       asset.ID,
+			RelatedType:     "asset",
+		})
+		instances = append(instances, instance)
+	}
+
+	// 更新Asset的已铸造数量
+	if err := tx.Model(&asset).Update("minted_count", asset.MintedCount+count).Error; err != nil {
+		return nil, err
+	}
+
+	// 社区事件（铸造并发放）
+	targetUID, err := eventUserTx(tx, targetUserID)
+	if err != nil {
+		return nil, err
+	}
+	description := fmt.Sprintf("藏品《%s》铸造 #%d-#%d 共 %d 份，发放给用户 %s", asset.Name, asset.MintedCount+1, asset.MintedCount+count, count, targetUID)
+	events.add(provenanceType, targetUserID, description, asset.ID, "asset", ownership...)
+
+	return instances, nil
+}
+
+// BurnInstancesTx 在调用方事务中销毁用户持有的藏品实例，每个实例的burn事件加入 events
+func (s *AssetService) BurnInstancesTx(tx *gorm.DB, events *eventBatch, ownerID uint64, instances []models.AssetInstance, reason string) error {
+	ownerUID, err := eventUserTx(tx, ownerID)
+	if err != nil {
+		return err
+	}
+	for i := range instances {
+		instance := &instances[i]
+		if err := burnInstanceTx(tx, events, ownerID, instance, burnRecord{
+			EventType:   "burn",
+			Description: fmt.Sprintf("用户 %s 销毁了藏品实例 %s，原因：%s", ownerUID, instance.TokenID, reason),
+			Provenance:  ProvenanceBurn,
+			RelatedID:   instance.ID,
+			RelatedType: "asset_instance",
+		}); err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		// TODO: 记录社区事件（空投事件）
+// burnRecord 销毁实例时写入的唯一一条事件及所有权记录，兑换等业务以自身上下文代替默认的burn记录
+type burnRecord struct {
+	EventType   string
+	Description string
+	Provenance  string
+	RelatedID   uint64
+	RelatedType string
+}
 
-		return nil
+// burnInstanceTx 销毁单个藏品实例并登记上链销毁，事件及所有权变更加入 events
+func burnInstanceTx(tx *gorm.DB, events *eventBatch, ownerID uint64, instance *models.AssetInstance, record burnRecord) error {
+	// 以持有者和状态作为条件，防止并发挂售或转移后仍被销毁
+	result := tx.Model(&models.AssetInstance{}).
+		Where("id = ? AND owner_id = ? AND status = ?", instance.ID, ownerID, "in_wallet").
+		Update("status", "burned")
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("藏品实例 %s 状态已变更，无法销毁", instance.TokenID)
+	}
+
+	if err := enqueueAnchorTx(tx, instance, ChainOpBurn, ownerID, 0); err != nil {
+		return err
+	}
+	events.add(record.EventType, ownerID, record.Description, record.RelatedID, record.RelatedType, models.OwnershipRecord{
+		AssetInstanceID: instance.ID,
+		TokenID:         instance.TokenID,
+		EventType:       record.Provenance,
+		FromUserID:      ownerID,
+		RelatedID:       record.RelatedID,
+		RelatedType:     record.RelatedType,
 	})
+	return nil
+}
 
+// TransferInstance 将持有的藏品实例转赠给指定UID的用户，默认关闭，需系统配置 asset_transfer_enabled 设为1开启
+func (s *AssetService) TransferInstance(fromUserID, instanceID uint64, toUID string) (*models.AssetInstance, error) {
+	if getConfigInt("asset_transfer_enabled", 0) != 1 {
+		return nil, errors.New("藏品转赠功能未开放")
+	}
+	if !utils.ValidateUID(toUID) {
+		return nil, errors.New("接收用户UID格式错误")
+	}
+
+	var instance models.AssetInstance
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var recipient models.User
+		if err := tx.Where("uid = ? AND status = ?", toUID, "active").First(&recipient).Error; err != nil {
+			return errors.New("接收用户不存在")
+		}
+		if recipient.ID == fromUserID {
+			return errors.New("不能转赠给自己")
+		}
+
+		if err := tx.First(&instance, instanceID).Error; err != nil {
+			return errors.New("藏品实例不存在")
+		}
+
+		// 以持有者和状态作为条件，防止与挂售、出价成交并发
+		result := tx.Model(&models.AssetInstance{}).
+			Where("id = ? AND owner_id = ? AND status = ?", instanceID, fromUserID, "in_wallet").
+			Update("owner_id", recipient.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("藏品不属于你或当前状态不可转赠")
+		}
+		instance.OwnerID = recipient.ID
+
+		if err := enqueueAnchorTx(tx, &instance, ChainOpTransfer, fromUserID, recipient.ID); err != nil {
+			return err
+		}
+
+		fromUID, err := eventUserTx(tx, fromUserID)
+		if err != nil {
+			return err
+		}
+		var events eventBatch
+		description := fmt.Sprintf("用户 %s 将藏品实例 %s 转赠给用户 %s", fromUID, instance.TokenID, maskUID(recipient.UID))
+		events.add("transfer", fromUserID, description, instance.ID, "asset_instance", models.OwnershipRecord{
+			AssetInstanceID: instance.ID,
+			TokenID:         instance.TokenID,
+			EventType:       ProvenanceTransfer,
+			FromUserID:      fromUserID,
+			ToUserID:        recipient.ID,
+			RelatedID:       instance.ID,
+			RelatedType:     "asset_instance",
+		})
+		return events.recordTx(tx)
+	})
 	if err != nil {
 		return nil, err
 	}
-
-	return instances, nil
+	return &instance, nil
 }
 
 // GetPendingReviewAssets 获取待审核的铸造请求
@@ -161,8 +314,8 @@ func (s *AssetService) GetAssetByID(assetID uint64) (*models.Asset, error) {
 	return &asset, err
 }
 
-// GetUserAssetInstances 获取用户的作品实例列表
-func (s *AssetService) GetUserAssetInstances(userID uint64, page, pageSize int, status string) ([]map[string]interface{}, int64, error) {
+// GetUserAssetInstances 获取用户的作品实例列表（支持状态、属性筛选，sortBy为rarity时按稀有度排名排序）
+func (s *AssetService) GetUserAssetInstances(userID uint64, page, pageSize int, status string, traits []TraitFilter, sortBy string) ([]map[string]interface{}, int64, error) {
 	var instances []models.AssetInstance
 	var total int64
 
@@ -173,15 +326,21 @@ func (s *AssetService) GetUserAssetInstances(userID uint64, page, pageSize int,
 	if status != "" {
 		query = query.Where("status = ?", status)
 	}
+	query = ApplyTraitFilters(query, traits)
 
 	// 获取总数
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
+	order := "created_at DESC"
+	if sortBy == InstanceSortRarity {
+		order = instanceOrder(sortBy)
+	}
+
 	// 分页查询
 	offset := (page - 1) * pageSize
-	if err := query.Offset(offset).Limit(pageSize).Order("created_at DESC").Find(&instances).Error; err != nil {
+	if err := query.Offset(offset).Limit(pageSize).Order(order).Find(&instances).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -207,6 +366,8 @@ func (s *AssetService) GetUserAssetInstances(userID uint64, page, pageSize int,
 			"description":   asset.Description,
 			"image_url":     asset.MediaURL,
 			"serial_number": instance.InstanceNo,
+			"rarity_score":  instance.RarityScore,
+			"rarity_rank":   instance.RarityRank,
 			"status":        instance.Status,
 			"is_listed":     isListed,
 			"created_at":    instance.CreatedAt,
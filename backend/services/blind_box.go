@@ -0,0 +1,580 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BlindBoxService 定义盲盒服务接口
+type BlindBoxService struct {
+	assetService           *AssetService
+	platformAccountService *PlatformAccountService
+}
+
+// NewBlindBoxService 创建一个新的BlindBoxService实例
+func NewBlindBoxService() *BlindBoxService {
+	return &BlindBoxService{
+		assetService:           NewAssetService(),
+		platformAccountService: NewPlatformAccountService(),
+	}
+}
+
+// TierInput 盲盒档位配置
+type TierInput struct {
+	Name    string `json:"name" binding:"required"`
+	AssetID uint64 `json:"asset_id" binding:"required"`
+	Weight  int    `json:"weight" binding:"required,min=1"`
+	Supply  int    `json:"supply" binding:"required,min=1"`
+}
+
+// DrawCheck 单次抽取的验证结果，EntropySeq/EntropyHash 为抽取时的事件哈希链链头，可在公开的哈希链中核对
+type DrawCheck struct {
+	DrawIndex      int    `json:"draw_index"`
+	EntropySeq     uint64 `json:"entropy_seq"`
+	EntropyHash    string `json:"entropy_hash"`
+	TierID         uint64 `json:"tier_id"`
+	ExpectedTierID uint64 `json:"expected_tier_id"`
+	Match          bool   `json:"match"`
+}
+
+// BlindBoxVerification 盲盒抽取验证结果
+type BlindBoxVerification struct {
+	SeedHash    string      `json:"seed_hash"`
+	Seed        string      `json:"seed"`
+	HashMatches bool        `json:"hash_matches"`
+	AllMatch    bool        `json:"all_match"`
+	Draws       []DrawCheck `json:"draws"`
+}
+
+// EnsureSchema 为已有数据库补建盲盒分成比例、抽取收入拆分和逐次熵字段（由 cmd/migrate 调用）
+func (s *BlindBoxService) EnsureSchema() error {
+	m := database.DB.Migrator()
+	if !m.HasColumn(&models.BlindBox{}, "CommissionRate") {
+		if err := m.AddColumn(&models.BlindBox{}, "CommissionRate"); err != nil {
+			return err
+		}
+	}
+	for _, field := range []string{"CreatorIncome", "PlatformIncome", "ParentRoyalty", "EntropySeq", "EntropyHash"} {
+		if !m.HasColumn(&models.BlindBoxDraw{}, field) {
+			if err := m.AddColumn(&models.BlindBoxDraw{}, field); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DefaultCommissionRate 获取系统配置的默认平台分成比例（%）
+func (s *BlindBoxService) DefaultCommissionRate() decimal.Decimal {
+	rate := decimal.NewFromInt(40)
+	var config models.SystemConfig
+	if err := database.DB.Where("`key` = ?", "default_commission_rate").First(&config).Error; err == nil {
+		if value, err := decimal.NewFromString(config.Value); err == nil {
+			rate = value
+		}
+	}
+	return rate
+}
+
+// CreateBox 创建盲盒（管理员），创建后为草稿状态，需提交种子承诺后才能开售
+func (s *BlindBoxService) CreateBox(box *models.BlindBox, tiers []TierInput) error {
+	if box.Price.LessThanOrEqual(decimal.Zero) {
+		return errors.New("盲盒价格必须大于0")
+	}
+	if box.CommissionRate.LessThan(decimal.Zero) || box.CommissionRate.GreaterThan(decimal.NewFromInt(100)) {
+		return errors.New("平台分成比例必须在0到100之间")
+	}
+	if box.EndAt != nil && !box.EndAt.After(box.StartAt) {
+		return errors.New("结束时间必须晚于开始时间")
+	}
+	if len(tiers) == 0 {
+		return errors.New("盲盒至少需要一个档位")
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		// 同一藏品可出现在多个档位，按藏品汇总校验剩余可铸造数量
+		required := make(map[uint64]int)
+		total := 0
+		for _, tier := range tiers {
+			if tier.Weight < 1 || tier.Supply < 1 {
+				return errors.New("档位权重和供应量必须大于0")
+			}
+			required[tier.AssetID] += tier.Supply
+			total += tier.Supply
+		}
+		for assetID, count := range required {
+			var asset models.Asset
+			if err := tx.First(&asset, assetID).Error; err != nil {
+				return fmt.Errorf("藏品%d不存在", assetID)
+			}
+			if asset.Status != "active" {
+				return fmt.Errorf("藏品《%s》未激活", asset.Name)
+			}
+			if asset.TotalSupply-asset.MintedCount < count {
+				return fmt.Errorf("藏品《%s》剩余可铸造数量不足", asset.Name)
+			}
+		}
+
+		box.TotalSupply = total
+		box.SoldCount = 0
+		box.Status = "draft"
+		if err := tx.Create(box).Error; err != nil {
+			return err
+		}
+
+		for _, input := range tiers {
+			tier := models.BlindBoxTier{
+				BoxID:   box.ID,
+				Name:    input.Name,
+				AssetID: input.AssetID,
+				Weight:  input.Weight,
+				Supply:  input.Supply,
+			}
+			if err := tx.Create(&tier).Error; err != nil {
+				return err
+			}
+			box.Tiers = append(box.Tiers, tier)
+		}
+		return nil
+	})
+}
+
+// CommitSeed 生成随机种子并公布承诺，随后盲盒开售（管理员）
+func (s *BlindBoxService) CommitSeed(boxID uint64) (*models.BlindBox, error) {
+	var box models.BlindBox
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&box, boxID).Error; err != nil {
+			return errors.New("盲盒不存在")
+		}
+		if box.Status != "draft" {
+			return errors.New("只有草稿状态的盲盒才能提交种子")
+		}
+
+		seed, err := generateSeed()
+		if err != nil {
+			return err
+		}
+		box.Seed = seed
+		box.SeedHash = hashSeed(seed)
+		box.Status = "on_sale"
+		if err := tx.Model(&box).Updates(map[string]interface{}{
+			"seed":      box.Seed,
+			"seed_hash": box.SeedHash,
+			"status":    box.Status,
+		}).Error; err != nil {
+			return err
+		}
+
+		description := fmt.Sprintf("盲盒《%s》公布随机种子承诺 SHA256=%s，将于%s开售",
+			box.Name, box.SeedHash, box.StartAt.Format("2006-01-02 15:04"))
+		_, err = recordEvent(tx, "blind_box_commit", 0, description, box.ID, "blind_box")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &box, nil
+}
+
+// OpenBox 购买并开启盲盒，按种子、抽取序号和抽取时的哈希链链头确定档位并铸造藏品
+// 链头随平台上每个事件推进，提交种子承诺时无法预知，持有种子也无法预先算出某个序号抽中的档位
+func (s *BlindBoxService) OpenBox(userID, boxID uint64) (*models.BlindBoxDraw, error) {
+	var draw models.BlindBoxDraw
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 1. 锁定盲盒，串行化抽取保证抽取序号连续
+		var box models.BlindBox
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&box, boxID).Error; err != nil {
+			return errors.New("盲盒不存在")
+		}
+		if err := checkBlindBoxAvailable(&box, time.Now()); err != nil {
+			return err
+		}
+
+		// 2. 扣减买家可用积分
+		result := tx.Model(&models.UserPoint{}).
+			Where("user_id = ? AND balance - frozen >= ?", userID, box.Price).
+			Updates(map[string]interface{}{
+				"balance":     gorm.Expr("balance - ?", box.Price),
+				"total_spent": gorm.Expr("total_spent + ?", box.Price),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("积分余额不足")
+		}
+
+		// 3. 以当前链头作为逐次熵确定档位
+		var head models.EventChainHead
+		if err := tx.Where("id = ?", eventChainHeadID).Limit(1).Find(&head).Error; err != nil {
+			return err
+		}
+		var tiers []models.BlindBoxTier
+		if err := tx.Where("box_id = ?", boxID).Order("id asc").Find(&tiers).Error; err != nil {
+			return err
+		}
+		drawIndex := box.SoldCount
+		picked, err := pickTier(box.Seed, drawIndex, head.LastHash, tiers)
+		if err != nil {
+			return err
+		}
+		tier := tiers[picked]
+
+		// 4. 铸造藏品给买家，铸造与开盒事件在事务最后统一记录
+		var events eventBatch
+		instances, err := s.assetService.MintAndAirdropTx(tx, &events, tier.AssetID, userID, 1)
+		if err != nil {
+			return err
+		}
+
+		// 5. 更新档位和盲盒计数
+		if err := tx.Model(&models.BlindBoxTier{}).Where("id = ?", tier.ID).
+			Update("drawn_count", gorm.Expr("drawn_count + ?", 1)).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&box).Update("sold_count", gorm.Expr("sold_count + ?", 1)).Error; err != nil {
+			return err
+		}
+
+		// 6. 按抽中藏品的创作者拆分收入，衍生作品从创作者收入中按比例分给上游创作者
+		var asset models.Asset
+		if err := tx.Select("id", "creator_id").First(&asset, tier.AssetID).Error; err != nil {
+			return err
+		}
+		creatorIncome, platformIncome := splitPrimaryRevenue(box.Price, box.CommissionRate, asset.CreatorID != 0)
+		royaltyShares, parentRoyalty, err := upstreamRoyaltyTx(tx, tier.AssetID, creatorIncome)
+		if err != nil {
+			return err
+		}
+		creatorIncome = creatorIncome.Sub(parentRoyalty)
+
+		// 7. 记录抽取结果
+		draw = models.BlindBoxDraw{
+			BoxID:           boxID,
+			DrawIndex:       drawIndex,
+			UserID:          userID,
+			TierID:          tier.ID,
+			AssetInstanceID: instances[0].ID,
+			Price:           box.Price,
+			CreatorIncome:   creatorIncome,
+			PlatformIncome:  platformIncome,
+			ParentRoyalty:   parentRoyalty,
+			EntropySeq:      head.LastSeq,
+			EntropyHash:     head.LastHash,
+		}
+		if err := tx.Create(&draw).Error; err != nil {
+			return err
+		}
+		draw.Tier = &tier
+		draw.AssetInstance = &instances[0]
+
+		if err := tx.Create(&models.PointTransaction{
+			UserID:      userID,
+			Type:        "spend",
+			Amount:      box.Price,
+			Description: fmt.Sprintf("开启盲盒《%s》", box.Name),
+			RelatedID:   draw.ID,
+			RelatedType: "blind_box_draw",
+		}).Error; err != nil {
+			return err
+		}
+
+		// 8. 创作者分成
+		if creatorIncome.GreaterThan(decimal.Zero) {
+			if err := tx.Model(&models.UserPoint{}).Where("user_id = ?", asset.CreatorID).Updates(map[string]interface{}{
+				"balance":      gorm.Expr("balance + ?", creatorIncome),
+				"total_earned": gorm.Expr("total_earned + ?", creatorIncome),
+			}).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&models.PointTransaction{
+				UserID:      asset.CreatorID,
+				Type:        "earn",
+				Amount:      creatorIncome,
+				Description: fmt.Sprintf("盲盒《%s》分成 %s", box.Name, instances[0].TokenID),
+				RelatedID:   draw.ID,
+				RelatedType: "blind_box_draw",
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		// 9. 上游版税
+		if err := createRoyaltyPayoutsTx(tx, RoyaltySourceBlindBox, draw.ID, tier.AssetID, royaltyShares, true); err != nil {
+			return err
+		}
+
+		// 10. 平台分成计入阳光账户
+		if platformIncome.GreaterThan(decimal.Zero) {
+			if err := s.platformAccountService.RecordPlatformIncomeTx(tx, "commission", platformIncome,
+				fmt.Sprintf("盲盒《%s》第%d抽平台分成", box.Name, drawIndex+1), draw.ID, "blind_box_draw"); err != nil {
+				return err
+			}
+		}
+
+		// 11. 记录社区事件，公示本次抽取使用的链头
+		userUID, err := eventUserTx(tx, userID)
+		if err != nil {
+			return err
+		}
+		description := fmt.Sprintf("用户 %s 开启盲盒《%s》第%d抽（链头 #%d %s），获得%s款 %s",
+			userUID, box.Name, drawIndex+1, head.LastSeq, head.LastHash, tier.Name, instances[0].TokenID)
+		events.add("blind_box_open", userID, description, draw.ID, "blind_box_draw")
+		return events.recordTx(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &draw, nil
+}
+
+// CloseBox 结束盲盒销售（管理员）
+func (s *BlindBoxService) CloseBox(boxID uint64) error {
+	result := database.DB.Model(&models.BlindBox{}).
+		Where("id = ? AND status IN ?", boxID, []string{"draft", "on_sale"}).
+		Update("status", "closed")
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("盲盒不存在或已结束")
+	}
+	return nil
+}
+
+// RevealSeed 公开随机种子（管理员），仅在售罄、已结束或已过结束时间后允许
+func (s *BlindBoxService) RevealSeed(boxID uint64) (*models.BlindBox, error) {
+	var box models.BlindBox
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&box, boxID).Error; err != nil {
+			return errors.New("盲盒不存在")
+		}
+		if box.SeedHash == "" {
+			return errors.New("盲盒尚未提交种子承诺")
+		}
+		if box.Status == "revealed" {
+			return errors.New("种子已公开")
+		}
+		ended := box.Status == "closed" || box.SoldCount >= box.TotalSupply ||
+			(box.EndAt != nil && !time.Now().Before(*box.EndAt))
+		if !ended {
+			return errors.New("盲盒销售结束后才能公开种子")
+		}
+
+		now := time.Now()
+		box.Status = "revealed"
+		box.RevealedAt = &now
+		if err := tx.Model(&box).Updates(map[string]interface{}{
+			"status":      box.Status,
+			"revealed_at": now,
+		}).Error; err != nil {
+			return err
+		}
+
+		description := fmt.Sprintf("盲盒《%s》公开随机种子 %s，共%d次抽取，可据此验证全部结果",
+			box.Name, box.Seed, box.SoldCount)
+		_, err := recordEvent(tx, "blind_box_reveal", 0, description, box.ID, "blind_box")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &box, nil
+}
+
+// VerifyBox 使用公开的种子重放全部抽取并与记录比对
+func (s *BlindBoxService) VerifyBox(boxID uint64) (*BlindBoxVerification, error) {
+	var box models.BlindBox
+	if err := database.DB.First(&box, boxID).Error; err != nil {
+		return nil, errors.New("盲盒不存在")
+	}
+	if box.Status != "revealed" {
+		return nil, errors.New("种子尚未公开，暂不能验证")
+	}
+
+	var tiers []models.BlindBoxTier
+	if err := database.DB.Where("box_id = ?", boxID).Order("id asc").Find(&tiers).Error; err != nil {
+		return nil, err
+	}
+	var draws []models.BlindBoxDraw
+	if err := database.DB.Where("box_id = ?", boxID).Order("draw_index asc").Find(&draws).Error; err != nil {
+		return nil, err
+	}
+
+	checks, allMatch := replayDraws(box.Seed, tiers, draws)
+	return &BlindBoxVerification{
+		SeedHash:    box.SeedHash,
+		Seed:        box.Seed,
+		HashMatches: hashSeed(box.Seed) == box.SeedHash,
+		AllMatch:    allMatch,
+		Draws:       checks,
+	}, nil
+}
+
+// ListBoxes 获取盲盒列表，statuses为空时返回全部
+func (s *BlindBoxService) ListBoxes(statuses []string, page, pageSize int) ([]models.BlindBox, int64, error) {
+	var boxes []models.BlindBox
+	var total int64
+
+	query := database.DB.Model(&models.BlindBox{})
+	if len(statuses) > 0 {
+		query = query.Where("status IN ?", statuses)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Preload("Tiers").Order("start_at desc").Offset(offset).Limit(pageSize).Find(&boxes).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return boxes, total, nil
+}
+
+// GetBox 获取盲盒详情
+func (s *BlindBoxService) GetBox(boxID uint64) (*models.BlindBox, error) {
+	var box models.BlindBox
+	if err := database.DB.Preload("Tiers.Asset").First(&box, boxID).Error; err != nil {
+		return nil, errors.New("盲盒不存在")
+	}
+	return &box, nil
+}
+
+// GetUserDraws 获取用户的盲盒抽取记录
+func (s *BlindBoxService) GetUserDraws(userID uint64, page, pageSize int) ([]models.BlindBoxDraw, int64, error) {
+	var draws []models.BlindBoxDraw
+	var total int64
+
+	query := database.DB.Model(&models.BlindBoxDraw{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Preload("Tier").Preload("AssetInstance").Order("id desc").Offset(offset).Limit(pageSize).Find(&draws).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return draws, total, nil
+}
+
+// checkBlindBoxAvailable 检查盲盒是否可购买
+func checkBlindBoxAvailable(box *models.BlindBox, now time.Time) error {
+	if box.Status != "on_sale" {
+		return errors.New("盲盒未在售")
+	}
+	if box.SoldCount >= box.TotalSupply {
+		return errors.New("盲盒已售罄")
+	}
+	if now.Before(box.StartAt) {
+		return errors.New("盲盒尚未开售")
+	}
+	if box.EndAt != nil && !now.Before(*box.EndAt) {
+		return errors.New("盲盒销售已结束")
+	}
+	return nil
+}
+
+// pickTier 根据种子、抽取序号和逐次熵在有剩余的档位中按权重选择，返回档位下标
+// 随机数对剩余档位总权重取模，档位按ID升序参与计算
+func pickTier(seed string, drawIndex int, entropy string, tiers []models.BlindBoxTier) (int, error) {
+	totalWeight := uint64(0)
+	for _, tier := range tiers {
+		if tier.DrawnCount < tier.Supply {
+			totalWeight += uint64(tier.Weight)
+		}
+	}
+	if totalWeight == 0 {
+		return -1, errors.New("盲盒已售罄")
+	}
+
+	r := drawUint64(seed, drawIndex, entropy) % totalWeight
+
+	for i, tier := range tiers {
+		if tier.DrawnCount >= tier.Supply {
+			continue
+		}
+		if r < uint64(tier.Weight) {
+			return i, nil
+		}
+		r -= uint64(tier.Weight)
+	}
+	return -1, errors.New("盲盒已售罄")
+}
+
+// replayDraws 从零开始按序号重放抽取，逐条与记录比对
+func replayDraws(seed string, tiers []models.BlindBoxTier, draws []models.BlindBoxDraw) ([]DrawCheck, bool) {
+	state := make([]models.BlindBoxTier, len(tiers))
+	copy(state, tiers)
+	for i := range state {
+		state[i].DrawnCount = 0
+	}
+
+	checks := make([]DrawCheck, 0, len(draws))
+	allMatch := true
+	for i, draw := range draws {
+		check := DrawCheck{DrawIndex: draw.DrawIndex, EntropySeq: draw.EntropySeq, EntropyHash: draw.EntropyHash, TierID: draw.TierID}
+		picked, err := pickTier(seed, i, draw.EntropyHash, state)
+		if err == nil && draw.DrawIndex == i {
+			check.ExpectedTierID = state[picked].ID
+			state[picked].DrawnCount++
+		}
+		check.Match = check.ExpectedTierID != 0 && check.ExpectedTierID == draw.TierID
+		if !check.Match {
+			allMatch = false
+		}
+		checks = append(checks, check)
+	}
+	return checks, allMatch
+}
+
+// drawUint64 盲盒抽取随机数：SHA256("种子:序号:链头哈希") 的前8字节（大端）
+// 升级前的历史抽取没有记录链头，按 seededUint64 重放
+func drawUint64(seed string, index int, entropy string) uint64 {
+	if entropy == "" {
+		return seededUint64(seed, index)
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d:%s", seed, index, entropy)))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// seededUint64 可验证随机数：SHA256("种子:序号") 的前8字节（大端）
+func seededUint64(seed string, index int) uint64 {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d", seed, index)))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// generateSeed 生成32字节随机种子
+func generateSeed() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashSeed 计算种子的SHA-256承诺
+func hashSeed(seed string) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"hoho-miniapp/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTiers() []models.BlindBoxTier {
+	return []models.BlindBoxTier{
+		{ID: 1, Name: "普通", Weight: 80, Supply: 80},
+		{ID: 2, Name: "稀有", Weight: 19, Supply: 19},
+		{ID: 3, Name: "隐藏", Weight: 1, Supply: 1},
+	}
+}
+
+// TestPickTierDeterministic 测试相同种子和序号的抽取结果一致
+func TestPickTierDeterministic(t *testing.T) {
+	tiers := testTiers()
+	for i := 0; i < 50; i++ {
+		first, err := pickTier("seed-a", i, "", tiers)
+		assert.NoError(t, err)
+		second, err := pickTier("seed-a", i, "", tiers)
+		assert.NoError(t, err)
+		assert.Equal(t, first, second)
+	}
+}
+
+// TestPickTierExhaustion 测试按档位供应量抽完全部盲盒
+func TestPickTierExhaustion(t *testing.T) {
+	tiers := testTiers()
+	for i := 0; i < 100; i++ {
+		picked, err := pickTier("seed-b", i, "", tiers)
+		assert.NoError(t, err)
+		tiers[picked].DrawnCount++
+	}
+
+	for _, tier := range tiers {
+		assert.Equal(t, tier.Supply, tier.DrawnCount, "档位%s应被恰好抽完", tier.Name)
+	}
+
+	_, err := pickTier("seed-b", 100, "", tiers)
+	assert.EqualError(t, err, "盲盒已售罄")
+}
+
+// TestPickTierSkipsEmptyTier 测试已抽完的档位不再被抽中
+func TestPickTierSkipsEmptyTier(t *testing.T) {
+	tiers := testTiers()
+	tiers[0].DrawnCount = tiers[0].Supply
+	tiers[1].DrawnCount = tiers[1].Supply
+
+	for i := 0; i < 20; i++ {
+		picked, err := pickTier("seed-c", i, "", tiers)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, picked)
+	}
+}
+
+// TestReplayDraws 测试重放验证能发现被篡改的抽取记录
+func TestReplayDraws(t *testing.T) {
+	seed := "4f1c0b5e"
+	tiers := testTiers()
+
+	state := testTiers()
+	var draws []models.BlindBoxDraw
+	for i := 0; i < 30; i++ {
+		entropy := hashSeed(fmt.Sprintf("head-%d", i))
+		picked, err := pickTier(seed, i, entropy, state)
+		assert.NoError(t, err)
+		state[picked].DrawnCount++
+		draws = append(draws, models.BlindBoxDraw{DrawIndex: i, TierID: state[picked].ID, EntropySeq: uint64(i), EntropyHash: entropy})
+	}
+
+	checks, allMatch := replayDraws(seed, tiers, draws)
+	assert.True(t, allMatch)
+	assert.Len(t, checks, 30)
+
+	// 篡改一条记录
+	tampered := make([]models.BlindBoxDraw, len(draws))
+	copy(tampered, draws)
+	if tampered[5].TierID == 3 {
+		tampered[5].TierID = 1
+	} else {
+		tampered[5].TierID = 3
+	}
+	checks, allMatch = replayDraws(seed, tiers, tampered)
+	assert.False(t, allMatch)
+	assert.False(t, checks[5].Match)
+
+	// 使用错误种子
+	_, allMatch = replayDraws("other-seed", tiers, draws)
+	assert.False(t, allMatch)
+
+	// 篡改抽取时记录的链头
+	tampered = make([]models.BlindBoxDraw, len(draws))
+	copy(tampered, draws)
+	for i := range tampered {
+		tampered[i].EntropyHash = hashSeed("forged")
+	}
+	_, allMatch = replayDraws(seed, tiers, tampered)
+	assert.False(t, allMatch)
+}
+
+// TestPickTierEntropy 测试抽取结果依赖抽取时的链头，持有种子无法预知某个序号的档位
+func TestPickTierEntropy(t *testing.T) {
+	tiers := testTiers()
+	picks := make(map[int]bool)
+	for i := 0; i < 200; i++ {
+		picked, err := pickTier("seed-d", 7, hashSeed(fmt.Sprintf("head-%d", i)), tiers)
+		assert.NoError(t, err)
+		picks[picked] = true
+	}
+	assert.True(t, len(picks) > 1, "同一序号在不同链头下应抽中不同档位")
+
+	// 没有记录链头的历史抽取按原算法重放
+	legacy, err := pickTier("seed-d", 7, "", tiers)
+	assert.NoError(t, err)
+	expected := int(seededUint64("seed-d", 7) % 100)
+	switch {
+	case expected < 80:
+		assert.Equal(t, 0, legacy)
+	case expected < 99:
+		assert.Equal(t, 1, legacy)
+	default:
+		assert.Equal(t, 2, legacy)
+	}
+}
+
+// TestHashSeed 测试种子承诺
+func TestHashSeed(t *testing.T) {
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", hashSeed("hello"))
+
+	seed, err := generateSeed()
+	assert.NoError(t, err)
+	assert.Len(t, seed, 64)
+	assert.Len(t, hashSeed(seed), 64)
+}
+
+// TestCheckBlindBoxAvailable 测试盲盒可购买状态检查
+func TestCheckBlindBoxAvailable(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		box      models.BlindBox
+		errorMsg string
+	}{
+		{"正常在售", models.BlindBox{Status: "on_sale", TotalSupply: 10, StartAt: past}, ""},
+		{"草稿未开售", models.BlindBox{Status: "draft", TotalSupply: 10, StartAt: past}, "盲盒未在售"},
+		{"已售罄", models.BlindBox{Status: "on_sale", TotalSupply: 10, SoldCount: 10, StartAt: past}, "盲盒已售罄"},
+		{"尚未开售", models.BlindBox{Status: "on_sale", TotalSupply: 10, StartAt: now.Add(time.Hour)}, "盲盒尚未开售"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkBlindBoxAvailable(&tt.box, now)
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.errorMsg)
+			}
+		})
+	}
+}
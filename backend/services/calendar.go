@@ -0,0 +1,336 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"gorm.io/gorm"
+)
+
+// defaultReminderMinutes 开售前提醒的提前分钟数（可通过系统配置 release_reminder_minutes 覆盖）
+const defaultReminderMinutes = 15
+
+// maxCalendarDays 单次查询日历的最大天数
+const maxCalendarDays = 92
+
+// CalendarService 定义发售日历服务接口
+type CalendarService struct{}
+
+// NewCalendarService 创建一个新的CalendarService实例
+func NewCalendarService() *CalendarService {
+	return &CalendarService{}
+}
+
+// ReleaseItem 日历中的一次发售
+type ReleaseItem struct {
+	AssetID      uint64    `json:"asset_id"`
+	Title        string    `json:"title"`
+	ThumbnailURL string    `json:"thumbnail_url"`
+	CreatorName  string    `json:"creator_name"`
+	Series       string    `json:"series"`
+	Source       string    `json:"source"`
+	ReleaseAt    time.Time `json:"release_at"`
+	Price        string    `json:"price"`
+	TotalSupply  int       `json:"total_supply"`
+	SaleID       uint64    `json:"sale_id,omitempty"`
+	SoldOut      bool      `json:"sold_out"`
+	Reminded     bool      `json:"reminded"`
+}
+
+// CalendarDay 按天分组的发售列表
+type CalendarDay struct {
+	Date  string        `json:"date"`
+	Items []ReleaseItem `json:"items"`
+}
+
+// GetCalendar 获取时间范围内的发售日历，userID不为0时标记用户已订阅的提醒
+func (s *CalendarService) GetCalendar(userID uint64, from, to time.Time) ([]CalendarDay, error) {
+	if !to.After(from) {
+		return nil, errors.New("结束日期必须晚于开始日期")
+	}
+	if to.Sub(from) > maxCalendarDays*24*time.Hour {
+		return nil, fmt.Errorf("单次最多查询%d天", maxCalendarDays)
+	}
+
+	var assets []models.Asset
+	if err := database.DB.Where("release_date >= ? AND release_date < ? AND status = ?", from, to, "active").
+		Order("release_date asc, id asc").Find(&assets).Error; err != nil {
+		return nil, err
+	}
+
+	items, err := s.buildItems(userID, assets)
+	if err != nil {
+		return nil, err
+	}
+	return groupReleasesByDay(items, from.Location()), nil
+}
+
+// GetUpcoming 获取即将发售的藏品（用于ICS导出）
+func (s *CalendarService) GetUpcoming(days int) ([]ReleaseItem, error) {
+	now := time.Now()
+	var assets []models.Asset
+	if err := database.DB.Where("release_date >= ? AND release_date < ? AND status = ?",
+		now, now.AddDate(0, 0, days), "active").
+		Order("release_date asc, id asc").Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return s.buildItems(0, assets)
+}
+
+// Subscribe 订阅藏品的开售提醒
+func (s *CalendarService) Subscribe(userID, assetID uint64) (*models.ReleaseReminder, error) {
+	var asset models.Asset
+	if err := database.DB.First(&asset, assetID).Error; err != nil {
+		return nil, errors.New("藏品不存在")
+	}
+	if asset.ReleaseDate == nil || !asset.ReleaseDate.After(time.Now()) {
+		return nil, errors.New("该藏品已开售，无需提醒")
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.ReleaseReminder{}).
+		Where("user_id = ? AND asset_id = ?", userID, assetID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, errors.New("已订阅开售提醒")
+	}
+
+	reminder := &models.ReleaseReminder{UserID: userID, AssetID: assetID}
+	if err := database.DB.Create(reminder).Error; err != nil {
+		return nil, err
+	}
+	reminder.Asset = &asset
+	return reminder, nil
+}
+
+// Unsubscribe 取消开售提醒
+func (s *CalendarService) Unsubscribe(userID, assetID uint64) error {
+	result := database.DB.Where("user_id = ? AND asset_id = ?", userID, assetID).
+		Delete(&models.ReleaseReminder{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("未订阅该藏品的开售提醒")
+	}
+	return nil
+}
+
+// GetUserReminders 获取用户订阅的发售，按开售时间排序
+func (s *CalendarService) GetUserReminders(userID uint64) ([]ReleaseItem, error) {
+	var assets []models.Asset
+	if err := database.DB.
+		Joins("JOIN release_reminders ON release_reminders.asset_id = assets.id").
+		Where("release_reminders.user_id = ?", userID).
+		Order("assets.release_date asc, assets.id asc").
+		Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	return s.buildItems(userID, assets)
+}
+
+// SendDueReminders 向即将开售藏品的订阅用户发送提醒通知（定时任务）
+func (s *CalendarService) SendDueReminders() error {
+	minutes := getConfigInt("release_reminder_minutes", defaultReminderMinutes)
+	now := time.Now()
+
+	var reminders []models.ReleaseReminder
+	if err := database.DB.Preload("Asset").
+		Joins("JOIN assets ON assets.id = release_reminders.asset_id").
+		Where("release_reminders.notified_at IS NULL AND assets.release_date <= ?", now.Add(time.Duration(minutes)*time.Minute)).
+		Find(&reminders).Error; err != nil {
+		return err
+	}
+
+	for _, reminder := range reminders {
+		if reminder.Asset == nil || reminder.Asset.ReleaseDate == nil {
+			continue
+		}
+		asset := reminder.Asset
+
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			// 条件更新保证每个订阅只提醒一次
+			result := tx.Model(&models.ReleaseReminder{}).
+				Where("id = ? AND notified_at IS NULL", reminder.ID).
+				Update("notified_at", now)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return nil
+			}
+
+			relatedID := uint(asset.ID)
+			return tx.Create(&models.Notification{
+				UserID:    uint(reminder.UserID),
+				Type:      "system",
+				Title:     "开售提醒",
+				Content:   fmt.Sprintf("您关注的藏品《%s》将于%s开售", asset.Name, asset.ReleaseDate.Format("01-02 15:04")),
+				RelatedID: &relatedID,
+			}).Error
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExportICS 将发售列表导出为iCalendar格式，每个事件带开售前提醒
+func (s *CalendarService) ExportICS(items []ReleaseItem) []byte {
+	minutes := getConfigInt("release_reminder_minutes", defaultReminderMinutes)
+	return buildICS(items, minutes, time.Now())
+}
+
+// buildItems 组装日历条目，补充首发信息和用户订阅状态
+func (s *CalendarService) buildItems(userID uint64, assets []models.Asset) ([]ReleaseItem, error) {
+	if len(assets) == 0 {
+		return []ReleaseItem{}, nil
+	}
+
+	assetIDs := make([]uint64, 0, len(assets))
+	for _, asset := range assets {
+		assetIDs = append(assetIDs, asset.ID)
+	}
+
+	var sales []models.PrimarySale
+	if err := database.DB.Where("asset_id IN ?", assetIDs).Find(&sales).Error; err != nil {
+		return nil, err
+	}
+	saleByAsset := make(map[uint64]models.PrimarySale, len(sales))
+	for _, sale := range sales {
+		saleByAsset[sale.AssetID] = sale
+	}
+
+	reminded := make(map[uint64]bool)
+	if userID != 0 {
+		var ids []uint64
+		if err := database.DB.Model(&models.ReleaseReminder{}).
+			Where("user_id = ? AND asset_id IN ?", userID, assetIDs).
+			Pluck("asset_id", &ids).Error; err != nil {
+			return nil, err
+		}
+		for _, id := range ids {
+			reminded[id] = true
+		}
+	}
+
+	items := make([]ReleaseItem, 0, len(assets))
+	for i := range assets {
+		asset := &assets[i]
+		if asset.ReleaseDate == nil {
+			continue
+		}
+		item := ReleaseItem{
+			AssetID:      asset.ID,
+			Title:        asset.Name,
+			ThumbnailURL: asset.ThumbnailURL,
+			CreatorName:  asset.CreatorName,
+			Series:       asset.Series,
+			Source:       asset.Source,
+			ReleaseAt:    *asset.ReleaseDate,
+			Price:        asset.Price.String(),
+			TotalSupply:  asset.TotalSupply,
+			SoldOut:      asset.SoldOut(),
+			Reminded:     reminded[asset.ID],
+		}
+		if sale, ok := saleByAsset[asset.ID]; ok {
+			item.SaleID = sale.ID
+			item.Price = sale.Price.String()
+			item.SoldOut = item.SoldOut || sale.Status == "sold_out"
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// groupReleasesByDay 按开售日期（指定时区）分组，输入需已按开售时间排序
+func groupReleasesByDay(items []ReleaseItem, loc *time.Location) []CalendarDay {
+	days := []CalendarDay{}
+	for _, item := range items {
+		date := item.ReleaseAt.In(loc).Format("2006-01-02")
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, CalendarDay{Date: date, Items: []ReleaseItem{}})
+		}
+		days[len(days)-1].Items = append(days[len(days)-1].Items, item)
+	}
+	return days
+}
+
+// buildICS 生成iCalendar（RFC 5545）内容
+func buildICS(items []ReleaseItem, reminderMinutes int, now time.Time) []byte {
+	const layout = "20060102T150405Z"
+
+	var buf bytes.Buffer
+	writeLine := func(line string) {
+		buf.WriteString(foldICSLine(line))
+		buf.WriteString("\r\n")
+	}
+
+	writeLine("BEGIN:VCALENDAR")
+	writeLine("VERSION:2.0")
+	writeLine("PRODID:-//HOHO//Release Calendar//ZH")
+	writeLine("CALSCALE:GREGORIAN")
+	writeLine("METHOD:PUBLISH")
+	writeLine("X-WR-CALNAME:" + escapeICSText("HOHO发售日历"))
+	for _, item := range items {
+		description := fmt.Sprintf("价格：%s 积分\n发行量：%d", item.Price, item.TotalSupply)
+		if item.CreatorName != "" {
+			description = fmt.Sprintf("创作者：%s\n%s", item.CreatorName, description)
+		}
+
+		writeLine("BEGIN:VEVENT")
+		writeLine(fmt.Sprintf("UID:release-%d@hoho-miniapp", item.AssetID))
+		writeLine("DTSTAMP:" + now.UTC().Format(layout))
+		writeLine("DTSTART:" + item.ReleaseAt.UTC().Format(layout))
+		writeLine("DURATION:PT30M")
+		writeLine("SUMMARY:" + escapeICSText("首发：《"+item.Title+"》"))
+		writeLine("DESCRIPTION:" + escapeICSText(description))
+		if reminderMinutes > 0 {
+			writeLine("BEGIN:VALARM")
+			writeLine("ACTION:DISPLAY")
+			writeLine(fmt.Sprintf("TRIGGER:-PT%dM", reminderMinutes))
+			writeLine("DESCRIPTION:" + escapeICSText("《"+item.Title+"》即将开售"))
+			writeLine("END:VALARM")
+		}
+		writeLine("END:VEVENT")
+	}
+	writeLine("END:VCALENDAR")
+
+	return buf.Bytes()
+}
+
+// escapeICSText 转义iCalendar文本值中的特殊字符
+func escapeICSText(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return replacer.Replace(value)
+}
+
+// foldICSLine 按RFC 5545将超过75字节的行折叠，不拆分多字节字符
+func foldICSLine(line string) string {
+	const limit = 75
+	if len(line) <= limit {
+		return line
+	}
+
+	var buf strings.Builder
+	width := 0
+	for _, r := range line {
+		size := utf8.RuneLen(r)
+		if width+size > limit {
+			buf.WriteString("\r\n ")
+			width = 1
+		}
+		buf.WriteRune(r)
+		width += size
+	}
+	return buf.String()
+}
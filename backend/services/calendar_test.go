@@ -0,0 +1,70 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestGroupReleasesByDay 测试发售按天分组
+func TestGroupReleasesByDay(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	items := []ReleaseItem{
+		{AssetID: 1, ReleaseAt: time.Date(2026, 3, 1, 10, 0, 0, 0, loc)},
+		{AssetID: 2, ReleaseAt: time.Date(2026, 3, 1, 20, 0, 0, 0, loc)},
+		// UTC时间为3月2日16:30，东八区为3月3日
+		{AssetID: 3, ReleaseAt: time.Date(2026, 3, 2, 16, 30, 0, 0, time.UTC)},
+	}
+
+	days := groupReleasesByDay(items, loc)
+	assert.Len(t, days, 2)
+	assert.Equal(t, "2026-03-01", days[0].Date)
+	assert.Len(t, days[0].Items, 2)
+	assert.Equal(t, "2026-03-03", days[1].Date)
+	assert.Equal(t, uint64(3), days[1].Items[0].AssetID)
+
+	assert.Empty(t, groupReleasesByDay(nil, loc))
+}
+
+// TestBuildICS 测试iCalendar导出格式
+func TestBuildICS(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	items := []ReleaseItem{{
+		AssetID:     7,
+		Title:       "山海, 第一季; 限定",
+		CreatorName: "阿木",
+		ReleaseAt:   time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC),
+		Price:       "99",
+		TotalSupply: 500,
+	}}
+
+	ics := string(buildICS(items, 15, now))
+	assert.True(t, strings.HasPrefix(ics, "BEGIN:VCALENDAR\r\n"))
+	assert.True(t, strings.HasSuffix(ics, "END:VCALENDAR\r\n"))
+	assert.Contains(t, ics, "UID:release-7@hoho-miniapp\r\n")
+	assert.Contains(t, ics, "DTSTART:20260302T120000Z\r\n")
+	assert.Contains(t, ics, "TRIGGER:-PT15M\r\n")
+	assert.Contains(t, ics, `山海\, 第一季\; 限定`)
+
+	for _, line := range strings.Split(ics, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+}
+
+// TestFoldICSLine 测试长行折叠不拆分多字节字符
+func TestFoldICSLine(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("藏品", 30)
+	folded := foldICSLine(line)
+
+	parts := strings.Split(folded, "\r\n")
+	assert.Greater(t, len(parts), 1)
+	for i, part := range parts {
+		assert.LessOrEqual(t, len(part), 75)
+		if i > 0 {
+			assert.True(t, strings.HasPrefix(part, " "))
+		}
+	}
+	assert.Equal(t, line, strings.ReplaceAll(folded, "\r\n ", ""))
+}
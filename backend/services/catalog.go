@@ -0,0 +1,410 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+)
+
+// defaultCatalogCacheSeconds 藏品目录缓存时长（秒），可通过系统配置 catalog_cache_seconds 覆盖，0为不缓存
+const defaultCatalogCacheSeconds = 30
+
+// trendingDays 热门排序统计的成交天数
+const trendingDays = 7
+
+// 目录排序方式
+const (
+	CatalogSortRecommended = "recommended" // 推荐：近7天成交数×3 + 持有人数
+	CatalogSortTrending    = "trending"    // 热门：近7天成交数
+	CatalogSortNew         = "new"         // 最新：按发售时间（未设置时按创建时间）
+)
+
+// recentTradesJoin 关联每个藏品自指定时间以来的成交数（tr.cnt），参数为起始时间
+const recentTradesJoin = `LEFT JOIN (
+	SELECT ai.asset_id, COUNT(*) AS cnt FROM trades
+	JOIN asset_instances ai ON ai.id = trades.asset_instance_id
+	WHERE trades.status = 'completed' AND trades.completed_at >= ? AND trades.deleted_at IS NULL
+	GROUP BY ai.asset_id
+) tr ON tr.asset_id = assets.id`
+
+// catalogSources 可筛选的藏品来源
+var catalogSources = map[string]bool{"platform": true, "jingtan": true, "community": true, "waveup": true}
+
+// CatalogService 定义藏品目录浏览服务接口
+type CatalogService struct{}
+
+// NewCatalogService 创建一个新的CatalogService实例
+func NewCatalogService() *CatalogService {
+	return &CatalogService{}
+}
+
+// CatalogQuery 目录查询条件
+type CatalogQuery struct {
+	Source       string
+	CollectionID uint64
+	Sort         string
+	Cursor       string
+	Limit        int
+}
+
+// CatalogItem 目录中的藏品，附带持有人数、挂售数和地板价
+type CatalogItem struct {
+	ID           uint64     `json:"id"`
+	CollectionID uint64     `json:"collection_id"`
+	Name         string     `json:"name"`
+	MediaURL     string     `json:"media_url"`
+	MediaType    string     `json:"media_type"`
+	ThumbnailURL string     `json:"thumbnail_url"`
+	CreatorID    uint64     `json:"creator_id"`
+	CreatorName  string     `json:"creator_name"`
+	Source       string     `json:"source"`
+	Series       string     `json:"series"`
+	Price        string     `json:"price"`
+	TotalSupply  int        `json:"total_supply"`
+	MintedCount  int        `json:"minted_count"`
+	ReleaseDate  *time.Time `json:"release_date"`
+	HolderCount  int64      `json:"holder_count"`
+	ListingCount int64      `json:"listing_count"`
+	FloorPrice   *string    `json:"floor_price"` // 无挂售时为null
+}
+
+// CatalogPage 目录分页结果，NextCursor为空表示没有更多
+type CatalogPage struct {
+	List       []CatalogItem `json:"list"`
+	NextCursor string        `json:"next_cursor"`
+	HasMore    bool          `json:"has_more"`
+}
+
+// catalogCursor 游标：最新排序为上一页最后一项的排序值和ID（键集分页）；
+// 热门与推荐的排序值随成交和持有变化，键集游标会在翻页间跳过或重复藏品，改为按偏移量分页
+type catalogCursor struct {
+	Score  int64
+	ID     uint64
+	Offset int
+	ByRank bool // 按偏移量分页
+}
+
+// ListAssets 浏览藏品目录，优先读取缓存
+func (s *CatalogService) ListAssets(query CatalogQuery) (*CatalogPage, error) {
+	query, err := normalizeCatalogQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := getConfigInt("catalog_cache_seconds", defaultCatalogCacheSeconds)
+	key := catalogCacheKey(query)
+	if ttl > 0 {
+		if page, ok := s.getCachedPage(key); ok {
+			return page, nil
+		}
+	}
+
+	page, err := s.queryAssets(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if ttl > 0 {
+		s.setCachedPage(key, page, time.Duration(ttl)*time.Second)
+	}
+	return page, nil
+}
+
+// queryAssets 最新排序按排序值和ID做键集分页，热门与推荐按偏移量分页，再批量补充统计数据
+func (s *CatalogService) queryAssets(query CatalogQuery) (*CatalogPage, error) {
+	byRank := query.Sort != CatalogSortNew
+	var cursor *catalogCursor
+	if query.Cursor != "" {
+		decoded, err := decodeCatalogCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if decoded.ByRank != byRank {
+			return nil, errors.New("游标与排序方式不匹配")
+		}
+		cursor = decoded
+	}
+
+	score := catalogScoreExpr(query.Sort)
+	db := database.DB.Table("assets").
+		Select("assets.id, "+score+" AS score").
+		Where("assets.status = ? AND assets.deleted_at IS NULL", "active")
+
+	if query.Sort != CatalogSortNew {
+		since := time.Now().AddDate(0, 0, -trendingDays)
+		db = db.Joins(recentTradesJoin, since)
+	}
+	if query.Sort == CatalogSortRecommended {
+		db = db.Joins(`LEFT JOIN (
+			SELECT asset_id, COUNT(DISTINCT owner_id) AS cnt FROM asset_instances
+			WHERE status <> 'burned' AND deleted_at IS NULL
+			GROUP BY asset_id
+		) holders ON holders.asset_id = assets.id`)
+	}
+
+	if query.Source != "" {
+		db = db.Where("assets.source = ?", query.Source)
+	}
+	if query.CollectionID != 0 {
+		db = db.Where("assets.collection_id = ?", query.CollectionID)
+	}
+	offset := 0
+	if cursor != nil {
+		if byRank {
+			offset = cursor.Offset
+			db = db.Offset(offset)
+		} else {
+			db = db.Where("("+score+" < ? OR ("+score+" = ? AND assets.id < ?))", cursor.Score, cursor.Score, cursor.ID)
+		}
+	}
+
+	var rows []struct {
+		ID    uint64
+		Score int64
+	}
+	if err := db.Order("score desc, assets.id desc").Limit(query.Limit + 1).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	page := &CatalogPage{List: []CatalogItem{}}
+	if len(rows) > query.Limit {
+		rows = rows[:query.Limit]
+		last := rows[len(rows)-1]
+		page.HasMore = true
+		if byRank {
+			page.NextCursor = encodeCatalogCursor(catalogCursor{Offset: offset + len(rows), ByRank: true})
+		} else {
+			page.NextCursor = encodeCatalogCursor(catalogCursor{Score: last.Score, ID: last.ID})
+		}
+	}
+	if len(rows) == 0 {
+		return page, nil
+	}
+
+	ids := make([]uint64, 0, len(rows))
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+	}
+	var assets []models.Asset
+	if err := database.DB.Where("id IN ?", ids).Find(&assets).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uint64]*models.Asset, len(assets))
+	for i := range assets {
+		byID[assets[i].ID] = &assets[i]
+	}
+
+	stats, err := loadAssetMarketStats(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range ids {
+		asset, ok := byID[id]
+		if !ok {
+			continue
+		}
+		page.List = append(page.List, buildCatalogItem(asset, stats[id]))
+	}
+	return page, nil
+}
+
+// buildCatalogItem 组装目录条目，stat为nil表示没有持有人和挂售
+func buildCatalogItem(asset *models.Asset, stat *assetMarketStat) CatalogItem {
+	item := CatalogItem{
+		ID:           asset.ID,
+		CollectionID: asset.CollectionID,
+		Name:         asset.Name,
+		MediaURL:     asset.MediaURL,
+		MediaType:    asset.MediaType,
+		ThumbnailURL: asset.ThumbnailURL,
+		CreatorID:    asset.CreatorID,
+		CreatorName:  asset.CreatorName,
+		Source:       asset.Source,
+		Series:       asset.Series,
+		Price:        asset.Price.String(),
+		TotalSupply:  asset.TotalSupply,
+		MintedCount:  asset.MintedCount,
+		ReleaseDate:  asset.ReleaseDate,
+	}
+	if stat != nil {
+		item.HolderCount = stat.holders
+		item.ListingCount = stat.listings
+		if stat.floor != nil {
+			floor := stat.floor.String()
+			item.FloorPrice = &floor
+		}
+	}
+	return item
+}
+
+// assetMarketStat 单个藏品的市场统计
+type assetMarketStat struct {
+	holders  int64
+	listings int64
+	floor    *decimal.Decimal
+}
+
+// loadAssetMarketStats 批量统计藏品的持有人数、在售挂单数和地板价
+func loadAssetMarketStats(assetIDs []uint64) (map[uint64]*assetMarketStat, error) {
+	stats := make(map[uint64]*assetMarketStat, len(assetIDs))
+	get := func(id uint64) *assetMarketStat {
+		if stats[id] == nil {
+			stats[id] = &assetMarketStat{}
+		}
+		return stats[id]
+	}
+
+	var holders []struct {
+		AssetID uint64
+		Cnt     int64
+	}
+	if err := database.DB.Model(&models.AssetInstance{}).
+		Select("asset_id, COUNT(DISTINCT owner_id) AS cnt").
+		Where("asset_id IN ? AND status <> ?", assetIDs, "burned").
+		Group("asset_id").Scan(&holders).Error; err != nil {
+		return nil, err
+	}
+	for _, h := range holders {
+		get(h.AssetID).holders = h.Cnt
+	}
+
+	var listings []struct {
+		AssetID uint64
+		Cnt     int64
+		Floor   decimal.NullDecimal
+	}
+	if err := database.DB.Model(&models.Listing{}).
+		Select("asset_instances.asset_id, COUNT(*) AS cnt, MIN(listings.price) AS floor").
+		Joins("JOIN asset_instances ON asset_instances.id = listings.asset_instance_id").
+		Where("asset_instances.asset_id IN ? AND listings.status = ?", assetIDs, "active").
+		Group("asset_instances.asset_id").Scan(&listings).Error; err != nil {
+		return nil, err
+	}
+	for _, l := range listings {
+		stat := get(l.AssetID)
+		stat.listings = l.Cnt
+		if l.Floor.Valid {
+			floor := l.Floor.Decimal
+			stat.floor = &floor
+		}
+	}
+	return stats, nil
+}
+
+// getCachedPage 读取缓存，Redis不可用时视为未命中
+func (s *CatalogService) getCachedPage(key string) (*CatalogPage, bool) {
+	if database.RDB == nil {
+		return nil, false
+	}
+	data, err := database.RDB.Get(database.Ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("读取藏品目录缓存失败: %v", err)
+		}
+		return nil, false
+	}
+	var page CatalogPage
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, false
+	}
+	return &page, true
+}
+
+// setCachedPage 写入缓存，失败仅记录日志
+func (s *CatalogService) setCachedPage(key string, page *CatalogPage, ttl time.Duration) {
+	if database.RDB == nil {
+		return
+	}
+	data, err := json.Marshal(page)
+	if err != nil {
+		return
+	}
+	if err := database.RDB.Set(database.Ctx, key, data, ttl).Err(); err != nil {
+		log.Printf("写入藏品目录缓存失败: %v", err)
+	}
+}
+
+// normalizeCatalogQuery 校验筛选条件并填充默认排序和每页数量
+func normalizeCatalogQuery(query CatalogQuery) (CatalogQuery, error) {
+	if query.Source != "" && !catalogSources[query.Source] {
+		return query, errors.New("不支持的藏品来源")
+	}
+	switch query.Sort {
+	case "":
+		query.Sort = CatalogSortRecommended
+	case CatalogSortRecommended, CatalogSortTrending, CatalogSortNew:
+	default:
+		return query, errors.New("不支持的排序方式")
+	}
+	if query.Limit < 1 || query.Limit > 50 {
+		query.Limit = 20
+	}
+	return query, nil
+}
+
+// catalogScoreExpr 排序值的SQL表达式，热门与推荐依赖 tr/holders 子查询
+func catalogScoreExpr(sort string) string {
+	switch sort {
+	case CatalogSortTrending:
+		return "COALESCE(tr.cnt, 0)"
+	case CatalogSortNew:
+		return "CAST(UNIX_TIMESTAMP(COALESCE(assets.release_date, assets.created_at)) AS SIGNED)"
+	default:
+		return "(COALESCE(tr.cnt, 0) * 3 + COALESCE(holders.cnt, 0))"
+	}
+}
+
+// catalogCacheKey 按查询条件生成缓存键
+func catalogCacheKey(query CatalogQuery) string {
+	return fmt.Sprintf("catalog:%s:%s:%d:%d:%s", query.Sort, query.Source, query.CollectionID, query.Limit, query.Cursor)
+}
+
+// encodeCatalogCursor 将游标编码为不透明字符串，偏移量游标以o开头
+func encodeCatalogCursor(cursor catalogCursor) string {
+	raw := fmt.Sprintf("%d_%d", cursor.Score, cursor.ID)
+	if cursor.ByRank {
+		raw = fmt.Sprintf("o%d", cursor.Offset)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCatalogCursor 解析游标
+func decodeCatalogCursor(value string) (*catalogCursor, error) {
+	invalid := errors.New("游标格式错误")
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, invalid
+	}
+	if strings.HasPrefix(string(raw), "o") {
+		offset, err := strconv.Atoi(string(raw[1:]))
+		if err != nil || offset <= 0 {
+			return nil, invalid
+		}
+		return &catalogCursor{Offset: offset, ByRank: true}, nil
+	}
+	parts := strings.SplitN(string(raw), "_", 2)
+	if len(parts) != 2 {
+		return nil, invalid
+	}
+	score, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, invalid
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || id == 0 {
+		return nil, invalid
+	}
+	return &catalogCursor{Score: score, ID: id}, nil
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// platformCollectionName 无系列的平台作品迁移后归属的集合名称
+const platformCollectionName = "平台藏品"
+
+// CatalogMigrationService 将旧版 artworks/artwork_instances 数据迁移到统一的 assets/asset_instances
+// 迁移可重复执行：已迁移的作品和实例通过 legacy_artwork_id/legacy_instance_id 识别并跳过
+type CatalogMigrationService struct{}
+
+// NewCatalogMigrationService 创建一个新的CatalogMigrationService实例
+func NewCatalogMigrationService() *CatalogMigrationService {
+	return &CatalogMigrationService{}
+}
+
+// CatalogMigrationReport 迁移结果统计
+type CatalogMigrationReport struct {
+	Assets     int                 `json:"assets"`     // 本次新建的藏品数
+	Instances  int                 `json:"instances"`  // 本次新建的藏品实例数
+	References map[string]int64    `json:"references"` // 各表改写的引用行数
+	Flattened  []FlattenedInstance `json:"flattened"`  // 本次由挂售/锁定状态迁移为持有中的实例，需人工核对
+}
+
+// FlattenedInstance 旧实例处于挂售或锁定状态，迁移后统一为持有中
+type FlattenedInstance struct {
+	AssetInstanceID  uint64 `json:"asset_instance_id"`
+	LegacyInstanceID uint64 `json:"legacy_instance_id"`
+	OwnerID          uint64 `json:"owner_id"`
+	LegacyStatus     string `json:"legacy_status"`
+}
+
+// legacyReference 引用旧作品表的字段，迁移后改为引用藏品表
+type legacyReference struct {
+	Model        interface{}
+	Table        string
+	LegacyColumn string
+	Field        string // 新字段在模型中的名称
+	Column       string
+	Instance     bool     // 引用实例（asset_instances）而非藏品（assets）
+	Constraint   string   // 旧外键，删除旧字段前需先删除
+	Index        string   // 包含旧字段的联合索引，删除旧字段前需先删除
+	Relation     string   // 新外键对应的模型关联
+	Indexes      []string // 新字段在模型中声明的索引（索引名或字段名）
+}
+
+var legacyReferences = []legacyReference{
+	{Model: &models.PrimarySale{}, Table: "primary_sales", LegacyColumn: "artwork_id", Field: "AssetID", Column: "asset_id",
+		Constraint: "fk_primary_sales_artwork", Index: "idx_artwork_id", Relation: "Asset", Indexes: []string{"AssetID"}},
+	{Model: &models.PrimarySaleOrder{}, Table: "primary_sale_orders", LegacyColumn: "artwork_instance_id", Field: "AssetInstanceID", Column: "asset_instance_id",
+		Instance: true, Constraint: "fk_primary_sale_orders_instance", Relation: "AssetInstance", Indexes: []string{"AssetInstanceID"}},
+	{Model: &models.Offer{}, Table: "offers", LegacyColumn: "artwork_instance_id", Field: "AssetInstanceID", Column: "asset_instance_id",
+		Instance: true, Constraint: "fk_offers_artwork_instance", Index: "idx_artwork_instance_id", Relation: "AssetInstance", Indexes: []string{"AssetInstanceID"}},
+	{Model: &models.ReleaseReminder{}, Table: "release_reminders", LegacyColumn: "artwork_id", Field: "AssetID", Column: "asset_id",
+		Constraint: "fk_release_reminders_artwork", Index: "idx_user_artwork", Relation: "Asset", Indexes: []string{"idx_user_asset", "AssetID"}},
+	{Model: &models.Lottery{}, Table: "lotteries", LegacyColumn: "bonus_artwork_id", Field: "BonusAssetID", Column: "bonus_asset_id"},
+}
+
+// Run 依次执行表结构升级、作品及实例迁移、引用改写
+func (s *CatalogMigrationService) Run() (*CatalogMigrationReport, error) {
+	report := &CatalogMigrationReport{References: make(map[string]int64)}
+
+	if err := s.migrateSchema(); err != nil {
+		return nil, fmt.Errorf("升级藏品表结构失败: %w", err)
+	}
+
+	if database.DB.Migrator().HasTable(&models.Artwork{}) {
+		var artworks []models.Artwork
+		if err := database.DB.Order("id asc").Find(&artworks).Error; err != nil {
+			return nil, err
+		}
+		for i := range artworks {
+			// 每个作品及其实例在一个事务中迁移，中断后重新执行不会产生半迁移数据
+			err := database.DB.Transaction(func(tx *gorm.DB) error {
+				assetID, created, err := s.migrateArtworkTx(tx, &artworks[i])
+				if err != nil {
+					return err
+				}
+				if created {
+					report.Assets++
+				}
+				count, flattened, err := s.migrateInstancesTx(tx, artworks[i].ID, assetID)
+				if err != nil {
+					return err
+				}
+				report.Instances += count
+				report.Flattened = append(report.Flattened, flattened...)
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("迁移作品 %d 失败: %w", artworks[i].ID, err)
+			}
+		}
+	}
+
+	for _, ref := range legacyReferences {
+		rows, err := s.migrateReference(ref)
+		if err != nil {
+			return nil, fmt.Errorf("改写 %s.%s 失败: %w", ref.Table, ref.LegacyColumn, err)
+		}
+		report.References[ref.Table+"."+ref.Column] = rows
+	}
+
+	return report, nil
+}
+
+// migrateSchema 为 assets/asset_instances 补充统一目录所需的字段和索引
+func (s *CatalogMigrationService) migrateSchema() error {
+	m := database.DB.Migrator()
+
+	assetFields := []string{"ThumbnailURL", "CreatorName", "Price", "Source", "Series", "ReleaseDate", "CreationID", "LegacyArtworkID"}
+	for _, field := range assetFields {
+		if !m.HasColumn(&models.Asset{}, field) {
+			if err := m.AddColumn(&models.Asset{}, field); err != nil {
+				return err
+			}
+		}
+	}
+	// 媒体类型新增3d
+	if err := m.AlterColumn(&models.Asset{}, "MediaType"); err != nil {
+		return err
+	}
+	for _, field := range []string{"ReleaseDate", "CreationID", "LegacyArtworkID"} {
+		if !m.HasIndex(&models.Asset{}, field) {
+			if err := m.CreateIndex(&models.Asset{}, field); err != nil {
+				return err
+			}
+		}
+	}
+
+	if !m.HasColumn(&models.AssetInstance{}, "LegacyInstanceID") {
+		if err := m.AddColumn(&models.AssetInstance{}, "LegacyInstanceID"); err != nil {
+			return err
+		}
+	}
+	if !m.HasIndex(&models.AssetInstance{}, "LegacyInstanceID") {
+		if err := m.CreateIndex(&models.AssetInstance{}, "LegacyInstanceID"); err != nil {
+			return err
+		}
+	}
+
+	// 平台藏品的 creator_id 为0，删除旧表结构中 creator_id 对 users 的外键
+	var constraints []string
+	if err := database.DB.Raw(`SELECT CONSTRAINT_NAME FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'assets' AND COLUMN_NAME = 'creator_id' AND REFERENCED_TABLE_NAME = 'users'`).
+		Scan(&constraints).Error; err != nil {
+		return err
+	}
+	for _, name := range constraints {
+		if err := database.DB.Exec(fmt.Sprintf("ALTER TABLE `assets` DROP FOREIGN KEY `%s`", name)).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateArtworkTx 为作品创建对应的藏品，已迁移时返回已有藏品ID
+func (s *CatalogMigrationService) migrateArtworkTx(tx *gorm.DB, artwork *models.Artwork) (uint64, bool, error) {
+	var existing models.Asset
+	err := tx.Unscoped().Select("id").Where("legacy_artwork_id = ?", artwork.ID).First(&existing).Error
+	if err == nil {
+		return existing.ID, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, false, err
+	}
+
+	collectionID, err := findOrCreateCollectionTx(tx, legacyCollectionName(artwork))
+	if err != nil {
+		return 0, false, err
+	}
+	asset, err := assetFromArtwork(artwork, collectionID)
+	if err != nil {
+		return 0, false, err
+	}
+	if err := tx.Create(asset).Error; err != nil {
+		return 0, false, err
+	}
+	return asset.ID, true, nil
+}
+
+// migrateInstancesTx 将作品的实例迁移为藏品实例，保留持有者，序列号作为TokenID
+// 实例编号在藏品内从1连续编排，迁移后 MintedCount 不小于最大实例编号，避免后续铸造编号冲突
+// 返回新建实例数以及由挂售/锁定状态迁移为持有中的实例
+func (s *CatalogMigrationService) migrateInstancesTx(tx *gorm.DB, artworkID uint, assetID uint64) (int, []FlattenedInstance, error) {
+	var instances []models.ArtworkInstance
+	if err := tx.Where("artwork_id = ?", artworkID).Order("id asc").Find(&instances).Error; err != nil {
+		return 0, nil, err
+	}
+
+	var migrated []uint64
+	if err := tx.Model(&models.AssetInstance{}).Unscoped().
+		Where("asset_id = ? AND legacy_instance_id IS NOT NULL", assetID).
+		Pluck("legacy_instance_id", &migrated).Error; err != nil {
+		return 0, nil, err
+	}
+	done := make(map[uint64]bool, len(migrated))
+	for _, id := range migrated {
+		done[id] = true
+	}
+
+	var maxNo int
+	if err := tx.Model(&models.AssetInstance{}).Unscoped().Where("asset_id = ?", assetID).
+		Select("COALESCE(MAX(instance_no), 0)").Scan(&maxNo).Error; err != nil {
+		return 0, nil, err
+	}
+
+	created := 0
+	var flattened []FlattenedInstance
+	for _, legacy := range instances {
+		legacyID := uint64(legacy.ID)
+		if done[legacyID] {
+			continue
+		}
+		maxNo++
+		// 旧实例的挂售/锁定状态没有对应的挂单或交易记录，统一迁移为持有中
+		instance := models.AssetInstance{
+			AssetID:          assetID,
+			InstanceNo:       maxNo,
+			OwnerID:          uint64(legacy.OwnerID),
+			TokenID:          legacy.SerialNumber,
+			Status:           "in_wallet",
+			LegacyInstanceID: &legacyID,
+			CreatedAt:        legacy.MintedAt,
+		}
+		if err := tx.Create(&instance).Error; err != nil {
+			return 0, nil, err
+		}
+		created++
+		if legacy.Status == "listed" || legacy.Status == "locked" {
+			flattened = append(flattened, FlattenedInstance{
+				AssetInstanceID:  instance.ID,
+				LegacyInstanceID: legacyID,
+				OwnerID:          instance.OwnerID,
+				LegacyStatus:     legacy.Status,
+			})
+		}
+	}
+
+	if err := tx.Model(&models.Asset{}).Where("id = ? AND minted_count < ?", assetID, maxNo).
+		Update("minted_count", maxNo).Error; err != nil {
+		return 0, nil, err
+	}
+	return created, flattened, nil
+}
+
+// migrateReference 将引用旧作品/实例ID的字段改写为新的藏品/实例ID，并删除旧字段
+func (s *CatalogMigrationService) migrateReference(ref legacyReference) (int64, error) {
+	m := database.DB.Migrator()
+	if !m.HasTable(ref.Table) || !m.HasColumn(ref.Table, ref.LegacyColumn) {
+		return 0, nil
+	}
+
+	if !m.HasColumn(ref.Table, ref.Column) {
+		if err := database.DB.Exec(fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN `%s` bigint unsigned NULL", ref.Table, ref.Column)).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	mapTable, mapColumn := "assets", "legacy_artwork_id"
+	if ref.Instance {
+		mapTable, mapColumn = "asset_instances", "legacy_instance_id"
+	}
+	result := database.DB.Exec(fmt.Sprintf(
+		"UPDATE `%s` t JOIN `%s` m ON m.`%s` = t.`%s` SET t.`%s` = m.id WHERE t.`%s` IS NULL",
+		ref.Table, mapTable, mapColumn, ref.LegacyColumn, ref.Column, ref.Column))
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	var unmapped int64
+	if err := database.DB.Table(ref.Table).
+		Where(fmt.Sprintf("`%s` IS NOT NULL AND `%s` IS NULL", ref.LegacyColumn, ref.Column)).
+		Count(&unmapped).Error; err != nil {
+		return 0, err
+	}
+	if unmapped > 0 {
+		return 0, fmt.Errorf("有%d条记录引用的作品不存在", unmapped)
+	}
+
+	if ref.Constraint != "" && m.HasConstraint(ref.Table, ref.Constraint) {
+		if err := m.DropConstraint(ref.Table, ref.Constraint); err != nil {
+			return 0, err
+		}
+	}
+	if ref.Index != "" && m.HasIndex(ref.Table, ref.Index) {
+		if err := m.DropIndex(ref.Table, ref.Index); err != nil {
+			return 0, err
+		}
+	}
+	if err := m.DropColumn(ref.Table, ref.LegacyColumn); err != nil {
+		return 0, err
+	}
+
+	// 按模型定义补齐新字段的约束、索引和外键
+	if err := m.AlterColumn(ref.Model, ref.Field); err != nil {
+		return 0, err
+	}
+	for _, index := range ref.Indexes {
+		if !m.HasIndex(ref.Model, index) {
+			if err := m.CreateIndex(ref.Model, index); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if ref.Relation != "" && !m.HasConstraint(ref.Model, ref.Relation) {
+		if err := m.CreateConstraint(ref.Model, ref.Relation); err != nil {
+			return 0, err
+		}
+	}
+	return result.RowsAffected, nil
+}
+
+// legacyCollectionName 旧作品迁移后归属的集合：有系列的按系列归集，否则按来源区分社区与平台
+func legacyCollectionName(artwork *models.Artwork) string {
+	if series := strings.TrimSpace(artwork.Series); series != "" {
+		return series
+	}
+	if artwork.Source == "community" || artwork.CreationID != nil {
+		return communityCollectionName
+	}
+	return platformCollectionName
+}
+
+// legacyAssetStatus 将旧作品状态映射为藏品状态，售罄由 MintedCount 体现
+func legacyAssetStatus(status string) string {
+	switch status {
+	case "pending":
+		return "pending_review"
+	case "active", "sold_out":
+		return "active"
+	default:
+		return "inactive"
+	}
+}
+
+// assetFromArtwork 根据旧作品构造藏品
+func assetFromArtwork(artwork *models.Artwork, collectionID uint64) (*models.Asset, error) {
+	price := decimal.Zero
+	if artwork.Price != "" {
+		var err error
+		if price, err = decimal.NewFromString(artwork.Price); err != nil {
+			return nil, fmt.Errorf("作品价格格式错误: %s", artwork.Price)
+		}
+	}
+
+	legacyID := uint64(artwork.ID)
+	asset := &models.Asset{
+		CollectionID:    collectionID,
+		Name:            artwork.Title,
+		Description:     artwork.Description,
+		MediaURL:        artwork.MediaURL,
+		MediaType:       artwork.MediaType,
+		ThumbnailURL:    artwork.ThumbnailURL,
+		TotalSupply:     int(artwork.TotalSupply),
+		MintedCount:     int(artwork.MintedCount),
+		CreatorName:     artwork.CreatorName,
+		Price:           price,
+		Source:          artwork.Source,
+		Series:          artwork.Series,
+		ReleaseDate:     artwork.ReleaseDate,
+		LegacyArtworkID: &legacyID,
+		Status:          legacyAssetStatus(artwork.Status),
+		CreatedAt:       artwork.CreatedAt,
+	}
+	if asset.MediaType == "" {
+		asset.MediaType = "image"
+	}
+	if asset.Source == "" {
+		asset.Source = "platform"
+	}
+	if artwork.CreatorID != nil {
+		asset.CreatorID = uint64(*artwork.CreatorID)
+	}
+	if artwork.CreationID != nil {
+		creationID := uint64(*artwork.CreationID)
+		asset.CreationID = &creationID
+	}
+	return asset, nil
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"testing"
+
+	"hoho-miniapp/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLegacyAssetStatus 测试旧作品状态映射
+func TestLegacyAssetStatus(t *testing.T) {
+	assert.Equal(t, "pending_review", legacyAssetStatus("pending"))
+	assert.Equal(t, "active", legacyAssetStatus("active"))
+	assert.Equal(t, "active", legacyAssetStatus("sold_out"))
+	assert.Equal(t, "inactive", legacyAssetStatus("draft"))
+	assert.Equal(t, "inactive", legacyAssetStatus("archived"))
+}
+
+// TestLegacyCollectionName 测试旧作品归属集合
+func TestLegacyCollectionName(t *testing.T) {
+	creationID := uint(3)
+	assert.Equal(t, "山海", legacyCollectionName(&models.Artwork{Series: " 山海 ", Source: "community"}))
+	assert.Equal(t, communityCollectionName, legacyCollectionName(&models.Artwork{Source: "community"}))
+	assert.Equal(t, communityCollectionName, legacyCollectionName(&models.Artwork{Source: "platform", CreationID: &creationID}))
+	assert.Equal(t, platformCollectionName, legacyCollectionName(&models.Artwork{Source: "jingtan"}))
+}
+
+// TestAssetFromArtwork 测试旧作品转换为藏品
+func TestAssetFromArtwork(t *testing.T) {
+	creatorID, creationID := uint(9), uint(4)
+	artwork := &models.Artwork{
+		ID:          12,
+		Title:       "山海",
+		CreatorID:   &creatorID,
+		CreationID:  &creationID,
+		TotalSupply: 100,
+		MintedCount: 100,
+		Price:       "19.90000000",
+		Source:      "community",
+		Status:      "sold_out",
+	}
+
+	asset, err := assetFromArtwork(artwork, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), asset.CollectionID)
+	assert.Equal(t, "山海", asset.Name)
+	assert.Equal(t, uint64(9), asset.CreatorID)
+	assert.Equal(t, uint64(4), *asset.CreationID)
+	assert.Equal(t, uint64(12), *asset.LegacyArtworkID)
+	assert.Equal(t, "19.9", asset.Price.String())
+	assert.Equal(t, "image", asset.MediaType)
+	assert.Equal(t, "active", asset.Status)
+	assert.True(t, asset.SoldOut())
+
+	// 平台作品没有创作者
+	asset, err = assetFromArtwork(&models.Artwork{ID: 1, Price: "1"}, 1)
+	assert.NoError(t, err)
+	assert.Zero(t, asset.CreatorID)
+	assert.Nil(t, asset.CreationID)
+	assert.Equal(t, "platform", asset.Source)
+
+	_, err = assetFromArtwork(&models.Artwork{ID: 2, Price: "abc"}, 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,74 @@
+package services
+
+import (
+	"testing"
+
+	"hoho-miniapp/backend/models"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCatalogCursor 测试目录游标编解码
+func TestCatalogCursor(t *testing.T) {
+	encoded := encodeCatalogCursor(catalogCursor{Score: 1767225600, ID: 42})
+	cursor, err := decodeCatalogCursor(encoded)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1767225600), cursor.Score)
+	assert.Equal(t, uint64(42), cursor.ID)
+
+	// 负数排序值也能还原
+	cursor, err = decodeCatalogCursor(encodeCatalogCursor(catalogCursor{Score: -3, ID: 1}))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(-3), cursor.Score)
+
+	// 热门与推荐使用偏移量游标
+	cursor, err = decodeCatalogCursor(encodeCatalogCursor(catalogCursor{Offset: 40, ByRank: true}))
+	assert.NoError(t, err)
+	assert.True(t, cursor.ByRank)
+	assert.Equal(t, 40, cursor.Offset)
+
+	for _, value := range []string{"!!!", "MTIz", "YWJjXzE", "MTJfMA", "bzA", "b3g"} {
+		_, err := decodeCatalogCursor(value)
+		assert.Error(t, err, value)
+	}
+}
+
+// TestNormalizeCatalogQuery 测试目录查询条件校验
+func TestNormalizeCatalogQuery(t *testing.T) {
+	query, err := normalizeCatalogQuery(CatalogQuery{})
+	assert.NoError(t, err)
+	assert.Equal(t, CatalogSortRecommended, query.Sort)
+	assert.Equal(t, 20, query.Limit)
+
+	query, err = normalizeCatalogQuery(CatalogQuery{Source: "jingtan", Sort: CatalogSortNew, Limit: 50})
+	assert.NoError(t, err)
+	assert.Equal(t, 50, query.Limit)
+
+	_, err = normalizeCatalogQuery(CatalogQuery{Source: "opensea"})
+	assert.Error(t, err)
+	_, err = normalizeCatalogQuery(CatalogQuery{Sort: "price"})
+	assert.Error(t, err)
+
+	// 不同查询条件使用不同的缓存键
+	a, _ := normalizeCatalogQuery(CatalogQuery{Sort: CatalogSortTrending})
+	b, _ := normalizeCatalogQuery(CatalogQuery{Sort: CatalogSortTrending, CollectionID: 3})
+	assert.NotEqual(t, catalogCacheKey(a), catalogCacheKey(b))
+}
+
+// TestBuildCatalogItem 测试目录条目组装
+func TestBuildCatalogItem(t *testing.T) {
+	asset := &models.Asset{ID: 8, Name: "山海", Price: decimal.RequireFromString("9.9"), TotalSupply: 10, MintedCount: 4}
+
+	item := buildCatalogItem(asset, nil)
+	assert.Equal(t, uint64(8), item.ID)
+	assert.Equal(t, "9.9", item.Price)
+	assert.Zero(t, item.HolderCount)
+	assert.Nil(t, item.FloorPrice)
+
+	floor := decimal.RequireFromString("12.50000000")
+	item = buildCatalogItem(asset, &assetMarketStat{holders: 3, listings: 2, floor: &floor})
+	assert.Equal(t, int64(3), item.HolderCount)
+	assert.Equal(t, int64(2), item.ListingCount)
+	assert.Equal(t, "12.5", *item.FloorPrice)
+}
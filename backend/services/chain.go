@@ -0,0 +1,137 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 上链操作类型
+const (
+	ChainOpMint     = "mint"
+	ChainOpTransfer = "transfer"
+	ChainOpBurn     = "burn"
+)
+
+// 链上交易状态
+const (
+	ChainTxPending   = "pending"
+	ChainTxConfirmed = "confirmed"
+	ChainTxFailed    = "failed"
+)
+
+// ChainOperation 提交到链上的一次操作
+type ChainOperation struct {
+	AnchorID  uint64 `json:"anchor_id"` // 幂等键，重复提交同一任务必须返回同一笔交易
+	TokenID   string `json:"token_id"`
+	Operation string `json:"operation"`
+	From      string `json:"from"` // 转出方UID，铸造时为空
+	To        string `json:"to"`   // 接收方UID，销毁时为空
+	Timestamp int64  `json:"timestamp"`
+}
+
+// ChainAdapter 链适配器，接入联盟链时实现此接口并在 NewChainAdapter 中注册
+type ChainAdapter interface {
+	// Name 适配器名称
+	Name() string
+	// Submit 提交操作并返回交易哈希，需按 AnchorID 保证幂等
+	Submit(op ChainOperation) (string, error)
+	// Status 查询交易状态：pending、confirmed 或 failed
+	Status(txHash string) (string, error)
+}
+
+// NewChainAdapter 按名称创建链适配器，名称为空时使用本地模拟账本
+func NewChainAdapter(name string) (ChainAdapter, error) {
+	switch name {
+	case "", "simulated":
+		return NewSimulatedLedger(), nil
+	default:
+		return nil, fmt.Errorf("不支持的链适配器: %s", name)
+	}
+}
+
+// SimulatedLedger 本地模拟账本：交易只追加写入 simulated_ledger_entries，
+// 每笔交易哈希为 SHA256(上一交易哈希 || 操作JSON)，写入后经过 chain_confirm_seconds 秒视为确认
+type SimulatedLedger struct{}
+
+// NewSimulatedLedger 创建一个新的SimulatedLedger实例
+func NewSimulatedLedger() *SimulatedLedger {
+	return &SimulatedLedger{}
+}
+
+// Name 适配器名称
+func (l *SimulatedLedger) Name() string {
+	return "simulated"
+}
+
+// Submit 将操作追加到模拟账本
+func (l *SimulatedLedger) Submit(op ChainOperation) (string, error) {
+	payload, err := json.Marshal(op)
+	if err != nil {
+		return "", err
+	}
+
+	var entry models.SimulatedLedgerEntry
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("anchor_id = ?", op.AnchorID).First(&entry).Error; err == nil {
+			return nil
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		// 锁定最新一笔交易，保证账本按顺序追加
+		var last models.SimulatedLedgerEntry
+		prevHash := ""
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Order("id desc").First(&last).Error; err == nil {
+			prevHash = last.TxHash
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		entry = models.SimulatedLedgerEntry{
+			AnchorID: op.AnchorID,
+			TxHash:   simulatedTxHash(prevHash, payload),
+			PrevHash: prevHash,
+			Payload:  string(payload),
+		}
+		return tx.Create(&entry).Error
+	})
+	if err != nil {
+		return "", err
+	}
+	return entry.TxHash, nil
+}
+
+// Status 查询模拟账本中的交易状态，账本中不存在的交易视为失败
+func (l *SimulatedLedger) Status(txHash string) (string, error) {
+	var entry models.SimulatedLedgerEntry
+	if err := database.DB.Where("tx_hash = ?", txHash).First(&entry).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ChainTxFailed, nil
+		}
+		return "", err
+	}
+
+	confirmDelay := time.Duration(getConfigInt("chain_confirm_seconds", 3)) * time.Second
+	if time.Since(entry.CreatedAt) < confirmDelay {
+		return ChainTxPending, nil
+	}
+	return ChainTxConfirmed, nil
+}
+
+// simulatedTxHash 模拟交易哈希：0x + SHA256(上一交易哈希 || 操作JSON)
+func simulatedTxHash(prevHash string, payload []byte) string {
+	h := sha256.New()
+	h.Write([]byte(prevHash))
+	h.Write(payload)
+	return "0x" + hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"gorm.io/gorm"
+)
+
+// 上链任务批量处理数量及重试参数
+const (
+	anchorBatchSize      = 100
+	anchorBaseBackoff    = 10 * time.Second
+	anchorMaxBackoff     = time.Hour
+	anchorSubmitTimeout  = 5 * time.Minute // 提交中状态超过此时间视为进程中断，重新放回队列
+	anchorLastErrorLimit = 500
+)
+
+// ChainAnchorService 定义上链任务服务接口
+type ChainAnchorService struct {
+	Adapter ChainAdapter
+}
+
+// NewChainAnchorService 创建一个新的ChainAnchorService实例
+func NewChainAnchorService(adapter ChainAdapter) *ChainAnchorService {
+	return &ChainAnchorService{Adapter: adapter}
+}
+
+// EnsureSchema 为已有数据库补建上链任务表、模拟账本表和实例交易哈希字段，
+// 并为尚无上链任务的历史实例补登记铸造任务（由 cmd/migrate 调用）
+func (s *ChainAnchorService) EnsureSchema() error {
+	m := database.DB.Migrator()
+	for _, model := range []interface{}{&models.ChainAnchor{}, &models.SimulatedLedgerEntry{}} {
+		if !m.HasTable(model) {
+			if err := m.CreateTable(model); err != nil {
+				return err
+			}
+		}
+	}
+	for _, field := range []string{"MintTxHash", "LastTxHash", "ChainStatus"} {
+		if !m.HasColumn(&models.AssetInstance{}, field) {
+			if err := m.AddColumn(&models.AssetInstance{}, field); err != nil {
+				return err
+			}
+		}
+	}
+
+	return database.DB.Exec(`
+		INSERT INTO chain_anchors (asset_instance_id, token_id, operation, from_user_id, to_user_id, status, attempts, next_attempt_at, created_at, updated_at)
+		SELECT ai.id, ai.token_id, ?, 0, ai.owner_id, 'pending', 0, NOW(), NOW(), NOW()
+		FROM asset_instances ai
+		WHERE ai.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM chain_anchors ca WHERE ca.asset_instance_id = ai.id)`, ChainOpMint).Error
+}
+
+// enqueueAnchorTx 在调用方事务中登记上链任务，并将实例标记为待上链
+func enqueueAnchorTx(tx *gorm.DB, instance *models.AssetInstance, operation string, fromUserID, toUserID uint64) error {
+	anchor := models.ChainAnchor{
+		AssetInstanceID: instance.ID,
+		TokenID:         instance.TokenID,
+		Operation:       operation,
+		FromUserID:      fromUserID,
+		ToUserID:        toUserID,
+		Status:          "pending",
+		NextAttemptAt:   time.Now(),
+	}
+	if err := tx.Create(&anchor).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.AssetInstance{}).Where("id = ?", instance.ID).Update("chain_status", "pending").Error
+}
+
+// SubmitPending 提交到期的待上链任务（由后台任务定时调用）
+// 同一实例的任务按登记顺序依次上链，前一个任务确认前不会提交后续任务
+func (s *ChainAnchorService) SubmitPending() error {
+	if err := database.DB.Model(&models.ChainAnchor{}).
+		Where("status = ? AND updated_at < ?", "submitting", time.Now().Add(-anchorSubmitTimeout)).
+		Update("status", "pending").Error; err != nil {
+		return err
+	}
+
+	var anchors []models.ChainAnchor
+	if err := database.DB.Where("status = ? AND next_attempt_at <= ?", "pending", time.Now()).
+		Where(`NOT EXISTS (SELECT 1 FROM chain_anchors p WHERE p.asset_instance_id = chain_anchors.asset_instance_id
+			AND p.id < chain_anchors.id AND p.status <> 'confirmed')`).
+		Order("id asc").Limit(anchorBatchSize).Find(&anchors).Error; err != nil {
+		return err
+	}
+
+	for i := range anchors {
+		if err := s.submitAnchor(&anchors[i]); err != nil {
+			return fmt.Errorf("上链任务%d提交失败: %w", anchors[i].ID, err)
+		}
+	}
+	return nil
+}
+
+// submitAnchor 抢占并提交单个任务，适配器报错时按退避重试
+func (s *ChainAnchorService) submitAnchor(anchor *models.ChainAnchor) error {
+	result := database.DB.Model(&models.ChainAnchor{}).
+		Where("id = ? AND status = ?", anchor.ID, "pending").
+		Update("status", "submitting")
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil // 已被其他实例处理
+	}
+
+	op, err := buildChainOperation(anchor)
+	if err != nil {
+		return s.failAttempt(anchor, err.Error())
+	}
+	txHash, err := s.Adapter.Submit(op)
+	if err != nil {
+		return s.failAttempt(anchor, err.Error())
+	}
+
+	now := time.Now()
+	return database.DB.Model(&models.ChainAnchor{}).Where("id = ?", anchor.ID).Updates(map[string]interface{}{
+		"status":       "submitted",
+		"tx_hash":      txHash,
+		"submitted_at": &now,
+		"last_error":   "",
+	}).Error
+}
+
+// ConfirmSubmitted 查询已提交交易的确认状态（由后台任务定时调用）
+// 确认后回写实例交易哈希；链上失败或超时未确认的任务重新排队
+func (s *ChainAnchorService) ConfirmSubmitted() error {
+	var anchors []models.ChainAnchor
+	if err := database.DB.Where("status = ?", "submitted").
+		Order("id asc").Limit(anchorBatchSize).Find(&anchors).Error; err != nil {
+		return err
+	}
+
+	timeout := time.Duration(getConfigInt("chain_confirm_timeout_minutes", 30)) * time.Minute
+	for i := range anchors {
+		anchor := &anchors[i]
+		status, err := s.Adapter.Status(anchor.TxHash)
+		if err != nil {
+			return fmt.Errorf("查询上链任务%d状态失败: %w", anchor.ID, err)
+		}
+
+		switch {
+		case status == ChainTxConfirmed:
+			err = confirmAnchor(anchor)
+		case status == ChainTxFailed:
+			err = s.failAttempt(anchor, "链上交易失败: "+anchor.TxHash)
+		case anchor.SubmittedAt != nil && time.Since(*anchor.SubmittedAt) > timeout:
+			err = s.failAttempt(anchor, "交易确认超时: "+anchor.TxHash)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// confirmAnchor 标记任务已确认并回写实例的交易哈希
+func confirmAnchor(anchor *models.ChainAnchor) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.ChainAnchor{}).
+			Where("id = ? AND status = ?", anchor.ID, "submitted").
+			Updates(map[string]interface{}{"status": "confirmed", "confirmed_at": &now})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		updates := map[string]interface{}{"last_tx_hash": anchor.TxHash}
+		if anchor.Operation == ChainOpMint {
+			updates["mint_tx_hash"] = anchor.TxHash
+		}
+		var remaining int64
+		if err := tx.Model(&models.ChainAnchor{}).
+			Where("asset_instance_id = ? AND status <> ?", anchor.AssetInstanceID, "confirmed").
+			Count(&remaining).Error; err != nil {
+			return err
+		}
+		if remaining == 0 {
+			updates["chain_status"] = "anchored"
+		}
+		return tx.Unscoped().Model(&models.AssetInstance{}).Where("id = ?", anchor.AssetInstanceID).Updates(updates).Error
+	})
+}
+
+// failAttempt 记录一次失败：未达上限时按退避重新排队，达到上限后标记失败等待管理员重试
+func (s *ChainAnchorService) failAttempt(anchor *models.ChainAnchor, reason string) error {
+	attempts := anchor.Attempts + 1
+	if runes := []rune(reason); len(runes) > anchorLastErrorLimit {
+		reason = string(runes[:anchorLastErrorLimit])
+	}
+	updates := map[string]interface{}{
+		"attempts":   attempts,
+		"last_error": reason,
+		"tx_hash":    "",
+	}
+
+	if attempts >= getConfigInt("chain_anchor_max_attempts", 10) {
+		updates["status"] = "failed"
+		return database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&models.ChainAnchor{}).Where("id = ?", anchor.ID).Updates(updates).Error; err != nil {
+				return err
+			}
+			return tx.Unscoped().Model(&models.AssetInstance{}).Where("id = ?", anchor.AssetInstanceID).
+				Update("chain_status", "failed").Error
+		})
+	}
+
+	updates["status"] = "pending"
+	updates["next_attempt_at"] = time.Now().Add(anchorBackoff(attempts))
+	return database.DB.Model(&models.ChainAnchor{}).Where("id = ?", anchor.ID).Updates(updates).Error
+}
+
+// anchorBackoff 第n次失败后的重试间隔：10秒起按2倍递增，最长1小时
+func anchorBackoff(attempts int) time.Duration {
+	backoff := anchorBaseBackoff
+	for i := 1; i < attempts && backoff < anchorMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > anchorMaxBackoff {
+		backoff = anchorMaxBackoff
+	}
+	return backoff
+}
+
+// buildChainOperation 组装上链操作，用户以UID表示
+func buildChainOperation(anchor *models.ChainAnchor) (ChainOperation, error) {
+	op := ChainOperation{
+		AnchorID:  anchor.ID,
+		TokenID:   anchor.TokenID,
+		Operation: anchor.Operation,
+		Timestamp: anchor.CreatedAt.Unix(),
+	}
+	uids := make(map[uint64]string)
+	for _, userID := range []uint64{anchor.FromUserID, anchor.ToUserID} {
+		if userID == 0 {
+			continue
+		}
+		var user models.User
+		if err := database.DB.Unscoped().Select("id", "uid").First(&user, userID).Error; err != nil {
+			return op, fmt.Errorf("用户%d不存在", userID)
+		}
+		uids[userID] = user.UID
+	}
+	op.From = uids[anchor.FromUserID]
+	op.To = uids[anchor.ToUserID]
+	return op, nil
+}
+
+// ListAnchors 获取上链任务列表（管理员）
+func (s *ChainAnchorService) ListAnchors(status string, page, pageSize int) ([]models.ChainAnchor, int64, error) {
+	var anchors []models.ChainAnchor
+	var total int64
+
+	query := database.DB.Model(&models.ChainAnchor{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("id desc").Offset(offset).Limit(pageSize).Find(&anchors).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return anchors, total, nil
+}
+
+// RetryAnchor 将失败的上链任务重新排队（管理员），重置失败次数
+func (s *ChainAnchorService) RetryAnchor(anchorID uint64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var anchor models.ChainAnchor
+		if err := tx.First(&anchor, anchorID).Error; err != nil {
+			return errors.New("上链任务不存在")
+		}
+
+		result := tx.Model(&models.ChainAnchor{}).
+			Where("id = ? AND status = ?", anchorID, "failed").
+			Updates(map[string]interface{}{
+				"status":          "pending",
+				"attempts":        0,
+				"next_attempt_at": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("只有失败的上链任务可以重试")
+		}
+		return tx.Unscoped().Model(&models.AssetInstance{}).Where("id = ?", anchor.AssetInstanceID).
+			Update("chain_status", "pending").Error
+	})
+}
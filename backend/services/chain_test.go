@@ -0,0 +1,36 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestAnchorBackoff 测试上链重试退避间隔
+func TestAnchorBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, anchorBackoff(1))
+	assert.Equal(t, 20*time.Second, anchorBackoff(2))
+	assert.Equal(t, 80*time.Second, anchorBackoff(4))
+	assert.Equal(t, time.Hour, anchorBackoff(20))
+}
+
+// TestSimulatedTxHash 测试模拟交易哈希链接上一笔交易
+func TestSimulatedTxHash(t *testing.T) {
+	payload := []byte(`{"anchor_id":1}`)
+	first := simulatedTxHash("", payload)
+	assert.Len(t, first, 66)
+	assert.Equal(t, "0x", first[:2])
+	assert.Equal(t, first, simulatedTxHash("", payload))
+	assert.NotEqual(t, first, simulatedTxHash(first, payload))
+}
+
+// TestNewChainAdapter 测试按名称选择链适配器
+func TestNewChainAdapter(t *testing.T) {
+	adapter, err := NewChainAdapter("")
+	assert.NoError(t, err)
+	assert.Equal(t, "simulated", adapter.Name())
+
+	_, err = NewChainAdapter("unknown")
+	assert.Error(t, err)
+}
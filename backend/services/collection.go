@@ -0,0 +1,195 @@
+package services
+
+import (
+	"errors"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// communityCollectionName 社区创作发布的藏品归属的集合名称
+const communityCollectionName = "社区创作"
+
+// CollectionService 定义藏品集合服务接口
+type CollectionService struct{}
+
+// NewCollectionService 创建一个新的CollectionService实例
+func NewCollectionService() *CollectionService {
+	return &CollectionService{}
+}
+
+// CollectionDetail 集合详情，附带成员藏品和市场统计
+type CollectionDetail struct {
+	Collection  *models.Collection `json:"collection"`
+	Assets      []CatalogItem      `json:"assets"`
+	AssetCount  int                `json:"asset_count"`
+	HolderCount int64              `json:"holder_count"` // 持有集合内任一藏品的用户数
+	TotalSupply int64              `json:"total_supply"`
+	FloorPrice  *string            `json:"floor_price"` // 集合内在售挂单的最低价，无挂售时为null
+	Volume      string             `json:"volume"`      // 累计成交额
+}
+
+// CreateCollection 创建藏品集合（管理员）
+func (s *CollectionService) CreateCollection(collection *models.Collection) error {
+	if err := fillCollectionCreator(collection); err != nil {
+		return err
+	}
+	if collection.Status == "" {
+		collection.Status = "active"
+	}
+	return database.DB.Create(collection).Error
+}
+
+// UpdateCollection 更新藏品集合（管理员）
+func (s *CollectionService) UpdateCollection(collectionID uint64, updates map[string]interface{}) (*models.Collection, error) {
+	var collection models.Collection
+	if err := database.DB.First(&collection, collectionID).Error; err != nil {
+		return nil, errors.New("集合不存在")
+	}
+
+	if creatorID, ok := updates["creator_id"].(uint64); ok && creatorID != 0 {
+		var creator models.User
+		if err := database.DB.Select("id", "nickname").First(&creator, creatorID).Error; err != nil {
+			return nil, errors.New("创作者不存在")
+		}
+		if _, ok := updates["creator_name"]; !ok {
+			updates["creator_name"] = creator.Nickname
+		}
+	}
+
+	if err := database.DB.Model(&collection).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// DeleteCollection 删除藏品集合（管理员），集合下仍有藏品时不允许删除
+func (s *CollectionService) DeleteCollection(collectionID uint64) error {
+	var collection models.Collection
+	if err := database.DB.First(&collection, collectionID).Error; err != nil {
+		return errors.New("集合不存在")
+	}
+
+	var count int64
+	if err := database.DB.Model(&models.Asset{}).Where("collection_id = ?", collectionID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return errors.New("集合下仍有藏品，请先迁移藏品或下架集合")
+	}
+	return database.DB.Delete(&collection).Error
+}
+
+// ListCollections 获取集合列表，按展示顺序排列，status为空时返回全部
+func (s *CollectionService) ListCollections(status string, page, pageSize int) ([]models.Collection, int64, error) {
+	var collections []models.Collection
+	var total int64
+
+	query := database.DB.Model(&models.Collection{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("sort_order asc, id desc").Offset(offset).Limit(pageSize).Find(&collections).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return collections, total, nil
+}
+
+// GetCollectionDetail 获取已上架集合的详情，包含上架藏品及持有人数、总发行量、地板价和成交额
+func (s *CollectionService) GetCollectionDetail(collectionID uint64) (*CollectionDetail, error) {
+	var collection models.Collection
+	if err := database.DB.Where("status = ?", "active").First(&collection, collectionID).Error; err != nil {
+		return nil, errors.New("集合不存在")
+	}
+
+	var assets []models.Asset
+	if err := database.DB.Where("collection_id = ? AND status = ?", collectionID, "active").
+		Order("release_date desc, id desc").Find(&assets).Error; err != nil {
+		return nil, err
+	}
+
+	detail := &CollectionDetail{
+		Collection: &collection,
+		Assets:     []CatalogItem{},
+		AssetCount: len(assets),
+		Volume:     "0",
+	}
+	if len(assets) == 0 {
+		return detail, nil
+	}
+
+	ids := make([]uint64, 0, len(assets))
+	for _, asset := range assets {
+		ids = append(ids, asset.ID)
+	}
+	stats, err := loadAssetMarketStats(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var floor *decimal.Decimal
+	for i := range assets {
+		stat := stats[assets[i].ID]
+		detail.Assets = append(detail.Assets, buildCatalogItem(&assets[i], stat))
+		detail.TotalSupply += int64(assets[i].TotalSupply)
+		if stat != nil && stat.floor != nil && (floor == nil || stat.floor.LessThan(*floor)) {
+			floor = stat.floor
+		}
+	}
+	if floor != nil {
+		value := floor.String()
+		detail.FloorPrice = &value
+	}
+
+	if err := database.DB.Model(&models.AssetInstance{}).
+		Where("asset_id IN ? AND status <> ?", ids, "burned").
+		Distinct("owner_id").Count(&detail.HolderCount).Error; err != nil {
+		return nil, err
+	}
+
+	var volume decimal.Decimal
+	if err := database.DB.Model(&models.Trade{}).
+		Select("COALESCE(SUM(trades.price), 0)").
+		Joins("JOIN asset_instances ON asset_instances.id = trades.asset_instance_id").
+		Where("asset_instances.asset_id IN ? AND trades.status = ?", ids, "completed").
+		Scan(&volume).Error; err != nil {
+		return nil, err
+	}
+	detail.Volume = volume.String()
+
+	return detail, nil
+}
+
+// fillCollectionCreator 校验创作者并在未指定名称时使用其昵称
+func fillCollectionCreator(collection *models.Collection) error {
+	if collection.CreatorID == 0 {
+		return nil
+	}
+	var creator models.User
+	if err := database.DB.Select("id", "nickname").First(&creator, collection.CreatorID).Error; err != nil {
+		return errors.New("创作者不存在")
+	}
+	if collection.CreatorName == "" {
+		collection.CreatorName = creator.Nickname
+	}
+	return nil
+}
+
+// findOrCreateCollectionTx 在调用方事务中按名称查找藏品集合，不存在时创建
+func findOrCreateCollectionTx(tx *gorm.DB, name string) (uint64, error) {
+	collection := models.Collection{Name: name, Status: "active"}
+	if err := tx.Where("name = ?", name).FirstOrCreate(&collection).Error; err != nil {
+		return 0, err
+	}
+	return collection.ID, nil
+}
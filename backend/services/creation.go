@@ -7,6 +7,9 @@ import (
 
 	"hoho-miniapp/backend/database"
 	"hoho-miniapp/backend/models"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type CreationService struct{}
@@ -15,17 +18,18 @@ func NewCreationService() *CreationService {
 	return &CreationService{}
 }
 
-// SubmitCreation 提交创作
-func (s *CreationService) SubmitCreation(userID uint, title, description, mediaURL, thumbnailURL string, totalSupply uint, price string) (*models.Creation, error) {
+// SubmitCreation 提交创作，parentAssetID不为空时作为衍生作品提交，并向上游创作者发起授权申请
+func (s *CreationService) SubmitCreation(userID uint, title, description, mediaURL, thumbnailURL string, totalSupply uint, price string, parentAssetID *uint64) (*models.Creation, error) {
 	creation := &models.Creation{
-		UserID:       userID,
-		Title:        title,
-		Description:  description,
-		MediaURL:     mediaURL,
-		ThumbnailURL: thumbnailURL,
-		TotalSupply:  totalSupply,
-		Price:        price,
-		Status:       "pending",
+		UserID:        userID,
+		Title:         title,
+		Description:   description,
+		MediaURL:      mediaURL,
+		ThumbnailURL:  thumbnailURL,
+		TotalSupply:   totalSupply,
+		Price:         price,
+		ParentAssetID: parentAssetID,
+		Status:        "pending",
 	}
 	
 	// 获取默认分成比例
@@ -34,7 +38,16 @@ func (s *CreationService) SubmitCreation(userID uint, title, description, mediaU
 		creation.CommissionRate = config.Value
 	}
 	
-	if err := database.DB.Create(creation).Error; err != nil {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(creation).Error; err != nil {
+			return err
+		}
+		if parentAssetID == nil {
+			return nil
+		}
+		return NewDerivativeService().createLicenseTx(tx, creation)
+	})
+	if err != nil {
 		return nil, err
 	}
 	
@@ -50,6 +63,19 @@ func (s *CreationService) GetCreationByID(id uint) (*models.Creation, error) {
 	return &creation, nil
 }
 
+// GetDerivativeLicense 获取衍生作品的授权申请，非衍生作品返回nil
+func (s *CreationService) GetDerivativeLicense(creationID uint) (*models.DerivativeLicense, error) {
+	var license models.DerivativeLicense
+	err := database.DB.Preload("ParentAsset").Where("creation_id = ?", creationID).First(&license).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &license, nil
+}
+
 // GetUserCreations 获取用户的创作列表
 func (s *CreationService) GetUserCreations(userID uint, status string, page, pageSize int) ([]models.Creation, int64, error) {
 	var creations []models.Creation
@@ -179,7 +205,7 @@ func (s *CreationService) RejectCreation(creationID, reviewerID uint, reason str
 	return nil
 }
 
-// PublishCreation 发布创作
+// PublishCreation 发布创作，生成藏品并开启首发（releaseDate为空时立即开售）
 func (s *CreationService) PublishCreation(creationID uint, releaseDate *time.Time) error {
 	// 检查创作状态
 	var creation models.Creation
@@ -191,32 +217,92 @@ func (s *CreationService) PublishCreation(creationID uint, releaseDate *time.Tim
 		return errors.New("只有审核通过的创作才能发布")
 	}
 	
-	now := time.Now()
+	// 衍生作品需先获得上游授权
+	var license models.DerivativeLicense
+	if creation.ParentAssetID != nil {
+		if err := database.DB.Where("creation_id = ?", creation.ID).First(&license).Error; err != nil || license.Status != "approved" {
+			return errors.New("衍生作品尚未获得上游授权")
+		}
+	}
 	
-	// 创建作品
-	artwork := &models.Artwork{
-		Title:        creation.Title,
-		Description:  creation.Description,
-		MediaType:    "image",
-		MediaURL:     creation.MediaURL,
-		ThumbnailURL: creation.ThumbnailURL,
-		TotalSupply:  creation.TotalSupply,
-		MintedCount:  0,
-		Price:        creation.Price,
-		Source:       "community",
-		ReleaseDate:  releaseDate,
-		Status:       "active",
-	}
-	
-	if err := database.DB.Create(artwork).Error; err != nil {
-		return err
+	price, err := decimal.NewFromString(creation.Price)
+	if err != nil || price.LessThanOrEqual(decimal.Zero) {
+		return errors.New("创作售价不正确")
+	}
+	commissionRate, err := decimal.NewFromString(creation.CommissionRate)
+	if err != nil {
+		return errors.New("平台分成比例不正确")
 	}
 	
-	// 更新创作状态
-	if err := database.DB.Model(&models.Creation{}).Where("id = ?", creationID).Updates(map[string]interface{}{
-		"status":       "published",
-		"published_at": now,
-	}).Error; err != nil {
+	now := time.Now()
+	startAt := now
+	if releaseDate != nil {
+		startAt = *releaseDate
+	}
+	
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// 条件更新防止重复发布
+		result := tx.Model(&models.Creation{}).Where("id = ? AND status = ?", creationID, "approved").Updates(map[string]interface{}{
+			"status":       "published",
+			"published_at": now,
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("该创作已发布")
+		}
+		
+		// 创建藏品，社区创作统一归入“社区创作”集合
+		collectionID, err := findOrCreateCollectionTx(tx, communityCollectionName)
+		if err != nil {
+			return err
+		}
+		var creator models.User
+		if err := tx.Select("id", "nickname").First(&creator, creation.UserID).Error; err != nil {
+			return errors.New("创作者不存在")
+		}
+		sourceID := uint64(creation.ID)
+		asset := &models.Asset{
+			CollectionID: collectionID,
+			Name:         creation.Title,
+			Description:  creation.Description,
+			MediaURL:     creation.MediaURL,
+			MediaType:    "image",
+			ThumbnailURL: creation.ThumbnailURL,
+			TotalSupply:  int(creation.TotalSupply),
+			CreatorID:    uint64(creation.UserID),
+			CreatorName:  creator.Nickname,
+			Price:        price,
+			Source:       "community",
+			ReleaseDate:  &startAt, // 未指定时以发布时间为准，便于出现在发售日历中
+			CreationID:   &sourceID,
+			Status:       "active",
+		}
+		if creation.ParentAssetID != nil {
+			asset.ParentAssetID = &license.ParentAssetID
+			asset.ParentRoyaltyRate = license.RoyaltyRate
+		}
+		if err := tx.Create(asset).Error; err != nil {
+			return err
+		}
+		
+		// 创建首发配置
+		sale := &models.PrimarySale{
+			AssetID:         asset.ID,
+			CreationID:      uint64(creation.ID),
+			CreatorID:       uint64(creation.UserID),
+			Price:           price,
+			CommissionRate:  commissionRate,
+			TotalStock:      int(creation.TotalSupply),
+			StartAt:         startAt,
+			PerUserLimit:    getConfigInt("primary_sale_per_user_limit", defaultPerUserLimit),
+			RequireRealName: true,
+			Status:          "on_sale",
+		}
+		return tx.Create(sale).Error
+	})
+	if err != nil {
 		return err
 	}
 	
@@ -225,7 +311,7 @@ func (s *CreationService) PublishCreation(creationID uint, releaseDate *time.Tim
 		UserID:    creation.UserID,
 		Type:      "system",
 		Title:     "作品已发布",
-		Content:   fmt.Sprintf("您的创作《%s》已成功发布", creation.Title),
+		Content:   fmt.Sprintf("您的创作《%s》已成功发布，将于%s开始首发", creation.Title, startAt.Format("2006-01-02 15:04")),
 		RelatedID: &creationID,
 	}
 	database.DB.Create(notification)
@@ -0,0 +1,384 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// 仪表盘统计区间
+const (
+	DashboardRangeToday = "today"
+	DashboardRange7d    = "7d"
+	DashboardRange30d   = "30d"
+)
+
+// 仪表盘列表数量及日活记录保留天数
+const (
+	dashboardTopAssetLimit  = 5
+	dashboardStuckListLimit = 10
+	dailyActiveRetainDays   = 40
+	dailyActiveSeenLimit    = 200000
+)
+
+// DashboardComparison 当前区间与上一区间的对比，上一区间为0时不计算变化率
+type DashboardComparison struct {
+	Current       decimal.Decimal  `json:"current"`
+	Previous      decimal.Decimal  `json:"previous"`
+	ChangePercent *decimal.Decimal `json:"change_percent"`
+}
+
+// DashboardTopAsset 区间内成交额最高的藏品
+type DashboardTopAsset struct {
+	AssetID    uint64          `json:"asset_id"`
+	Name       string          `json:"name"`
+	Thumbnail  string          `json:"thumbnail_url"`
+	Volume     decimal.Decimal `json:"volume"`
+	TradeCount int64           `json:"trade_count"`
+}
+
+// DashboardStuckTrade 长时间未完成支付的交易
+type DashboardStuckTrade struct {
+	ID        uint64          `json:"id"`
+	TradeNo   string          `json:"trade_no"`
+	BuyerID   uint64          `json:"buyer_id"`
+	SellerID  uint64          `json:"seller_id"`
+	Price     decimal.Decimal `json:"price"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// DashboardStats 管理后台仪表盘统计
+type DashboardStats struct {
+	Range               string                `json:"range"`
+	PeriodStart         time.Time             `json:"period_start"`
+	PeriodEnd           time.Time             `json:"period_end"`
+	TotalUsers          int64                 `json:"total_users"`
+	TotalAssets         int64                 `json:"total_assets"`
+	NewUsers            DashboardComparison   `json:"new_users"`
+	ActiveUsers         DashboardComparison   `json:"active_users"` // 区间覆盖自然日内的去重活跃用户（今日即DAU，对比昨日全天）
+	TradeVolume         DashboardComparison   `json:"trade_volume"`
+	TradeCount          DashboardComparison   `json:"trade_count"`
+	FeesCollected       DashboardComparison   `json:"fees_collected"`
+	PendingAssetReviews int64                 `json:"pending_asset_reviews"`
+	PendingCreations    int64                 `json:"pending_creations"`
+	PendingReviews      int64                 `json:"pending_reviews"`
+	StuckTradeMinutes   int                   `json:"stuck_trade_minutes"`
+	StuckTradeCount     int64                 `json:"stuck_trade_count"`
+	StuckTrades         []DashboardStuckTrade `json:"stuck_trades"`
+	TopAssets           []DashboardTopAsset   `json:"top_assets"`
+	GeneratedAt         time.Time             `json:"generated_at"`
+}
+
+// dashboardPeriod 统计时间段 [Start, End)
+type dashboardPeriod struct {
+	Start time.Time
+	End   time.Time
+}
+
+// DashboardService 定义管理后台仪表盘服务接口
+type DashboardService struct{}
+
+// NewDashboardService 创建一个新的DashboardService实例
+func NewDashboardService() *DashboardService {
+	return &DashboardService{}
+}
+
+// dailyActiveSeen 本进程当天已计入日活的用户，同一用户每天只写一次Redis
+// 超过 dailyActiveSeenLimit 时清空重记，重复写入 HyperLogLog 不影响计数
+var dailyActiveSeen = struct {
+	sync.Mutex
+	day   string
+	users map[uint64]struct{}
+}{}
+
+// markDailyActive 标记用户当天已计入日活，已标记过时返回false
+func markDailyActive(day string, userID uint64) bool {
+	dailyActiveSeen.Lock()
+	defer dailyActiveSeen.Unlock()
+	if dailyActiveSeen.day != day || len(dailyActiveSeen.users) >= dailyActiveSeenLimit {
+		dailyActiveSeen.day = day
+		dailyActiveSeen.users = make(map[uint64]struct{})
+	}
+	if _, ok := dailyActiveSeen.users[userID]; ok {
+		return false
+	}
+	dailyActiveSeen.users[userID] = struct{}{}
+	return true
+}
+
+// unmarkDailyActive 写入Redis失败时撤销标记，下次请求重试
+func unmarkDailyActive(day string, userID uint64) {
+	dailyActiveSeen.Lock()
+	defer dailyActiveSeen.Unlock()
+	if dailyActiveSeen.day == day {
+		delete(dailyActiveSeen.users, userID)
+	}
+}
+
+// TrackDailyActive 将用户计入当天的活跃用户（HyperLogLog去重），Redis不可用时忽略
+// 每个进程内同一用户每天只写一次Redis，避免每个请求都多一次网络往返
+func TrackDailyActive(userID uint64) {
+	if database.RDB == nil {
+		return
+	}
+	now := time.Now()
+	day := now.Format("2006-01-02")
+	if !markDailyActive(day, userID) {
+		return
+	}
+	key := dailyActiveKey(now)
+	pipe := database.RDB.Pipeline()
+	pipe.PFAdd(database.Ctx, key, userID)
+	pipe.Expire(database.Ctx, key, dailyActiveRetainDays*24*time.Hour)
+	if _, err := pipe.Exec(database.Ctx); err != nil {
+		unmarkDailyActive(day, userID)
+		log.Printf("记录日活用户失败: %v", err)
+	}
+}
+
+// dailyActiveKey 日活统计的Redis键
+func dailyActiveKey(day time.Time) string {
+	return "stats:dau:" + day.Format("2006-01-02")
+}
+
+// GetStats 获取仪表盘统计，按区间缓存 dashboard_cache_seconds 秒
+func (s *DashboardService) GetStats(rangeKey string) (*DashboardStats, error) {
+	if rangeKey == "" {
+		rangeKey = DashboardRangeToday
+	}
+	current, previous, err := dashboardPeriods(rangeKey, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	key := "dashboard:stats:" + rangeKey
+	if stats, ok := s.getCachedStats(key); ok {
+		return stats, nil
+	}
+
+	stats, err := s.computeStats(rangeKey, current, previous)
+	if err != nil {
+		return nil, err
+	}
+	if ttl := getConfigInt("dashboard_cache_seconds", 30); ttl > 0 {
+		s.setCachedStats(key, stats, time.Duration(ttl)*time.Second)
+	}
+	return stats, nil
+}
+
+// computeStats 从数据库和日活记录计算统计
+func (s *DashboardService) computeStats(rangeKey string, current, previous dashboardPeriod) (*DashboardStats, error) {
+	stats := &DashboardStats{
+		Range:       rangeKey,
+		PeriodStart: current.Start,
+		PeriodEnd:   current.End,
+		GeneratedAt: time.Now(),
+	}
+
+	if err := database.DB.Model(&models.User{}).Count(&stats.TotalUsers).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Model(&models.Asset{}).Count(&stats.TotalAssets).Error; err != nil {
+		return nil, err
+	}
+
+	var curNew, prevNew int64
+	if err := countUsersBetween(current, &curNew); err != nil {
+		return nil, err
+	}
+	if err := countUsersBetween(previous, &prevNew); err != nil {
+		return nil, err
+	}
+	stats.NewUsers = compareDashboard(decimal.NewFromInt(curNew), decimal.NewFromInt(prevNew))
+	stats.ActiveUsers = compareDashboard(
+		decimal.NewFromInt(countDailyActive(current)), decimal.NewFromInt(countDailyActive(previous)))
+
+	curTrades, err := sumTradesBetween(current)
+	if err != nil {
+		return nil, err
+	}
+	prevTrades, err := sumTradesBetween(previous)
+	if err != nil {
+		return nil, err
+	}
+	stats.TradeVolume = compareDashboard(curTrades.Volume, prevTrades.Volume)
+	stats.TradeCount = compareDashboard(decimal.NewFromInt(curTrades.Count), decimal.NewFromInt(prevTrades.Count))
+
+	curFees, err := sumFeesBetween(current)
+	if err != nil {
+		return nil, err
+	}
+	prevFees, err := sumFeesBetween(previous)
+	if err != nil {
+		return nil, err
+	}
+	stats.FeesCollected = compareDashboard(curFees, prevFees)
+
+	if err := database.DB.Model(&models.Asset{}).Where("status = ?", "pending_review").
+		Count(&stats.PendingAssetReviews).Error; err != nil {
+		return nil, err
+	}
+	if err := database.DB.Model(&models.Creation{}).Where("status = ?", "pending").
+		Count(&stats.PendingCreations).Error; err != nil {
+		return nil, err
+	}
+	stats.PendingReviews = stats.PendingAssetReviews + stats.PendingCreations
+
+	stats.StuckTradeMinutes = getConfigInt("trade_pending_alert_minutes", 30)
+	stuckBefore := time.Now().Add(-time.Duration(stats.StuckTradeMinutes) * time.Minute)
+	stuckQuery := func() *gorm.DB {
+		return database.DB.Model(&models.Trade{}).Where("status = ? AND created_at < ?", "pending", stuckBefore)
+	}
+	if err := stuckQuery().Count(&stats.StuckTradeCount).Error; err != nil {
+		return nil, err
+	}
+	stats.StuckTrades = []DashboardStuckTrade{}
+	if err := stuckQuery().Select("id, trade_no, buyer_id, seller_id, price, created_at").
+		Order("created_at asc").Limit(dashboardStuckListLimit).Scan(&stats.StuckTrades).Error; err != nil {
+		return nil, err
+	}
+
+	stats.TopAssets = []DashboardTopAsset{}
+	if err := database.DB.Table("trades t").
+		Select("a.id AS asset_id, a.name, a.thumbnail_url AS thumbnail, SUM(t.price) AS volume, COUNT(*) AS trade_count").
+		Joins("JOIN asset_instances ai ON ai.id = t.asset_instance_id").
+		Joins("JOIN assets a ON a.id = ai.asset_id").
+		Where("t.deleted_at IS NULL AND t.status = ? AND t.completed_at >= ? AND t.completed_at < ?", "completed", current.Start, current.End).
+		Group("a.id, a.name, a.thumbnail_url").
+		Order("volume desc").Limit(dashboardTopAssetLimit).
+		Scan(&stats.TopAssets).Error; err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// dashboardPeriods 计算统计区间及用于对比的上一区间
+// today 为今日零点至今，对比昨日同一时段；7d/30d 为截至当前的滚动区间，对比之前等长区间
+func dashboardPeriods(rangeKey string, now time.Time) (dashboardPeriod, dashboardPeriod, error) {
+	switch rangeKey {
+	case DashboardRangeToday:
+		start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		return dashboardPeriod{Start: start, End: now},
+			dashboardPeriod{Start: start.AddDate(0, 0, -1), End: now.AddDate(0, 0, -1)}, nil
+	case DashboardRange7d, DashboardRange30d:
+		days := 7
+		if rangeKey == DashboardRange30d {
+			days = 30
+		}
+		start := now.AddDate(0, 0, -days)
+		return dashboardPeriod{Start: start, End: now},
+			dashboardPeriod{Start: start.AddDate(0, 0, -days), End: start}, nil
+	default:
+		return dashboardPeriod{}, dashboardPeriod{}, errors.New("不支持的统计区间")
+	}
+}
+
+// compareDashboard 计算变化率（百分比，保留2位小数）
+func compareDashboard(current, previous decimal.Decimal) DashboardComparison {
+	comparison := DashboardComparison{Current: current, Previous: previous}
+	if !previous.IsZero() {
+		change := current.Sub(previous).Div(previous).Mul(decimal.NewFromInt(100)).Round(2)
+		comparison.ChangePercent = &change
+	}
+	return comparison
+}
+
+// countUsersBetween 统计区间内的注册用户数
+func countUsersBetween(period dashboardPeriod, count *int64) error {
+	return database.DB.Model(&models.User{}).
+		Where("created_at >= ? AND created_at < ?", period.Start, period.End).
+		Count(count).Error
+}
+
+// countDailyActive 统计区间所覆盖自然日内的去重活跃用户，Redis不可用时返回0
+func countDailyActive(period dashboardPeriod) int64 {
+	if database.RDB == nil {
+		return 0
+	}
+	count, err := database.RDB.PFCount(database.Ctx, dailyActiveKeys(period)...).Result()
+	if err != nil {
+		log.Printf("统计活跃用户失败: %v", err)
+		return 0
+	}
+	return count
+}
+
+// dailyActiveKeys 区间覆盖的每个自然日对应的日活键
+func dailyActiveKeys(period dashboardPeriod) []string {
+	var keys []string
+	start := period.Start
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+	for ; day.Before(period.End); day = day.AddDate(0, 0, 1) {
+		keys = append(keys, dailyActiveKey(day))
+	}
+	return keys
+}
+
+// dashboardTradeSum 区间内已完成交易的笔数和总额
+type dashboardTradeSum struct {
+	Count  int64
+	Volume decimal.Decimal
+}
+
+// sumTradesBetween 统计区间内完成的交易（按成交时间）
+func sumTradesBetween(period dashboardPeriod) (dashboardTradeSum, error) {
+	var sum dashboardTradeSum
+	err := database.DB.Model(&models.Trade{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(price), 0) AS volume").
+		Where("status = ? AND completed_at >= ? AND completed_at < ?", "completed", period.Start, period.End).
+		Scan(&sum).Error
+	return sum, err
+}
+
+// sumFeesBetween 统计区间内的平台手续费收入
+func sumFeesBetween(period dashboardPeriod) (decimal.Decimal, error) {
+	var fees decimal.Decimal
+	err := database.DB.Model(&models.PlatformTransaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("type = ? AND created_at >= ? AND created_at < ?", "fee", period.Start, period.End).
+		Scan(&fees).Error
+	return fees, err
+}
+
+// getCachedStats 读取缓存，Redis不可用时视为未命中
+func (s *DashboardService) getCachedStats(key string) (*DashboardStats, bool) {
+	if database.RDB == nil {
+		return nil, false
+	}
+	data, err := database.RDB.Get(database.Ctx, key).Bytes()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			log.Printf("读取仪表盘缓存失败: %v", err)
+		}
+		return nil, false
+	}
+	var stats DashboardStats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return nil, false
+	}
+	return &stats, true
+}
+
+// setCachedStats 写入缓存，失败仅记录日志
+func (s *DashboardService) setCachedStats(key string, stats *DashboardStats, ttl time.Duration) {
+	if database.RDB == nil {
+		return
+	}
+	data, err := json.Marshal(stats)
+	if err != nil {
+		return
+	}
+	if err := database.RDB.Set(database.Ctx, key, data, ttl).Err(); err != nil {
+		log.Printf("写入仪表盘缓存失败: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"hoho-miniapp/backend/database"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDashboardPeriods 测试仪表盘统计区间与对比区间
+func TestDashboardPeriods(t *testing.T) {
+	now := time.Date(2026, 3, 15, 10, 30, 0, 0, time.Local)
+
+	current, previous, err := dashboardPeriods(DashboardRangeToday, now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 15, 0, 0, 0, 0, time.Local), current.Start)
+	assert.Equal(t, now, current.End)
+	assert.Equal(t, time.Date(2026, 3, 14, 0, 0, 0, 0, time.Local), previous.Start)
+	assert.Equal(t, now.AddDate(0, 0, -1), previous.End)
+
+	current, previous, err = dashboardPeriods(DashboardRange7d, now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, -7), current.Start)
+	assert.Equal(t, current.Start, previous.End)
+	assert.Equal(t, now.AddDate(0, 0, -14), previous.Start)
+
+	_, _, err = dashboardPeriods("1y", now)
+	assert.Error(t, err)
+}
+
+// TestCompareDashboard 测试变化率计算，上期为0时不计算
+func TestCompareDashboard(t *testing.T) {
+	comparison := compareDashboard(decimal.NewFromInt(150), decimal.NewFromInt(120))
+	assert.NotNil(t, comparison.ChangePercent)
+	assert.Equal(t, "25", comparison.ChangePercent.String())
+
+	comparison = compareDashboard(decimal.NewFromInt(1), decimal.NewFromInt(3))
+	assert.Equal(t, "-66.67", comparison.ChangePercent.String())
+
+	comparison = compareDashboard(decimal.NewFromInt(5), decimal.Zero)
+	assert.Nil(t, comparison.ChangePercent)
+}
+
+// TestDailyActiveKeys 测试活跃用户统计覆盖的自然日
+func TestDailyActiveKeys(t *testing.T) {
+	now := time.Date(2026, 3, 15, 10, 30, 0, 0, time.Local)
+	current, previous, _ := dashboardPeriods(DashboardRangeToday, now)
+	assert.Equal(t, []string{"stats:dau:2026-03-15"}, dailyActiveKeys(current))
+	assert.Equal(t, []string{"stats:dau:2026-03-14"}, dailyActiveKeys(previous))
+
+	current, _, _ = dashboardPeriods(DashboardRange7d, now)
+	keys := dailyActiveKeys(current)
+	assert.Len(t, keys, 8)
+	assert.Equal(t, "stats:dau:2026-03-08", keys[0])
+	assert.Equal(t, "stats:dau:2026-03-15", keys[7])
+}
+
+// TestTrackDailyActive 测试同一用户当天只写一次Redis，日期变化后重新计入
+func TestTrackDailyActive(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	previous := database.RDB
+	database.RDB = client
+	t.Cleanup(func() {
+		database.RDB = previous
+		client.Close()
+	})
+	unmarkDailyActive(time.Now().Format("2006-01-02"), 7)
+
+	key := dailyActiveKey(time.Now())
+	TrackDailyActive(7)
+	assert.True(t, mr.Exists(key))
+
+	// 已计入的用户不再写Redis
+	mr.Del(key)
+	TrackDailyActive(7)
+	assert.False(t, mr.Exists(key))
+
+	assert.False(t, markDailyActive(time.Now().Format("2006-01-02"), 7))
+	assert.True(t, markDailyActive("2099-01-01", 7))
+}
@@ -0,0 +1,434 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// maxLineageDepth 上游版税最多向上分账的层数，防止异常数据导致无限追溯
+const maxLineageDepth = 10
+
+// maxDerivativeRoyaltyRate 上游分成比例上限（%）
+var maxDerivativeRoyaltyRate = decimal.NewFromInt(50)
+
+// defaultDerivativeRoyaltyRate 审批时未指定比例使用的默认上游分成比例（%）
+const defaultDerivativeRoyaltyRate = "5.00"
+
+// 版税分账来源
+const (
+	RoyaltySourcePrimarySale = "primary_sale_order"
+	RoyaltySourceTrade       = "trade"
+	RoyaltySourceBlindBox    = "blind_box_draw"
+)
+
+// DerivativeService 定义衍生作品授权与血缘服务接口
+type DerivativeService struct{}
+
+// NewDerivativeService 创建一个新的DerivativeService实例
+func NewDerivativeService() *DerivativeService {
+	return &DerivativeService{}
+}
+
+// LineageNode 血缘链上的藏品
+type LineageNode struct {
+	AssetID      uint64 `json:"asset_id"`
+	Name         string `json:"name"`
+	ThumbnailURL string `json:"thumbnail_url"`
+	CreatorID    uint64 `json:"creator_id"`
+	CreatorName  string `json:"creator_name"`
+	Depth        int    `json:"depth"`        // 与当前藏品相隔的层数
+	RoyaltyRate  string `json:"royalty_rate"` // 下游成交时分给该藏品创作者的比例（%），衍生列表中为该衍生作品向当前藏品的分成比例
+}
+
+// AssetLineage 藏品血缘：向上的上游链和直接衍生作品
+type AssetLineage struct {
+	AssetID     uint64        `json:"asset_id"`
+	Ancestors   []LineageNode `json:"ancestors"`   // 由近到远
+	Derivatives []LineageNode `json:"derivatives"` // 直接衍生的已上架作品
+}
+
+// royaltyLink 血缘链上的一级：成交藏品向该上游藏品的分成比例
+type royaltyLink struct {
+	AncestorAssetID uint64
+	RecipientID     uint64
+	Rate            decimal.Decimal // 下一级藏品的 ParentRoyaltyRate（%）
+}
+
+// royaltyShare 上游某一级实际获得的分成
+type royaltyShare struct {
+	AncestorAssetID uint64
+	RecipientID     uint64
+	Depth           int
+	Amount          decimal.Decimal
+}
+
+// EnsureSchema 为已有数据库补建衍生授权、版税分账表及相关字段（由 cmd/migrate 调用）
+func (s *DerivativeService) EnsureSchema() error {
+	m := database.DB.Migrator()
+	for _, table := range []interface{}{&models.DerivativeLicense{}, &models.RoyaltyPayout{}} {
+		if !m.HasTable(table) {
+			if err := m.CreateTable(table); err != nil {
+				return err
+			}
+		}
+	}
+
+	columns := []struct {
+		model interface{}
+		field string
+	}{
+		{&models.Asset{}, "ParentAssetID"},
+		{&models.Asset{}, "ParentRoyaltyRate"},
+		{&models.Creation{}, "ParentAssetID"},
+		{&models.Trade{}, "ParentRoyalty"},
+		{&models.PrimarySaleOrder{}, "ParentRoyalty"},
+	}
+	for _, column := range columns {
+		if !m.HasColumn(column.model, column.field) {
+			if err := m.AddColumn(column.model, column.field); err != nil {
+				return err
+			}
+		}
+	}
+	for _, model := range []interface{}{&models.Asset{}, &models.Creation{}} {
+		if !m.HasIndex(model, "ParentAssetID") {
+			if err := m.CreateIndex(model, "ParentAssetID"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// createLicenseTx 在提交创作的事务中为声明了上游藏品的创作生成授权申请
+func (s *DerivativeService) createLicenseTx(tx *gorm.DB, creation *models.Creation) error {
+	var parent models.Asset
+	if err := tx.Where("status = ?", "active").First(&parent, *creation.ParentAssetID).Error; err != nil {
+		return errors.New("上游藏品不存在或未上架")
+	}
+
+	license := &models.DerivativeLicense{
+		CreationID:      uint64(creation.ID),
+		ApplicantID:     uint64(creation.UserID),
+		ParentAssetID:   parent.ID,
+		ParentCreatorID: parent.CreatorID,
+		Status:          "pending",
+	}
+	if err := tx.Create(license).Error; err != nil {
+		return err
+	}
+
+	// 平台藏品由管理员审批，无需通知
+	if parent.CreatorID == 0 {
+		return nil
+	}
+	relatedID := uint(license.ID)
+	return tx.Create(&models.Notification{
+		UserID:    uint(parent.CreatorID),
+		Type:      "system",
+		Title:     "衍生创作授权申请",
+		Content:   fmt.Sprintf("有创作者申请基于您的藏品《%s》创作衍生作品《%s》", parent.Name, creation.Title),
+		RelatedID: &relatedID,
+	}).Error
+}
+
+// ApproveByCreator 上游创作者批准衍生授权，rate为空时使用默认比例
+func (s *DerivativeService) ApproveByCreator(licenseID, creatorID uint64, rate string) (*models.DerivativeLicense, error) {
+	return s.respond(licenseID, "approved", rate, "", "creator", creatorID, func(query *gorm.DB) *gorm.DB {
+		return query.Where("parent_creator_id = ? AND parent_creator_id <> 0", creatorID)
+	})
+}
+
+// RejectByCreator 上游创作者拒绝衍生授权
+func (s *DerivativeService) RejectByCreator(licenseID, creatorID uint64, reason string) (*models.DerivativeLicense, error) {
+	return s.respond(licenseID, "rejected", "", reason, "creator", creatorID, func(query *gorm.DB) *gorm.DB {
+		return query.Where("parent_creator_id = ? AND parent_creator_id <> 0", creatorID)
+	})
+}
+
+// ApproveByAdmin 管理员批准衍生授权（平台藏品的衍生或代上游创作者处理）
+func (s *DerivativeService) ApproveByAdmin(licenseID, adminID uint64, rate string) (*models.DerivativeLicense, error) {
+	return s.respond(licenseID, "approved", rate, "", "admin", adminID, nil)
+}
+
+// RejectByAdmin 管理员拒绝衍生授权
+func (s *DerivativeService) RejectByAdmin(licenseID, adminID uint64, reason string) (*models.DerivativeLicense, error) {
+	return s.respond(licenseID, "rejected", "", reason, "admin", adminID, nil)
+}
+
+// respond 处理授权申请，以待审批状态作为条件防止重复处理
+func (s *DerivativeService) respond(licenseID uint64, status, rate, reason, approvedBy string, reviewerID uint64, scope func(*gorm.DB) *gorm.DB) (*models.DerivativeLicense, error) {
+	updates := map[string]interface{}{
+		"status":       status,
+		"approved_by":  approvedBy,
+		"reviewer_id":  reviewerID,
+		"responded_at": time.Now(),
+	}
+	if status == "approved" {
+		royaltyRate, err := parseDerivativeRoyaltyRate(rate)
+		if err != nil {
+			return nil, err
+		}
+		updates["royalty_rate"] = royaltyRate
+	} else {
+		updates["reject_reason"] = reason
+	}
+
+	query := database.DB.Model(&models.DerivativeLicense{}).Where("id = ? AND status = ?", licenseID, "pending")
+	if scope != nil {
+		query = scope(query)
+	}
+	result := query.Updates(updates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, errors.New("授权申请不存在或已处理")
+	}
+
+	var license models.DerivativeLicense
+	if err := database.DB.Preload("Creation").Preload("ParentAsset").First(&license, licenseID).Error; err != nil {
+		return nil, err
+	}
+
+	title := "衍生创作授权已通过"
+	content := fmt.Sprintf("您的衍生创作已获得授权，上游分成比例 %s%%", license.RoyaltyRate.StringFixed(2))
+	if status == "rejected" {
+		title = "衍生创作授权未通过"
+		content = fmt.Sprintf("您的衍生创作授权申请未通过，原因：%s", reason)
+	}
+	relatedID := uint(license.CreationID)
+	database.DB.Create(&models.Notification{
+		UserID:    uint(license.ApplicantID),
+		Type:      "system",
+		Title:     title,
+		Content:   content,
+		RelatedID: &relatedID,
+	})
+
+	return &license, nil
+}
+
+// ListReceivedLicenses 获取上游创作者收到的授权申请
+func (s *DerivativeService) ListReceivedLicenses(creatorID uint64, status string, page, pageSize int) ([]models.DerivativeLicense, int64, error) {
+	query := database.DB.Model(&models.DerivativeLicense{}).Where("parent_creator_id = ? AND parent_creator_id <> 0", creatorID)
+	return s.listLicenses(query, status, page, pageSize)
+}
+
+// ListLicenses 获取全部授权申请（管理员）
+func (s *DerivativeService) ListLicenses(status string, page, pageSize int) ([]models.DerivativeLicense, int64, error) {
+	return s.listLicenses(database.DB.Model(&models.DerivativeLicense{}), status, page, pageSize)
+}
+
+// listLicenses 分页查询授权申请
+func (s *DerivativeService) listLicenses(query *gorm.DB, status string, page, pageSize int) ([]models.DerivativeLicense, int64, error) {
+	var licenses []models.DerivativeLicense
+	var total int64
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Preload("Creation").Preload("ParentAsset").Order("id desc").Offset(offset).Limit(pageSize).Find(&licenses).Error; err != nil {
+		return nil, 0, err
+	}
+	return licenses, total, nil
+}
+
+// GetLineage 获取藏品的上游链和直接衍生作品
+func (s *DerivativeService) GetLineage(assetID uint64) (*AssetLineage, error) {
+	var asset models.Asset
+	if err := database.DB.Where("status = ?", "active").First(&asset, assetID).Error; err != nil {
+		return nil, errors.New("藏品不存在")
+	}
+
+	lineage := &AssetLineage{AssetID: asset.ID, Ancestors: []LineageNode{}, Derivatives: []LineageNode{}}
+
+	child := asset
+	seen := map[uint64]bool{asset.ID: true}
+	for depth := 1; child.ParentAssetID != nil && depth <= maxLineageDepth; depth++ {
+		var parent models.Asset
+		if err := database.DB.Unscoped().First(&parent, *child.ParentAssetID).Error; err != nil || seen[parent.ID] {
+			break
+		}
+		seen[parent.ID] = true
+		lineage.Ancestors = append(lineage.Ancestors, lineageNode(&parent, depth, child.ParentRoyaltyRate))
+		child = parent
+	}
+
+	var derivatives []models.Asset
+	if err := database.DB.Where("parent_asset_id = ? AND status = ?", asset.ID, "active").
+		Order("id desc").Find(&derivatives).Error; err != nil {
+		return nil, err
+	}
+	for i := range derivatives {
+		lineage.Derivatives = append(lineage.Derivatives, lineageNode(&derivatives[i], 1, derivatives[i].ParentRoyaltyRate))
+	}
+	return lineage, nil
+}
+
+// upstreamRoyaltyTx 计算成交藏品应向上游分出的版税，返回逐级分成及总额（即直接上游的分成）
+func upstreamRoyaltyTx(tx *gorm.DB, assetID uint64, amount decimal.Decimal) ([]royaltyShare, decimal.Decimal, error) {
+	var asset models.Asset
+	if err := tx.Unscoped().Select("id", "parent_asset_id", "parent_royalty_rate").First(&asset, assetID).Error; err != nil {
+		return nil, decimal.Zero, err
+	}
+
+	var links []royaltyLink
+	child := asset
+	seen := map[uint64]bool{asset.ID: true}
+	for len(links) < maxLineageDepth && child.ParentAssetID != nil && child.ParentRoyaltyRate.GreaterThan(decimal.Zero) {
+		var parent models.Asset
+		if err := tx.Unscoped().Select("id", "creator_id", "parent_asset_id", "parent_royalty_rate").First(&parent, *child.ParentAssetID).Error; err != nil {
+			return nil, decimal.Zero, err
+		}
+		if seen[parent.ID] {
+			break
+		}
+		seen[parent.ID] = true
+		links = append(links, royaltyLink{AncestorAssetID: parent.ID, RecipientID: parent.CreatorID, Rate: child.ParentRoyaltyRate})
+		child = parent
+	}
+
+	shares := cascadeRoyalty(amount, links)
+	total := decimal.Zero
+	for _, share := range shares {
+		total = total.Add(share.Amount)
+	}
+	return shares, total, nil
+}
+
+// createRoyaltyPayoutsTx 记录上游分账明细，paid为true时立即入账（首发），否则等成交完成后由 payRoyaltyPayoutsTx 入账（二级交易）
+func createRoyaltyPayoutsTx(tx *gorm.DB, sourceType string, sourceID, assetID uint64, shares []royaltyShare, paid bool) error {
+	for _, share := range shares {
+		payout := &models.RoyaltyPayout{
+			SourceType:      sourceType,
+			SourceID:        sourceID,
+			AssetID:         assetID,
+			AncestorAssetID: share.AncestorAssetID,
+			RecipientID:     share.RecipientID,
+			Depth:           share.Depth,
+			Amount:          share.Amount,
+			Status:          "pending",
+		}
+		if err := tx.Create(payout).Error; err != nil {
+			return err
+		}
+		if paid {
+			if err := creditRoyaltyPayoutTx(tx, payout); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// payRoyaltyPayoutsTx 入账来源下全部待入账的上游分成
+func payRoyaltyPayoutsTx(tx *gorm.DB, sourceType string, sourceID uint64) error {
+	var payouts []models.RoyaltyPayout
+	if err := tx.Where("source_type = ? AND source_id = ? AND status = ?", sourceType, sourceID, "pending").Find(&payouts).Error; err != nil {
+		return err
+	}
+	for i := range payouts {
+		if err := creditRoyaltyPayoutTx(tx, &payouts[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// creditRoyaltyPayoutTx 将一笔上游分成计入创作者积分（平台藏品计入平台账户），以待入账状态作为条件防止重复入账
+func creditRoyaltyPayoutTx(tx *gorm.DB, payout *models.RoyaltyPayout) error {
+	result := tx.Model(&models.RoyaltyPayout{}).Where("id = ? AND status = ?", payout.ID, "pending").Update("status", "paid")
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	description := fmt.Sprintf("衍生作品上游版税（藏品%d）", payout.AssetID)
+	if payout.RecipientID == 0 {
+		return NewPlatformAccountService().RecordPlatformIncomeTx(tx, "commission", payout.Amount, description, payout.ID, "royalty_payout")
+	}
+
+	if err := tx.Model(&models.UserPoint{}).Where("user_id = ?", payout.RecipientID).Updates(map[string]interface{}{
+		"balance":      gorm.Expr("balance + ?", payout.Amount),
+		"total_earned": gorm.Expr("total_earned + ?", payout.Amount),
+	}).Error; err != nil {
+		return err
+	}
+	return tx.Create(&models.PointTransaction{
+		UserID:      payout.RecipientID,
+		Type:        "earn",
+		Amount:      payout.Amount,
+		Description: description,
+		RelatedID:   payout.ID,
+		RelatedType: "royalty_payout",
+	}).Error
+}
+
+// cascadeRoyalty 逐级计算上游分成：直接上游获得 amount × 比例，
+// 再向上每一级从下一级的所得中按该级比例分出，保证各级所得之和等于直接上游的分成
+func cascadeRoyalty(amount decimal.Decimal, links []royaltyLink) []royaltyShare {
+	shares := make([]royaltyShare, 0, len(links))
+	gross := amount
+	for i, link := range links {
+		cut := gross.Mul(link.Rate).Div(decimal.NewFromInt(100)).RoundBank(8)
+		if cut.LessThanOrEqual(decimal.Zero) {
+			break
+		}
+		if i > 0 {
+			// 从下一级所得中扣出本级分成
+			shares[i-1].Amount = shares[i-1].Amount.Sub(cut)
+		}
+		shares = append(shares, royaltyShare{
+			AncestorAssetID: link.AncestorAssetID,
+			RecipientID:     link.RecipientID,
+			Depth:           i + 1,
+			Amount:          cut,
+		})
+		gross = cut
+	}
+	return shares
+}
+
+// parseDerivativeRoyaltyRate 解析上游分成比例（%），为空时使用系统配置的默认值
+func parseDerivativeRoyaltyRate(rate string) (decimal.Decimal, error) {
+	if rate == "" {
+		rate = defaultDerivativeRoyaltyRate
+		var config models.SystemConfig
+		if err := database.DB.Where("`key` = ?", "derivative_royalty_rate").First(&config).Error; err == nil {
+			rate = config.Value
+		}
+	}
+	value, err := decimal.NewFromString(rate)
+	if err != nil || value.IsNegative() || value.GreaterThan(maxDerivativeRoyaltyRate) {
+		return decimal.Zero, fmt.Errorf("上游分成比例需在0-%s之间", maxDerivativeRoyaltyRate.String())
+	}
+	return value.Round(2), nil
+}
+
+// lineageNode 构建血缘节点
+func lineageNode(asset *models.Asset, depth int, rate decimal.Decimal) LineageNode {
+	return LineageNode{
+		AssetID:      asset.ID,
+		Name:         asset.Name,
+		ThumbnailURL: asset.ThumbnailURL,
+		CreatorID:    asset.CreatorID,
+		CreatorName:  asset.CreatorName,
+		Depth:        depth,
+		RoyaltyRate:  rate.StringFixed(2),
+	}
+}
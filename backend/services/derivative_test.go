@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCascadeRoyalty 测试上游版税逐级分成且总额守恒
+func TestCascadeRoyalty(t *testing.T) {
+	links := []royaltyLink{
+		{AncestorAssetID: 2, RecipientID: 20, Rate: decimal.NewFromInt(10)}, // 衍生作品向直接上游分10%
+		{AncestorAssetID: 3, RecipientID: 30, Rate: decimal.NewFromInt(20)}, // 直接上游再向其上游分20%
+	}
+	shares := cascadeRoyalty(decimal.NewFromInt(100), links)
+
+	assert.Len(t, shares, 2)
+	// 直接上游所得10，其中2再分给更上一级
+	assert.True(t, shares[0].Amount.Equal(decimal.NewFromInt(8)))
+	assert.Equal(t, 1, shares[0].Depth)
+	assert.True(t, shares[1].Amount.Equal(decimal.NewFromInt(2)))
+	assert.Equal(t, uint64(30), shares[1].RecipientID)
+	assert.Equal(t, 2, shares[1].Depth)
+
+	total := shares[0].Amount.Add(shares[1].Amount)
+	assert.True(t, total.Equal(decimal.NewFromInt(10)))
+}
+
+// TestCascadeRoyaltyStopsAtZero 测试比例为0或金额过小时停止向上分成
+func TestCascadeRoyaltyStopsAtZero(t *testing.T) {
+	links := []royaltyLink{
+		{AncestorAssetID: 2, RecipientID: 20, Rate: decimal.NewFromInt(5)},
+		{AncestorAssetID: 3, RecipientID: 30, Rate: decimal.Zero},
+		{AncestorAssetID: 4, RecipientID: 40, Rate: decimal.NewFromInt(50)},
+	}
+	shares := cascadeRoyalty(decimal.NewFromInt(100), links)
+	assert.Len(t, shares, 1)
+	assert.True(t, shares[0].Amount.Equal(decimal.NewFromInt(5)))
+
+	assert.Empty(t, cascadeRoyalty(decimal.RequireFromString("0.00000001"), links))
+	assert.Empty(t, cascadeRoyalty(decimal.NewFromInt(100), nil))
+}
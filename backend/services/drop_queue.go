@@ -0,0 +1,327 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// defaultTicketTTL 购买凭证默认有效期（秒），可通过系统配置 drop_ticket_ttl_seconds 覆盖
+const defaultTicketTTL = 300
+
+// 排队状态
+const (
+	QueueStatusWaiting = "waiting"  // 排队中
+	QueueStatusTicket  = "ticket"   // 已获得购买凭证
+	QueueStatusSoldOut = "sold_out" // 已售罄
+)
+
+// Redis 键说明（{id}为首发ID）：
+//   drop:{id}:stock          剩余可发放库存
+//   drop:{id}:seq            入队序号
+//   drop:{id}:queue          排队队列 ZSET，score为入队序号
+//   drop:{id}:tickets        已发放凭证 ZSET，score为过期时间戳
+//   drop:{id}:ticket:{uid}   用户的凭证值
+// 库存只在发放凭证时扣减，凭证过期时归还，所有变更都在 Lua 脚本中原子执行，保证不超卖。
+
+// joinQueueScript 入队，已在队列或已持有凭证时不重复入队
+// KEYS: queue, seq, tickets  ARGV: userID
+var joinQueueScript = redis.NewScript(`
+if redis.call('ZSCORE', KEYS[3], ARGV[1]) then
+	return 0
+end
+if not redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	local seq = redis.call('INCR', KEYS[2])
+	redis.call('ZADD', KEYS[1], seq, ARGV[1])
+end
+return 1
+`)
+
+// admitScript 按入队顺序为队首用户发放凭证，直到库存用尽
+// KEYS: stock, queue, tickets  ARGV: now, ttl, ticketKeyPrefix, tokens...
+var admitScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local ttl = tonumber(ARGV[2])
+local admitted = 0
+for i = 4, #ARGV do
+	local stock = tonumber(redis.call('GET', KEYS[1]) or '0')
+	if stock <= 0 then
+		break
+	end
+	local head = redis.call('ZRANGE', KEYS[2], 0, 0)
+	if #head == 0 then
+		break
+	end
+	local uid = head[1]
+	redis.call('ZREM', KEYS[2], uid)
+	redis.call('DECR', KEYS[1])
+	redis.call('ZADD', KEYS[3], now + ttl, uid)
+	redis.call('SET', ARGV[3] .. uid, ARGV[i], 'EX', ttl)
+	admitted = admitted + 1
+end
+return admitted
+`)
+
+// consumeTicketScript 核销购买凭证
+// KEYS: tickets, ticketKey  ARGV: userID, token, now
+var consumeTicketScript = redis.NewScript(`
+local expireAt = redis.call('ZSCORE', KEYS[1], ARGV[1])
+if not expireAt or tonumber(expireAt) <= tonumber(ARGV[3]) then
+	return 0
+end
+if redis.call('GET', KEYS[2]) ~= ARGV[2] then
+	return 0
+end
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('DEL', KEYS[2])
+return 1
+`)
+
+// releaseExpiredScript 回收过期未支付的凭证并归还库存
+// KEYS: stock, tickets  ARGV: now, ticketKeyPrefix
+var releaseExpiredScript = redis.NewScript(`
+local expired = redis.call('ZRANGEBYSCORE', KEYS[2], '-inf', ARGV[1])
+for _, uid in ipairs(expired) do
+	redis.call('ZREM', KEYS[2], uid)
+	redis.call('DEL', ARGV[2] .. uid)
+	redis.call('INCR', KEYS[1])
+end
+return #expired
+`)
+
+// DropQueueService 定义热门首发排队服务接口
+type DropQueueService struct{}
+
+// NewDropQueueService 创建一个新的DropQueueService实例
+func NewDropQueueService() *DropQueueService {
+	return &DropQueueService{}
+}
+
+// QueueState 用户的排队状态
+type QueueState struct {
+	Status    string     `json:"status"`
+	Position  int64      `json:"position,omitempty"`   // 前方排队人数+1
+	QueueSize int64      `json:"queue_size,omitempty"` // 当前排队总人数
+	Ticket    string     `json:"ticket,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// JoinQueue 加入首发排队
+func (s *DropQueueService) JoinQueue(userID uint64, sale *models.PrimarySale) (*QueueState, error) {
+	if !sale.QueueEnabled {
+		return nil, errors.New("该首发无需排队")
+	}
+	if err := checkSaleAvailable(sale, time.Now()); err != nil {
+		return nil, err
+	}
+
+	// 首个用户入队时初始化库存计数
+	remaining := sale.TotalStock - sale.SoldCount
+	if err := database.RDB.SetNX(database.Ctx, dropKey(sale.ID, "stock"), remaining, 0).Err(); err != nil {
+		return nil, err
+	}
+
+	if err := joinQueue(sale.ID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.GetQueueState(userID, sale.ID)
+}
+
+// GetQueueState 查询用户的排队状态，查询时顺带推进队列
+func (s *DropQueueService) GetQueueState(userID, saleID uint64) (*QueueState, error) {
+	if err := s.admit(saleID); err != nil {
+		return nil, err
+	}
+
+	member := strconv.FormatUint(userID, 10)
+
+	expireAt, err := database.RDB.ZScore(database.Ctx, dropKey(saleID, "tickets"), member).Result()
+	if err == nil {
+		token, err := database.RDB.Get(database.Ctx, ticketKey(saleID, userID)).Result()
+		if err == redis.Nil {
+			// 凭证已过期，等待定时任务回收库存
+			return nil, errors.New("购买凭证已过期，请重新排队")
+		}
+		if err != nil {
+			return nil, err
+		}
+		expiresAt := time.Unix(int64(expireAt), 0)
+		return &QueueState{Status: QueueStatusTicket, Ticket: token, ExpiresAt: &expiresAt}, nil
+	} else if err != redis.Nil {
+		return nil, err
+	}
+
+	rank, err := database.RDB.ZRank(database.Ctx, dropKey(saleID, "queue"), member).Result()
+	if err == redis.Nil {
+		return nil, errors.New("你不在排队队列中")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 库存已发完且没有待支付凭证可回收时，排队用户无法再买到
+	stock, _ := database.RDB.Get(database.Ctx, dropKey(saleID, "stock")).Int()
+	pending, _ := database.RDB.ZCard(database.Ctx, dropKey(saleID, "tickets")).Result()
+	if stock <= 0 && pending == 0 {
+		return &QueueState{Status: QueueStatusSoldOut}, nil
+	}
+
+	size, _ := database.RDB.ZCard(database.Ctx, dropKey(saleID, "queue")).Result()
+	return &QueueState{Status: QueueStatusWaiting, Position: rank + 1, QueueSize: size}, nil
+}
+
+// ConsumeTicket 核销用户的购买凭证，凭证无效或已过期时返回错误
+func (s *DropQueueService) ConsumeTicket(userID, saleID uint64, token string) error {
+	if token == "" {
+		return errors.New("请先排队获取购买凭证")
+	}
+
+	return consumeTicketAt(saleID, userID, token, time.Now())
+}
+
+// RestoreStock 凭证已核销但购买失败时归还库存
+func (s *DropQueueService) RestoreStock(saleID uint64) {
+	if err := database.RDB.Incr(database.Ctx, dropKey(saleID, "stock")).Err(); err != nil {
+		log.Printf("首发%d归还排队库存失败: %v", saleID, err)
+	}
+}
+
+// ReleaseExpiredTickets 回收所有排队首发中过期未支付的凭证，并继续放行排队用户（定时任务）
+func (s *DropQueueService) ReleaseExpiredTickets() error {
+	var saleIDs []uint64
+	if err := database.DB.Model(&models.PrimarySale{}).
+		Where("queue_enabled = ? AND status = ?", true, "on_sale").
+		Pluck("id", &saleIDs).Error; err != nil {
+		return err
+	}
+
+	for _, saleID := range saleIDs {
+		released, err := releaseExpiredAt(saleID, time.Now())
+		if err != nil {
+			return err
+		}
+		if released > 0 {
+			log.Printf("首发%d回收了%d个过期购买凭证", saleID, released)
+		}
+		if err := s.admit(saleID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ClearQueue 清理首发的排队数据（首发结束时调用）
+func (s *DropQueueService) ClearQueue(saleID uint64) error {
+	pattern := fmt.Sprintf("drop:%d:*", saleID)
+	iter := database.RDB.Scan(database.Ctx, 0, pattern, 100).Iterator()
+	var keys []string
+	for iter.Next(database.Ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	return database.RDB.Del(database.Ctx, keys...).Err()
+}
+
+// admit 放行队首用户，凭证有效期取系统配置
+func (s *DropQueueService) admit(saleID uint64) error {
+	ttl := getConfigInt("drop_ticket_ttl_seconds", defaultTicketTTL)
+	_, err := admitAt(saleID, time.Now(), ttl)
+	return err
+}
+
+// joinQueue 执行入队脚本
+func joinQueue(saleID, userID uint64) error {
+	keys := []string{dropKey(saleID, "queue"), dropKey(saleID, "seq"), dropKey(saleID, "tickets")}
+	return joinQueueScript.Run(database.Ctx, database.RDB, keys, userID).Err()
+}
+
+// admitAt 按 now 时刻放行队首用户，每次最多发放 admitBatch 个凭证，返回发放数量
+func admitAt(saleID uint64, now time.Time, ttl int) (int, error) {
+	const admitBatch = 50
+
+	stock, err := database.RDB.Get(database.Ctx, dropKey(saleID, "stock")).Int()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if stock <= 0 {
+		return 0, nil
+	}
+
+	batch := admitBatch
+	if stock < batch {
+		batch = stock
+	}
+
+	args := []interface{}{now.Unix(), ttl, ticketKeyPrefix(saleID)}
+	for i := 0; i < batch; i++ {
+		token, err := generateTicket()
+		if err != nil {
+			return 0, err
+		}
+		args = append(args, token)
+	}
+
+	keys := []string{dropKey(saleID, "stock"), dropKey(saleID, "queue"), dropKey(saleID, "tickets")}
+	return admitScript.Run(database.Ctx, database.RDB, keys, args...).Int()
+}
+
+// consumeTicketAt 按 now 时刻核销购买凭证
+func consumeTicketAt(saleID, userID uint64, token string, now time.Time) error {
+	keys := []string{dropKey(saleID, "tickets"), ticketKey(saleID, userID)}
+	ok, err := consumeTicketScript.Run(database.Ctx, database.RDB, keys, userID, token, now.Unix()).Int()
+	if err != nil {
+		return err
+	}
+	if ok == 0 {
+		return errors.New("购买凭证无效或已过期")
+	}
+	return nil
+}
+
+// releaseExpiredAt 回收 now 时刻已过期的凭证并归还库存，返回回收数量
+func releaseExpiredAt(saleID uint64, now time.Time) (int, error) {
+	keys := []string{dropKey(saleID, "stock"), dropKey(saleID, "tickets")}
+	return releaseExpiredScript.Run(database.Ctx, database.RDB, keys, now.Unix(), ticketKeyPrefix(saleID)).Int()
+}
+
+// dropKey 生成首发排队相关的Redis键
+func dropKey(saleID uint64, name string) string {
+	return fmt.Sprintf("drop:%d:%s", saleID, name)
+}
+
+// ticketKeyPrefix 用户购买凭证键前缀
+func ticketKeyPrefix(saleID uint64) string {
+	return fmt.Sprintf("drop:%d:ticket:", saleID)
+}
+
+// ticketKey 用户购买凭证键
+func ticketKey(saleID, userID uint64) string {
+	return ticketKeyPrefix(saleID) + strconv.FormatUint(userID, 10)
+}
+
+// generateTicket 生成随机购买凭证
+func generateTicket() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"hoho-miniapp/backend/database"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDropQueueKeys 测试排队相关Redis键
+func TestDropQueueKeys(t *testing.T) {
+	assert.Equal(t, "drop:12:stock", dropKey(12, "stock"))
+	assert.Equal(t, "drop:12:queue", dropKey(12, "queue"))
+	assert.Equal(t, "drop:12:ticket:", ticketKeyPrefix(12))
+	assert.Equal(t, "drop:12:ticket:345", ticketKey(12, 345))
+}
+
+// TestGenerateTicket 测试购买凭证生成
+func TestGenerateTicket(t *testing.T) {
+	tickets := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		ticket, err := generateTicket()
+		assert.NoError(t, err)
+		assert.Len(t, ticket, 32)
+		assert.False(t, tickets[ticket], "购买凭证应该是唯一的")
+		tickets[ticket] = true
+	}
+}
+
+// setupDropQueueRedis 以 miniredis 替换全局Redis客户端，测试结束后恢复
+func setupDropQueueRedis(t *testing.T, saleID uint64, stock int) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	previous := database.RDB
+	database.RDB = client
+	t.Cleanup(func() {
+		database.RDB = previous
+		client.Close()
+	})
+	assert.NoError(t, client.Set(database.Ctx, dropKey(saleID, "stock"), stock, 0).Err())
+}
+
+// queueTicket 读取用户的凭证值
+func queueTicket(t *testing.T, saleID, userID uint64) string {
+	t.Helper()
+	token, err := database.RDB.Get(database.Ctx, ticketKey(saleID, userID)).Result()
+	assert.NoError(t, err)
+	return token
+}
+
+// TestDropQueueAdmitOrder 测试按入队顺序放行，重复入队不改变顺序，放行数量不超过库存
+func TestDropQueueAdmitOrder(t *testing.T) {
+	const saleID = 1
+	setupDropQueueRedis(t, saleID, 2)
+	now := time.Unix(1700000000, 0)
+
+	for _, userID := range []uint64{30, 10, 20, 30} {
+		assert.NoError(t, joinQueue(saleID, userID))
+	}
+
+	admitted, err := admitAt(saleID, now, 60)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, admitted)
+
+	holders, err := database.RDB.ZRange(database.Ctx, dropKey(saleID, "tickets"), 0, -1).Result()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"30", "10"}, holders)
+
+	waiting, err := database.RDB.ZRange(database.Ctx, dropKey(saleID, "queue"), 0, -1).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"20"}, waiting)
+
+	stock, err := database.RDB.Get(database.Ctx, dropKey(saleID, "stock")).Int()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stock)
+
+	// 库存用尽后不再放行
+	admitted, err = admitAt(saleID, now, 60)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, admitted)
+
+	// 已持有凭证的用户不会重新入队
+	assert.NoError(t, joinQueue(saleID, 10))
+	waiting, err = database.RDB.ZRange(database.Ctx, dropKey(saleID, "queue"), 0, -1).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"20"}, waiting)
+}
+
+// TestDropQueueNeverOversold 测试任意放行、核销、回收组合下发放的凭证总数不超过库存
+func TestDropQueueNeverOversold(t *testing.T) {
+	const saleID = 2
+	const stock = 3
+	setupDropQueueRedis(t, saleID, stock)
+	now := time.Unix(1700000000, 0)
+
+	for userID := uint64(1); userID <= 10; userID++ {
+		assert.NoError(t, joinQueue(saleID, userID))
+	}
+	for i := 0; i < 5; i++ {
+		_, err := admitAt(saleID, now, 60)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, consumeTicketAt(saleID, 1, queueTicket(t, saleID, 1), now))
+
+	// 未过期的凭证不回收
+	released, err := releaseExpiredAt(saleID, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, released)
+	_, err = admitAt(saleID, now, 60)
+	assert.NoError(t, err)
+
+	pending, err := database.RDB.ZCard(database.Ctx, dropKey(saleID, "tickets")).Result()
+	assert.NoError(t, err)
+	remaining, err := database.RDB.Get(database.Ctx, dropKey(saleID, "stock")).Int()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), pending)
+	assert.Equal(t, 0, remaining)
+	assert.Equal(t, int64(stock), pending+int64(remaining)+1, "已核销+待支付+剩余库存应等于总库存")
+}
+
+// TestDropQueueConsumeTicket 测试凭证核销：错误凭证和过期凭证被拒绝，同一凭证只能核销一次
+func TestDropQueueConsumeTicket(t *testing.T) {
+	const saleID = 3
+	setupDropQueueRedis(t, saleID, 2)
+	now := time.Unix(1700000000, 0)
+
+	assert.NoError(t, joinQueue(saleID, 1))
+	assert.NoError(t, joinQueue(saleID, 2))
+	_, err := admitAt(saleID, now, 60)
+	assert.NoError(t, err)
+
+	assert.Error(t, consumeTicketAt(saleID, 1, "wrong-ticket", now))
+	assert.Error(t, consumeTicketAt(saleID, 2, queueTicket(t, saleID, 1), now), "不能使用他人的凭证")
+
+	token := queueTicket(t, saleID, 1)
+	assert.NoError(t, consumeTicketAt(saleID, 1, token, now.Add(59*time.Second)))
+	assert.Error(t, consumeTicketAt(saleID, 1, token, now.Add(59*time.Second)), "凭证不能重复核销")
+
+	assert.Error(t, consumeTicketAt(saleID, 2, queueTicket(t, saleID, 2), now.Add(60*time.Second)), "过期凭证不能核销")
+}
+
+// TestDropQueueReleaseExpired 测试过期凭证回收后库存归还，并放行下一位排队用户
+func TestDropQueueReleaseExpired(t *testing.T) {
+	const saleID = 4
+	setupDropQueueRedis(t, saleID, 1)
+	now := time.Unix(1700000000, 0)
+
+	assert.NoError(t, joinQueue(saleID, 1))
+	assert.NoError(t, joinQueue(saleID, 2))
+	_, err := admitAt(saleID, now, 60)
+	assert.NoError(t, err)
+	expired := queueTicket(t, saleID, 1)
+
+	released, err := releaseExpiredAt(saleID, now.Add(60*time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, released)
+
+	stock, err := database.RDB.Get(database.Ctx, dropKey(saleID, "stock")).Int()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, stock)
+	exists, err := database.RDB.Exists(database.Ctx, ticketKey(saleID, 1)).Result()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), exists)
+
+	later := now.Add(61 * time.Second)
+	admitted, err := admitAt(saleID, later, 60)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, admitted)
+	assert.NoError(t, consumeTicketAt(saleID, 2, queueTicket(t, saleID, 2), later))
+	assert.Error(t, consumeTicketAt(saleID, 1, expired, later), "已回收的凭证不能核销")
+}
@@ -3,6 +3,8 @@ package services
 import (
 	"hoho-miniapp/backend/database"
 	"hoho-miniapp/backend/models"
+
+	"gorm.io/gorm"
 )
 
 // EventService 定义社区事件服务接口
@@ -23,13 +25,114 @@ func (s *EventService) CreateEvent(eventType string, userID uint64, description
 		RelatedType: relatedType,
 	}
 
-	if err := database.DB.Create(&event).Error; err != nil {
+	if err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return appendEventTx(tx, &event)
+	}); err != nil {
+		return nil, err
+	}
+
+	return &event, nil
+}
+
+// recordEvent 在调用方事务中记录社区事件并追加到哈希链，保证事件与业务变更同时提交
+// 链头锁持有到事务提交，调用方应在其他业务变更完成后最后调用
+func recordEvent(tx *gorm.DB, eventType string, userID uint64, description string, relatedID uint64, relatedType string) (*models.CommunityEvent, error) {
+	event := models.CommunityEvent{
+		EventType:   eventType,
+		UserID:      userID,
+		Description: description,
+		RelatedID:   relatedID,
+		RelatedType: relatedType,
+	}
+
+	if err := appendEventTx(tx, &event); err != nil {
 		return nil, err
 	}
 
 	return &event, nil
 }
 
+// eventBatch 收集事务中待记录的社区事件及其关联的所有权记录，在事务最后一步统一写入
+// 追加事件会锁定链头直到事务提交，业务变更全部完成后再写入可缩短链头锁的持有时间；
+// 铸造、销毁等可组合的流程把事件加入调用方的 eventBatch，而不是各自直接调用 recordEvent
+type eventBatch struct {
+	pending []pendingEvent
+}
+
+// pendingEvent 待记录的事件，ownership 在事件入链后关联其ID写入
+type pendingEvent struct {
+	event     models.CommunityEvent
+	ownership []models.OwnershipRecord
+}
+
+// add 加入一条待记录的事件
+func (b *eventBatch) add(eventType string, userID uint64, description string, relatedID uint64, relatedType string, ownership ...models.OwnershipRecord) {
+	b.pending = append(b.pending, pendingEvent{
+		event: models.CommunityEvent{
+			EventType:   eventType,
+			UserID:      userID,
+			Description: description,
+			RelatedID:   relatedID,
+			RelatedType: relatedType,
+		},
+		ownership: ownership,
+	})
+}
+
+// recordTx 按加入顺序将事件追加到哈希链，再一次性写入关联的所有权记录，应作为事务的最后一步调用
+func (b *eventBatch) recordTx(tx *gorm.DB) error {
+	var records []models.OwnershipRecord
+	for i := range b.pending {
+		item := &b.pending[i]
+		if err := appendEventTx(tx, &item.event); err != nil {
+			return err
+		}
+		for _, record := range item.ownership {
+			record.EventID = &item.event.ID
+			records = append(records, record)
+		}
+	}
+	b.pending = nil
+	if len(records) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(records, 500).Error
+}
+
+// eventUserTx 社区事件描述中的用户标识，使用脱敏UID而不暴露内部用户ID
+func eventUserTx(tx *gorm.DB, userID uint64) (string, error) {
+	var uid string
+	if err := tx.Model(&models.User{}).Select("uid").Where("id = ?", userID).Scan(&uid).Error; err != nil {
+		return "", err
+	}
+	return maskUID(uid), nil
+}
+
+// fillEventUIDs 为公开的事件列表填充脱敏UID
+func fillEventUIDs(events []models.CommunityEvent) error {
+	ids := make([]uint64, 0, len(events))
+	for _, event := range events {
+		if event.UserID != 0 {
+			ids = append(ids, event.UserID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	var users []models.User
+	if err := database.DB.Select("id", "uid").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return err
+	}
+	uids := make(map[uint64]string, len(users))
+	for _, user := range users {
+		uids[user.ID] = maskUID(user.UID)
+	}
+	for i := range events {
+		events[i].UserUID = uids[events[i].UserID]
+	}
+	return nil
+}
+
 // GetEvents 获取社区事件列表（分页）
 func (s *EventService) GetEvents(page, pageSize int, eventType string) ([]models.CommunityEvent, int64, error) {
 	var events []models.CommunityEvent
@@ -52,6 +155,9 @@ func (s *EventService) GetEvents(page, pageSize int, eventType string) ([]models
 	if err := query.Limit(pageSize).Offset(offset).Order("id desc").Find(&events).Error; err != nil {
 		return nil, 0, err
 	}
+	if err := fillEventUIDs(events); err != nil {
+		return nil, 0, err
+	}
 
 	return events, total, nil
 }
@@ -62,5 +168,9 @@ func (s *EventService) GetEventByID(eventID uint64) (*models.CommunityEvent, err
 	if err := database.DB.First(&event, eventID).Error; err != nil {
 		return nil, err
 	}
-	return &event, nil
+	events := []models.CommunityEvent{event}
+	if err := fillEventUIDs(events); err != nil {
+		return nil, err
+	}
+	return &events[0], nil
 }
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// eventChainHeadID 链头记录的固定ID
+const eventChainHeadID = 1
+
+// maxChainBreaks 校验报告中最多列出的断点数量
+const maxChainBreaks = 100
+
+// ChainBreak 哈希链中的一处断点
+type ChainBreak struct {
+	EventID uint64 `json:"event_id"`
+	Seq     uint64 `json:"seq"`
+	Reason  string `json:"reason"`
+}
+
+// ChainReport 哈希链校验结果
+type ChainReport struct {
+	Valid    bool         `json:"valid"`
+	Checked  int64        `json:"checked"`
+	HeadSeq  uint64       `json:"head_seq"`
+	HeadHash string       `json:"head_hash"`
+	Breaks   []ChainBreak `json:"breaks"`
+}
+
+// eventPayload 参与哈希计算的事件规范化内容，字段顺序固定
+type eventPayload struct {
+	Seq         uint64 `json:"seq"`
+	PrevHash    string `json:"prev_hash"`
+	EventType   string `json:"event_type"`
+	UserID      uint64 `json:"user_id"`
+	Description string `json:"description"`
+	RelatedID   uint64 `json:"related_id"`
+	RelatedType string `json:"related_type"`
+	CreatedAt   int64  `json:"created_at"` // Unix秒
+}
+
+// eventHash 计算事件哈希：SHA256(规范化JSON)，规范化内容包含上一事件的哈希
+func eventHash(event *models.CommunityEvent) string {
+	payload, _ := json.Marshal(eventPayload{
+		Seq:         event.ChainSeq,
+		PrevHash:    event.PrevHash,
+		EventType:   event.EventType,
+		UserID:      event.UserID,
+		Description: event.Description,
+		RelatedID:   event.RelatedID,
+		RelatedType: event.RelatedType,
+		CreatedAt:   event.CreatedAt.Unix(),
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// appendEventTx 在调用方事务中将事件追加到哈希链
+// 锁定链头直到事务提交，因此事件与业务变更一同提交或回滚，且序号不会分叉
+func appendEventTx(tx *gorm.DB, event *models.CommunityEvent) error {
+	var head models.EventChainHead
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		FirstOrCreate(&head, models.EventChainHead{ID: eventChainHeadID}).Error; err != nil {
+		return err
+	}
+
+	// 数据库时间字段只保存到秒，先截断以保证重算哈希时一致
+	event.CreatedAt = time.Now().Truncate(time.Second)
+	event.ChainSeq = head.LastSeq + 1
+	event.PrevHash = head.LastHash
+	event.Hash = eventHash(event)
+	if err := tx.Create(event).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&head).Updates(map[string]interface{}{
+		"last_seq":  event.ChainSeq,
+		"last_hash": event.Hash,
+	}).Error
+}
+
+// EnsureChain 为已有数据库补建哈希链字段和链头，并按ID顺序为尚未入链的历史事件补链（由 cmd/migrate 调用）
+func (s *EventService) EnsureChain() error {
+	m := database.DB.Migrator()
+	if !m.HasTable(&models.EventChainHead{}) {
+		if err := m.CreateTable(&models.EventChainHead{}); err != nil {
+			return err
+		}
+	}
+	for _, field := range []string{"ChainSeq", "PrevHash", "Hash"} {
+		if !m.HasColumn(&models.CommunityEvent{}, field) {
+			if err := m.AddColumn(&models.CommunityEvent{}, field); err != nil {
+				return err
+			}
+		}
+	}
+	if !m.HasIndex(&models.CommunityEvent{}, "ChainSeq") {
+		if err := m.CreateIndex(&models.CommunityEvent{}, "ChainSeq"); err != nil {
+			return err
+		}
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var head models.EventChainHead
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			FirstOrCreate(&head, models.EventChainHead{ID: eventChainHeadID}).Error; err != nil {
+			return err
+		}
+
+		var events []models.CommunityEvent
+		if err := tx.Unscoped().Where("chain_seq IS NULL OR chain_seq = 0").Order("id asc").Find(&events).Error; err != nil {
+			return err
+		}
+		for i := range events {
+			event := &events[i]
+			event.ChainSeq = head.LastSeq + 1
+			event.PrevHash = head.LastHash
+			event.Hash = eventHash(event)
+			if err := tx.Unscoped().Model(event).UpdateColumns(map[string]interface{}{
+				"chain_seq": event.ChainSeq,
+				"prev_hash": event.PrevHash,
+				"hash":      event.Hash,
+			}).Error; err != nil {
+				return err
+			}
+			head.LastSeq = event.ChainSeq
+			head.LastHash = event.Hash
+		}
+
+		return tx.Model(&head).Updates(map[string]interface{}{
+			"last_seq":  head.LastSeq,
+			"last_hash": head.LastHash,
+		}).Error
+	})
+}
+
+// VerifyChain 按序号遍历全部事件（含软删除），报告被修改、删除或插入的断点
+func (s *EventService) VerifyChain() (*ChainReport, error) {
+	var head models.EventChainHead
+	if err := database.DB.First(&head, eventChainHeadID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("事件哈希链尚未初始化")
+		}
+		return nil, err
+	}
+
+	report := &ChainReport{HeadSeq: head.LastSeq, HeadHash: head.LastHash, Breaks: []ChainBreak{}}
+	var prev *models.CommunityEvent
+	var cursor uint64
+	for {
+		var batch []models.CommunityEvent
+		if err := database.DB.Unscoped().Where("chain_seq > ?", cursor).
+			Order("chain_seq asc").Limit(1000).Find(&batch).Error; err != nil {
+			return nil, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+		for i := range batch {
+			event := &batch[i]
+			for _, reason := range checkChainLink(prev, event) {
+				report.addBreak(event.ID, event.ChainSeq, reason)
+			}
+			prev = event
+		}
+		report.Checked += int64(len(batch))
+		cursor = prev.ChainSeq
+	}
+
+	var unchained int64
+	if err := database.DB.Unscoped().Model(&models.CommunityEvent{}).
+		Where("chain_seq IS NULL OR chain_seq = 0").Count(&unchained).Error; err != nil {
+		return nil, err
+	}
+	if unchained > 0 {
+		report.addBreak(0, 0, fmt.Sprintf("存在%d个未入链的事件", unchained))
+	}
+
+	var lastSeq uint64
+	var lastHash string
+	if prev != nil {
+		lastSeq, lastHash = prev.ChainSeq, prev.Hash
+	}
+	if lastSeq != head.LastSeq || lastHash != head.LastHash {
+		report.addBreak(0, head.LastSeq, fmt.Sprintf("链尾与链头不一致：链尾序号%d，链头序号%d", lastSeq, head.LastSeq))
+	}
+
+	report.Valid = len(report.Breaks) == 0
+	return report, nil
+}
+
+// checkChainLink 校验单个事件与其前一个事件的链接关系，prev为空表示链首
+func checkChainLink(prev, event *models.CommunityEvent) []string {
+	var reasons []string
+	expectedSeq, expectedPrev := uint64(1), ""
+	if prev != nil {
+		expectedSeq, expectedPrev = prev.ChainSeq+1, prev.Hash
+	}
+	if event.ChainSeq > expectedSeq {
+		reasons = append(reasons, fmt.Sprintf("缺少序号%d至%d的事件", expectedSeq, event.ChainSeq-1))
+	} else if event.ChainSeq < expectedSeq {
+		reasons = append(reasons, "序号重复或乱序")
+	}
+	if event.PrevHash != expectedPrev {
+		reasons = append(reasons, "上一哈希不匹配")
+	}
+	if eventHash(event) != event.Hash {
+		reasons = append(reasons, "事件内容与哈希不符")
+	}
+	if event.DeletedAt.Valid {
+		reasons = append(reasons, "事件已被删除")
+	}
+	return reasons
+}
+
+// addBreak 记录一处断点，最多保留前 maxChainBreaks 条
+func (r *ChainReport) addBreak(eventID, seq uint64, reason string) {
+	if len(r.Breaks) < maxChainBreaks {
+		r.Breaks = append(r.Breaks, ChainBreak{EventID: eventID, Seq: seq, Reason: reason})
+	}
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"hoho-miniapp/backend/models"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// buildTestChain 构造一条合法的事件哈希链
+func buildTestChain(n int) []models.CommunityEvent {
+	events := make([]models.CommunityEvent, n)
+	prevHash := ""
+	base := time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)
+	for i := range events {
+		events[i] = models.CommunityEvent{
+			ID:          uint64(i + 1),
+			EventType:   "trade",
+			UserID:      uint64(100 + i),
+			Description: "用户购买了藏品",
+			RelatedID:   uint64(i + 1),
+			RelatedType: "trade",
+			ChainSeq:    uint64(i + 1),
+			PrevHash:    prevHash,
+			CreatedAt:   base.Add(time.Duration(i) * time.Minute),
+		}
+		events[i].Hash = eventHash(&events[i])
+		prevHash = events[i].Hash
+	}
+	return events
+}
+
+// TestEventHash 测试事件哈希只依赖规范化内容
+func TestEventHash(t *testing.T) {
+	events := buildTestChain(1)
+	event := events[0]
+	assert.Len(t, event.Hash, 64)
+
+	// 同一时刻的不同时区表示得到相同哈希
+	event.CreatedAt = event.CreatedAt.In(time.FixedZone("CST", 8*3600))
+	assert.Equal(t, event.Hash, eventHash(&event))
+
+	event.Description = "用户购买了藏品！"
+	assert.NotEqual(t, event.Hash, eventHash(&event))
+}
+
+// TestCheckChainLink 测试哈希链断点检测
+func TestCheckChainLink(t *testing.T) {
+	events := buildTestChain(4)
+	var prev *models.CommunityEvent
+	for i := range events {
+		assert.Empty(t, checkChainLink(prev, &events[i]))
+		prev = &events[i]
+	}
+
+	// 修改内容
+	edited := events[2]
+	edited.Description = "篡改"
+	assert.Equal(t, []string{"事件内容与哈希不符"}, checkChainLink(&events[1], &edited))
+
+	// 删除中间事件
+	assert.Equal(t, []string{"缺少序号2至2的事件", "上一哈希不匹配"}, checkChainLink(&events[0], &events[2]))
+
+	// 软删除
+	deleted := events[1]
+	deleted.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	assert.Equal(t, []string{"事件已被删除"}, checkChainLink(&events[0], &deleted))
+
+	// 链首必须从序号1开始且没有上一哈希
+	assert.Equal(t, []string{"缺少序号1至1的事件", "上一哈希不匹配"}, checkChainLink(nil, &events[1]))
+}
+
+// TestChainReportAddBreak 测试断点数量上限
+func TestChainReportAddBreak(t *testing.T) {
+	report := &ChainReport{}
+	for i := 0; i < maxChainBreaks+5; i++ {
+		report.addBreak(uint64(i), uint64(i), "x")
+	}
+	assert.Len(t, report.Breaks, maxChainBreaks)
+}
@@ -0,0 +1,53 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// maxIDRetries 生成的编号发生唯一索引冲突时的最大尝试次数
+const maxIDRetries = 3
+
+// createWithRetry 插入记录，生成的编号（column列，如UID、TokenID或单号）冲突时调用 regenerate 重新生成后重试
+// TranslateError 不保留冲突的索引名，冲突后查询当前编号是否已被占用：未被占用说明冲突来自其他唯一索引（如手机号），直接返回
+// MySQL中单条语句失败不会中止事务，因此可在调用方事务内直接重试
+func createWithRetry(tx *gorm.DB, value interface{}, column string, regenerate func()) error {
+	var err error
+	for attempt := 0; attempt < maxIDRetries; attempt++ {
+		if attempt > 0 {
+			regenerate()
+		}
+		if err = tx.Create(value).Error; !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return err
+		}
+		taken, checkErr := generatedValueTaken(tx, value, column)
+		if checkErr != nil {
+			return checkErr
+		}
+		if !taken {
+			return err
+		}
+	}
+	return err
+}
+
+// generatedValueTaken 检查记录中column列的当前值是否已存在于表中
+func generatedValueTaken(tx *gorm.DB, value interface{}, column string) (bool, error) {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(value); err != nil {
+		return false, err
+	}
+	field := stmt.Schema.LookUpField(column)
+	if field == nil {
+		return false, errors.New("未知的编号字段: " + column)
+	}
+	current, _ := field.ValueOf(tx.Statement.Context, reflect.ValueOf(value))
+
+	var count int64
+	if err := tx.Table(stmt.Schema.Table).Where(field.DBName+" = ?", current).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"regexp"
+	"testing"
+
+	"hoho-miniapp/backend/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	mysqldriver "gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupRetryDB 使用sqlmock模拟MySQL，唯一索引冲突经 TranslateError 转换为 gorm.ErrDuplicatedKey
+func setupRetryDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	sqlDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(mysqldriver.New(mysqldriver.Config{Conn: sqlDB, SkipInitializeWithVersion: true}),
+		&gorm.Config{TranslateError: true, SkipDefaultTransaction: true, Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	return db, mock
+}
+
+var duplicateEntry = &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"}
+
+// TestCreateWithRetryRegeneratesTakenUID 测试生成的UID已被占用时重新生成后重试
+func TestCreateWithRetryRegeneratesTakenUID(t *testing.T) {
+	db, mock := setupRetryDB(t)
+	insert := regexp.QuoteMeta("INSERT INTO `users`")
+	count := regexp.QuoteMeta("SELECT count(*) FROM `users` WHERE uid = ?")
+
+	mock.ExpectExec(insert).WillReturnError(duplicateEntry)
+	mock.ExpectQuery(count).WithArgs("100000001").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectExec(insert).WillReturnResult(sqlmock.NewResult(7, 1))
+
+	user := models.User{Phone: "13800000000", UID: "100000001"}
+	regenerated := 0
+	err := createWithRetry(db, &user, "uid", func() {
+		regenerated++
+		user.UID = "100000002"
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, regenerated)
+	assert.Equal(t, uint64(7), user.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestCreateWithRetryStopsOnOtherIndex 测试冲突来自其他唯一索引（如手机号）时不再重试
+func TestCreateWithRetryStopsOnOtherIndex(t *testing.T) {
+	db, mock := setupRetryDB(t)
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `users`")).WillReturnError(duplicateEntry)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `users` WHERE uid = ?")).
+		WithArgs("100000001").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	user := models.User{Phone: "13800000000", UID: "100000001"}
+	err := createWithRetry(db, &user, "uid", func() { t.Fatal("不应重新生成UID") })
+	assert.ErrorIs(t, err, gorm.ErrDuplicatedKey)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,745 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LotteryService 定义首发抽签服务接口
+type LotteryService struct{}
+
+// NewLotteryService 创建一个新的LotteryService实例
+func NewLotteryService() *LotteryService {
+	return &LotteryService{}
+}
+
+// LotteryInput 创建抽签的配置
+type LotteryInput struct {
+	SaleID            uint64    `json:"sale_id" binding:"required"`
+	WinnerCount       int       `json:"winner_count" binding:"required,min=1"`
+	RegisterStartAt   time.Time `json:"register_start_at" binding:"required"`
+	RegisterEndAt     time.Time `json:"register_end_at" binding:"required"`
+	PurchaseStartAt   time.Time `json:"purchase_start_at" binding:"required"`
+	PurchaseEndAt     time.Time `json:"purchase_end_at" binding:"required"`
+	BonusAssetID      *uint64   `json:"bonus_asset_id"`
+	TicketsPerHolding int       `json:"tickets_per_holding"`
+	MaxTicketsPerUser int       `json:"max_tickets_per_user"`
+}
+
+// LotteryPublicEntry 公开的报名记录，报名者仅展示脱敏UID
+type LotteryPublicEntry struct {
+	ID        uint64    `json:"id"`
+	UID       string    `json:"uid"`
+	Tickets   int       `json:"tickets"`
+	WinRank   int       `json:"win_rank"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// EntryCheck 单条报名记录的验证结果，报名者仅展示脱敏UID
+type EntryCheck struct {
+	EntryID      uint64 `json:"entry_id"`
+	UID          string `json:"uid"`
+	Tickets      int    `json:"tickets"`
+	WinRank      int    `json:"win_rank"`
+	ExpectedRank int    `json:"expected_rank"`
+	Match        bool   `json:"match"`
+}
+
+// LotteryVerification 抽签结果验证
+type LotteryVerification struct {
+	SeedHash           string       `json:"seed_hash"`
+	Seed               string       `json:"seed"`
+	HashMatches        bool         `json:"hash_matches"`
+	EntriesHash        string       `json:"entries_hash"` // 开奖时公示的报名名单哈希
+	EntriesHashMatches bool         `json:"entries_hash_matches"`
+	AllMatch           bool         `json:"all_match"`
+	Entries            []EntryCheck `json:"entries"`
+}
+
+// EnsureSchema 为已有数据库补建报名名单哈希字段（由 cmd/migrate 调用）
+func (s *LotteryService) EnsureSchema() error {
+	m := database.DB.Migrator()
+	if !m.HasColumn(&models.Lottery{}, "EntriesHash") {
+		return m.AddColumn(&models.Lottery{}, "EntriesHash")
+	}
+	return nil
+}
+
+// CreateLottery 为首发创建抽签（管理员），创建时公布随机种子承诺
+func (s *LotteryService) CreateLottery(input LotteryInput) (*models.Lottery, error) {
+	if !input.RegisterEndAt.After(input.RegisterStartAt) {
+		return nil, errors.New("报名结束时间必须晚于开始时间")
+	}
+	if input.PurchaseStartAt.Before(input.RegisterEndAt) {
+		return nil, errors.New("购买窗口不能早于报名结束时间")
+	}
+	if !input.PurchaseEndAt.After(input.PurchaseStartAt) {
+		return nil, errors.New("购买结束时间必须晚于开始时间")
+	}
+	if input.TicketsPerHolding < 0 {
+		return nil, errors.New("持有加签数不能为负数")
+	}
+	if input.TicketsPerHolding > 0 && input.BonusAssetID == nil {
+		return nil, errors.New("请指定加签的持有藏品")
+	}
+	if input.MaxTicketsPerUser < 1 {
+		input.MaxTicketsPerUser = 1
+	}
+
+	lottery := &models.Lottery{
+		SaleID:            input.SaleID,
+		WinnerCount:       input.WinnerCount,
+		RegisterStartAt:   input.RegisterStartAt,
+		RegisterEndAt:     input.RegisterEndAt,
+		PurchaseStartAt:   input.PurchaseStartAt,
+		PurchaseEndAt:     input.PurchaseEndAt,
+		BonusAssetID:      input.BonusAssetID,
+		TicketsPerHolding: input.TicketsPerHolding,
+		MaxTicketsPerUser: input.MaxTicketsPerUser,
+		Status:            "registering",
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var sale models.PrimarySale
+		if err := tx.Preload("Asset").First(&sale, input.SaleID).Error; err != nil {
+			return errors.New("首发不存在")
+		}
+		if sale.Status != "on_sale" {
+			return errors.New("首发已结束")
+		}
+		if sale.QueueEnabled {
+			return errors.New("开启排队的首发不能同时抽签")
+		}
+		if input.WinnerCount > sale.TotalStock-sale.SoldCount {
+			return errors.New("中签名额不能超过剩余库存")
+		}
+
+		var count int64
+		if err := tx.Model(&models.Lottery{}).Where("sale_id = ?", input.SaleID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return errors.New("该首发已创建抽签")
+		}
+
+		seed, err := generateSeed()
+		if err != nil {
+			return err
+		}
+		lottery.Seed = seed
+		lottery.SeedHash = hashSeed(seed)
+		if err := tx.Create(lottery).Error; err != nil {
+			return err
+		}
+
+		name := ""
+		if sale.Asset != nil {
+			name = sale.Asset.Name
+		}
+		description := fmt.Sprintf("首发《%s》开放抽签报名，中签名额%d，随机种子承诺 SHA256=%s",
+			name, lottery.WinnerCount, lottery.SeedHash)
+		_, err = recordEvent(tx, "lottery_commit", 0, description, lottery.ID, "lottery")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return lottery, nil
+}
+
+// ListLotteries 获取抽签列表，status为空时返回全部
+func (s *LotteryService) ListLotteries(status string, page, pageSize int) ([]models.Lottery, int64, error) {
+	var lotteries []models.Lottery
+	var total int64
+
+	query := database.DB.Model(&models.Lottery{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Preload("Sale.Asset").Order("register_start_at desc").Offset(offset).Limit(pageSize).Find(&lotteries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return lotteries, total, nil
+}
+
+// GetLottery 获取抽签详情
+func (s *LotteryService) GetLottery(lotteryID uint64) (*models.Lottery, error) {
+	var lottery models.Lottery
+	if err := database.DB.Preload("Sale.Asset").First(&lottery, lotteryID).Error; err != nil {
+		return nil, errors.New("抽签不存在")
+	}
+	return &lottery, nil
+}
+
+// Register 报名抽签，按首发价格冻结积分，签数按持有藏品数量加权
+func (s *LotteryService) Register(userID, lotteryID uint64) (*models.LotteryEntry, error) {
+	var entry models.LotteryEntry
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 锁定抽签并在锁内检查报名窗口，与开奖串行，开奖后不会再有报名写入
+		var lottery models.Lottery
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lottery, lotteryID).Error; err != nil {
+			return errors.New("抽签不存在")
+		}
+		if err := checkLotteryRegistrable(&lottery, time.Now()); err != nil {
+			return err
+		}
+
+		var sale models.PrimarySale
+		if err := tx.First(&sale, lottery.SaleID).Error; err != nil {
+			return errors.New("首发不存在")
+		}
+		if sale.Status != "on_sale" {
+			return errors.New("首发已结束")
+		}
+
+		// 1. 锁定用户行，防止同一用户并发报名
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return errors.New("用户不存在")
+		}
+		if sale.RequireRealName && !user.IdentityVerified {
+			return errors.New("请先完成实名认证")
+		}
+
+		var exists int64
+		if err := tx.Model(&models.LotteryEntry{}).Where("lottery_id = ? AND user_id = ?", lotteryID, userID).Count(&exists).Error; err != nil {
+			return err
+		}
+		if exists > 0 {
+			return errors.New("已报名该抽签")
+		}
+
+		// 2. 计算签数
+		var holdings int64
+		if lottery.BonusAssetID != nil && lottery.TicketsPerHolding > 0 {
+			if err := tx.Model(&models.AssetInstance{}).
+				Where("asset_id = ? AND owner_id = ? AND status <> ?", *lottery.BonusAssetID, userID, "burned").
+				Count(&holdings).Error; err != nil {
+				return err
+			}
+		}
+		tickets := calcLotteryTickets(int(holdings), lottery.TicketsPerHolding, lottery.MaxTicketsPerUser)
+
+		// 3. 冻结购买所需积分
+		result := tx.Model(&models.UserPoint{}).
+			Where("user_id = ? AND balance - frozen >= ?", userID, sale.Price).
+			Update("frozen", gorm.Expr("frozen + ?", sale.Price))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("积分余额不足")
+		}
+
+		entry = models.LotteryEntry{
+			LotteryID:    lotteryID,
+			UserID:       userID,
+			Tickets:      tickets,
+			FrozenAmount: sale.Price,
+			Status:       "registered",
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&models.PointTransaction{
+			UserID:      userID,
+			Type:        "freeze",
+			Amount:      sale.Price,
+			Description: "首发抽签报名冻结",
+			RelatedID:   entry.ID,
+			RelatedType: "lottery_entry",
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Lottery{}).Where("id = ?", lotteryID).Updates(map[string]interface{}{
+			"entry_count":   gorm.Expr("entry_count + ?", 1),
+			"total_tickets": gorm.Expr("total_tickets + ?", tickets),
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// GetUserEntry 获取用户在抽签中的报名记录
+func (s *LotteryService) GetUserEntry(userID, lotteryID uint64) (*models.LotteryEntry, error) {
+	var entry models.LotteryEntry
+	if err := database.DB.Where("lottery_id = ? AND user_id = ?", lotteryID, userID).First(&entry).Error; err != nil {
+		return nil, errors.New("未报名该抽签")
+	}
+	return &entry, nil
+}
+
+// GetEntries 获取开奖后的报名名单（按报名顺序），用于公开验证
+func (s *LotteryService) GetEntries(lotteryID uint64, page, pageSize int) ([]LotteryPublicEntry, int64, error) {
+	lottery, err := s.GetLottery(lotteryID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if lottery.RevealedSeed == "" {
+		return nil, 0, errors.New("抽签尚未开奖")
+	}
+
+	var entries []models.LotteryEntry
+	var total int64
+
+	query := database.DB.Model(&models.LotteryEntry{}).Where("lottery_id = ?", lotteryID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("id asc").Offset(offset).Limit(pageSize).Find(&entries).Error; err != nil {
+		return nil, 0, err
+	}
+	uids, err := maskedEntryUIDs(entries)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	list := make([]LotteryPublicEntry, 0, len(entries))
+	for _, entry := range entries {
+		list = append(list, LotteryPublicEntry{
+			ID:        entry.ID,
+			UID:       uids[entry.UserID],
+			Tickets:   entry.Tickets,
+			WinRank:   entry.WinRank,
+			Status:    entry.Status,
+			CreatedAt: entry.CreatedAt,
+		})
+	}
+	return list, total, nil
+}
+
+// maskedEntryUIDs 查询报名者的脱敏UID
+func maskedEntryUIDs(entries []models.LotteryEntry) (map[uint64]string, error) {
+	uids := make(map[uint64]string, len(entries))
+	if len(entries) == 0 {
+		return uids, nil
+	}
+	userIDs := make([]uint64, 0, len(entries))
+	for _, entry := range entries {
+		userIDs = append(userIDs, entry.UserID)
+	}
+	var users []models.User
+	if err := database.DB.Unscoped().Select("id", "uid").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		uids[user.ID] = maskUID(user.UID)
+	}
+	return uids, nil
+}
+
+// maskUID 脱敏UID：保留前3位和后2位
+func maskUID(uid string) string {
+	runes := []rune(uid)
+	if len(runes) <= 5 {
+		if len(runes) == 0 {
+			return ""
+		}
+		return string(runes[:1]) + "****"
+	}
+	return string(runes[:3]) + "****" + string(runes[len(runes)-2:])
+}
+
+// Draw 开奖：报名截止后用种子和报名名单哈希抽出中签者，解冻未中签者积分并公开种子和名单哈希
+// 名单哈希在报名截止后才确定，持有种子也无法在报名期间预知某个报名能否中签
+func (s *LotteryService) Draw(lotteryID uint64) (*models.Lottery, error) {
+	var lottery models.Lottery
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lottery, lotteryID).Error; err != nil {
+			return errors.New("抽签不存在")
+		}
+		if lottery.Status != "registering" {
+			return errors.New("抽签已开奖或已取消")
+		}
+		now := time.Now()
+		if now.Before(lottery.RegisterEndAt) {
+			return errors.New("报名尚未截止")
+		}
+
+		var entries []models.LotteryEntry
+		if err := tx.Where("lottery_id = ?", lotteryID).Order("id asc").Find(&entries).Error; err != nil {
+			return err
+		}
+
+		entriesHash := lotteryEntriesHash(entries)
+		winners := drawLotteryWinners(lottery.Seed, entriesHash, entries, lottery.WinnerCount)
+		ranks := make(map[int]int, len(winners))
+		for rank, idx := range winners {
+			ranks[idx] = rank + 1
+		}
+
+		for i := range entries {
+			entry := &entries[i]
+			if rank, ok := ranks[i]; ok {
+				if err := tx.Model(entry).Updates(map[string]interface{}{
+					"status":   "won",
+					"win_rank": rank,
+				}).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			if err := tx.Model(entry).Update("status", "lost").Error; err != nil {
+				return err
+			}
+			if err := unfreezeLotteryEntryTx(tx, entry, "首发抽签未中签解冻"); err != nil {
+				return err
+			}
+		}
+
+		lottery.Status = "drawn"
+		lottery.RevealedSeed = lottery.Seed
+		lottery.EntriesHash = entriesHash
+		lottery.DrawnAt = &now
+		if err := tx.Model(&lottery).Updates(map[string]interface{}{
+			"status":        lottery.Status,
+			"revealed_seed": lottery.RevealedSeed,
+			"entries_hash":  lottery.EntriesHash,
+			"drawn_at":      lottery.DrawnAt,
+		}).Error; err != nil {
+			return err
+		}
+
+		description := fmt.Sprintf("首发抽签%d开奖：%d人报名共%d签，%d人中签，报名名单哈希 %s，公开随机种子 %s",
+			lottery.ID, len(entries), lottery.TotalTickets, len(winners), lottery.EntriesHash, lottery.RevealedSeed)
+		_, err := recordEvent(tx, "lottery_draw", 0, description, lottery.ID, "lottery")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &lottery, nil
+}
+
+// FinishLottery 购买窗口结束后将未购买的中签者及遗留的未开奖报名标记为过期并解冻积分
+func (s *LotteryService) FinishLottery(lotteryID uint64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var lottery models.Lottery
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lottery, lotteryID).Error; err != nil {
+			return errors.New("抽签不存在")
+		}
+		if lottery.Status != "drawn" {
+			return errors.New("抽签未开奖或已结束")
+		}
+		if time.Now().Before(lottery.PurchaseEndAt) {
+			return errors.New("购买窗口尚未结束")
+		}
+
+		var entries []models.LotteryEntry
+		if err := tx.Where("lottery_id = ? AND status IN ?", lotteryID, []string{"won", "registered"}).Find(&entries).Error; err != nil {
+			return err
+		}
+		for i := range entries {
+			description := "首发抽签中签未购买解冻"
+			if entries[i].Status == "registered" {
+				description = "首发抽签未参与开奖解冻"
+			}
+			if err := tx.Model(&entries[i]).Update("status", "expired").Error; err != nil {
+				return err
+			}
+			if err := unfreezeLotteryEntryTx(tx, &entries[i], description); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&lottery).Update("status", "finished").Error
+	})
+}
+
+// CancelLottery 取消抽签（管理员），仅报名阶段允许，解冻全部报名者积分
+func (s *LotteryService) CancelLottery(lotteryID uint64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var lottery models.Lottery
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&lottery, lotteryID).Error; err != nil {
+			return errors.New("抽签不存在")
+		}
+		if lottery.Status != "registering" {
+			return errors.New("只有报名中的抽签才能取消")
+		}
+
+		var entries []models.LotteryEntry
+		if err := tx.Where("lottery_id = ?", lotteryID).Find(&entries).Error; err != nil {
+			return err
+		}
+		for i := range entries {
+			if err := tx.Model(&entries[i]).Update("status", "expired").Error; err != nil {
+				return err
+			}
+			if err := unfreezeLotteryEntryTx(tx, &entries[i], "首发抽签取消解冻"); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&lottery).Update("status", "cancelled").Error
+	})
+}
+
+// RunDueLotteries 对报名已截止的抽签开奖，对购买窗口已结束的抽签收尾（定时任务）
+func (s *LotteryService) RunDueLotteries() error {
+	now := time.Now()
+
+	var drawIDs []uint64
+	if err := database.DB.Model(&models.Lottery{}).
+		Where("status = ? AND register_end_at <= ?", "registering", now).
+		Pluck("id", &drawIDs).Error; err != nil {
+		return err
+	}
+	for _, id := range drawIDs {
+		if _, err := s.Draw(id); err != nil {
+			return fmt.Errorf("抽签%d开奖失败: %w", id, err)
+		}
+	}
+
+	var finishIDs []uint64
+	if err := database.DB.Model(&models.Lottery{}).
+		Where("status = ? AND purchase_end_at <= ?", "drawn", now).
+		Pluck("id", &finishIDs).Error; err != nil {
+		return err
+	}
+	for _, id := range finishIDs {
+		if err := s.FinishLottery(id); err != nil {
+			return fmt.Errorf("抽签%d收尾失败: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// VerifyLottery 用公开的种子和报名名单重放开奖，校验每条记录的中签顺序
+func (s *LotteryService) VerifyLottery(lotteryID uint64) (*LotteryVerification, error) {
+	lottery, err := s.GetLottery(lotteryID)
+	if err != nil {
+		return nil, err
+	}
+	if lottery.RevealedSeed == "" {
+		return nil, errors.New("抽签尚未开奖")
+	}
+
+	var entries []models.LotteryEntry
+	if err := database.DB.Where("lottery_id = ?", lotteryID).Order("id asc").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	uids, err := maskedEntryUIDs(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	return replayLottery(lottery.RevealedSeed, lottery.SeedHash, lottery.EntriesHash, entries, uids, lottery.WinnerCount), nil
+}
+
+// consumeLotteryWinTx 首发购买时校验抽签资格：存在进行中的抽签时仅中签者可在购买窗口内购买，
+// 核销中签资格并解冻报名时冻结的积分，由调用方随后正常扣款
+func consumeLotteryWinTx(tx *gorm.DB, saleID, userID uint64, now time.Time) error {
+	var lottery models.Lottery
+	err := tx.Where("sale_id = ?", saleID).First(&lottery).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	switch lottery.Status {
+	case "registering":
+		return errors.New("该首发需抽签购买，请先报名并等待开奖")
+	case "drawn":
+	default:
+		// 抽签已结束或取消，剩余库存按普通首发销售
+		return nil
+	}
+
+	if now.Before(lottery.PurchaseStartAt) {
+		return errors.New("中签购买尚未开始")
+	}
+	if !now.Before(lottery.PurchaseEndAt) {
+		return errors.New("中签购买已结束")
+	}
+
+	result := tx.Model(&models.LotteryEntry{}).
+		Where("lottery_id = ? AND user_id = ? AND status = ?", lottery.ID, userID, "won").
+		Update("status", "purchased")
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("未中签或已购买")
+	}
+
+	var entry models.LotteryEntry
+	if err := tx.Where("lottery_id = ? AND user_id = ?", lottery.ID, userID).First(&entry).Error; err != nil {
+		return err
+	}
+	return unfreezeLotteryEntryTx(tx, &entry, "首发抽签中签购买解冻")
+}
+
+// unfreezeLotteryEntryTx 解冻报名时冻结的积分
+func unfreezeLotteryEntryTx(tx *gorm.DB, entry *models.LotteryEntry, description string) error {
+	if entry.FrozenAmount.LessThanOrEqual(decimal.Zero) {
+		return nil
+	}
+
+	result := tx.Model(&models.UserPoint{}).
+		Where("user_id = ? AND frozen >= ?", entry.UserID, entry.FrozenAmount).
+		Update("frozen", gorm.Expr("frozen - ?", entry.FrozenAmount))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("用户%d冻结积分不足，无法解冻", entry.UserID)
+	}
+
+	return tx.Create(&models.PointTransaction{
+		UserID:      entry.UserID,
+		Type:        "unfreeze",
+		Amount:      entry.FrozenAmount,
+		Description: description,
+		RelatedID:   entry.ID,
+		RelatedType: "lottery_entry",
+	}).Error
+}
+
+// checkLotteryRegistrable 检查抽签是否处于报名窗口
+func checkLotteryRegistrable(lottery *models.Lottery, now time.Time) error {
+	if lottery.Status != "registering" {
+		return errors.New("抽签报名已结束")
+	}
+	if now.Before(lottery.RegisterStartAt) {
+		return errors.New("抽签报名尚未开始")
+	}
+	if !now.Before(lottery.RegisterEndAt) {
+		return errors.New("抽签报名已结束")
+	}
+	return nil
+}
+
+// calcLotteryTickets 计算签数：基础1签，每持有一份加权藏品增加 perHolding 签，不超过上限
+// 用户体系中没有会员等级，签数只按持有藏品加权
+func calcLotteryTickets(holdings, perHolding, maxTickets int) int {
+	tickets := 1 + holdings*perHolding
+	if maxTickets > 0 && tickets > maxTickets {
+		tickets = maxTickets
+	}
+	return tickets
+}
+
+// lotteryEntriesHash 报名名单哈希：按ID升序将每条报名记为"报名ID:签数"逐行拼接后取SHA-256
+// 只使用公开名单中的字段，任何人可按公开的报名名单重新计算
+func lotteryEntriesHash(entries []models.LotteryEntry) string {
+	var b strings.Builder
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "%d:%d\n", entry.ID, entry.Tickets)
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// lotteryUint64 开奖随机数：SHA256("种子:名单哈希:k") 的前8字节（大端）
+// 升级前开奖的抽签没有记录名单哈希，按 seededUint64 重放
+func lotteryUint64(seed, entriesHash string, k int) uint64 {
+	if entriesHash == "" {
+		return seededUint64(seed, k)
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%s:%d", seed, entriesHash, k)))
+	return binary.BigEndian.Uint64(sum[:8])
+}
+
+// drawLotteryWinners 按签数加权不放回抽取中签者，返回按中签顺序排列的报名记录下标
+// 报名记录按ID升序排列，第k次抽取的随机数对剩余总签数取模，落在哪条记录的签数区间即由其中签
+func drawLotteryWinners(seed, entriesHash string, entries []models.LotteryEntry, winnerCount int) []int {
+	remaining := make([]int, 0, len(entries))
+	total := uint64(0)
+	for i, entry := range entries {
+		if entry.Tickets > 0 {
+			remaining = append(remaining, i)
+			total += uint64(entry.Tickets)
+		}
+	}
+
+	winners := make([]int, 0, winnerCount)
+	for k := 0; k < winnerCount && len(remaining) > 0; k++ {
+		r := lotteryUint64(seed, entriesHash, k) % total
+		for j, idx := range remaining {
+			tickets := uint64(entries[idx].Tickets)
+			if r < tickets {
+				winners = append(winners, idx)
+				total -= tickets
+				remaining = append(remaining[:j], remaining[j+1:]...)
+				break
+			}
+			r -= tickets
+		}
+	}
+	return winners
+}
+
+// replayLottery 用种子和按名单重新计算的名单哈希重放开奖，并与记录的中签顺序比对
+// entriesHash为开奖时公示的名单哈希（升级前开奖的为空），uids为报名用户ID到脱敏UID的映射
+func replayLottery(seed, seedHash, entriesHash string, entries []models.LotteryEntry, uids map[uint64]string, winnerCount int) *LotteryVerification {
+	result := &LotteryVerification{
+		SeedHash:           seedHash,
+		Seed:               seed,
+		HashMatches:        hashSeed(seed) == seedHash,
+		EntriesHash:        entriesHash,
+		EntriesHashMatches: true,
+		AllMatch:           true,
+		Entries:            make([]EntryCheck, 0, len(entries)),
+	}
+	if entriesHash != "" {
+		result.EntriesHashMatches = lotteryEntriesHash(entries) == entriesHash
+	}
+
+	expected := make(map[int]int)
+	for rank, idx := range drawLotteryWinners(seed, entriesHash, entries, winnerCount) {
+		expected[idx] = rank + 1
+	}
+
+	for i, entry := range entries {
+		check := EntryCheck{
+			EntryID:      entry.ID,
+			UID:          uids[entry.UserID],
+			Tickets:      entry.Tickets,
+			WinRank:      entry.WinRank,
+			ExpectedRank: expected[i],
+		}
+		check.Match = check.WinRank == check.ExpectedRank
+		if !check.Match {
+			result.AllMatch = false
+		}
+		result.Entries = append(result.Entries, check)
+	}
+	if !result.HashMatches || !result.EntriesHashMatches {
+		result.AllMatch = false
+	}
+	return result
+}
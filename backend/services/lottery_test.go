@@ -0,0 +1,134 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"hoho-miniapp/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testEntries() []models.LotteryEntry {
+	return []models.LotteryEntry{
+		{ID: 1, UserID: 101, Tickets: 1},
+		{ID: 2, UserID: 102, Tickets: 3},
+		{ID: 3, UserID: 103, Tickets: 1},
+		{ID: 4, UserID: 104, Tickets: 2},
+		{ID: 5, UserID: 105, Tickets: 1},
+	}
+}
+
+// TestCalcLotteryTickets 测试签数计算
+func TestCalcLotteryTickets(t *testing.T) {
+	tests := []struct {
+		name       string
+		holdings   int
+		perHolding int
+		maxTickets int
+		expected   int
+	}{
+		{"未持有", 0, 2, 5, 1},
+		{"持有加签", 2, 1, 5, 3},
+		{"超过上限", 10, 2, 5, 5},
+		{"不加签", 3, 0, 5, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, calcLotteryTickets(tt.holdings, tt.perHolding, tt.maxTickets))
+		})
+	}
+}
+
+// TestDrawLotteryWinners 测试开奖结果确定且不重复
+func TestDrawLotteryWinners(t *testing.T) {
+	entries := testEntries()
+
+	entriesHash := lotteryEntriesHash(entries)
+	first := drawLotteryWinners("seed-a", entriesHash, entries, 3)
+	second := drawLotteryWinners("seed-a", entriesHash, entries, 3)
+	assert.Equal(t, first, second)
+	assert.Len(t, first, 3)
+
+	seen := make(map[int]bool)
+	for _, idx := range first {
+		assert.False(t, seen[idx])
+		seen[idx] = true
+	}
+
+	// 名额多于报名人数时全部中签
+	assert.Len(t, drawLotteryWinners("seed-a", entriesHash, entries, 10), len(entries))
+	assert.Empty(t, drawLotteryWinners("seed-a", entriesHash, nil, 3))
+}
+
+// TestLotteryEntriesHash 测试名单哈希随报名名单变化，并参与决定开奖随机数
+func TestLotteryEntriesHash(t *testing.T) {
+	entries := testEntries()
+	hash := lotteryEntriesHash(entries)
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, lotteryEntriesHash(testEntries()))
+
+	// 多一条报名或签数变化都会改变名单哈希
+	more := append(testEntries(), models.LotteryEntry{ID: 6, UserID: 106, Tickets: 1})
+	assert.NotEqual(t, hash, lotteryEntriesHash(more))
+	changed := testEntries()
+	changed[0].Tickets = 2
+	assert.NotEqual(t, hash, lotteryEntriesHash(changed))
+
+	// 同一种子下名单哈希不同则随机数不同，未记录名单哈希的旧抽签按种子重放
+	assert.NotEqual(t, lotteryUint64("seed-a", hash, 0), lotteryUint64("seed-a", lotteryEntriesHash(more), 0))
+	assert.Equal(t, seededUint64("seed-a", 0), lotteryUint64("seed-a", "", 0))
+}
+
+// TestReplayLottery 测试用公开种子重放开奖
+func TestReplayLottery(t *testing.T) {
+	seed := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	entries := testEntries()
+	entriesHash := lotteryEntriesHash(entries)
+	for rank, idx := range drawLotteryWinners(seed, entriesHash, entries, 2) {
+		entries[idx].WinRank = rank + 1
+	}
+
+	uids := map[uint64]string{101: maskUID("1234567890")}
+	result := replayLottery(seed, hashSeed(seed), entriesHash, entries, uids, 2)
+	assert.True(t, result.HashMatches)
+	assert.True(t, result.EntriesHashMatches)
+	assert.True(t, result.AllMatch)
+	assert.Equal(t, "123****90", result.Entries[0].UID)
+
+	// 篡改中签记录后验证失败
+	for i := range entries {
+		if entries[i].WinRank == 0 {
+			entries[i].WinRank = 3
+			break
+		}
+	}
+	result = replayLottery(seed, hashSeed(seed), entriesHash, entries, uids, 2)
+	assert.False(t, result.AllMatch)
+
+	// 开奖后增删报名记录，名单哈希不再匹配
+	result = replayLottery(seed, hashSeed(seed), entriesHash, testEntries()[:4], uids, 2)
+	assert.False(t, result.EntriesHashMatches)
+	assert.False(t, result.AllMatch)
+
+	result = replayLottery(seed, hashSeed("other"), entriesHash, testEntries(), uids, 2)
+	assert.False(t, result.HashMatches)
+	assert.False(t, result.AllMatch)
+}
+
+// TestCheckLotteryRegistrable 测试报名窗口校验
+func TestCheckLotteryRegistrable(t *testing.T) {
+	now := time.Now()
+	lottery := &models.Lottery{
+		Status:          "registering",
+		RegisterStartAt: now.Add(-time.Hour),
+		RegisterEndAt:   now.Add(time.Hour),
+	}
+	assert.NoError(t, checkLotteryRegistrable(lottery, now))
+	assert.Error(t, checkLotteryRegistrable(lottery, now.Add(-2*time.Hour)))
+	assert.Error(t, checkLotteryRegistrable(lottery, now.Add(time.Hour)))
+
+	lottery.Status = "drawn"
+	assert.Error(t, checkLotteryRegistrable(lottery, now))
+}
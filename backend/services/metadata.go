@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"gorm.io/gorm"
+)
+
+// MetadataAttribute ERC-721元数据中的属性
+type MetadataAttribute struct {
+	DisplayType string      `json:"display_type,omitempty"`
+	TraitType   string      `json:"trait_type"`
+	Value       interface{} `json:"value"`
+	MaxValue    int         `json:"max_value,omitempty"`
+}
+
+// MetadataCollection 元数据中的所属集合
+type MetadataCollection struct {
+	ID          uint64 `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+}
+
+// MetadataCreator 元数据中的创作者，平台藏品为“平台”
+type MetadataCreator struct {
+	Name string `json:"name"`
+}
+
+// TokenMetadata ERC-721风格的藏品实例元数据，供合作平台和钱包渲染
+type TokenMetadata struct {
+	Name         string              `json:"name"`
+	Description  string              `json:"description"`
+	Image        string              `json:"image"`
+	AnimationURL string              `json:"animation_url,omitempty"` // 视频、音频和3D藏品的原始媒体
+	Attributes   []MetadataAttribute `json:"attributes"`
+	TokenID      string              `json:"token_id"`
+	InstanceNo   int                 `json:"instance_no"`
+	TotalSupply  int                 `json:"total_supply"`
+	Burned       bool                `json:"burned"`
+	MintTxHash   string              `json:"mint_tx_hash,omitempty"`
+	Collection   *MetadataCollection `json:"collection"`
+	Creator      MetadataCreator     `json:"creator"`
+}
+
+// MetadataService 定义藏品元数据服务接口
+type MetadataService struct{}
+
+// NewMetadataService 创建一个新的MetadataService实例
+func NewMetadataService() *MetadataService {
+	return &MetadataService{}
+}
+
+// GetTokenMetadata 按TokenID生成藏品实例的元数据
+func (s *MetadataService) GetTokenMetadata(tokenID string) (*TokenMetadata, error) {
+	var instance models.AssetInstance
+	if err := database.DB.Preload("Asset", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("token_id = ?", tokenID).First(&instance).Error; err != nil || instance.Asset == nil {
+		return nil, errors.New("藏品实例不存在")
+	}
+	asset := instance.Asset
+
+	// 藏品级属性对全部实例生效，实例级属性覆盖同类型的藏品级属性
+	var traits []models.AssetTrait
+	if err := database.DB.Where("asset_id = ? AND (asset_instance_id IS NULL OR asset_instance_id = ?)", asset.ID, instance.ID).
+		Find(&traits).Error; err != nil {
+		return nil, err
+	}
+	merged := mergeInstanceTraits([]models.AssetInstance{instance}, traits)[0].Traits
+
+	metadata := &TokenMetadata{
+		Name:        fmt.Sprintf("%s #%d", asset.Name, instance.InstanceNo),
+		Description: asset.Description,
+		Image:       asset.MediaURL,
+		Attributes:  metadataAttributes(merged, instance.InstanceNo, asset.TotalSupply),
+		TokenID:     instance.TokenID,
+		InstanceNo:  instance.InstanceNo,
+		TotalSupply: asset.TotalSupply,
+		Burned:      instance.Status == "burned",
+		MintTxHash:  instance.MintTxHash,
+		Creator:     MetadataCreator{Name: asset.CreatorName},
+	}
+	if asset.MediaType != "image" {
+		metadata.Image = asset.ThumbnailURL
+		metadata.AnimationURL = asset.MediaURL
+	}
+	if metadata.Creator.Name == "" && asset.CreatorID == 0 {
+		metadata.Creator.Name = "平台"
+	}
+
+	var collection models.Collection
+	if err := database.DB.Unscoped().First(&collection, asset.CollectionID).Error; err == nil {
+		metadata.Collection = &MetadataCollection{
+			ID:          collection.ID,
+			Name:        collection.Name,
+			Description: collection.Description,
+			Image:       collection.CoverImage,
+		}
+	}
+
+	return metadata, nil
+}
+
+// metadataAttributes 将属性按类型排序输出，并追加编号属性
+func metadataAttributes(traits map[string]string, instanceNo, totalSupply int) []MetadataAttribute {
+	types := make([]string, 0, len(traits))
+	for traitType := range traits {
+		types = append(types, traitType)
+	}
+	sort.Strings(types)
+
+	attributes := make([]MetadataAttribute, 0, len(types)+1)
+	for _, traitType := range types {
+		attributes = append(attributes, MetadataAttribute{TraitType: traitType, Value: traits[traitType]})
+	}
+	return append(attributes, MetadataAttribute{
+		DisplayType: "number",
+		TraitType:   "编号",
+		Value:       instanceNo,
+		MaxValue:    totalSupply,
+	})
+}
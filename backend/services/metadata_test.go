@@ -0,0 +1,20 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMetadataAttributes 测试元数据属性排序及编号属性
+func TestMetadataAttributes(t *testing.T) {
+	attributes := metadataAttributes(map[string]string{"背景": "星空", "表情": "微笑"}, 7, 100)
+	assert.Equal(t, []MetadataAttribute{
+		{TraitType: "背景", Value: "星空"},
+		{TraitType: "表情", Value: "微笑"},
+		{DisplayType: "number", TraitType: "编号", Value: 7, MaxValue: 100},
+	}, attributes)
+
+	attributes = metadataAttributes(map[string]string{}, 1, 1)
+	assert.Len(t, attributes, 1)
+}
@@ -0,0 +1,375 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// 指标时间序列查询的默认及最大跨度（天）
+const (
+	metricsDefaultDays = 30
+	metricsMaxDays     = 366
+)
+
+// metricsDateLayout 统计日期格式
+const metricsDateLayout = "2006-01-02"
+
+// MetricsService 定义平台指标快照服务接口
+type MetricsService struct{}
+
+// NewMetricsService 创建一个新的MetricsService实例
+func NewMetricsService() *MetricsService {
+	return &MetricsService{}
+}
+
+// EnsureSchema 为已有数据库补建指标快照表，并将存量指标改为可空、积分总额更名为持有总额（由 cmd/migrate 调用）
+func (s *MetricsService) EnsureSchema() error {
+	m := database.DB.Migrator()
+	if !m.HasTable(&models.MetricSnapshot{}) {
+		return m.CreateTable(&models.MetricSnapshot{})
+	}
+	if m.HasColumn(&models.MetricSnapshot{}, "points_issued") {
+		if err := m.RenameColumn(&models.MetricSnapshot{}, "points_issued", "PointsHeld"); err != nil {
+			return err
+		}
+	}
+	for _, field := range []string{"PointsHeld", "PointsCirculating", "PointsFrozen", "ActiveHolders"} {
+		if err := m.AlterColumn(&models.MetricSnapshot{}, field); err != nil {
+			return err
+		}
+	}
+	if !m.HasColumn(&models.MetricSnapshot{}, "StockCapturedAt") {
+		if err := m.AddColumn(&models.MetricSnapshot{}, "StockCapturedAt"); err != nil {
+			return err
+		}
+		// 历史快照的存量指标均在生成时采集
+		return database.DB.Model(&models.MetricSnapshot{}).Where("stock_captured_at IS NULL").
+			UpdateColumn("stock_captured_at", gorm.Expr("created_at")).Error
+	}
+	return nil
+}
+
+// RunDailyMetrics 补齐截至昨天缺失的指标快照（由后台任务定时调用）
+// 从首份快照的日期起逐日检查，最多回溯 metricsMaxDays 天；尚无快照时只生成昨天
+func (s *MetricsService) RunDailyMetrics() error {
+	now := time.Now()
+	dates, err := missingMetricDates(now)
+	if err != nil {
+		return err
+	}
+	for _, date := range dates {
+		if _, err := s.createSnapshot(date, now); err != nil {
+			return fmt.Errorf("生成 %s 指标快照失败: %w", date, err)
+		}
+	}
+	return nil
+}
+
+// missingMetricDates 返回需要补生成快照的日期，按日期升序
+func missingMetricDates(now time.Time) ([]string, error) {
+	yesterday := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -1)
+	start := yesterday
+	var first models.MetricSnapshot
+	err := database.DB.Order("snapshot_date asc").First(&first).Error
+	if err == nil {
+		firstDate, err := time.ParseInLocation(metricsDateLayout, first.SnapshotDate, now.Location())
+		if err != nil {
+			return nil, err
+		}
+		start = firstDate
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if earliest := yesterday.AddDate(0, 0, -(metricsMaxDays - 1)); start.Before(earliest) {
+		start = earliest
+	}
+
+	var existing []string
+	if err := database.DB.Model(&models.MetricSnapshot{}).
+		Where("snapshot_date >= ? AND snapshot_date <= ?", start.Format(metricsDateLayout), yesterday.Format(metricsDateLayout)).
+		Pluck("snapshot_date", &existing).Error; err != nil {
+		return nil, err
+	}
+	return metricDatesBetween(start, yesterday, existing), nil
+}
+
+// metricDatesBetween 列出 [start, end] 内不在 existing 中的日期
+func metricDatesBetween(start, end time.Time, existing []string) []string {
+	done := make(map[string]bool, len(existing))
+	for _, date := range existing {
+		done[date] = true
+	}
+	var dates []string
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		if date := day.Format(metricsDateLayout); !done[date] {
+			dates = append(dates, date)
+		}
+	}
+	return dates
+}
+
+// CreateSnapshot 汇总指定日期的平台指标并保存，以社区事件公示指标哈希
+func (s *MetricsService) CreateSnapshot(date string) (*models.MetricSnapshot, error) {
+	return s.createSnapshot(date, time.Now())
+}
+
+func (s *MetricsService) createSnapshot(date string, now time.Time) (*models.MetricSnapshot, error) {
+	start, err := time.ParseInLocation(metricsDateLayout, date, now.Location())
+	if err != nil {
+		return nil, errors.New("日期格式错误，应为 YYYY-MM-DD")
+	}
+	end := start.AddDate(0, 0, 1)
+	if end.After(now) {
+		return nil, errors.New("只能为已结束的日期生成指标快照")
+	}
+
+	var snapshot models.MetricSnapshot
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		snapshot = models.MetricSnapshot{SnapshotDate: date}
+		// 存量指标只能取当前值，仅在统计日期刚结束（次日内）时采集，回补的历史日期留空
+		if now.Before(end.AddDate(0, 0, 1)) {
+			if err := collectStockMetricsTx(tx, &snapshot, now); err != nil {
+				return err
+			}
+		}
+		if err := collectPlatformBalanceTx(tx, &snapshot, end); err != nil {
+			return err
+		}
+		if err := collectDailyMetricsTx(tx, &snapshot, start, end); err != nil {
+			return err
+		}
+
+		snapshot.Hash = metricSnapshotHash(&snapshot)
+		if err := tx.Create(&snapshot).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return errors.New("该日期的指标快照已生成")
+			}
+			return err
+		}
+
+		description := fmt.Sprintf("平台指标快照 %s：指标哈希 %s", date, snapshot.Hash)
+		event, err := recordEvent(tx, "metrics_snapshot", 0, description, snapshot.ID, "metric_snapshot")
+		if err != nil {
+			return err
+		}
+		snapshot.EventID = &event.ID
+		return tx.Model(&snapshot).Update("event_id", event.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// collectStockMetricsTx 采集当前的存量指标：用户持有积分、持有人数
+func collectStockMetricsTx(tx *gorm.DB, snapshot *models.MetricSnapshot, now time.Time) error {
+	var points struct {
+		Balance decimal.Decimal
+		Frozen  decimal.Decimal
+	}
+	if err := tx.Model(&models.UserPoint{}).
+		Select("COALESCE(SUM(balance), 0) AS balance, COALESCE(SUM(frozen), 0) AS frozen").
+		Scan(&points).Error; err != nil {
+		return err
+	}
+
+	var holders int64
+	if err := tx.Model(&models.AssetInstance{}).
+		Where("status <> ? AND owner_id <> 0", "burned").
+		Distinct("owner_id").Count(&holders).Error; err != nil {
+		return err
+	}
+
+	// 采集时间取整到秒，保证入库后仍可按公示值复算哈希
+	capturedAt := now.Truncate(time.Second)
+	snapshot.PointsHeld = decimal.NewNullDecimal(points.Balance)
+	snapshot.PointsFrozen = decimal.NewNullDecimal(points.Frozen)
+	snapshot.PointsCirculating = decimal.NewNullDecimal(points.Balance.Sub(points.Frozen))
+	snapshot.ActiveHolders = &holders
+	snapshot.StockCapturedAt = &capturedAt
+	return nil
+}
+
+// collectPlatformBalanceTx 取统计日期结束前最后一笔平台账户流水的记账后余额
+func collectPlatformBalanceTx(tx *gorm.DB, snapshot *models.MetricSnapshot, end time.Time) error {
+	var last models.PlatformTransaction
+	err := tx.Where("created_at < ?", end).Order("created_at desc, id desc").First(&last).Error
+	if err == nil {
+		snapshot.PlatformBalance = last.BalanceAfter
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	return nil
+}
+
+// collectDailyMetricsTx 汇总 [start, end) 内的流量指标，交易按成交时间统计
+func collectDailyMetricsTx(tx *gorm.DB, snapshot *models.MetricSnapshot, start, end time.Time) error {
+	var trades struct {
+		Count  int64
+		Volume decimal.Decimal
+	}
+	if err := tx.Model(&models.Trade{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(price), 0) AS volume").
+		Where("status = ? AND completed_at >= ? AND completed_at < ?", "completed", start, end).
+		Scan(&trades).Error; err != nil {
+		return err
+	}
+	snapshot.TradeCount = trades.Count
+	snapshot.TradeVolume = trades.Volume
+
+	if err := tx.Model(&models.PlatformTransaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("type = ? AND created_at >= ? AND created_at < ?", "fee", start, end).
+		Scan(&snapshot.FeesCollected).Error; err != nil {
+		return err
+	}
+
+	// 版税 = 二级交易中付给创作者的版税（平台藏品的版税计入平台分成，不计在内）+ 已支付的上游衍生版税
+	var creatorRoyalty, parentRoyalty decimal.Decimal
+	if err := tx.Table("trades t").
+		Select("COALESCE(SUM(t.creator_royalty), 0)").
+		Joins("JOIN asset_instances ai ON ai.id = t.asset_instance_id").
+		Joins("JOIN assets a ON a.id = ai.asset_id").
+		Where("t.deleted_at IS NULL AND t.status = ? AND t.completed_at >= ? AND t.completed_at < ? AND a.creator_id <> 0", "completed", start, end).
+		Scan(&creatorRoyalty).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.PointTransaction{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("related_type = ? AND created_at >= ? AND created_at < ?", "royalty_payout", start, end).
+		Scan(&parentRoyalty).Error; err != nil {
+		return err
+	}
+	snapshot.RoyaltiesPaid = creatorRoyalty.Add(parentRoyalty)
+
+	if err := tx.Table("point_transactions pt").
+		Select("COALESCE(SUM(pt.amount), 0)").
+		Joins("JOIN community_events ce ON ce.related_id = pt.id AND ce.related_type = ? AND ce.event_type = ?", "point_transaction", "airdrop_points").
+		Where("pt.deleted_at IS NULL AND pt.created_at >= ? AND pt.created_at < ?", start, end).
+		Scan(&snapshot.AirdropPoints).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.OwnershipRecord{}).
+		Where("event_type = ? AND created_at >= ? AND created_at < ?", ProvenanceAirdrop, start, end).
+		Count(&snapshot.AirdropAssets).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.User{}).
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Count(&snapshot.NewUsers).Error
+}
+
+// metricSnapshotHash 指标哈希：SHA256(规范化JSON)，金额统一保留8位小数，字段顺序固定，未采集的存量指标为 null
+// 任何人可按公示的指标值重新计算并与社区事件中的哈希比对
+func metricSnapshotHash(snapshot *models.MetricSnapshot) string {
+	var capturedAt *int64
+	if snapshot.StockCapturedAt != nil {
+		unix := snapshot.StockCapturedAt.Unix()
+		capturedAt = &unix
+	}
+	payload, _ := json.Marshal(struct {
+		SnapshotDate      string  `json:"snapshot_date"`
+		PointsHeld        *string `json:"points_held"`
+		PointsCirculating *string `json:"points_circulating"`
+		PointsFrozen      *string `json:"points_frozen"`
+		ActiveHolders     *int64  `json:"active_holders"`
+		StockCapturedAt   *int64  `json:"stock_captured_at"`
+		PlatformBalance   string  `json:"platform_balance"`
+		TradeVolume       string  `json:"trade_volume"`
+		TradeCount        int64   `json:"trade_count"`
+		FeesCollected     string  `json:"fees_collected"`
+		RoyaltiesPaid     string  `json:"royalties_paid"`
+		AirdropPoints     string  `json:"airdrop_points"`
+		AirdropAssets     int64   `json:"airdrop_assets"`
+		NewUsers          int64   `json:"new_users"`
+	}{
+		SnapshotDate:      snapshot.SnapshotDate,
+		PointsHeld:        nullDecimalFixed(snapshot.PointsHeld),
+		PointsCirculating: nullDecimalFixed(snapshot.PointsCirculating),
+		PointsFrozen:      nullDecimalFixed(snapshot.PointsFrozen),
+		ActiveHolders:     snapshot.ActiveHolders,
+		StockCapturedAt:   capturedAt,
+		PlatformBalance:   snapshot.PlatformBalance.StringFixed(8),
+		TradeVolume:       snapshot.TradeVolume.StringFixed(8),
+		TradeCount:        snapshot.TradeCount,
+		FeesCollected:     snapshot.FeesCollected.StringFixed(8),
+		RoyaltiesPaid:     snapshot.RoyaltiesPaid.StringFixed(8),
+		AirdropPoints:     snapshot.AirdropPoints.StringFixed(8),
+		AirdropAssets:     snapshot.AirdropAssets,
+		NewUsers:          snapshot.NewUsers,
+	})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// nullDecimalFixed 可空金额的哈希表示，未采集时为 nil
+func nullDecimalFixed(value decimal.NullDecimal) *string {
+	if !value.Valid {
+		return nil
+	}
+	fixed := value.Decimal.StringFixed(8)
+	return &fixed
+}
+
+// metricsDateRange 解析时间序列查询区间，缺省为截至昨天的最近30天
+func metricsDateRange(from, to string, now time.Time) (string, string, error) {
+	toDate := now.AddDate(0, 0, -1)
+	if to != "" {
+		parsed, err := time.ParseInLocation(metricsDateLayout, to, time.Local)
+		if err != nil {
+			return "", "", errors.New("结束日期格式错误，应为 YYYY-MM-DD")
+		}
+		toDate = parsed
+	}
+	fromDate := toDate.AddDate(0, 0, -(metricsDefaultDays - 1))
+	if from != "" {
+		parsed, err := time.ParseInLocation(metricsDateLayout, from, time.Local)
+		if err != nil {
+			return "", "", errors.New("开始日期格式错误，应为 YYYY-MM-DD")
+		}
+		fromDate = parsed
+	}
+
+	if fromDate.After(toDate) {
+		return "", "", errors.New("开始日期不能晚于结束日期")
+	}
+	if toDate.Sub(fromDate) >= metricsMaxDays*24*time.Hour {
+		return "", "", fmt.Errorf("查询区间不能超过%d天", metricsMaxDays)
+	}
+	return fromDate.Format(metricsDateLayout), toDate.Format(metricsDateLayout), nil
+}
+
+// ListMetrics 获取指定日期区间的指标时间序列，按日期升序（公开）
+func (s *MetricsService) ListMetrics(from, to string) ([]models.MetricSnapshot, string, string, error) {
+	fromDate, toDate, err := metricsDateRange(from, to, time.Now())
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	var snapshots []models.MetricSnapshot
+	if err := database.DB.Where("snapshot_date >= ? AND snapshot_date <= ?", fromDate, toDate).
+		Order("snapshot_date asc").Find(&snapshots).Error; err != nil {
+		return nil, "", "", err
+	}
+	return snapshots, fromDate, toDate, nil
+}
+
+// GetLatestMetrics 获取最新一天的指标快照（公开）
+func (s *MetricsService) GetLatestMetrics() (*models.MetricSnapshot, error) {
+	var snapshot models.MetricSnapshot
+	if err := database.DB.Order("snapshot_date desc").First(&snapshot).Error; err != nil {
+		return nil, errors.New("暂无指标快照")
+	}
+	return &snapshot, nil
+}
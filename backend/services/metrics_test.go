@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"hoho-miniapp/backend/models"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMetricSnapshotHash 测试指标哈希与金额小数位无关，且随指标值变化
+func TestMetricSnapshotHash(t *testing.T) {
+	snapshot := &models.MetricSnapshot{
+		SnapshotDate:  "2026-01-02",
+		PointsHeld:    decimal.NewNullDecimal(decimal.RequireFromString("1000.5")),
+		TradeVolume:   decimal.RequireFromString("88"),
+		TradeCount:    3,
+		FeesCollected: decimal.RequireFromString("2.2"),
+	}
+	reloaded := *snapshot
+	reloaded.PointsHeld = decimal.NewNullDecimal(decimal.RequireFromString("1000.50000000"))
+	reloaded.TradeVolume = decimal.RequireFromString("88.00000000")
+	assert.Equal(t, metricSnapshotHash(snapshot), metricSnapshotHash(&reloaded))
+	assert.Len(t, metricSnapshotHash(snapshot), 64)
+
+	reloaded.TradeCount = 4
+	assert.NotEqual(t, metricSnapshotHash(snapshot), metricSnapshotHash(&reloaded))
+
+	// 未采集的存量指标与采集到的零值不同
+	missing := *snapshot
+	missing.PointsHeld = decimal.NullDecimal{}
+	zero := *snapshot
+	zero.PointsHeld = decimal.NewNullDecimal(decimal.Zero)
+	assert.NotEqual(t, metricSnapshotHash(&missing), metricSnapshotHash(&zero))
+}
+
+// TestMetricDatesBetween 测试补生成快照时跳过已有日期并跨月逐日列出
+func TestMetricDatesBetween(t *testing.T) {
+	start := time.Date(2026, 2, 27, 0, 0, 0, 0, time.Local)
+	end := time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)
+
+	dates := metricDatesBetween(start, end, []string{"2026-02-28", "2026-03-02"})
+	assert.Equal(t, []string{"2026-02-27", "2026-03-01"}, dates)
+
+	assert.Empty(t, metricDatesBetween(start, end, []string{"2026-02-27", "2026-02-28", "2026-03-01", "2026-03-02"}))
+	assert.Equal(t, []string{"2026-03-02"}, metricDatesBetween(end, end, nil))
+}
+
+// TestMetricsDateRange 测试时间序列查询区间的缺省值与校验
+func TestMetricsDateRange(t *testing.T) {
+	now := time.Date(2026, 3, 15, 10, 0, 0, 0, time.Local)
+
+	from, to, err := metricsDateRange("", "", now)
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-02-13", from)
+	assert.Equal(t, "2026-03-14", to)
+
+	from, to, err = metricsDateRange("2026-03-01", "2026-03-01", now)
+	assert.NoError(t, err)
+	assert.Equal(t, "2026-03-01", from)
+	assert.Equal(t, "2026-03-01", to)
+
+	_, _, err = metricsDateRange("2026-03-10", "2026-03-01", now)
+	assert.Error(t, err)
+	_, _, err = metricsDateRange("2025-01-01", "2026-03-01", now)
+	assert.Error(t, err)
+	_, _, err = metricsDateRange("2026/03/01", "", now)
+	assert.Error(t, err)
+}
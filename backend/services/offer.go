@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"time"
 
+	"hoho-miniapp/backend/config"
 	"hoho-miniapp/backend/database"
 	"hoho-miniapp/backend/models"
+	"hoho-miniapp/backend/utils"
 	
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
 )
 
 type OfferService struct{}
@@ -18,26 +21,39 @@ func NewOfferService() *OfferService {
 }
 
 // CreateOffer 创建出价
-func (s *OfferService) CreateOffer(buyerID, artworkInstanceID uint, price string) (*models.Offer, error) {
-	// 检查作品实例是否存在
-	var instance models.ArtworkInstance
-	if err := database.DB.Preload("Artwork").Preload("Owner").First(&instance, artworkInstanceID).Error; err != nil {
-		return nil, errors.New("作品不存在")
+func (s *OfferService) CreateOffer(buyerID uint, assetInstanceID uint64, price string) (*models.Offer, error) {
+	// 检查藏品实例是否存在
+	var instance models.AssetInstance
+	if err := database.DB.Preload("Asset").First(&instance, assetInstanceID).Error; err != nil {
+		return nil, errors.New("藏品不存在")
 	}
 	
-	// 检查是否是自己的作品
-	if instance.OwnerID == buyerID {
-		return nil, errors.New("不能对自己的作品出价")
+	// 检查是否是自己的藏品
+	if instance.OwnerID == uint64(buyerID) {
+		return nil, errors.New("不能对自己的藏品出价")
 	}
 	
-	// 检查作品状态
-	if instance.Status == "listed" {
-		return nil, errors.New("该作品正在挂售中，请直接购买")
+	// 检查藏品状态
+	if instance.Status == "on_sale" {
+		return nil, errors.New("该藏品正在挂售中，请直接购买")
+	}
+	if instance.Status == "burned" {
+		return nil, errors.New("该藏品已销毁")
+	}
+	
+	priceDecimal, err := decimal.NewFromString(price)
+	if err != nil || priceDecimal.LessThanOrEqual(decimal.Zero) {
+		return nil, errors.New("出价金额无效")
 	}
 	
-	// TODO: 实现积分检查和冻结逻辑
-	priceDecimal, _ := decimal.NewFromString(price)
-	_ = priceDecimal
+	// 出价时不冻结积分，仅检查可用积分；卖家接受时再扣减买家积分
+	var buyerPoints models.UserPoint
+	if err := database.DB.Where("user_id = ?", buyerID).First(&buyerPoints).Error; err != nil {
+		return nil, errors.New("买家积分信息不存在")
+	}
+	if buyerPoints.Balance.Sub(buyerPoints.Frozen).LessThan(priceDecimal) {
+		return nil, errors.New("可用积分不足")
+	}
 	
 	// 获取出价有效期配置
 	var config models.SystemConfig
@@ -50,11 +66,11 @@ func (s *OfferService) CreateOffer(buyerID, artworkInstanceID uint, price string
 	
 	// 创建出价
 	offer := &models.Offer{
-		BuyerID:           buyerID,
-		ArtworkInstanceID: artworkInstanceID,
-		Price:             price,
-		Status:            "pending",
-		ExpiresAt:         &expiresAt,
+		BuyerID:         buyerID,
+		AssetInstanceID: assetInstanceID,
+		Price:           price,
+		Status:          "pending",
+		ExpiresAt:       &expiresAt,
 	}
 	
 	if err := database.DB.Create(offer).Error; err != nil {
@@ -63,10 +79,10 @@ func (s *OfferService) CreateOffer(buyerID, artworkInstanceID uint, price string
 	
 	// 发送通知给卖家
 	notification := &models.Notification{
-		UserID:    instance.OwnerID,
+		UserID:    uint(instance.OwnerID),
 		Type:      "offer",
 		Title:     "收到新出价",
-		Content:   fmt.Sprintf("您的作品《%s》收到了 %s 积分的出价", instance.Artwork.Title, price),
+		Content:   fmt.Sprintf("您的藏品《%s》收到了 %s 积分的出价", instance.Asset.Name, price),
 		RelatedID: &offer.ID,
 	}
 	database.DB.Create(notification)
@@ -79,7 +95,7 @@ func (s *OfferService) GetUserOffers(userID uint, status string, page, pageSize
 	var offers []models.Offer
 	var total int64
 	
-	query := database.DB.Where("buyer_id = ?", userID).Preload("ArtworkInstance.Artwork")
+	query := database.DB.Where("buyer_id = ?", userID).Preload("AssetInstance.Asset")
 	
 	if status != "" {
 		query = query.Where("status = ?", status)
@@ -100,9 +116,9 @@ func (s *OfferService) GetReceivedOffers(ownerID uint, status string, page, page
 	var offers []models.Offer
 	var total int64
 	
-	query := database.DB.Joins("JOIN artwork_instances ON offers.artwork_instance_id = artwork_instances.id").
-		Where("artwork_instances.owner_id = ?", ownerID).
-		Preload("ArtworkInstance.Artwork").
+	query := database.DB.Joins("JOIN asset_instances ON offers.asset_instance_id = asset_instances.id").
+		Where("asset_instances.owner_id = ?", ownerID).
+		Preload("AssetInstance.Asset").
 		Preload("Buyer")
 	
 	if status != "" {
@@ -123,7 +139,7 @@ func (s *OfferService) GetReceivedOffers(ownerID uint, status string, page, page
 func (s *OfferService) AcceptOffer(offerID, sellerID uint) error {
 	// 获取出价信息
 	var offer models.Offer
-	if err := database.DB.Preload("ArtworkInstance.Artwork").Preload("Buyer").First(&offer, offerID).Error; err != nil {
+	if err := database.DB.Preload("AssetInstance.Asset").Preload("Buyer").First(&offer, offerID).Error; err != nil {
 		return err
 	}
 	
@@ -138,10 +154,16 @@ func (s *OfferService) AcceptOffer(offerID, sellerID uint) error {
 		return errors.New("出价已过期")
 	}
 	
-	// 检查是否是作品所有者
-	if offer.ArtworkInstance.OwnerID != sellerID {
+	// 检查是否是藏品所有者
+	if offer.AssetInstance == nil || offer.AssetInstance.OwnerID != uint64(sellerID) {
 		return errors.New("无权接受该出价")
 	}
+	if offer.AssetInstance.Asset == nil {
+		return errors.New("藏品不存在")
+	}
+	if offer.AssetInstance.Status != "in_wallet" {
+		return errors.New("藏品当前状态不可交易")
+	}
 	
 	// 开始交易
 	tx := database.DB.Begin()
@@ -159,28 +181,145 @@ func (s *OfferService) AcceptOffer(offerID, sellerID uint) error {
 		feeRate, _ = decimal.NewFromString(feeConfig.Value)
 	}
 	
-	fee := price.Mul(feeRate).Div(decimal.NewFromInt(100))
+	fee := price.Mul(feeRate).Div(decimal.NewFromInt(100)).RoundBank(8)
 	
-	// 转移作品所有权
-	if err := tx.Model(&models.ArtworkInstance{}).Where("id = ?", offer.ArtworkInstanceID).
-		Update("owner_id", offer.BuyerID).Error; err != nil {
+	// 转移藏品所有权，以持有者和状态作为条件防止并发转移
+	result := tx.Model(&models.AssetInstance{}).
+		Where("id = ? AND owner_id = ? AND status = ?", offer.AssetInstanceID, sellerID, "in_wallet").
+		Update("owner_id", offer.BuyerID)
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return errors.New("藏品当前状态不可交易")
+	}
+	
+	// 买家：以可用积分（余额-冻结）为条件扣减，防止并发超额扣减
+	result = tx.Model(&models.UserPoint{}).
+		Where("user_id = ? AND balance - frozen >= ?", offer.BuyerID, price).
+		Updates(map[string]interface{}{
+			"balance":     gorm.Expr("balance - ?", price),
+			"total_spent": gorm.Expr("total_spent + ?", price),
+		})
+	if result.Error != nil {
+		tx.Rollback()
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		tx.Rollback()
+		return errors.New("买家可用积分不足")
+	}
+	
+	// 版税：与挂售成交一致，创作者版税加上衍生作品的上游分成，均由卖家承担
+	creatorRoyalty := price.Mul(config.AppConfig.CreatorRoyaltyRate).RoundBank(config.AppConfig.DecimalPrecision)
+	royaltyShares, parentRoyalty, err := upstreamRoyaltyTx(tx, offer.AssetInstance.AssetID, price)
+	if err != nil {
 		tx.Rollback()
 		return err
 	}
+	sellerReceived := price.Sub(fee).Sub(creatorRoyalty).Sub(parentRoyalty)
+	if sellerReceived.LessThan(decimal.Zero) {
+		tx.Rollback()
+		return errors.New("出价金额不足以支付手续费和版税")
+	}
 	
 	// 创建交易记录
-	sellerReceived := price.Sub(fee)
+	completedAt := time.Now()
 	trade := &models.Trade{
-		AssetInstanceID: uint64(offer.ArtworkInstanceID),
+		TradeNo:         utils.GenerateOrderNo("TR"),
+		AssetInstanceID: offer.AssetInstanceID,
 		SellerID:        uint64(sellerID),
 		BuyerID:         uint64(offer.BuyerID),
 		Price:           price,
 		PlatformFee:     fee,
-		CreatorRoyalty:  decimal.Zero,
+		CreatorRoyalty:  creatorRoyalty,
+		ParentRoyalty:   parentRoyalty,
 		SellerReceived:  sellerReceived,
 		Status:          "completed",
+		CompletedAt:     &completedAt,
+	}
+	if err := createWithRetry(tx, trade, "trade_no", func() { trade.TradeNo = utils.GenerateOrderNo("TR") }); err != nil {
+		tx.Rollback()
+		return err
+	}
+	
+	// 卖家：增加积分
+	if err := tx.Model(&models.UserPoint{}).Where("user_id = ?", sellerID).Updates(map[string]interface{}{
+		"balance":      gorm.Expr("balance + ?", sellerReceived),
+		"total_earned": gorm.Expr("total_earned + ?", sellerReceived),
+	}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	
+	// 买卖双方积分流水
+	if err := tx.Create(&[]models.PointTransaction{
+		{
+			UserID:      uint64(offer.BuyerID),
+			Type:        "spend",
+			Amount:      price,
+			Description: fmt.Sprintf("出价购买藏品 %s", offer.AssetInstance.TokenID),
+			RelatedID:   trade.ID,
+			RelatedType: "trade",
+		},
+		{
+			UserID:      uint64(sellerID),
+			Type:        "earn",
+			Amount:      sellerReceived,
+			Description: fmt.Sprintf("接受出价出售藏品 %s", offer.AssetInstance.TokenID),
+			RelatedID:   trade.ID,
+			RelatedType: "trade",
+		},
+	}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	
+	// 创作者：增加版税，平台藏品的版税计入平台分成
+	creatorID := offer.AssetInstance.Asset.CreatorID
+	if creatorID == 0 {
+		if err := NewPlatformAccountService().RecordPlatformIncomeTx(tx, "commission", creatorRoyalty,
+			fmt.Sprintf("交易 %s 平台藏品版税", trade.TradeNo), trade.ID, "trade"); err != nil {
+			tx.Rollback()
+			return err
+		}
+	} else if creatorRoyalty.GreaterThan(decimal.Zero) {
+		if err := tx.Model(&models.UserPoint{}).Where("user_id = ?", creatorID).Updates(map[string]interface{}{
+			"balance":      gorm.Expr("balance + ?", creatorRoyalty),
+			"total_earned": gorm.Expr("total_earned + ?", creatorRoyalty),
+		}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Create(&models.PointTransaction{
+			UserID:      creatorID,
+			Type:        "earn",
+			Amount:      creatorRoyalty,
+			Description: fmt.Sprintf("藏品 %s 交易版税", offer.AssetInstance.TokenID),
+			RelatedID:   trade.ID,
+			RelatedType: "trade",
+		}).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	
+	// 上游创作者：衍生作品版税，成交即入账
+	if err := createRoyaltyPayoutsTx(tx, RoyaltySourceTrade, trade.ID, offer.AssetInstance.AssetID, royaltyShares, true); err != nil {
+		tx.Rollback()
+		return err
 	}
-	if err := tx.Create(trade).Error; err != nil {
+	
+	// 平台：记入交易手续费
+	if err := NewPlatformAccountService().RecordPlatformIncomeTx(tx, "fee", fee,
+		fmt.Sprintf("交易 %s 手续费", trade.TradeNo), trade.ID, "trade"); err != nil {
+		tx.Rollback()
+		return err
+	}
+	
+	if err := enqueueAnchorTx(tx, offer.AssetInstance, ChainOpTransfer, uint64(sellerID), uint64(offer.BuyerID)); err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -195,14 +334,44 @@ func (s *OfferService) AcceptOffer(offerID, sellerID uint) error {
 		return err
 	}
 	
-	tx.Commit()
+	// 最后记录社区事件和所有权变更
+	sellerUID, err := eventUserTx(tx, uint64(sellerID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	buyerUID, err := eventUserTx(tx, uint64(offer.BuyerID))
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	var events eventBatch
+	description := fmt.Sprintf("用户 %s 接受出价，以 %s 积分将藏品 %s 出售给用户 %s", sellerUID, price.String(), offer.AssetInstance.TokenID, buyerUID)
+	events.add("trade", uint64(offer.BuyerID), description, trade.ID, "trade", models.OwnershipRecord{
+		AssetInstanceID: offer.AssetInstanceID,
+		TokenID:         offer.AssetInstance.TokenID,
+		EventType:       ProvenanceOffer,
+		FromUserID:      uint64(sellerID),
+		ToUserID:        uint64(offer.BuyerID),
+		Price:           &price,
+		RelatedID:       trade.ID,
+		RelatedType:     "trade",
+	})
+	if err := events.recordTx(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	
+	if err := tx.Commit().Error; err != nil {
+		return err
+	}
 	
 	// 发送通知
 	buyerNotification := &models.Notification{
 		UserID:    offer.BuyerID,
 		Type:      "offer",
 		Title:     "出价已接受",
-		Content:   fmt.Sprintf("您对作品《%s》的出价已被接受", offer.ArtworkInstance.Artwork.Title),
+		Content:   fmt.Sprintf("您对藏品《%s》的出价已被接受", offer.AssetInstance.Asset.Name),
 		RelatedID: &offerID,
 	}
 	database.DB.Create(buyerNotification)
@@ -214,7 +383,7 @@ func (s *OfferService) AcceptOffer(offerID, sellerID uint) error {
 func (s *OfferService) RejectOffer(offerID, sellerID uint) error {
 	// 获取出价信息
 	var offer models.Offer
-	if err := database.DB.Preload("ArtworkInstance").First(&offer, offerID).Error; err != nil {
+	if err := database.DB.Preload("AssetInstance").First(&offer, offerID).Error; err != nil {
 		return err
 	}
 	
@@ -223,8 +392,8 @@ func (s *OfferService) RejectOffer(offerID, sellerID uint) error {
 		return errors.New("出价已处理")
 	}
 	
-	// 检查是否是作品所有者
-	if offer.ArtworkInstance.OwnerID != sellerID {
+	// 检查是否是藏品所有者
+	if offer.AssetInstance == nil || offer.AssetInstance.OwnerID != uint64(sellerID) {
 		return errors.New("无权拒绝该出价")
 	}
 	
@@ -0,0 +1,387 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"gorm.io/gorm"
+)
+
+// MerkleProofStep 包含证明中的一个兄弟节点，Position 表示兄弟节点位于左侧还是右侧
+type MerkleProofStep struct {
+	Hash     string `json:"hash"`
+	Position string `json:"position"` // left, right
+}
+
+// OwnershipProof 持有者在某次快照中的包含证明
+// 校验方法：leaf_hash = SHA256(0x00 || "token_id|owner_uid|status")，
+// 依次与 proof 中的兄弟节点计算 SHA256(0x01 || 左 || 右)，结果应等于 merkle_root；
+// 再用平台公布的公钥（OWNERSHIP_SNAPSHOT_PUBLIC_KEY，可从 /ownership-snapshots/public-key 获取）对 signed_message 校验 signature（Ed25519）
+type OwnershipProof struct {
+	SnapshotID    uint64            `json:"snapshot_id"`
+	SnapshotDate  string            `json:"snapshot_date"`
+	MerkleRoot    string            `json:"merkle_root"`
+	LeafCount     int               `json:"leaf_count"`
+	Signature     string            `json:"signature"`
+	PublicKey     string            `json:"public_key"`
+	SignedMessage string            `json:"signed_message"`
+	EventID       *uint64           `json:"event_id"`
+	LeafIndex     int               `json:"leaf_index"`
+	TokenID       string            `json:"token_id"`
+	OwnerUID      string            `json:"owner_uid"`
+	Status        string            `json:"status"`
+	LeafHash      string            `json:"leaf_hash"`
+	Proof         []MerkleProofStep `json:"proof"`
+}
+
+// snapshotSigningKeyEnv 快照签名密钥的环境变量，值为32字节Ed25519种子的hex编码
+const snapshotSigningKeyEnv = "OWNERSHIP_SNAPSHOT_SIGNING_KEY"
+
+// snapshotPublicKeyEnv 平台公布的快照验签公钥（32字节hex），与白皮书等渠道公布的值一致
+// 验签只使用该公钥而不使用快照记录中的公钥，篡改数据库无法替换验签公钥
+const snapshotPublicKeyEnv = "OWNERSHIP_SNAPSHOT_PUBLIC_KEY"
+
+// OwnershipSnapshotService 定义所有权快照服务接口
+type OwnershipSnapshotService struct{}
+
+// NewOwnershipSnapshotService 创建一个新的OwnershipSnapshotService实例
+func NewOwnershipSnapshotService() *OwnershipSnapshotService {
+	return &OwnershipSnapshotService{}
+}
+
+// EnsureSchema 为已有数据库补建快照表（由 cmd/migrate 调用）
+func (s *OwnershipSnapshotService) EnsureSchema() error {
+	m := database.DB.Migrator()
+	for _, model := range []interface{}{&models.OwnershipSnapshot{}, &models.OwnershipSnapshotLeaf{}} {
+		if !m.HasTable(model) {
+			if err := m.CreateTable(model); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// RunDailySnapshot 生成当天的所有权快照，当天已生成或尚无藏品实例时跳过（由后台任务定时调用）
+func (s *OwnershipSnapshotService) RunDailySnapshot() error {
+	date := time.Now().Format("2006-01-02")
+
+	var count int64
+	if err := database.DB.Model(&models.OwnershipSnapshot{}).Where("snapshot_date = ?", date).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	if err := database.DB.Model(&models.AssetInstance{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return nil
+	}
+
+	_, err := s.CreateSnapshot(date)
+	return err
+}
+
+// CreateSnapshot 对全部藏品实例构建Merkle树，保存叶子并以签名社区事件公示根哈希
+func (s *OwnershipSnapshotService) CreateSnapshot(date string) (*models.OwnershipSnapshot, error) {
+	privateKey, err := snapshotSigningKey()
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := SnapshotPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if !publicKey.Equal(privateKey.Public()) {
+		return nil, errors.New("快照签名密钥与公布的公钥不匹配")
+	}
+
+	var snapshot models.OwnershipSnapshot
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		type instanceRow struct {
+			TokenID  string
+			OwnerUID string
+			Status   string
+		}
+		var rows []instanceRow
+		if err := tx.Table("asset_instances AS ai").
+			Select("ai.token_id, COALESCE(u.uid, '') AS owner_uid, ai.status").
+			Joins("LEFT JOIN users u ON u.id = ai.owner_id").
+			Where("ai.deleted_at IS NULL").
+			Scan(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return errors.New("暂无藏品实例")
+		}
+		// 按TokenID字节序排列，保证叶子顺序与数据库排序规则无关
+		sort.Slice(rows, func(i, j int) bool { return rows[i].TokenID < rows[j].TokenID })
+
+		leaves := make([]models.OwnershipSnapshotLeaf, len(rows))
+		hashes := make([][]byte, len(rows))
+		for i, row := range rows {
+			hashes[i] = merkleLeafHash(row.TokenID, row.OwnerUID, row.Status)
+			leaves[i] = models.OwnershipSnapshotLeaf{
+				LeafIndex: i,
+				TokenID:   row.TokenID,
+				OwnerUID:  row.OwnerUID,
+				Status:    row.Status,
+				LeafHash:  hex.EncodeToString(hashes[i]),
+			}
+		}
+
+		root := hex.EncodeToString(merkleRoot(hashes))
+		message := snapshotSignedMessage(date, root, len(leaves))
+		snapshot = models.OwnershipSnapshot{
+			SnapshotDate: date,
+			MerkleRoot:   root,
+			LeafCount:    len(leaves),
+			Signature:    hex.EncodeToString(ed25519.Sign(privateKey, []byte(message))),
+			PublicKey:    hex.EncodeToString(privateKey.Public().(ed25519.PublicKey)),
+		}
+		if err := tx.Create(&snapshot).Error; err != nil {
+			return err
+		}
+
+		for i := range leaves {
+			leaves[i].SnapshotID = snapshot.ID
+		}
+		if err := tx.CreateInBatches(leaves, 1000).Error; err != nil {
+			return err
+		}
+
+		description := fmt.Sprintf("所有权快照 %s：Merkle根 %s，共%d个藏品实例，签名 %s",
+			date, root, len(leaves), snapshot.Signature)
+		event, err := recordEvent(tx, "ownership_snapshot", 0, description, snapshot.ID, "ownership_snapshot")
+		if err != nil {
+			return err
+		}
+		snapshot.EventID = &event.ID
+		return tx.Model(&snapshot).Update("event_id", event.ID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
+// ListSnapshots 获取已公示的快照列表（公开）
+func (s *OwnershipSnapshotService) ListSnapshots(page, pageSize int) ([]models.OwnershipSnapshot, int64, error) {
+	var snapshots []models.OwnershipSnapshot
+	var total int64
+
+	query := database.DB.Model(&models.OwnershipSnapshot{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("id desc").Offset(offset).Limit(pageSize).Find(&snapshots).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return snapshots, total, nil
+}
+
+// GetSnapshot 获取快照详情（公开）
+func (s *OwnershipSnapshotService) GetSnapshot(snapshotID uint64) (*models.OwnershipSnapshot, error) {
+	var snapshot models.OwnershipSnapshot
+	if err := database.DB.First(&snapshot, snapshotID).Error; err != nil {
+		return nil, errors.New("快照不存在")
+	}
+	return &snapshot, nil
+}
+
+// GetUserProof 获取用户在指定快照（snapshotID为0时取最新快照）中持有某藏品实例的包含证明
+func (s *OwnershipSnapshotService) GetUserProof(userID, snapshotID uint64, tokenID string) (*OwnershipProof, error) {
+	var user models.User
+	if err := database.DB.Select("id", "uid").First(&user, userID).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	var snapshot models.OwnershipSnapshot
+	query := database.DB.Model(&models.OwnershipSnapshot{})
+	if snapshotID > 0 {
+		query = query.Where("id = ?", snapshotID)
+	}
+	if err := query.Order("id desc").First(&snapshot).Error; err != nil {
+		return nil, errors.New("快照不存在")
+	}
+
+	var leaf models.OwnershipSnapshotLeaf
+	if err := database.DB.Where("snapshot_id = ? AND token_id = ?", snapshot.ID, tokenID).First(&leaf).Error; err != nil {
+		return nil, errors.New("该快照中不存在此藏品实例")
+	}
+	if leaf.OwnerUID != user.UID {
+		return nil, errors.New("该快照中你不是此藏品实例的持有者")
+	}
+
+	publicKey, err := SnapshotPublicKey()
+	if err != nil {
+		return nil, err
+	}
+	if !verifySnapshotSignature(publicKey, &snapshot) {
+		return nil, errors.New("快照签名无法用公布的公钥校验，拒绝出具证明")
+	}
+
+	var hashHexes []string
+	if err := database.DB.Model(&models.OwnershipSnapshotLeaf{}).
+		Where("snapshot_id = ?", snapshot.ID).
+		Order("leaf_index asc").
+		Pluck("leaf_hash", &hashHexes).Error; err != nil {
+		return nil, err
+	}
+	hashes := make([][]byte, len(hashHexes))
+	for i, h := range hashHexes {
+		b, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, fmt.Errorf("快照叶子%d哈希格式错误", i)
+		}
+		hashes[i] = b
+	}
+
+	return &OwnershipProof{
+		SnapshotID:    snapshot.ID,
+		SnapshotDate:  snapshot.SnapshotDate,
+		MerkleRoot:    snapshot.MerkleRoot,
+		LeafCount:     snapshot.LeafCount,
+		Signature:     snapshot.Signature,
+		PublicKey:     hex.EncodeToString(publicKey),
+		SignedMessage: snapshotSignedMessage(snapshot.SnapshotDate, snapshot.MerkleRoot, snapshot.LeafCount),
+		EventID:       snapshot.EventID,
+		LeafIndex:     leaf.LeafIndex,
+		TokenID:       leaf.TokenID,
+		OwnerUID:      leaf.OwnerUID,
+		Status:        leaf.Status,
+		LeafHash:      leaf.LeafHash,
+		Proof:         merkleProof(hashes, leaf.LeafIndex),
+	}, nil
+}
+
+// snapshotSigningKey 从环境变量读取快照签名私钥
+func snapshotSigningKey() (ed25519.PrivateKey, error) {
+	seed, err := hex.DecodeString(os.Getenv(snapshotSigningKeyEnv))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("未配置快照签名密钥 %s（32字节hex）", snapshotSigningKeyEnv)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// SnapshotPublicKey 读取平台公布的快照验签公钥
+func SnapshotPublicKey() (ed25519.PublicKey, error) {
+	key, err := hex.DecodeString(os.Getenv(snapshotPublicKeyEnv))
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("未配置快照验签公钥 %s（32字节hex）", snapshotPublicKeyEnv)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// verifySnapshotSignature 用公布的公钥校验快照签名，快照记录中的公钥与之不同时同样视为无效
+func verifySnapshotSignature(publicKey ed25519.PublicKey, snapshot *models.OwnershipSnapshot) bool {
+	if snapshot.PublicKey != hex.EncodeToString(publicKey) {
+		return false
+	}
+	signature, err := hex.DecodeString(snapshot.Signature)
+	if err != nil {
+		return false
+	}
+	message := snapshotSignedMessage(snapshot.SnapshotDate, snapshot.MerkleRoot, snapshot.LeafCount)
+	return ed25519.Verify(publicKey, []byte(message), signature)
+}
+
+// snapshotSignedMessage 快照签名的原文
+func snapshotSignedMessage(date, root string, leafCount int) string {
+	return fmt.Sprintf("hoho-ownership-snapshot|%s|%s|%d", date, root, leafCount)
+}
+
+// merkleLeafHash 叶子哈希：SHA256(0x00 || "token_id|owner_uid|status")
+func merkleLeafHash(tokenID, ownerUID, status string) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x00})
+	h.Write([]byte(tokenID + "|" + ownerUID + "|" + status))
+	return h.Sum(nil)
+}
+
+// merkleNodeHash 内部节点哈希：SHA256(0x01 || 左 || 右)，与叶子使用不同前缀防止伪造
+func merkleNodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// merkleNextLevel 计算上一层节点，奇数个时最后一个节点直接晋升而不复制
+func merkleNextLevel(level [][]byte) [][]byte {
+	next := make([][]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		if i+1 == len(level) {
+			next = append(next, level[i])
+			continue
+		}
+		next = append(next, merkleNodeHash(level[i], level[i+1]))
+	}
+	return next
+}
+
+// merkleRoot 计算Merkle根，没有叶子时返回nil
+func merkleRoot(leaves [][]byte) []byte {
+	if len(leaves) == 0 {
+		return nil
+	}
+	level := leaves
+	for len(level) > 1 {
+		level = merkleNextLevel(level)
+	}
+	return level[0]
+}
+
+// merkleProof 生成第index个叶子的包含证明
+func merkleProof(leaves [][]byte, index int) []MerkleProofStep {
+	proof := []MerkleProofStep{}
+	level := leaves
+	for len(level) > 1 {
+		if index%2 == 1 {
+			proof = append(proof, MerkleProofStep{Hash: hex.EncodeToString(level[index-1]), Position: "left"})
+		} else if index+1 < len(level) {
+			proof = append(proof, MerkleProofStep{Hash: hex.EncodeToString(level[index+1]), Position: "right"})
+		}
+		level = merkleNextLevel(level)
+		index /= 2
+	}
+	return proof
+}
+
+// VerifyMerkleProof 校验包含证明，leafHash、proof中的哈希和root均为hex编码
+func VerifyMerkleProof(leafHash string, proof []MerkleProofStep, root string) bool {
+	current, err := hex.DecodeString(leafHash)
+	if err != nil {
+		return false
+	}
+	for _, step := range proof {
+		sibling, err := hex.DecodeString(step.Hash)
+		if err != nil {
+			return false
+		}
+		switch step.Position {
+		case "left":
+			current = merkleNodeHash(sibling, current)
+		case "right":
+			current = merkleNodeHash(current, sibling)
+		default:
+			return false
+		}
+	}
+	return hex.EncodeToString(current) == root
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"hoho-miniapp/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMerkleProof 测试各种叶子数量下每个叶子的包含证明都能校验通过
+func TestMerkleProof(t *testing.T) {
+	for n := 1; n <= 9; n++ {
+		leaves := make([][]byte, n)
+		for i := range leaves {
+			leaves[i] = merkleLeafHash(string(rune('A'+i)), "10001", "in_wallet")
+		}
+		root := hex.EncodeToString(merkleRoot(leaves))
+		for i := range leaves {
+			proof := merkleProof(leaves, i)
+			assert.True(t, VerifyMerkleProof(hex.EncodeToString(leaves[i]), proof, root), "n=%d i=%d", n, i)
+		}
+	}
+}
+
+// TestMerkleProofRejectsTampering 测试篡改叶子或证明后校验失败
+func TestMerkleProofRejectsTampering(t *testing.T) {
+	leaves := [][]byte{
+		merkleLeafHash("T1", "10001", "in_wallet"),
+		merkleLeafHash("T2", "10002", "on_sale"),
+		merkleLeafHash("T3", "10003", "burned"),
+	}
+	root := hex.EncodeToString(merkleRoot(leaves))
+	proof := merkleProof(leaves, 1)
+
+	forged := hex.EncodeToString(merkleLeafHash("T2", "10009", "on_sale"))
+	assert.False(t, VerifyMerkleProof(forged, proof, root))
+
+	proof[0].Position = "right"
+	assert.False(t, VerifyMerkleProof(hex.EncodeToString(leaves[1]), proof, root))
+
+	// 内部节点不能冒充叶子
+	node := hex.EncodeToString(merkleNodeHash(leaves[0], leaves[1]))
+	assert.False(t, VerifyMerkleProof(node, nil, hex.EncodeToString(leaves[0])))
+	assert.Nil(t, merkleRoot(nil))
+}
+
+// TestSnapshotSigningKey 测试签名密钥读取与签名校验
+func TestSnapshotSigningKey(t *testing.T) {
+	t.Setenv(snapshotSigningKeyEnv, "")
+	_, err := snapshotSigningKey()
+	assert.Error(t, err)
+
+	t.Setenv(snapshotSigningKeyEnv, "0101010101010101010101010101010101010101010101010101010101010101")
+	key, err := snapshotSigningKey()
+	assert.NoError(t, err)
+
+	message := []byte(snapshotSignedMessage("2026-01-02", "ab", 3))
+	assert.Equal(t, "hoho-ownership-snapshot|2026-01-02|ab|3", string(message))
+	assert.True(t, ed25519.Verify(key.Public().(ed25519.PublicKey), message, ed25519.Sign(key, message)))
+}
+
+// TestVerifySnapshotSignature 测试只认可公布的公钥签出的快照
+func TestVerifySnapshotSignature(t *testing.T) {
+	t.Setenv(snapshotPublicKeyEnv, "")
+	_, err := SnapshotPublicKey()
+	assert.Error(t, err)
+
+	key := ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize))
+	publicKey := key.Public().(ed25519.PublicKey)
+	t.Setenv(snapshotPublicKeyEnv, hex.EncodeToString(publicKey))
+	published, err := SnapshotPublicKey()
+	assert.NoError(t, err)
+	assert.True(t, published.Equal(publicKey))
+
+	snapshot := &models.OwnershipSnapshot{SnapshotDate: "2026-01-02", MerkleRoot: "ab", LeafCount: 3, PublicKey: hex.EncodeToString(publicKey)}
+	snapshot.Signature = hex.EncodeToString(ed25519.Sign(key, []byte(snapshotSignedMessage("2026-01-02", "ab", 3))))
+	assert.True(t, verifySnapshotSignature(published, snapshot))
+
+	// 用其他密钥重新签名并替换快照记录中的公钥，仍无法通过公布的公钥校验
+	other := ed25519.NewKeyFromSeed([]byte("0123456789abcdef0123456789abcdef"))
+	forged := *snapshot
+	forged.MerkleRoot = "cd"
+	forged.PublicKey = hex.EncodeToString(other.Public().(ed25519.PublicKey))
+	forged.Signature = hex.EncodeToString(ed25519.Sign(other, []byte(snapshotSignedMessage("2026-01-02", "cd", 3))))
+	assert.False(t, verifySnapshotSignature(published, &forged))
+
+	forged.PublicKey = snapshot.PublicKey
+	assert.False(t, verifySnapshotSignature(published, &forged))
+}
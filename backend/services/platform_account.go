@@ -1,122 +1,146 @@
 package services
 
 import (
+	"errors"
+
 	"hoho-miniapp/backend/database"
 	"hoho-miniapp/backend/models"
-	
+
 	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// platformAccountID 平台账户（阳光账户）固定ID
+const platformAccountID = 1
+
 type PlatformAccountService struct{}
 
 func NewPlatformAccountService() *PlatformAccountService {
 	return &PlatformAccountService{}
 }
 
+// EnsureSchema 将已有数据库的平台账户金额字段调整为 decimal(30,8) 并补建关联类型字段（由 cmd/migrate 调用）
+func (s *PlatformAccountService) EnsureSchema() error {
+	m := database.DB.Migrator()
+	for _, field := range []string{"TotalBalance", "CommissionIncome", "FeeIncome", "TotalExpense"} {
+		if err := m.AlterColumn(&models.PlatformAccount{}, field); err != nil {
+			return err
+		}
+	}
+	for _, field := range []string{"Amount", "BalanceAfter"} {
+		if err := m.AlterColumn(&models.PlatformTransaction{}, field); err != nil {
+			return err
+		}
+	}
+	if !m.HasColumn(&models.PlatformTransaction{}, "RelatedType") {
+		return m.AddColumn(&models.PlatformTransaction{}, "RelatedType")
+	}
+	return nil
+}
+
 // GetPlatformAccount 获取平台账户信息
 func (s *PlatformAccountService) GetPlatformAccount() (*models.PlatformAccount, error) {
 	var account models.PlatformAccount
-	if err := database.DB.First(&account, 1).Error; err != nil {
+	if err := database.DB.First(&account, platformAccountID).Error; err != nil {
 		return nil, err
 	}
 	return &account, nil
 }
 
 // RecordPlatformIncome 记录平台收入
-func (s *PlatformAccountService) RecordPlatformIncome(incomeType, amount, description string, relatedID *uint) error {
-	// 获取平台账户
-	var account models.PlatformAccount
-	if err := database.DB.First(&account, 1).Error; err != nil {
-		return err
-	}
-	
-	// 计算新余额
-	currentBalance, _ := decimal.NewFromString(account.TotalBalance)
-	amountDecimal, _ := decimal.NewFromString(amount)
-	newBalance := currentBalance.Add(amountDecimal)
-	
-	// 更新账户余额
-	updates := map[string]interface{}{
-		"total_balance": newBalance.String(),
-	}
-	
-	if incomeType == "commission" {
-		commissionIncome, _ := decimal.NewFromString(account.CommissionIncome)
-		updates["commission_income"] = commissionIncome.Add(amountDecimal).String()
-	} else if incomeType == "fee" {
-		feeIncome, _ := decimal.NewFromString(account.FeeIncome)
-		updates["fee_income"] = feeIncome.Add(amountDecimal).String()
-	}
-	
-	if err := database.DB.Model(&account).Updates(updates).Error; err != nil {
-		return err
-	}
-	
-	// 记录交易
-	transaction := &models.PlatformTransaction{
-		Type:         incomeType,
-		Amount:       amount,
-		BalanceAfter: newBalance.String(),
-		Description:  description,
-		RelatedID:    relatedID,
+func (s *PlatformAccountService) RecordPlatformIncome(incomeType string, amount decimal.Decimal, description string, relatedID uint64, relatedType string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		return s.RecordPlatformIncomeTx(tx, incomeType, amount, description, relatedID, relatedType)
+	})
+}
+
+// RecordPlatformIncomeTx 在调用方事务中记录平台收入（commission 或 fee），与业务变更一同提交
+func (s *PlatformAccountService) RecordPlatformIncomeTx(tx *gorm.DB, incomeType string, amount decimal.Decimal, description string, relatedID uint64, relatedType string) error {
+	if incomeType != "commission" && incomeType != "fee" {
+		return errors.New("不支持的平台收入类型")
 	}
-	
-	return database.DB.Create(transaction).Error
+	return bookPlatformTx(tx, incomeType, amount, description, relatedID, relatedType)
 }
 
 // RecordPlatformExpense 记录平台支出
-func (s *PlatformAccountService) RecordPlatformExpense(amount, description string, relatedID *uint) error {
-	// 获取平台账户
-	var account models.PlatformAccount
-	if err := database.DB.First(&account, 1).Error; err != nil {
-		return err
+func (s *PlatformAccountService) RecordPlatformExpense(amount decimal.Decimal, description string, relatedID uint64, relatedType string) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		return bookPlatformTx(tx, "expense", amount, description, relatedID, relatedType)
+	})
+}
+
+// bookPlatformTx 以原子增量更新平台账户并写入交易记录
+// UPDATE 持有账户行锁直到事务提交，随后读取的余额即为本笔记账后的余额，并发记账时 balance_after 依次连续
+func bookPlatformTx(tx *gorm.DB, txType string, amount decimal.Decimal, description string, relatedID uint64, relatedType string) error {
+	if amount.IsZero() {
+		return nil
+	}
+	if amount.IsNegative() {
+		return errors.New("平台账户记账金额不能为负数")
+	}
+
+	updates := map[string]interface{}{}
+	switch txType {
+	case "commission":
+		updates["total_balance"] = gorm.Expr("total_balance + ?", amount)
+		updates["commission_income"] = gorm.Expr("commission_income + ?", amount)
+	case "fee":
+		updates["total_balance"] = gorm.Expr("total_balance + ?", amount)
+		updates["fee_income"] = gorm.Expr("fee_income + ?", amount)
+	case "expense":
+		updates["total_balance"] = gorm.Expr("total_balance - ?", amount)
+		updates["total_expense"] = gorm.Expr("total_expense + ?", amount)
+	default:
+		return errors.New("不支持的平台账户交易类型")
 	}
-	
-	// 计算新余额
-	currentBalance, _ := decimal.NewFromString(account.TotalBalance)
-	amountDecimal, _ := decimal.NewFromString(amount)
-	newBalance := currentBalance.Sub(amountDecimal)
-	
-	// 更新账户余额
-	totalExpense, _ := decimal.NewFromString(account.TotalExpense)
-	updates := map[string]interface{}{
-		"total_balance":  newBalance.String(),
-		"total_expense":  totalExpense.Add(amountDecimal).String(),
-	}
-	
-	if err := database.DB.Model(&account).Updates(updates).Error; err != nil {
+
+	result := tx.Model(&models.PlatformAccount{}).Where("id = ?", platformAccountID).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("平台账户未初始化")
+	}
+
+	var account models.PlatformAccount
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "total_balance").
+		First(&account, platformAccountID).Error; err != nil {
 		return err
 	}
-	
-	// 记录交易
+
 	transaction := &models.PlatformTransaction{
-		Type:         "expense",
+		Type:         txType,
 		Amount:       amount,
-		BalanceAfter: newBalance.String(),
+		BalanceAfter: account.TotalBalance,
 		Description:  description,
-		RelatedID:    relatedID,
+		RelatedType:  relatedType,
+	}
+	if relatedID != 0 {
+		transaction.RelatedID = &relatedID
 	}
-	
-	return database.DB.Create(transaction).Error
+	return tx.Create(transaction).Error
 }
 
 // GetPlatformTransactions 获取平台交易记录
 func (s *PlatformAccountService) GetPlatformTransactions(transactionType string, page, pageSize int) ([]models.PlatformTransaction, int64, error) {
 	var transactions []models.PlatformTransaction
 	var total int64
-	
+
 	query := database.DB.Model(&models.PlatformTransaction{})
-	
+
 	if transactionType != "" {
 		query = query.Where("type = ?", transactionType)
 	}
-	
-	query.Count(&total)
-	
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
 	offset := (page - 1) * pageSize
-	if err := query.Order("created_at DESC").Offset(offset).Limit(pageSize).Find(&transactions).Error; err != nil {
+	if err := query.Order("id DESC").Offset(offset).Limit(pageSize).Find(&transactions).Error; err != nil {
 		return nil, 0, err
 	}
-	
+
 	return transactions, total, nil
 }
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRecordPlatformIncomeValidation 测试平台记账在访问数据库前的参数校验
+func TestRecordPlatformIncomeValidation(t *testing.T) {
+	s := NewPlatformAccountService()
+
+	err := s.RecordPlatformIncomeTx(nil, "expense", decimal.NewFromInt(1), "非收入类型", 1, "trade")
+	assert.EqualError(t, err, "不支持的平台收入类型")
+
+	err = s.RecordPlatformIncomeTx(nil, "fee", decimal.NewFromInt(-1), "负数金额", 1, "trade")
+	assert.EqualError(t, err, "平台账户记账金额不能为负数")
+
+	// 零金额（如平台藏品免手续费）不记账
+	assert.NoError(t, s.RecordPlatformIncomeTx(nil, "commission", decimal.Zero, "零金额", 1, "trade"))
+}
@@ -0,0 +1,407 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+	"hoho-miniapp/backend/utils"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// defaultPerUserLimit 首发默认每人限购数量（可通过系统配置 primary_sale_per_user_limit 覆盖）
+const defaultPerUserLimit = 5
+
+// PrimarySaleService 定义藏品首发服务接口
+type PrimarySaleService struct {
+	platformAccountService *PlatformAccountService
+	dropQueueService       *DropQueueService
+	assetService           *AssetService
+}
+
+// NewPrimarySaleService 创建一个新的PrimarySaleService实例
+func NewPrimarySaleService() *PrimarySaleService {
+	return &PrimarySaleService{
+		platformAccountService: NewPlatformAccountService(),
+		dropQueueService:       NewDropQueueService(),
+		assetService:           NewAssetService(),
+	}
+}
+
+// ListSales 获取首发列表，status为空时返回全部
+func (s *PrimarySaleService) ListSales(status string, page, pageSize int) ([]models.PrimarySale, int64, error) {
+	var sales []models.PrimarySale
+	var total int64
+
+	query := database.DB.Model(&models.PrimarySale{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Preload("Asset").Order("start_at desc").Offset(offset).Limit(pageSize).Find(&sales).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return sales, total, nil
+}
+
+// GetSale 获取首发详情
+func (s *PrimarySaleService) GetSale(saleID uint64) (*models.PrimarySale, error) {
+	var sale models.PrimarySale
+	if err := database.DB.Preload("Asset").First(&sale, saleID).Error; err != nil {
+		return nil, errors.New("首发不存在")
+	}
+	return &sale, nil
+}
+
+// UpdateSale 修改首发配置（管理员），仅允许修改时间、限购、实名要求、排队开关和优先购设置
+func (s *PrimarySaleService) UpdateSale(saleID uint64, updates map[string]interface{}) (*models.PrimarySale, error) {
+	var sale models.PrimarySale
+	if err := database.DB.First(&sale, saleID).Error; err != nil {
+		return nil, errors.New("首发不存在")
+	}
+	if sale.Status != "on_sale" {
+		return nil, errors.New("首发已结束，无法修改")
+	}
+
+	if limit, ok := updates["per_user_limit"].(int); ok && limit < 0 {
+		return nil, errors.New("限购数量不能为负数")
+	}
+	if priorityStartAt, ok := updates["priority_start_at"].(time.Time); ok {
+		startAt := sale.StartAt
+		if newStartAt, ok := updates["start_at"].(time.Time); ok {
+			startAt = newStartAt
+		}
+		if !priorityStartAt.Before(startAt) {
+			return nil, errors.New("优先购开始时间必须早于开售时间")
+		}
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&sale).Updates(updates).Error; err != nil {
+			return err
+		}
+		// 开售时间变更时同步藏品的发售日期，并重置已发送的开售提醒
+		startAt, ok := updates["start_at"].(time.Time)
+		if !ok {
+			return nil
+		}
+		if err := tx.Model(&models.Asset{}).Where("id = ?", sale.AssetID).Update("release_date", startAt).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ReleaseReminder{}).Where("asset_id = ?", sale.AssetID).Update("notified_at", nil).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 关闭排队时清理排队数据，避免再次开启时沿用过期的库存计数
+	if enabled, ok := updates["queue_enabled"].(bool); ok && !enabled {
+		if err := s.dropQueueService.ClearQueue(saleID); err != nil {
+			return nil, err
+		}
+	}
+	return &sale, nil
+}
+
+// CloseSale 提前结束首发（管理员）
+func (s *PrimarySaleService) CloseSale(saleID uint64) error {
+	result := database.DB.Model(&models.PrimarySale{}).
+		Where("id = ? AND status = ?", saleID, "on_sale").
+		Update("status", "closed")
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("首发不存在或已结束")
+	}
+	return s.dropQueueService.ClearQueue(saleID)
+}
+
+// JoinQueue 加入热门首发的排队
+func (s *PrimarySaleService) JoinQueue(userID, saleID uint64) (*QueueState, error) {
+	var sale models.PrimarySale
+	if err := database.DB.First(&sale, saleID).Error; err != nil {
+		return nil, errors.New("首发不存在")
+	}
+	if err := s.checkBuyerEligible(userID, &sale); err != nil {
+		return nil, err
+	}
+	return s.dropQueueService.JoinQueue(userID, &sale)
+}
+
+// GetQueueState 查询排队状态
+func (s *PrimarySaleService) GetQueueState(userID, saleID uint64) (*QueueState, error) {
+	return s.dropQueueService.GetQueueState(userID, saleID)
+}
+
+// Purchase 首发购买：预扣库存、扣减积分、铸造藏品实例并分账
+// 开启排队的首发需携带排队获得的购买凭证，购买失败时归还凭证占用的库存；抽签首发仅中签者可购买
+func (s *PrimarySaleService) Purchase(userID, saleID uint64, ticket string) (*models.PrimarySaleOrder, error) {
+	var order models.PrimarySaleOrder
+
+	var current models.PrimarySale
+	if err := database.DB.Select("id", "queue_enabled").First(&current, saleID).Error; err != nil {
+		return nil, errors.New("首发不存在")
+	}
+	queueEnabled := current.QueueEnabled
+	if queueEnabled {
+		if err := s.dropQueueService.ConsumeTicket(userID, saleID, ticket); err != nil {
+			return nil, err
+		}
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var sale models.PrimarySale
+		if err := tx.First(&sale, saleID).Error; err != nil {
+			return errors.New("首发不存在")
+		}
+		if err := checkSaleAvailable(&sale, time.Now()); err != nil {
+			return err
+		}
+
+		// 1. 锁定用户行，串行化同一用户的并发购买以保证限购
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return errors.New("用户不存在")
+		}
+		if sale.RequireRealName && !user.IdentityVerified {
+			return errors.New("请先完成实名认证")
+		}
+
+		var bought int64
+		if err := tx.Model(&models.PrimarySaleOrder{}).Where("sale_id = ? AND user_id = ?", saleID, userID).Count(&bought).Error; err != nil {
+			return err
+		}
+		if err := checkPurchaseLimit(int(bought), sale.PerUserLimit); err != nil {
+			return err
+		}
+
+		// 抽签首发仅中签者可购买，核销资格并解冻报名冻结的积分
+		if err := consumeLotteryWinTx(tx, saleID, userID, time.Now()); err != nil {
+			return err
+		}
+
+		// 优先购时段消耗一份优先购权益
+		if inPriorityWindow(&sale, time.Now()) {
+			if err := consumePriorityRightTx(tx, saleID, userID); err != nil {
+				return err
+			}
+		}
+
+		// 2. 原子预扣库存
+		result := tx.Model(&models.PrimarySale{}).
+			Where("id = ? AND status = ? AND sold_count < total_stock", saleID, "on_sale").
+			Update("sold_count", gorm.Expr("sold_count + ?", 1))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("已售罄")
+		}
+		if sale.SoldCount+1 >= sale.TotalStock {
+			if err := tx.Model(&models.PrimarySale{}).Where("id = ? AND sold_count >= total_stock", saleID).
+				Update("status", "sold_out").Error; err != nil {
+				return err
+			}
+		}
+
+		// 3. 扣减买家可用积分（余额减冻结部分）
+		result = tx.Model(&models.UserPoint{}).
+			Where("user_id = ? AND balance - frozen >= ?", userID, sale.Price).
+			Updates(map[string]interface{}{
+				"balance":     gorm.Expr("balance - ?", sale.Price),
+				"total_spent": gorm.Expr("total_spent + ?", sale.Price),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("积分余额不足")
+		}
+
+		// 4. 铸造藏品实例，铸造与购买事件在事务最后统一记录
+		var events eventBatch
+		instances, err := s.assetService.MintAndAirdropTx(tx, &events, sale.AssetID, userID, 1)
+		if err != nil {
+			return err
+		}
+		instance := instances[0]
+
+		// 5. 创建订单
+		creatorIncome, platformIncome := splitPrimaryRevenue(sale.Price, sale.CommissionRate, sale.CreatorID != 0)
+		// 衍生作品从创作者收入中按比例分给上游创作者
+		royaltyShares, parentRoyalty, err := upstreamRoyaltyTx(tx, sale.AssetID, creatorIncome)
+		if err != nil {
+			return err
+		}
+		creatorIncome = creatorIncome.Sub(parentRoyalty)
+		order = models.PrimarySaleOrder{
+			OrderNo:         utils.GenerateOrderNo("PS"),
+			SaleID:          saleID,
+			UserID:          userID,
+			AssetInstanceID: instance.ID,
+			Price:           sale.Price,
+			CreatorIncome:   creatorIncome,
+			PlatformIncome:  platformIncome,
+			ParentRoyalty:   parentRoyalty,
+		}
+		if err := createWithRetry(tx, &order, "order_no", func() { order.OrderNo = utils.GenerateOrderNo("PS") }); err != nil {
+			return err
+		}
+
+		if err := tx.Create(&models.PointTransaction{
+			UserID:      userID,
+			Type:        "spend",
+			Amount:      sale.Price,
+			Description: fmt.Sprintf("首发购买藏品 %s", instance.TokenID),
+			RelatedID:   order.ID,
+			RelatedType: "primary_sale_order",
+		}).Error; err != nil {
+			return err
+		}
+
+		// 6. 创作者分成
+		if creatorIncome.GreaterThan(decimal.Zero) {
+			if err := tx.Model(&models.UserPoint{}).Where("user_id = ?", sale.CreatorID).Updates(map[string]interface{}{
+				"balance":      gorm.Expr("balance + ?", creatorIncome),
+				"total_earned": gorm.Expr("total_earned + ?", creatorIncome),
+			}).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&models.PointTransaction{
+				UserID:      sale.CreatorID,
+				Type:        "earn",
+				Amount:      creatorIncome,
+				Description: fmt.Sprintf("藏品首发分成 %s", instance.TokenID),
+				RelatedID:   order.ID,
+				RelatedType: "primary_sale_order",
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		// 7. 上游版税
+		if err := createRoyaltyPayoutsTx(tx, RoyaltySourcePrimarySale, order.ID, sale.AssetID, royaltyShares, true); err != nil {
+			return err
+		}
+
+		// 8. 平台分成计入阳光账户
+		if platformIncome.GreaterThan(decimal.Zero) {
+			if err := s.platformAccountService.RecordPlatformIncomeTx(tx, "commission", platformIncome,
+				fmt.Sprintf("首发订单 %s 平台分成", order.OrderNo), order.ID, "primary_sale_order"); err != nil {
+				return err
+			}
+		}
+
+		// 9. 记录社区事件
+		userUID, err := eventUserTx(tx, userID)
+		if err != nil {
+			return err
+		}
+		description := fmt.Sprintf("用户 %s 以 %s 积分首发购买了藏品 %s", userUID, sale.Price.String(), instance.TokenID)
+		events.add("primary_sale", userID, description, order.ID, "primary_sale_order")
+		return events.recordTx(tx)
+	})
+	if err != nil {
+		if queueEnabled {
+			s.dropQueueService.RestoreStock(saleID)
+		}
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// checkBuyerEligible 入队前预检实名、优先购权益和限购，避免不符合条件的用户占用凭证
+func (s *PrimarySaleService) checkBuyerEligible(userID uint64, sale *models.PrimarySale) error {
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return errors.New("用户不存在")
+	}
+	if sale.RequireRealName && !user.IdentityVerified {
+		return errors.New("请先完成实名认证")
+	}
+
+	if inPriorityWindow(sale, time.Now()) {
+		ok, err := hasPriorityRight(sale.ID, userID)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("优先购时段仅限持有优先购权益的用户购买")
+		}
+	}
+
+	var bought int64
+	if err := database.DB.Model(&models.PrimarySaleOrder{}).Where("sale_id = ? AND user_id = ?", sale.ID, userID).Count(&bought).Error; err != nil {
+		return err
+	}
+	return checkPurchaseLimit(int(bought), sale.PerUserLimit)
+}
+
+// GetUserOrders 获取用户的首发订单
+func (s *PrimarySaleService) GetUserOrders(userID uint64, page, pageSize int) ([]models.PrimarySaleOrder, int64, error) {
+	var orders []models.PrimarySaleOrder
+	var total int64
+
+	query := database.DB.Model(&models.PrimarySaleOrder{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Preload("AssetInstance.Asset").Order("id desc").Offset(offset).Limit(pageSize).Find(&orders).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+// checkSaleAvailable 检查首发是否处于可购买状态，优先购时段视为已开始（由调用方校验权益）
+func checkSaleAvailable(sale *models.PrimarySale, now time.Time) error {
+	if sale.Status == "sold_out" || sale.SoldCount >= sale.TotalStock {
+		return errors.New("已售罄")
+	}
+	if sale.Status != "on_sale" {
+		return errors.New("首发已结束")
+	}
+	if now.Before(sale.StartAt) && !inPriorityWindow(sale, now) {
+		return errors.New("首发尚未开始")
+	}
+	if sale.EndAt != nil && !now.Before(*sale.EndAt) {
+		return errors.New("首发已结束")
+	}
+	return nil
+}
+
+// checkPurchaseLimit 检查用户是否已达到限购数量，limit为0表示不限购
+func checkPurchaseLimit(bought, limit int) error {
+	if limit > 0 && bought >= limit {
+		return fmt.Errorf("每人限购%d份", limit)
+	}
+	return nil
+}
+
+// splitPrimaryRevenue 按平台分成比例拆分首发收入，平台藏品（无创作者）全部计入平台
+// 平台分成使用银行家舍入法保留8位小数，创作者获得剩余部分以保证总额守恒
+func splitPrimaryRevenue(price, commissionRate decimal.Decimal, hasCreator bool) (decimal.Decimal, decimal.Decimal) {
+	if !hasCreator {
+		return decimal.Zero, price
+	}
+	platformIncome := price.Mul(commissionRate).Div(decimal.NewFromInt(100)).RoundBank(8)
+	if platformIncome.GreaterThan(price) {
+		platformIncome = price
+	}
+	return price.Sub(platformIncome), platformIncome
+}
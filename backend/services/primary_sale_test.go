@@ -0,0 +1,113 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"hoho-miniapp/backend/models"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCheckSaleAvailable 测试首发可购买状态检查
+func TestCheckSaleAvailable(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.Local)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		sale     models.PrimarySale
+		errorMsg string
+	}{
+		{
+			name: "正常在售",
+			sale: models.PrimarySale{Status: "on_sale", TotalStock: 10, SoldCount: 3, StartAt: past},
+		},
+		{
+			name:     "尚未开始",
+			sale:     models.PrimarySale{Status: "on_sale", TotalStock: 10, StartAt: future},
+			errorMsg: "首发尚未开始",
+		},
+		{
+			name:     "已过结束时间",
+			sale:     models.PrimarySale{Status: "on_sale", TotalStock: 10, StartAt: past, EndAt: &now},
+			errorMsg: "首发已结束",
+		},
+		{
+			name:     "库存售罄",
+			sale:     models.PrimarySale{Status: "on_sale", TotalStock: 10, SoldCount: 10, StartAt: past},
+			errorMsg: "已售罄",
+		},
+		{
+			name:     "已关闭",
+			sale:     models.PrimarySale{Status: "closed", TotalStock: 10, StartAt: past},
+			errorMsg: "首发已结束",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkSaleAvailable(&tt.sale, now)
+			if tt.errorMsg == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.errorMsg)
+			}
+		})
+	}
+}
+
+// TestCheckPurchaseLimit 测试首发限购
+func TestCheckPurchaseLimit(t *testing.T) {
+	tests := []struct {
+		name        string
+		bought      int
+		limit       int
+		expectError bool
+	}{
+		{"未达限购", 1, 2, false},
+		{"已达限购", 2, 2, true},
+		{"不限购", 100, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkPurchaseLimit(tt.bought, tt.limit)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestSplitPrimaryRevenue 测试首发分账
+func TestSplitPrimaryRevenue(t *testing.T) {
+	tests := []struct {
+		name             string
+		price            string
+		rate             string
+		hasCreator       bool
+		expectedCreator  string
+		expectedPlatform string
+	}{
+		{"默认40%分成", "100", "40.00", true, "60", "40"},
+		{"舍入后总额守恒", "0.00000003", "50.00", true, "0.00000001", "0.00000002"},
+		{"零分成", "10", "0", true, "10", "0"},
+		{"平台作品全部计入平台", "10", "40.00", false, "0", "10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price := decimal.RequireFromString(tt.price)
+			creator, platform := splitPrimaryRevenue(price, decimal.RequireFromString(tt.rate), tt.hasCreator)
+
+			assert.True(t, creator.Equal(decimal.RequireFromString(tt.expectedCreator)), "creator: %s", creator)
+			assert.True(t, platform.Equal(decimal.RequireFromString(tt.expectedPlatform)), "platform: %s", platform)
+			assert.True(t, creator.Add(platform).Equal(price))
+		})
+	}
+}
@@ -0,0 +1,423 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+	"hoho-miniapp/backend/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PriorityRightService 定义首发优先购权益服务接口
+type PriorityRightService struct{}
+
+// NewPriorityRightService 创建一个新的PriorityRightService实例
+func NewPriorityRightService() *PriorityRightService {
+	return &PriorityRightService{}
+}
+
+// ImportRowError 名单导入失败的行
+type ImportRowError struct {
+	Line   int    `json:"line"`
+	Reason string `json:"reason"`
+}
+
+// ImportResult 名单导入结果
+type ImportResult struct {
+	Imported int              `json:"imported"`
+	Failed   []ImportRowError `json:"failed"`
+}
+
+// SnapshotInput 持仓快照发放配置
+type SnapshotInput struct {
+	AssetIDs           []uint64   `json:"asset_ids"`
+	JingtanNames       []string   `json:"jingtan_names"`
+	QuantityPerHolding int        `json:"quantity_per_holding" binding:"required,min=1"`
+	MaxPerUser         int        `json:"max_per_user" binding:"min=0"`
+	SnapshotAt         *time.Time `json:"snapshot_at"` // 为空时立即快照
+}
+
+// ImportCSV 导入优先购名单（管理员）
+// 每行格式为“用户UID或手机号,份数”，首行可为表头；同一用户多次发放时份数累加
+func (s *PriorityRightService) ImportCSV(saleID uint64, r io.Reader) (*ImportResult, error) {
+	if _, err := getPrioritySale(database.DB, saleID); err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("CSV格式错误: %w", err)
+	}
+
+	result := &ImportResult{Failed: []ImportRowError{}}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for i, record := range records {
+			line := i + 1
+			account, quantity, err := parseRightRecord(record)
+			if err != nil {
+				// 首行无法解析份数时视为表头
+				if line == 1 {
+					continue
+				}
+				result.Failed = append(result.Failed, ImportRowError{Line: line, Reason: err.Error()})
+				continue
+			}
+
+			var user models.User
+			if err := tx.Where("uid = ? OR phone = ?", account, account).First(&user).Error; err != nil {
+				result.Failed = append(result.Failed, ImportRowError{Line: line, Reason: "用户不存在"})
+				continue
+			}
+
+			if err := grantPriorityRightTx(tx, saleID, user.ID, quantity, "csv"); err != nil {
+				return err
+			}
+			result.Imported++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CreateSnapshot 创建持仓快照发放任务（管理员），快照时间已到时立即执行
+func (s *PriorityRightService) CreateSnapshot(saleID uint64, input SnapshotInput) (*models.PrioritySnapshot, error) {
+	if len(input.AssetIDs) == 0 && len(input.JingtanNames) == 0 {
+		return nil, errors.New("请指定快照的藏品或鲸探资产")
+	}
+	if _, err := getPrioritySale(database.DB, saleID); err != nil {
+		return nil, err
+	}
+
+	snapshotAt := time.Now()
+	if input.SnapshotAt != nil {
+		snapshotAt = *input.SnapshotAt
+	}
+
+	assetIDs := make([]string, 0, len(input.AssetIDs))
+	for _, id := range input.AssetIDs {
+		assetIDs = append(assetIDs, strconv.FormatUint(id, 10))
+	}
+
+	snapshot := &models.PrioritySnapshot{
+		SaleID:             saleID,
+		AssetIDs:           strings.Join(assetIDs, ","),
+		JingtanNames:       strings.Join(input.JingtanNames, ","),
+		QuantityPerHolding: input.QuantityPerHolding,
+		MaxPerUser:         input.MaxPerUser,
+		SnapshotAt:         snapshotAt,
+		Status:             "pending",
+	}
+	if err := database.DB.Create(snapshot).Error; err != nil {
+		return nil, err
+	}
+
+	if !time.Now().Before(snapshotAt) {
+		if err := s.ExecuteSnapshot(snapshot.ID); err != nil {
+			return nil, err
+		}
+		if err := database.DB.First(snapshot, snapshot.ID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return snapshot, nil
+}
+
+// ExecuteSnapshot 执行持仓快照：统计持有数量并按比例发放优先购权益
+func (s *PriorityRightService) ExecuteSnapshot(snapshotID uint64) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var snapshot models.PrioritySnapshot
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&snapshot, snapshotID).Error; err != nil {
+			return errors.New("快照任务不存在")
+		}
+		if snapshot.Status != "pending" {
+			return errors.New("快照任务已执行")
+		}
+
+		holdings := make(map[uint64]int)
+		type ownerCount struct {
+			OwnerID uint64
+			Count   int
+		}
+
+		if assetIDs := splitIDs(snapshot.AssetIDs); len(assetIDs) > 0 {
+			var rows []ownerCount
+			if err := tx.Model(&models.AssetInstance{}).
+				Select("owner_id, COUNT(*) AS count").
+				Where("asset_id IN ? AND status <> ?", assetIDs, "burned").
+				Group("owner_id").Scan(&rows).Error; err != nil {
+				return err
+			}
+			for _, row := range rows {
+				holdings[row.OwnerID] += row.Count
+			}
+		}
+
+		if names := splitNames(snapshot.JingtanNames); len(names) > 0 {
+			var rows []ownerCount
+			if err := tx.Model(&models.JingtanAsset{}).
+				Select("user_id AS owner_id, COUNT(*) AS count").
+				Where("name IN ? AND status = ?", names, "active").
+				Group("user_id").Scan(&rows).Error; err != nil {
+				return err
+			}
+			for _, row := range rows {
+				holdings[row.OwnerID] += row.Count
+			}
+		}
+
+		granted := 0
+		for userID, count := range holdings {
+			quantity := snapshotQuantity(count, snapshot.QuantityPerHolding, snapshot.MaxPerUser)
+			if quantity == 0 {
+				continue
+			}
+			if err := grantPriorityRightTx(tx, snapshot.SaleID, userID, quantity, "snapshot"); err != nil {
+				return err
+			}
+			granted++
+		}
+
+		now := time.Now()
+		return tx.Model(&snapshot).Updates(map[string]interface{}{
+			"status":        "done",
+			"granted_users": granted,
+			"executed_at":   &now,
+		}).Error
+	})
+}
+
+// RunDueSnapshots 执行已到快照时间的发放任务（定时任务）
+func (s *PriorityRightService) RunDueSnapshots() error {
+	var ids []uint64
+	if err := database.DB.Model(&models.PrioritySnapshot{}).
+		Where("status = ? AND snapshot_at <= ?", "pending", time.Now()).
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := s.ExecuteSnapshot(id); err != nil {
+			return fmt.Errorf("优先购快照%d执行失败: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// ListSaleRights 获取首发的优先购名单（管理员）
+func (s *PriorityRightService) ListSaleRights(saleID uint64, page, pageSize int) ([]models.PriorityRight, int64, error) {
+	var rights []models.PriorityRight
+	var total int64
+
+	query := database.DB.Model(&models.PriorityRight{}).Where("sale_id = ?", saleID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("id asc").Offset(offset).Limit(pageSize).Find(&rights).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return rights, total, nil
+}
+
+// ListSnapshots 获取首发的快照发放任务（管理员）
+func (s *PriorityRightService) ListSnapshots(saleID uint64) ([]models.PrioritySnapshot, error) {
+	var snapshots []models.PrioritySnapshot
+	err := database.DB.Where("sale_id = ?", saleID).Order("id desc").Find(&snapshots).Error
+	return snapshots, err
+}
+
+// GetUserRights 获取用户的优先购权益
+func (s *PriorityRightService) GetUserRights(userID uint64, page, pageSize int) ([]models.PriorityRight, int64, error) {
+	var rights []models.PriorityRight
+	var total int64
+
+	query := database.DB.Model(&models.PriorityRight{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Preload("Sale.Asset").Order("id desc").Offset(offset).Limit(pageSize).Find(&rights).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return rights, total, nil
+}
+
+// Transfer 转让优先购权益，仅在首发允许转让且优先购结束前可用
+func (s *PriorityRightService) Transfer(fromUserID, rightID uint64, toUID string, quantity int) error {
+	if quantity < 1 {
+		return errors.New("转让份数必须大于0")
+	}
+	if !utils.ValidateUID(toUID) {
+		return errors.New("接收用户UID格式错误")
+	}
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var right models.PriorityRight
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", rightID, fromUserID).First(&right).Error; err != nil {
+			return errors.New("优先购权益不存在")
+		}
+
+		var sale models.PrimarySale
+		if err := tx.First(&sale, right.SaleID).Error; err != nil {
+			return errors.New("首发不存在")
+		}
+		if !sale.PriorityTransferable {
+			return errors.New("该首发的优先购权益不可转让")
+		}
+		if sale.Status != "on_sale" || !time.Now().Before(sale.StartAt) {
+			return errors.New("优先购已结束")
+		}
+		if right.Quantity-right.UsedQuantity < quantity {
+			return errors.New("可转让份数不足")
+		}
+
+		var recipient models.User
+		if err := tx.Where("uid = ?", toUID).First(&recipient).Error; err != nil {
+			return errors.New("接收用户不存在")
+		}
+		if recipient.ID == fromUserID {
+			return errors.New("不能转让给自己")
+		}
+
+		if err := tx.Model(&right).Update("quantity", gorm.Expr("quantity - ?", quantity)).Error; err != nil {
+			return err
+		}
+		if err := grantPriorityRightTx(tx, right.SaleID, recipient.ID, quantity, "transfer"); err != nil {
+			return err
+		}
+
+		fromUID, err := eventUserTx(tx, fromUserID)
+		if err != nil {
+			return err
+		}
+		description := fmt.Sprintf("用户 %s 向用户 %s 转让了首发%d的%d份优先购权益",
+			fromUID, maskUID(recipient.UID), right.SaleID, quantity)
+		_, err = recordEvent(tx, "priority_transfer", fromUserID, description, right.ID, "priority_right")
+		return err
+	})
+}
+
+// getPrioritySale 获取可发放优先购权益的首发
+func getPrioritySale(tx *gorm.DB, saleID uint64) (*models.PrimarySale, error) {
+	var sale models.PrimarySale
+	if err := tx.First(&sale, saleID).Error; err != nil {
+		return nil, errors.New("首发不存在")
+	}
+	if sale.Status != "on_sale" {
+		return nil, errors.New("首发已结束")
+	}
+	if sale.PriorityStartAt == nil {
+		return nil, errors.New("该首发未设置优先购时段")
+	}
+	return &sale, nil
+}
+
+// grantPriorityRightTx 在调用方事务中发放优先购权益，已有权益时累加份数
+func grantPriorityRightTx(tx *gorm.DB, saleID, userID uint64, quantity int, source string) error {
+	right := models.PriorityRight{
+		SaleID:   saleID,
+		UserID:   userID,
+		Quantity: quantity,
+		Source:   source,
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "sale_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"quantity": gorm.Expr("quantity + ?", quantity)}),
+	}).Create(&right).Error
+}
+
+// consumePriorityRightTx 优先购时段内购买时消耗一份权益
+func consumePriorityRightTx(tx *gorm.DB, saleID, userID uint64) error {
+	result := tx.Model(&models.PriorityRight{}).
+		Where("sale_id = ? AND user_id = ? AND used_quantity < quantity", saleID, userID).
+		Update("used_quantity", gorm.Expr("used_quantity + ?", 1))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("优先购时段仅限持有优先购权益的用户购买")
+	}
+	return nil
+}
+
+// hasPriorityRight 检查用户是否有剩余的优先购权益
+func hasPriorityRight(saleID, userID uint64) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.PriorityRight{}).
+		Where("sale_id = ? AND user_id = ? AND used_quantity < quantity", saleID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// inPriorityWindow 判断当前是否处于优先购时段
+func inPriorityWindow(sale *models.PrimarySale, now time.Time) bool {
+	return sale.PriorityStartAt != nil && !now.Before(*sale.PriorityStartAt) && now.Before(sale.StartAt)
+}
+
+// snapshotQuantity 按持有数量计算发放份数，maxPerUser为0表示不限
+func snapshotQuantity(holdings, perHolding, maxPerUser int) int {
+	quantity := holdings * perHolding
+	if maxPerUser > 0 && quantity > maxPerUser {
+		quantity = maxPerUser
+	}
+	return quantity
+}
+
+// parseRightRecord 解析名单行，返回用户标识和份数（未填写份数时为1）
+func parseRightRecord(record []string) (string, int, error) {
+	if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+		return "", 0, errors.New("缺少用户UID或手机号")
+	}
+	account := strings.TrimSpace(record[0])
+	if len(record) < 2 || strings.TrimSpace(record[1]) == "" {
+		return account, 1, nil
+	}
+	quantity, err := strconv.Atoi(strings.TrimSpace(record[1]))
+	if err != nil || quantity < 1 {
+		return "", 0, errors.New("份数必须为正整数")
+	}
+	return account, quantity, nil
+}
+
+// splitIDs 解析逗号分隔的ID
+func splitIDs(value string) []uint64 {
+	var ids []uint64
+	for _, part := range strings.Split(value, ",") {
+		if id, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// splitNames 解析逗号分隔的名称
+func splitNames(value string) []string {
+	var names []string
+	for _, part := range strings.Split(value, ",") {
+		if name := strings.TrimSpace(part); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
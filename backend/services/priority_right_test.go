@@ -0,0 +1,78 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"hoho-miniapp/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseRightRecord 测试优先购名单行解析
+func TestParseRightRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		record   []string
+		account  string
+		quantity int
+		wantErr  bool
+	}{
+		{"UID和份数", []string{"U10001", "3"}, "U10001", 3, false},
+		{"未填写份数", []string{"13800138000"}, "13800138000", 1, false},
+		{"去除空格", []string{" U10002 ", " 2 "}, "U10002", 2, false},
+		{"表头", []string{"uid", "quantity"}, "", 0, true},
+		{"份数为0", []string{"U10003", "0"}, "", 0, true},
+		{"空行", []string{""}, "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account, quantity, err := parseRightRecord(tt.record)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.account, account)
+			assert.Equal(t, tt.quantity, quantity)
+		})
+	}
+}
+
+// TestSnapshotQuantity 测试持仓快照发放份数计算
+func TestSnapshotQuantity(t *testing.T) {
+	assert.Equal(t, 0, snapshotQuantity(0, 2, 5))
+	assert.Equal(t, 4, snapshotQuantity(2, 2, 5))
+	assert.Equal(t, 5, snapshotQuantity(10, 2, 5))
+	assert.Equal(t, 20, snapshotQuantity(10, 2, 0))
+}
+
+// TestInPriorityWindow 测试优先购时段判断及开售检查
+func TestInPriorityWindow(t *testing.T) {
+	now := time.Now()
+	priorityStartAt := now.Add(-time.Hour)
+	sale := &models.PrimarySale{
+		TotalStock:      10,
+		Status:          "on_sale",
+		StartAt:         now.Add(time.Hour),
+		PriorityStartAt: &priorityStartAt,
+	}
+
+	assert.True(t, inPriorityWindow(sale, now))
+	assert.NoError(t, checkSaleAvailable(sale, now))
+	assert.False(t, inPriorityWindow(sale, now.Add(-2*time.Hour)))
+	assert.Error(t, checkSaleAvailable(sale, now.Add(-2*time.Hour)))
+	assert.False(t, inPriorityWindow(sale, now.Add(2*time.Hour)))
+
+	sale.PriorityStartAt = nil
+	assert.False(t, inPriorityWindow(sale, now))
+	assert.Error(t, checkSaleAvailable(sale, now))
+}
+
+// TestSplitIDs 测试逗号分隔ID解析
+func TestSplitIDs(t *testing.T) {
+	assert.Equal(t, []uint64{1, 2, 30}, splitIDs("1, 2,x,30"))
+	assert.Empty(t, splitIDs(""))
+	assert.Equal(t, []string{"鲸探限定藏品#1", "鲸探限定藏品#2"}, splitNames("鲸探限定藏品#1, ,鲸探限定藏品#2"))
+}
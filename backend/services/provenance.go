@@ -0,0 +1,135 @@
+package services
+
+import (
+	"errors"
+	"time"
+	"unicode/utf8"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"gorm.io/gorm"
+)
+
+// 所有权变更类型
+const (
+	ProvenanceMint     = "mint"
+	ProvenanceAirdrop  = "airdrop"
+	ProvenanceTrade    = "trade"
+	ProvenanceOffer    = "offer"
+	ProvenanceTransfer = "transfer"
+	ProvenanceBurn     = "burn"
+	ProvenanceRedeem   = "redeem"
+)
+
+// ProvenanceService 定义藏品溯源服务接口
+type ProvenanceService struct{}
+
+// NewProvenanceService 创建一个新的ProvenanceService实例
+func NewProvenanceService() *ProvenanceService {
+	return &ProvenanceService{}
+}
+
+// ProvenanceParty 溯源记录中的用户，仅公开脱敏后的UID和昵称
+type ProvenanceParty struct {
+	UID      string `json:"uid"`
+	Nickname string `json:"nickname"`
+}
+
+// ProvenanceEntry 一条所有权变更
+type ProvenanceEntry struct {
+	EventType string           `json:"event_type"`
+	From      *ProvenanceParty `json:"from"` // 铸造/空投时为null
+	To        *ProvenanceParty `json:"to"`   // 销毁时为null
+	Price     *string          `json:"price"`
+	EventID   *uint64          `json:"event_id"` // 对应的社区事件，可通过 /api/v1/events/:id 查看
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// Provenance 藏品实例的溯源时间线
+type Provenance struct {
+	TokenID         string            `json:"token_id"`
+	AssetInstanceID uint64            `json:"asset_instance_id"`
+	AssetID         uint64            `json:"asset_id"`
+	AssetName       string            `json:"asset_name"`
+	InstanceNo      int               `json:"instance_no"`
+	Status          string            `json:"status"`
+	Owner           *ProvenanceParty  `json:"owner"` // 当前持有者，已销毁时为null
+	History         []ProvenanceEntry `json:"history"`
+}
+
+// EnsureSchema 为已有数据库补建所有权变更记录表（由 cmd/migrate 调用）
+func (s *ProvenanceService) EnsureSchema() error {
+	m := database.DB.Migrator()
+	if !m.HasTable(&models.OwnershipRecord{}) {
+		return m.CreateTable(&models.OwnershipRecord{})
+	}
+	return nil
+}
+
+// GetProvenance 按TokenID获取藏品实例的所有权变更时间线（按时间先后）
+func (s *ProvenanceService) GetProvenance(tokenID string) (*Provenance, error) {
+	var instance models.AssetInstance
+	if err := database.DB.Preload("Asset", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("token_id = ?", tokenID).First(&instance).Error; err != nil {
+		return nil, errors.New("藏品实例不存在")
+	}
+
+	var records []models.OwnershipRecord
+	if err := database.DB.Where("asset_instance_id = ?", instance.ID).Order("id asc").Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	userIDs := []uint64{instance.OwnerID}
+	for _, record := range records {
+		userIDs = append(userIDs, record.FromUserID, record.ToUserID)
+	}
+	var users []models.User
+	if err := database.DB.Unscoped().Select("id", "uid", "nickname").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	parties := make(map[uint64]*ProvenanceParty, len(users))
+	for _, user := range users {
+		parties[user.ID] = &ProvenanceParty{UID: maskUID(user.UID), Nickname: maskNickname(user.Nickname)}
+	}
+
+	provenance := &Provenance{
+		TokenID:         instance.TokenID,
+		AssetInstanceID: instance.ID,
+		AssetID:         instance.AssetID,
+		InstanceNo:      instance.InstanceNo,
+		Status:          instance.Status,
+		History:         make([]ProvenanceEntry, 0, len(records)),
+	}
+	if instance.Asset != nil {
+		provenance.AssetName = instance.Asset.Name
+	}
+	if instance.Status != "burned" {
+		provenance.Owner = parties[instance.OwnerID]
+	}
+
+	for _, record := range records {
+		entry := ProvenanceEntry{
+			EventType: record.EventType,
+			From:      parties[record.FromUserID],
+			To:        parties[record.ToUserID],
+			EventID:   record.EventID,
+			CreatedAt: record.CreatedAt,
+		}
+		if record.Price != nil {
+			price := record.Price.String()
+			entry.Price = &price
+		}
+		provenance.History = append(provenance.History, entry)
+	}
+	return provenance, nil
+}
+
+// maskNickname 脱敏昵称：仅保留首字
+func maskNickname(nickname string) string {
+	if nickname == "" {
+		return ""
+	}
+	first, _ := utf8.DecodeRuneInString(nickname)
+	return string(first) + "**"
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMaskUID 测试溯源UID脱敏
+func TestMaskUID(t *testing.T) {
+	assert.Equal(t, "123****89", maskUID("123456789"))
+	assert.Equal(t, "1****", maskUID("12345"))
+	assert.Equal(t, "", maskUID(""))
+}
+
+// TestMaskNickname 测试溯源昵称脱敏
+func TestMaskNickname(t *testing.T) {
+	assert.Equal(t, "山**", maskNickname("山海经"))
+	assert.Equal(t, "a**", maskNickname("alice"))
+	assert.Equal(t, "", maskNickname(""))
+}
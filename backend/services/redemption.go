@@ -0,0 +1,394 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+	"hoho-miniapp/backend/utils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// redemptionTransitions 兑换订单状态机：当前状态 -> 允许流转到的状态
+// after_sale -> shipped 用于售后换货后重新发货
+var redemptionTransitions = map[string][]string{
+	"pending":    {"shipped"},
+	"shipped":    {"delivered", "after_sale"},
+	"delivered":  {"after_sale", "closed"},
+	"after_sale": {"shipped", "closed"},
+}
+
+// RedemptionService 定义实物兑换服务接口
+type RedemptionService struct {
+	addressService *AddressService
+}
+
+// NewRedemptionService 创建一个新的RedemptionService实例
+func NewRedemptionService() *RedemptionService {
+	return &RedemptionService{addressService: NewAddressService()}
+}
+
+// CreateRedeemableItem 将藏品设置为可兑换实物（管理员）
+func (s *RedemptionService) CreateRedeemableItem(item *models.RedeemableItem) error {
+	if item.Stock < 1 {
+		return errors.New("实物库存必须大于0")
+	}
+	if item.StartAt != nil && item.EndAt != nil && !item.EndAt.After(*item.StartAt) {
+		return errors.New("结束时间必须晚于开始时间")
+	}
+
+	var asset models.Asset
+	if err := database.DB.First(&asset, item.AssetID).Error; err != nil {
+		return errors.New("藏品不存在")
+	}
+
+	var count int64
+	database.DB.Model(&models.RedeemableItem{}).Where("asset_id = ?", item.AssetID).Count(&count)
+	if count > 0 {
+		return errors.New("该藏品已配置兑换")
+	}
+
+	item.RedeemedCount = 0
+	if item.Status == "" {
+		item.Status = "active"
+	}
+	return database.DB.Create(item).Error
+}
+
+// UpdateRedeemableItem 更新兑换配置（管理员）
+func (s *RedemptionService) UpdateRedeemableItem(itemID uint64, updates map[string]interface{}) (*models.RedeemableItem, error) {
+	var item models.RedeemableItem
+	if err := database.DB.First(&item, itemID).Error; err != nil {
+		return nil, errors.New("兑换配置不存在")
+	}
+
+	if stock, ok := updates["stock"].(int); ok && stock < item.RedeemedCount {
+		return nil, errors.New("库存不能小于已兑换数量")
+	}
+	if err := validateRedeemWindow(&item, updates); err != nil {
+		return nil, err
+	}
+
+	if err := database.DB.Model(&item).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// validateRedeemWindow 校验合并更新后的兑换时间窗口，结束时间必须晚于开始时间
+func validateRedeemWindow(item *models.RedeemableItem, updates map[string]interface{}) error {
+	startAt, endAt := item.StartAt, item.EndAt
+	if newStartAt, ok := updates["start_at"].(time.Time); ok {
+		startAt = &newStartAt
+	}
+	if newEndAt, ok := updates["end_at"].(time.Time); ok {
+		endAt = &newEndAt
+	}
+	if startAt != nil && endAt != nil && !endAt.After(*startAt) {
+		return errors.New("结束时间必须晚于开始时间")
+	}
+	return nil
+}
+
+// ListRedeemableItems 获取兑换配置列表，onlyAvailable为true时仅返回当前可兑换的
+func (s *RedemptionService) ListRedeemableItems(onlyAvailable bool, page, pageSize int) ([]models.RedeemableItem, int64, error) {
+	var items []models.RedeemableItem
+	var total int64
+
+	query := database.DB.Model(&models.RedeemableItem{})
+	if onlyAvailable {
+		now := time.Now()
+		query = query.Where("status = ?", "active").
+			Where("start_at IS NULL OR start_at <= ?", now).
+			Where("end_at IS NULL OR end_at > ?", now).
+			Where("redeemed_count < stock")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Preload("Asset").Order("id desc").Offset(offset).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+// Redeem 使用藏品实例兑换实物，收货信息取自用户的收货地址
+func (s *RedemptionService) Redeem(userID, instanceID, addressID uint64) (*models.RedemptionOrder, error) {
+	address, err := s.addressService.GetAddress(userID, addressID)
+	if err != nil {
+		return nil, err
+	}
+
+	var order models.RedemptionOrder
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// 1. 锁定藏品实例
+		var instance models.AssetInstance
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&instance, instanceID).Error; err != nil {
+			return errors.New("藏品实例不存在")
+		}
+		if instance.OwnerID != userID {
+			return errors.New("你不是该藏品的拥有者")
+		}
+		if instance.Status != "in_wallet" {
+			return errors.New("该藏品当前不可兑换")
+		}
+		if instance.RedeemedAt != nil {
+			return errors.New("该藏品已兑换过实物")
+		}
+
+		// 2. 锁定兑换配置，校验库存和兑换时间
+		var item models.RedeemableItem
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("asset_id = ?", instance.AssetID).First(&item).Error; err != nil {
+			return errors.New("该藏品不支持兑换实物")
+		}
+		if err := checkRedeemableAvailable(&item, time.Now()); err != nil {
+			return err
+		}
+
+		// 3. 不销毁的实例标记为已兑换（销毁在创建订单后进行，以订单作为销毁记录的上下文）
+		if !item.BurnOnRedeem {
+			if err := tx.Model(&instance).Update("redeemed_at", time.Now()).Error; err != nil {
+				return err
+			}
+		}
+
+		// 4. 扣减库存
+		if err := tx.Model(&item).Update("redeemed_count", gorm.Expr("redeemed_count + ?", 1)).Error; err != nil {
+			return err
+		}
+
+		// 5. 创建兑换订单
+		order = models.RedemptionOrder{
+			OrderNo:          utils.GenerateOrderNo("RD"),
+			UserID:           userID,
+			RedeemableItemID: item.ID,
+			AssetInstanceID:  instance.ID,
+			ReceiverName:     address.ReceiverName,
+			ReceiverPhone:    address.Phone,
+			ShippingAddress:  address.FullAddress(),
+			Status:           "pending",
+		}
+		if err := createWithRetry(tx, &order, "order_no", func() { order.OrderNo = utils.GenerateOrderNo("RD") }); err != nil {
+			return err
+		}
+
+		// 6. 记录社区事件和所有权变更，兑换后销毁的实例只记录一条兑换记录，不再有持有者
+		userUID, err := eventUserTx(tx, userID)
+		if err != nil {
+			return err
+		}
+		description := fmt.Sprintf("用户 %s 使用藏品实例 %s 兑换了实物《%s》", userUID, instance.TokenID, item.ItemName)
+		var events eventBatch
+		if item.BurnOnRedeem {
+			if err := burnInstanceTx(tx, &events, userID, &instance, burnRecord{
+				EventType:   "redeem",
+				Description: description,
+				Provenance:  ProvenanceRedeem,
+				RelatedID:   order.ID,
+				RelatedType: "redemption_order",
+			}); err != nil {
+				return err
+			}
+		} else {
+			events.add("redeem", userID, description, order.ID, "redemption_order", models.OwnershipRecord{
+				AssetInstanceID: instance.ID,
+				TokenID:         instance.TokenID,
+				EventType:       ProvenanceRedeem,
+				FromUserID:      userID,
+				ToUserID:        userID,
+				RelatedID:       order.ID,
+				RelatedType:     "redemption_order",
+			})
+		}
+		return events.recordTx(tx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &order, nil
+}
+
+// GetUserOrders 获取用户的兑换订单
+func (s *RedemptionService) GetUserOrders(userID uint64, status string, page, pageSize int) ([]models.RedemptionOrder, int64, error) {
+	var orders []models.RedemptionOrder
+	var total int64
+
+	query := database.DB.Model(&models.RedemptionOrder{}).Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Preload("RedeemableItem").Order("id desc").Offset(offset).Limit(pageSize).Find(&orders).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+// GetUserOrder 获取用户的兑换订单详情
+func (s *RedemptionService) GetUserOrder(userID, orderID uint64) (*models.RedemptionOrder, error) {
+	var order models.RedemptionOrder
+	if err := database.DB.Preload("RedeemableItem").Preload("AssetInstance").First(&order, orderID).Error; err != nil {
+		return nil, errors.New("兑换订单不存在")
+	}
+	if order.UserID != userID {
+		return nil, errors.New("你没有权限查看此订单")
+	}
+	return &order, nil
+}
+
+// RequestAfterSale 用户申请售后
+func (s *RedemptionService) RequestAfterSale(userID, orderID uint64, reason string) error {
+	var order models.RedemptionOrder
+	if err := database.DB.First(&order, orderID).Error; err != nil {
+		return errors.New("兑换订单不存在")
+	}
+	if order.UserID != userID {
+		return errors.New("你没有权限操作此订单")
+	}
+	return s.transit(&order, "after_sale", map[string]interface{}{"after_sale_reason": reason})
+}
+
+// ListOrders 获取兑换订单列表（管理员）
+func (s *RedemptionService) ListOrders(status string, page, pageSize int) ([]models.RedemptionOrder, int64, error) {
+	var orders []models.RedemptionOrder
+	var total int64
+
+	query := database.DB.Model(&models.RedemptionOrder{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Preload("RedeemableItem").Order("id desc").Offset(offset).Limit(pageSize).Find(&orders).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return orders, total, nil
+}
+
+// UpdateOrderStatus 管理员推进订单履约状态
+func (s *RedemptionService) UpdateOrderStatus(orderID uint64, status, carrier, trackingNo, remark string) (*models.RedemptionOrder, error) {
+	var order models.RedemptionOrder
+	if err := database.DB.First(&order, orderID).Error; err != nil {
+		return nil, errors.New("兑换订单不存在")
+	}
+
+	updates := map[string]interface{}{}
+	if remark != "" {
+		updates["remark"] = remark
+	}
+	if status == "shipped" {
+		if carrier == "" || trackingNo == "" {
+			return nil, errors.New("发货需要填写物流公司和物流单号")
+		}
+		updates["carrier"] = carrier
+		updates["tracking_no"] = trackingNo
+	}
+
+	if err := s.transit(&order, status, updates); err != nil {
+		return nil, err
+	}
+
+	// 通知用户
+	if content := redemptionNotifyContent(&order); content != "" {
+		relatedID := uint(order.ID)
+		notification := &models.Notification{
+			UserID:    uint(order.UserID),
+			Type:      "system",
+			Title:     "兑换订单状态更新",
+			Content:   content,
+			RelatedID: &relatedID,
+		}
+		database.DB.Create(notification)
+	}
+
+	return &order, nil
+}
+
+// transit 按状态机流转订单状态，使用原状态作为更新条件防止并发重复流转
+func (s *RedemptionService) transit(order *models.RedemptionOrder, to string, updates map[string]interface{}) error {
+	if !canTransitRedemption(order.Status, to) {
+		return fmt.Errorf("订单状态不能从 %s 变更为 %s", order.Status, to)
+	}
+
+	now := time.Now()
+	updates["status"] = to
+	switch to {
+	case "shipped":
+		updates["shipped_at"] = now
+	case "delivered":
+		updates["delivered_at"] = now
+	case "closed":
+		updates["closed_at"] = now
+	}
+
+	result := database.DB.Model(&models.RedemptionOrder{}).Where("id = ? AND status = ?", order.ID, order.Status).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("订单状态已变更，请刷新后重试")
+	}
+
+	return database.DB.First(order, order.ID).Error
+}
+
+// canTransitRedemption 判断订单状态能否从from流转到to
+func canTransitRedemption(from, to string) bool {
+	for _, next := range redemptionTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRedeemableAvailable 检查兑换配置在指定时间是否可兑换
+func checkRedeemableAvailable(item *models.RedeemableItem, now time.Time) error {
+	if item.Status != "active" {
+		return errors.New("该实物兑换已下架")
+	}
+	if item.StartAt != nil && now.Before(*item.StartAt) {
+		return errors.New("兑换尚未开始")
+	}
+	if item.EndAt != nil && !now.Before(*item.EndAt) {
+		return errors.New("兑换已结束")
+	}
+	if item.RedeemedCount >= item.Stock {
+		return errors.New("实物库存不足")
+	}
+	return nil
+}
+
+// redemptionNotifyContent 生成订单状态变更通知内容
+func redemptionNotifyContent(order *models.RedemptionOrder) string {
+	switch order.Status {
+	case "shipped":
+		return fmt.Sprintf("您的兑换订单 %s 已发货，物流公司：%s，单号：%s", order.OrderNo, order.Carrier, order.TrackingNo)
+	case "delivered":
+		return fmt.Sprintf("您的兑换订单 %s 已签收", order.OrderNo)
+	case "closed":
+		return fmt.Sprintf("您的兑换订单 %s 已完成", order.OrderNo)
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"hoho-miniapp/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestCanTransitRedemption 测试兑换订单状态机
+func TestCanTransitRedemption(t *testing.T) {
+	tests := []struct {
+		from    string
+		to      string
+		allowed bool
+	}{
+		{"pending", "shipped", true},
+		{"pending", "delivered", false},
+		{"pending", "closed", false},
+		{"shipped", "delivered", true},
+		{"shipped", "after_sale", true},
+		{"shipped", "pending", false},
+		{"delivered", "after_sale", true},
+		{"delivered", "closed", true},
+		{"delivered", "shipped", false},
+		{"after_sale", "shipped", true},
+		{"after_sale", "closed", true},
+		{"closed", "after_sale", false},
+		{"closed", "shipped", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			assert.Equal(t, tt.allowed, canTransitRedemption(tt.from, tt.to))
+		})
+	}
+}
+
+// TestCheckRedeemableAvailable 测试兑换配置可用性判断
+func TestCheckRedeemableAvailable(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+	before := now.Add(-time.Hour)
+	after := now.Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		item        models.RedeemableItem
+		expectError bool
+	}{
+		{"有库存且在窗口内", models.RedeemableItem{Status: "active", Stock: 10, RedeemedCount: 3, StartAt: &before, EndAt: &after}, false},
+		{"库存已兑完", models.RedeemableItem{Status: "active", Stock: 10, RedeemedCount: 10}, true},
+		{"已下架", models.RedeemableItem{Status: "inactive", Stock: 10}, true},
+		{"尚未开始", models.RedeemableItem{Status: "active", Stock: 10, StartAt: &after}, true},
+		{"已经结束", models.RedeemableItem{Status: "active", Stock: 10, EndAt: &before}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRedeemableAvailable(&tt.item, now)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestValidateRedeemWindow 测试更新兑换配置时按合并后的时间窗口校验
+func TestValidateRedeemWindow(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+	end := start.Add(24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		item        models.RedeemableItem
+		updates     map[string]interface{}
+		expectError bool
+	}{
+		{"无时间窗口", models.RedeemableItem{}, map[string]interface{}{"stock": 10}, false},
+		{"仅更新开始时间", models.RedeemableItem{EndAt: &end}, map[string]interface{}{"start_at": start}, false},
+		{"开始时间晚于已有结束时间", models.RedeemableItem{EndAt: &end}, map[string]interface{}{"start_at": end.Add(time.Hour)}, true},
+		{"结束时间早于已有开始时间", models.RedeemableItem{StartAt: &start}, map[string]interface{}{"end_at": start.Add(-time.Hour)}, true},
+		{"结束时间等于开始时间", models.RedeemableItem{}, map[string]interface{}{"start_at": start, "end_at": start}, true},
+		{"同时更新为有效窗口", models.RedeemableItem{StartAt: &end, EndAt: &end}, map[string]interface{}{"start_at": start, "end_at": end}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRedeemWindow(&tt.item, tt.updates)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"gorm.io/gorm"
+)
+
+// 搜索依赖的 ngram 全文索引（ngram_token_size 默认为2，少于2个字的关键词使用前缀匹配）
+var searchIndexes = []struct {
+	Table   string
+	Name    string
+	Columns string
+}{
+	{Table: "assets", Name: "ft_assets_name_description", Columns: "name, description"},
+	{Table: "collections", Name: "ft_collections_name", Columns: "name"},
+	{Table: "users", Name: "ft_users_nickname", Columns: "nickname"},
+}
+
+// searchActivityDays 搜索排序统计成交活跃度的天数
+const searchActivityDays = 30
+
+// maxSearchKeywordLength 搜索关键词最大字数
+const maxSearchKeywordLength = 50
+
+// 搜索范围
+const (
+	SearchTypeAsset      = "asset"
+	SearchTypeCollection = "collection"
+	SearchTypeCreator    = "creator"
+	SearchTypeUser       = "user"
+)
+
+// SearchService 定义全站搜索服务接口
+type SearchService struct{}
+
+// NewSearchService 创建一个新的SearchService实例
+func NewSearchService() *SearchService {
+	return &SearchService{}
+}
+
+// SearchAssetHit 藏品搜索结果
+type SearchAssetHit struct {
+	ID           uint64  `json:"id"`
+	Name         string  `json:"name"`
+	ThumbnailURL string  `json:"thumbnail_url"`
+	CreatorName  string  `json:"creator_name"`
+	Source       string  `json:"source"`
+	Price        string  `json:"price"`
+	Score        float64 `json:"score"`
+}
+
+// SearchCollectionHit 集合搜索结果
+type SearchCollectionHit struct {
+	ID          uint64  `json:"id"`
+	Name        string  `json:"name"`
+	CoverImage  string  `json:"cover_image"`
+	CreatorName string  `json:"creator_name"`
+	Score       float64 `json:"score"`
+}
+
+// SearchUserHit 用户/创作者搜索结果，仅包含公开信息
+type SearchUserHit struct {
+	ID        uint64  `json:"id"`
+	UID       string  `json:"uid"`
+	Nickname  string  `json:"nickname"`
+	AvatarURL string  `json:"avatar_url"`
+	Score     float64 `json:"score"`
+}
+
+// SearchResult 全站搜索结果，按类型分组
+type SearchResult struct {
+	Keyword     string                `json:"keyword"`
+	Assets      []SearchAssetHit      `json:"assets"`
+	Collections []SearchCollectionHit `json:"collections"`
+	Creators    []SearchUserHit       `json:"creators"`
+	Users       []SearchUserHit       `json:"users"`
+}
+
+// EnsureIndexes 为已有数据库补建全文索引（由 cmd/migrate 调用）
+func (s *SearchService) EnsureIndexes() error {
+	m := database.DB.Migrator()
+	for _, index := range searchIndexes {
+		if m.HasIndex(index.Table, index.Name) {
+			continue
+		}
+		sql := fmt.Sprintf("ALTER TABLE `%s` ADD FULLTEXT INDEX `%s` (%s) WITH PARSER ngram", index.Table, index.Name, index.Columns)
+		if err := database.DB.Exec(sql).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Search 搜索藏品、集合、创作者和用户，types为空时搜索全部类型
+// 相关度按成交活跃度加权：score = 相关度 × (1 + ln(1 + 近30天成交数))
+func (s *SearchService) Search(keyword string, types []string, limit int) (*SearchResult, error) {
+	keyword = normalizeSearchKeyword(keyword)
+	if keyword == "" {
+		return nil, errors.New("请输入搜索关键词")
+	}
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	want := make(map[string]bool)
+	for _, t := range types {
+		switch t {
+		case SearchTypeAsset, SearchTypeCollection, SearchTypeCreator, SearchTypeUser:
+			want[t] = true
+		case "":
+		default:
+			return nil, fmt.Errorf("不支持的搜索类型: %s", t)
+		}
+	}
+	all := len(want) == 0
+
+	result := &SearchResult{
+		Keyword:     keyword,
+		Assets:      []SearchAssetHit{},
+		Collections: []SearchCollectionHit{},
+		Creators:    []SearchUserHit{},
+		Users:       []SearchUserHit{},
+	}
+	since := time.Now().AddDate(0, 0, -searchActivityDays)
+
+	if all || want[SearchTypeAsset] {
+		if err := s.searchAssets(keyword, since, limit).Scan(&result.Assets).Error; err != nil {
+			return nil, err
+		}
+	}
+	if all || want[SearchTypeCollection] {
+		if err := s.searchCollections(keyword, since, limit).Scan(&result.Collections).Error; err != nil {
+			return nil, err
+		}
+	}
+	if all || want[SearchTypeCreator] {
+		if err := s.searchCreators(keyword, since, limit).Scan(&result.Creators).Error; err != nil {
+			return nil, err
+		}
+	}
+	if all || want[SearchTypeUser] {
+		if err := s.searchUsers(keyword, limit).Scan(&result.Users).Error; err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Suggest 输入联想：返回与前缀匹配的藏品名、集合名和创作者昵称，按成交活跃度排序并去重
+func (s *SearchService) Suggest(prefix string, limit int) ([]string, error) {
+	prefix = normalizeSearchKeyword(prefix)
+	if prefix == "" {
+		return []string{}, nil
+	}
+	if limit < 1 || limit > 20 {
+		limit = 10
+	}
+	since := time.Now().AddDate(0, 0, -searchActivityDays)
+	pattern := escapeLike(prefix) + "%"
+
+	var names []string
+	var assetNames []string
+	if err := database.DB.Table("assets").
+		Select("assets.name").
+		Joins(recentTradesJoin, since).
+		Where("assets.status = ? AND assets.deleted_at IS NULL AND assets.name LIKE ?", "active", pattern).
+		Order("COALESCE(tr.cnt, 0) desc, assets.id desc").
+		Limit(limit).Pluck("assets.name", &assetNames).Error; err != nil {
+		return nil, err
+	}
+	names = append(names, assetNames...)
+
+	var collectionNames []string
+	if err := database.DB.Model(&models.Collection{}).
+		Where("status = ? AND name LIKE ?", "active", pattern).
+		Order("sort_order asc, id desc").
+		Limit(limit).Pluck("name", &collectionNames).Error; err != nil {
+		return nil, err
+	}
+	names = append(names, collectionNames...)
+
+	var creatorNames []string
+	if err := database.DB.Model(&models.User{}).
+		Where("status = ? AND nickname LIKE ?", "active", pattern).
+		Where("EXISTS (SELECT 1 FROM assets WHERE assets.creator_id = users.id AND assets.status = 'active' AND assets.deleted_at IS NULL)").
+		Order("id desc").
+		Limit(limit).Pluck("nickname", &creatorNames).Error; err != nil {
+		return nil, err
+	}
+	names = append(names, creatorNames...)
+
+	return dedupeSuggestions(names, limit), nil
+}
+
+// searchAssets 按名称和描述搜索已上架藏品
+func (s *SearchService) searchAssets(keyword string, since time.Time, limit int) *gorm.DB {
+	match, args := searchMatchExpr(keyword, "assets.name, assets.description", "assets.name")
+	return database.DB.Table("assets").
+		Select("assets.id, assets.name, assets.thumbnail_url, assets.creator_name, assets.source, assets.price, "+
+			"("+match+") * (1 + LN(1 + COALESCE(tr.cnt, 0))) AS score", args...).
+		Joins(recentTradesJoin, since).
+		Where("assets.status = ? AND assets.deleted_at IS NULL", "active").
+		Where("("+match+") > 0", args...).
+		Order("score desc, assets.id desc").
+		Limit(limit)
+}
+
+// searchCollections 按名称搜索已上架集合，成交活跃度按集合内藏品汇总
+func (s *SearchService) searchCollections(keyword string, since time.Time, limit int) *gorm.DB {
+	match, args := searchMatchExpr(keyword, "collections.name", "collections.name")
+	return database.DB.Table("collections").
+		Select("collections.id, collections.name, collections.cover_image, collections.creator_name, "+
+			"("+match+") * (1 + LN(1 + COALESCE(ct.cnt, 0))) AS score", args...).
+		Joins(`LEFT JOIN (
+			SELECT assets.collection_id, SUM(tr.cnt) AS cnt FROM assets `+recentTradesJoin+`
+			GROUP BY assets.collection_id
+		) ct ON ct.collection_id = collections.id`, since).
+		Where("collections.status = ? AND collections.deleted_at IS NULL", "active").
+		Where("("+match+") > 0", args...).
+		Order("score desc, collections.id desc").
+		Limit(limit)
+}
+
+// searchCreators 按昵称搜索有上架藏品的创作者，成交活跃度按其藏品汇总
+func (s *SearchService) searchCreators(keyword string, since time.Time, limit int) *gorm.DB {
+	match, args := searchMatchExpr(keyword, "users.nickname", "users.nickname")
+	return database.DB.Table("users").
+		Select("users.id, users.uid, users.nickname, users.avatar_url, "+
+			"("+match+") * (1 + LN(1 + COALESCE(ut.cnt, 0))) AS score", args...).
+		Joins(`JOIN (
+			SELECT assets.creator_id, SUM(COALESCE(tr.cnt, 0)) AS cnt FROM assets `+recentTradesJoin+`
+			WHERE assets.status = 'active' AND assets.deleted_at IS NULL
+			GROUP BY assets.creator_id
+		) ut ON ut.creator_id = users.id`, since).
+		Where("users.status = ? AND users.deleted_at IS NULL", "active").
+		Where("("+match+") > 0", args...).
+		Order("score desc, users.id desc").
+		Limit(limit)
+}
+
+// searchUsers 按UID前缀搜索用户
+func (s *SearchService) searchUsers(keyword string, limit int) *gorm.DB {
+	return database.DB.Table("users").
+		Select("users.id, users.uid, users.nickname, users.avatar_url, 1 AS score").
+		Where("users.status = ? AND users.deleted_at IS NULL", "active").
+		Where("users.uid LIKE ?", escapeLike(strings.ToUpper(keyword))+"%").
+		Order("LENGTH(users.uid) asc, users.id desc").
+		Limit(limit)
+}
+
+// searchMatchExpr 生成相关度表达式：两个字及以上使用 ngram 全文短语匹配，单字使用名称前缀匹配
+func searchMatchExpr(keyword, columns, nameColumn string) (string, []interface{}) {
+	if utf8.RuneCountInString(keyword) < 2 {
+		return "CASE WHEN " + nameColumn + " LIKE ? THEN 1 ELSE 0 END", []interface{}{escapeLike(keyword) + "%"}
+	}
+	return "MATCH(" + columns + ") AGAINST(? IN BOOLEAN MODE)", []interface{}{booleanPhrase(keyword)}
+}
+
+// normalizeSearchKeyword 去除首尾空白、合并连续空白、去掉全文检索运算符并限制长度
+func normalizeSearchKeyword(keyword string) string {
+	keyword = strings.Map(func(r rune) rune {
+		switch r {
+		case '+', '-', '<', '>', '(', ')', '~', '*', '"', '@', '\\':
+			return ' '
+		}
+		return r
+	}, keyword)
+	keyword = strings.Join(strings.Fields(keyword), " ")
+	if utf8.RuneCountInString(keyword) > maxSearchKeywordLength {
+		keyword = strings.TrimSpace(string([]rune(keyword)[:maxSearchKeywordLength]))
+	}
+	return keyword
+}
+
+// booleanPhrase 将关键词包装为布尔模式短语，要求 ngram 连续出现
+func booleanPhrase(keyword string) string {
+	return `"` + keyword + `"`
+}
+
+// escapeLike 转义 LIKE 通配符
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// dedupeSuggestions 去除重复和空白的联想词，保留原有顺序
+func dedupeSuggestions(names []string, limit int) []string {
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, limit)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		result = append(result, name)
+		if len(result) == limit {
+			break
+		}
+	}
+	return result
+}
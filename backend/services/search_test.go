@@ -0,0 +1,44 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNormalizeSearchKeyword 测试搜索关键词清洗
+func TestNormalizeSearchKeyword(t *testing.T) {
+	assert.Equal(t, "山海 经", normalizeSearchKeyword("  山海   经 "))
+	// 去掉全文检索运算符，避免用户输入改变匹配语义
+	assert.Equal(t, "山海 限定", normalizeSearchKeyword(`+山海 -"限定"*`))
+	assert.Equal(t, "", normalizeSearchKeyword(" ()~ "))
+
+	long := ""
+	for i := 0; i < 60; i++ {
+		long += "龙"
+	}
+	assert.Equal(t, maxSearchKeywordLength, len([]rune(normalizeSearchKeyword(long))))
+}
+
+// TestSearchMatchExpr 测试单字前缀匹配与多字全文匹配
+func TestSearchMatchExpr(t *testing.T) {
+	expr, args := searchMatchExpr("龙", "assets.name, assets.description", "assets.name")
+	assert.Contains(t, expr, "assets.name LIKE ?")
+	assert.Equal(t, []interface{}{"龙%"}, args)
+
+	expr, args = searchMatchExpr("山海", "assets.name, assets.description", "assets.name")
+	assert.Equal(t, "MATCH(assets.name, assets.description) AGAINST(? IN BOOLEAN MODE)", expr)
+	assert.Equal(t, []interface{}{`"山海"`}, args)
+}
+
+// TestEscapeLike 测试LIKE通配符转义
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, `100\%\_a\\b`, escapeLike(`100%_a\b`))
+}
+
+// TestDedupeSuggestions 测试联想词去重
+func TestDedupeSuggestions(t *testing.T) {
+	names := []string{"山海", " 山海 ", "", "山海经", "山海集", "山海图"}
+	assert.Equal(t, []string{"山海", "山海经", "山海集"}, dedupeSuggestions(names, 3))
+	assert.Empty(t, dedupeSuggestions(nil, 5))
+}
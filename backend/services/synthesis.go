@@ -0,0 +1,296 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SynthesisService 定义合成服务接口
+type SynthesisService struct {
+	assetService *AssetService
+}
+
+// NewSynthesisService 创建一个新的SynthesisService实例
+func NewSynthesisService() *SynthesisService {
+	return &SynthesisService{assetService: NewAssetService()}
+}
+
+// RecipeInput 创建配方时的材料参数
+type RecipeInput struct {
+	AssetID  uint64 `json:"asset_id" binding:"required"`
+	Quantity int    `json:"quantity" binding:"required,min=1"`
+}
+
+// CreateRecipe 创建合成配方（管理员）
+func (s *SynthesisService) CreateRecipe(name, description string, outputAssetID uint64, outputCount int, startAt, endAt *time.Time, maxTimes int, inputs []RecipeInput) (*models.SynthesisRecipe, error) {
+	if len(inputs) == 0 {
+		return nil, errors.New("配方至少需要一种材料")
+	}
+	if outputCount < 1 {
+		return nil, errors.New("产出数量必须大于0")
+	}
+	if startAt != nil && endAt != nil && !endAt.After(*startAt) {
+		return nil, errors.New("结束时间必须晚于开始时间")
+	}
+
+	var outputAsset models.Asset
+	if err := database.DB.First(&outputAsset, outputAssetID).Error; err != nil {
+		return nil, errors.New("产出藏品不存在")
+	}
+
+	seen := make(map[uint64]bool)
+	for _, input := range inputs {
+		if input.AssetID == outputAssetID {
+			return nil, errors.New("材料不能与产出藏品相同")
+		}
+		if seen[input.AssetID] {
+			return nil, errors.New("同一材料藏品不能重复配置")
+		}
+		seen[input.AssetID] = true
+	}
+
+	recipe := models.SynthesisRecipe{
+		Name:          name,
+		Description:   description,
+		OutputAssetID: outputAssetID,
+		OutputCount:   outputCount,
+		StartAt:       startAt,
+		EndAt:         endAt,
+		MaxTimes:      maxTimes,
+		Status:        "active",
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&recipe).Error; err != nil {
+			return err
+		}
+		for _, input := range inputs {
+			var asset models.Asset
+			if err := tx.First(&asset, input.AssetID).Error; err != nil {
+				return fmt.Errorf("材料藏品 %d 不存在", input.AssetID)
+			}
+			recipeInput := models.SynthesisRecipeInput{
+				RecipeID: recipe.ID,
+				AssetID:  input.AssetID,
+				Quantity: input.Quantity,
+			}
+			if err := tx.Create(&recipeInput).Error; err != nil {
+				return err
+			}
+			recipe.Inputs = append(recipe.Inputs, recipeInput)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &recipe, nil
+}
+
+// UpdateRecipeStatus 启用/停用合成配方（管理员）
+func (s *SynthesisService) UpdateRecipeStatus(recipeID uint64, status string) error {
+	result := database.DB.Model(&models.SynthesisRecipe{}).Where("id = ?", recipeID).Update("status", status)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("合成配方不存在")
+	}
+	return nil
+}
+
+// ListRecipes 获取合成配方列表，onlyAvailable为true时仅返回当前可合成的配方
+func (s *SynthesisService) ListRecipes(onlyAvailable bool, page, pageSize int) ([]models.SynthesisRecipe, int64, error) {
+	var recipes []models.SynthesisRecipe
+	var total int64
+
+	query := database.DB.Model(&models.SynthesisRecipe{})
+	if onlyAvailable {
+		now := time.Now()
+		query = query.Where("status = ?", "active").
+			Where("start_at IS NULL OR start_at <= ?", now).
+			Where("end_at IS NULL OR end_at > ?", now).
+			Where("max_times = 0 OR used_times < max_times")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Preload("Inputs.Asset").Preload("OutputAsset").Order("id desc").Offset(offset).Limit(pageSize).Find(&recipes).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return recipes, total, nil
+}
+
+// GetRecipe 获取合成配方详情
+func (s *SynthesisService) GetRecipe(recipeID uint64) (*models.SynthesisRecipe, error) {
+	var recipe models.SynthesisRecipe
+	if err := database.DB.Preload("Inputs.Asset").Preload("OutputAsset").First(&recipe, recipeID).Error; err != nil {
+		return nil, errors.New("合成配方不存在")
+	}
+	return &recipe, nil
+}
+
+// Synthesize 执行合成：销毁材料实例并铸造产出藏品，全部在同一事务中完成
+func (s *SynthesisService) Synthesize(userID, recipeID uint64, instanceIDs []uint64) (*models.SynthesisRecord, []models.AssetInstance, error) {
+	var record models.SynthesisRecord
+	var outputs []models.AssetInstance
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// 1. 锁定配方，保证全局次数上限在并发下准确
+		var recipe models.SynthesisRecipe
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Inputs").First(&recipe, recipeID).Error; err != nil {
+			return errors.New("合成配方不存在")
+		}
+		if err := checkRecipeAvailable(&recipe, time.Now()); err != nil {
+			return err
+		}
+
+		// 2. 锁定并校验材料实例
+		var instances []models.AssetInstance
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id IN ?", instanceIDs).Find(&instances).Error; err != nil {
+			return err
+		}
+		if len(instances) != len(instanceIDs) {
+			return errors.New("部分材料藏品不存在")
+		}
+		for _, instance := range instances {
+			if instance.OwnerID != userID {
+				return errors.New("你不是材料藏品的拥有者")
+			}
+			if instance.Status != "in_wallet" {
+				return fmt.Errorf("材料藏品 %s 当前不可用于合成", instance.TokenID)
+			}
+		}
+		if err := matchRecipeInputs(recipe.Inputs, instances); err != nil {
+			return err
+		}
+
+		// 3. 销毁材料，销毁、铸造与合成事件在事务最后统一记录
+		var events eventBatch
+		if err := s.assetService.BurnInstancesTx(tx, &events, userID, instances, fmt.Sprintf("合成《%s》", recipe.Name)); err != nil {
+			return err
+		}
+
+		// 4. 铸造产出藏品
+		minted, err := s.assetService.MintAndAirdropTx(tx, &events, recipe.OutputAssetID, userID, recipe.OutputCount)
+		if err != nil {
+			return err
+		}
+		outputs = minted
+
+		// 5. 累加配方使用次数
+		if err := tx.Model(&recipe).Update("used_times", gorm.Expr("used_times + ?", 1)).Error; err != nil {
+			return err
+		}
+
+		// 6. 记录合成记录和社区事件
+		record = models.SynthesisRecord{
+			RecipeID:          recipe.ID,
+			UserID:            userID,
+			BurnedInstanceIDs: joinInstanceIDs(instances),
+			OutputInstanceIDs: joinInstanceIDs(outputs),
+		}
+		if err := tx.Create(&record).Error; err != nil {
+			return err
+		}
+
+		userUID, err := eventUserTx(tx, userID)
+		if err != nil {
+			return err
+		}
+		description := fmt.Sprintf("用户 %s 使用配方《%s》销毁 %d 个藏品，合成 %d 个新藏品", userUID, recipe.Name, len(instances), len(outputs))
+		events.add("synthesis", userID, description, record.ID, "synthesis_record")
+		return events.recordTx(tx)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &record, outputs, nil
+}
+
+// GetUserRecords 获取用户的合成记录
+func (s *SynthesisService) GetUserRecords(userID uint64, page, pageSize int) ([]models.SynthesisRecord, int64, error) {
+	var records []models.SynthesisRecord
+	var total int64
+
+	query := database.DB.Model(&models.SynthesisRecord{}).Where("user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Order("id desc").Offset(offset).Limit(pageSize).Find(&records).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, nil
+}
+
+// checkRecipeAvailable 检查配方在指定时间是否可合成
+func checkRecipeAvailable(recipe *models.SynthesisRecipe, now time.Time) error {
+	if recipe.Status != "active" {
+		return errors.New("合成配方已停用")
+	}
+	if recipe.StartAt != nil && now.Before(*recipe.StartAt) {
+		return errors.New("合成尚未开始")
+	}
+	if recipe.EndAt != nil && !now.Before(*recipe.EndAt) {
+		return errors.New("合成已结束")
+	}
+	if recipe.MaxTimes > 0 && recipe.UsedTimes >= recipe.MaxTimes {
+		return errors.New("合成次数已达上限")
+	}
+	return nil
+}
+
+// matchRecipeInputs 校验提交的材料实例与配方要求完全一致（种类和数量都不能多也不能少）
+func matchRecipeInputs(inputs []models.SynthesisRecipeInput, instances []models.AssetInstance) error {
+	required := make(map[uint64]int)
+	for _, input := range inputs {
+		required[input.AssetID] += input.Quantity
+	}
+
+	provided := make(map[uint64]int)
+	seen := make(map[uint64]bool)
+	for _, instance := range instances {
+		if seen[instance.ID] {
+			return errors.New("材料藏品不能重复提交")
+		}
+		seen[instance.ID] = true
+		if _, ok := required[instance.AssetID]; !ok {
+			return fmt.Errorf("藏品 %d 不是该配方的材料", instance.AssetID)
+		}
+		provided[instance.AssetID]++
+	}
+
+	for assetID, quantity := range required {
+		if provided[assetID] != quantity {
+			return fmt.Errorf("材料藏品 %d 需要 %d 个，实际提交 %d 个", assetID, quantity, provided[assetID])
+		}
+	}
+	return nil
+}
+
+// joinInstanceIDs 将实例ID拼接为逗号分隔字符串
+func joinInstanceIDs(instances []models.AssetInstance) string {
+	ids := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		ids = append(ids, strconv.FormatUint(instance.ID, 10))
+	}
+	return strings.Join(ids, ",")
+}
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"hoho-miniapp/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMatchRecipeInputs 测试合成材料校验
+func TestMatchRecipeInputs(t *testing.T) {
+	// 配方：3个藏品A + 1个藏品B
+	inputs := []models.SynthesisRecipeInput{
+		{AssetID: 1, Quantity: 3},
+		{AssetID: 2, Quantity: 1},
+	}
+
+	tests := []struct {
+		name        string
+		instances   []models.AssetInstance
+		expectError bool
+	}{
+		{
+			name: "材料完全匹配",
+			instances: []models.AssetInstance{
+				{ID: 11, AssetID: 1}, {ID: 12, AssetID: 1}, {ID: 13, AssetID: 1}, {ID: 21, AssetID: 2},
+			},
+			expectError: false,
+		},
+		{
+			name: "材料数量不足",
+			instances: []models.AssetInstance{
+				{ID: 11, AssetID: 1}, {ID: 12, AssetID: 1}, {ID: 21, AssetID: 2},
+			},
+			expectError: true,
+		},
+		{
+			name: "材料数量过多",
+			instances: []models.AssetInstance{
+				{ID: 11, AssetID: 1}, {ID: 12, AssetID: 1}, {ID: 13, AssetID: 1}, {ID: 14, AssetID: 1}, {ID: 21, AssetID: 2},
+			},
+			expectError: true,
+		},
+		{
+			name: "包含非配方材料",
+			instances: []models.AssetInstance{
+				{ID: 11, AssetID: 1}, {ID: 12, AssetID: 1}, {ID: 13, AssetID: 1}, {ID: 21, AssetID: 2}, {ID: 31, AssetID: 3},
+			},
+			expectError: true,
+		},
+		{
+			name: "重复提交同一实例",
+			instances: []models.AssetInstance{
+				{ID: 11, AssetID: 1}, {ID: 11, AssetID: 1}, {ID: 13, AssetID: 1}, {ID: 21, AssetID: 2},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := matchRecipeInputs(inputs, tt.instances)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestCheckRecipeAvailable 测试配方可用性判断
+func TestCheckRecipeAvailable(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+	before := now.Add(-time.Hour)
+	after := now.Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		recipe      models.SynthesisRecipe
+		expectError bool
+	}{
+		{"不限时间和次数", models.SynthesisRecipe{Status: "active"}, false},
+		{"已停用", models.SynthesisRecipe{Status: "inactive"}, true},
+		{"尚未开始", models.SynthesisRecipe{Status: "active", StartAt: &after}, true},
+		{"已经结束", models.SynthesisRecipe{Status: "active", EndAt: &before}, true},
+		{"在时间窗口内", models.SynthesisRecipe{Status: "active", StartAt: &before, EndAt: &after}, false},
+		{"次数已达上限", models.SynthesisRecipe{Status: "active", MaxTimes: 10, UsedTimes: 10}, true},
+		{"次数未达上限", models.SynthesisRecipe{Status: "active", MaxTimes: 10, UsedTimes: 9}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkRecipeAvailable(&tt.recipe, now)
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"fmt"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+)
+
+// getConfigInt 读取整数类型的系统配置，不存在或格式错误时返回默认值
+func getConfigInt(key string, defaultValue int) int {
+	var config models.SystemConfig
+	if err := database.DB.Where("`key` = ?", key).First(&config).Error; err != nil {
+		return defaultValue
+	}
+
+	value := defaultValue
+	if _, err := fmt.Sscanf(config.Value, "%d", &value); err != nil {
+		return defaultValue
+	}
+	return value
+}
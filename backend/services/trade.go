@@ -7,6 +7,7 @@ import (
 	"hoho-miniapp/backend/config"
 	"hoho-miniapp/backend/database"
 	"hoho-miniapp/backend/models"
+	"hoho-miniapp/backend/utils"
 	"time"
 
 	"github.com/shopspring/decimal"
@@ -21,6 +22,50 @@ func NewTradeService() *TradeService {
 	return &TradeService{}
 }
 
+// EnsureSchema 为已有数据库补建交易单号和成交时间字段，为历史交易补发单号后建立唯一索引（由 cmd/migrate 调用）
+func (s *TradeService) EnsureSchema() error {
+	m := database.DB.Migrator()
+	if !m.HasColumn(&models.Trade{}, "CompletedAt") {
+		if err := m.AddColumn(&models.Trade{}, "CompletedAt"); err != nil {
+			return err
+		}
+	}
+	if !m.HasIndex(&models.Trade{}, "CompletedAt") {
+		if err := m.CreateIndex(&models.Trade{}, "CompletedAt"); err != nil {
+			return err
+		}
+	}
+	// 历史已完成交易没有成交时间，以最后更新时间近似补齐
+	if err := database.DB.Unscoped().Model(&models.Trade{}).
+		Where("status = ? AND completed_at IS NULL", "completed").
+		UpdateColumn("completed_at", gorm.Expr("updated_at")).Error; err != nil {
+		return err
+	}
+
+	if !m.HasColumn(&models.Trade{}, "TradeNo") {
+		if err := m.AddColumn(&models.Trade{}, "TradeNo"); err != nil {
+			return err
+		}
+	}
+
+	var ids []uint64
+	if err := database.DB.Unscoped().Model(&models.Trade{}).
+		Where("trade_no IS NULL OR trade_no = ''").Order("id asc").Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if err := database.DB.Unscoped().Model(&models.Trade{}).Where("id = ?", id).
+			UpdateColumn("trade_no", utils.GenerateOrderNo("TR")).Error; err != nil {
+			return err
+		}
+	}
+
+	if !m.HasIndex(&models.Trade{}, "TradeNo") {
+		return m.CreateIndex(&models.Trade{}, "TradeNo")
+	}
+	return nil
+}
+
 // CreateListing 创建挂售单
 func (s *TradeService) CreateListing(sellerID uint64, assetInstanceID uint64, price decimal.Decimal) (*models.Listing, error) {
 	// 1. 检查藏品实例是否存在且属于卖家
@@ -189,10 +234,15 @@ func (s *TradeService) ExecuteTrade(listingID uint64, buyerID uint64) (*models.T
 		// 6.3. 计算手续费和版税（使用银行家舍入法，精确到8位小数）
 		platformFee := listing.Price.Mul(config.AppConfig.PlatformFeeRate).RoundBank(config.AppConfig.DecimalPrecision)
 		creatorRoyalty := listing.Price.Mul(config.AppConfig.CreatorRoyaltyRate).RoundBank(config.AppConfig.DecimalPrecision)
-		sellerReceived := listing.Price.Sub(platformFee).Sub(creatorRoyalty)
+		// 衍生作品另按血缘链向上游创作者分成，由卖家承担
+		royaltyShares, parentRoyalty, err := upstreamRoyaltyTx(tx, freshInstance.AssetID, listing.Price)
+		if err != nil {
+			return err
+		}
+		sellerReceived := listing.Price.Sub(platformFee).Sub(creatorRoyalty).Sub(parentRoyalty)
 
 		// 验证积分守恒（防止舍入误差导致积分不守恒）
-		totalDistributed := platformFee.Add(creatorRoyalty).Add(sellerReceived)
+		totalDistributed := platformFee.Add(creatorRoyalty).Add(parentRoyalty).Add(sellerReceived)
 		if !totalDistributed.Equal(listing.Price) {
 			// 如果有舍入误差，调整卖家收入（误差通常在0.00000001以内）
 			diff := listing.Price.Sub(totalDistributed)
@@ -201,6 +251,7 @@ func (s *TradeService) ExecuteTrade(listingID uint64, buyerID uint64) (*models.T
 
 		// 6.4. 创建Trade记录
 		*trade = models.Trade{
+			TradeNo:         utils.GenerateOrderNo("TR"),
 			ListingID:       listingID,
 			AssetInstanceID: listing.AssetInstanceID,
 			BuyerID:         buyerID,
@@ -208,11 +259,15 @@ func (s *TradeService) ExecuteTrade(listingID uint64, buyerID uint64) (*models.T
 			Price:           listing.Price,
 			PlatformFee:     platformFee,
 			CreatorRoyalty:  creatorRoyalty,
+			ParentRoyalty:   parentRoyalty,
 			SellerReceived:  sellerReceived,
 			Status:          "pending",
 		}
 
-		if err := tx.Create(trade).Error; err != nil {
+		if err := createWithRetry(tx, trade, "trade_no", func() { trade.TradeNo = utils.GenerateOrderNo("TR") }); err != nil {
+			return err
+		}
+		if err := createRoyaltyPayoutsTx(tx, RoyaltySourceTrade, trade.ID, freshInstance.AssetID, royaltyShares, false); err != nil {
 			return err
 		}
 
@@ -259,6 +314,18 @@ func (s *TradeService) CompleteTradePayment(tradeID uint64) error {
 	}
 
 	return database.DB.Transaction(func(tx *gorm.DB) error {
+		// 0. 以状态为条件抢占交易，防止重复结算
+		result := tx.Model(&models.Trade{}).Where("id = ? AND status = ?", trade.ID, "pending").Updates(map[string]interface{}{
+			"status":       "completed",
+			"completed_at": time.Now(),
+		})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("交易已处理")
+		}
+
 		// 1. 买家：扣减积分（从冻结改为已扣减）
 		if err := tx.Model(&models.UserPoint{}).Where("user_id = ?", trade.BuyerID).Updates(map[string]interface{}{
 			"balance":     gorm.Expr("balance - ?", trade.Price),
@@ -276,14 +343,36 @@ func (s *TradeService) CompleteTradePayment(tradeID uint64) error {
 			return err
 		}
 
-		// 3. 创作者：增加版税
-		if err := tx.Model(&models.UserPoint{}).Where("user_id = ?", gorm.Expr("(SELECT creator_id FROM assets WHERE id = (SELECT asset_id FROM asset_instances WHERE id = ?))", trade.AssetInstanceID)).Updates(map[string]interface{}{
+		// 3. 创作者：增加版税，平台藏品的版税计入平台分成
+		var creatorID uint64
+		if err := tx.Model(&models.Asset{}).Select("creator_id").
+			Where("id = (SELECT asset_id FROM asset_instances WHERE id = ?)", trade.AssetInstanceID).
+			Scan(&creatorID).Error; err != nil {
+			return err
+		}
+		if creatorID == 0 {
+			if err := NewPlatformAccountService().RecordPlatformIncomeTx(tx, "commission", trade.CreatorRoyalty,
+				fmt.Sprintf("交易 %s 平台藏品版税", trade.TradeNo), trade.ID, "trade"); err != nil {
+				return err
+			}
+		} else if err := tx.Model(&models.UserPoint{}).Where("user_id = ?", creatorID).Updates(map[string]interface{}{
 			"balance":      gorm.Expr("balance + ?", trade.CreatorRoyalty),
 			"total_earned": gorm.Expr("total_earned + ?", trade.CreatorRoyalty),
 		}).Error; err != nil {
 			return err
 		}
 
+		// 3.1. 平台：记入交易手续费
+		if err := NewPlatformAccountService().RecordPlatformIncomeTx(tx, "fee", trade.PlatformFee,
+			fmt.Sprintf("交易 %s 手续费", trade.TradeNo), trade.ID, "trade"); err != nil {
+			return err
+		}
+
+		// 3.2. 上游创作者：衍生作品版税
+		if err := payRoyaltyPayoutsTx(tx, RoyaltySourceTrade, trade.ID); err != nil {
+			return err
+		}
+
 		// 4. 更新AssetInstance的所有者
 		if err := tx.Model(&models.AssetInstance{}).Where("id = ?", trade.AssetInstanceID).Updates(map[string]interface{}{
 			"owner_id": trade.BuyerID,
@@ -292,24 +381,33 @@ func (s *TradeService) CompleteTradePayment(tradeID uint64) error {
 			return err
 		}
 
-		// 5. 更新Trade状态为completed
-		if err := tx.Model(&trade).Update("status", "completed").Error; err != nil {
+		// 5. 登记上链转移
+		var instance models.AssetInstance
+		if err := tx.Select("id", "token_id").First(&instance, trade.AssetInstanceID).Error; err != nil {
 			return err
 		}
-
-		// 6. 记录社区事件
-		event := models.CommunityEvent{
-			EventType:   "trade",
-			UserID:      trade.BuyerID,
-			Description: fmt.Sprintf("用户 uid%d 以 %s 积分购买了藏品", trade.BuyerID, trade.Price.String()),
-			RelatedID:   trade.ID,
-			RelatedType: "trade",
-		}
-		if err := tx.Create(&event).Error; err != nil {
+		if err := enqueueAnchorTx(tx, &instance, ChainOpTransfer, trade.SellerID, trade.BuyerID); err != nil {
 			return err
 		}
 
-		return nil
+		// 6. 最后记录社区事件和所有权变更
+		buyerUID, err := eventUserTx(tx, trade.BuyerID)
+		if err != nil {
+			return err
+		}
+		description := fmt.Sprintf("用户 %s 以 %s 积分购买了藏品", buyerUID, trade.Price.String())
+		var events eventBatch
+		events.add("trade", trade.BuyerID, description, trade.ID, "trade", models.OwnershipRecord{
+			AssetInstanceID: instance.ID,
+			TokenID:         instance.TokenID,
+			EventType:       ProvenanceTrade,
+			FromUserID:      trade.SellerID,
+			ToUserID:        trade.BuyerID,
+			Price:           &trade.Price,
+			RelatedID:       trade.ID,
+			RelatedType:     "trade",
+		})
+		return events.recordTx(tx)
 	})
 }
 
@@ -331,24 +429,31 @@ func (s *TradeService) GetListings(page, pageSize int) ([]models.Listing, int64,
 	return listings, total, nil
 }
 
-// ListListings 获取挂售列表（支持状态筛选）
-func (s *TradeService) ListListings(status string, page, pageSize int) ([]models.Listing, int64, error) {
+// ListListings 获取挂售列表（支持状态、属性筛选，sortBy为rarity时按稀有度排名排序）
+func (s *TradeService) ListListings(status string, traits []TraitFilter, sortBy string, page, pageSize int) ([]models.Listing, int64, error) {
 	var listings []models.Listing
 	var total int64
 
-	query := database.DB.Model(&models.Listing{})
+	query := database.DB.Model(&models.Listing{}).
+		Joins("JOIN asset_instances ON asset_instances.id = listings.asset_instance_id")
 
 	// 如果指定了状态，则筛选
 	if status != "" && status != "all" {
-		query = query.Where("status = ?", status)
+		query = query.Where("listings.status = ?", status)
 	}
+	query = ApplyTraitFilters(query, traits)
 
 	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
+	order := "listings.id desc"
+	if sortBy == InstanceSortRarity {
+		order = "asset_instances.rarity_rank = 0, asset_instances.rarity_rank asc, listings.id desc"
+	}
+
 	offset := (page - 1) * pageSize
-	if err := query.Preload("AssetInstance").Preload("AssetInstance.Asset").Limit(pageSize).Offset(offset).Order("id desc").Find(&listings).Error; err != nil {
+	if err := query.Preload("AssetInstance").Preload("AssetInstance.Asset").Limit(pageSize).Offset(offset).Order(order).Find(&listings).Error; err != nil {
 		return nil, 0, err
 	}
 
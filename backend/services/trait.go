@@ -0,0 +1,482 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"hoho-miniapp/backend/database"
+	"hoho-miniapp/backend/models"
+
+	"gorm.io/gorm"
+)
+
+// 单个藏品或实例最多设置的属性数
+const maxTraitsPerTarget = 30
+
+// 实例排序方式
+const (
+	InstanceSortNumber = "number" // 按藏品、编号排序
+	InstanceSortRarity = "rarity" // 按稀有度排名排序，未计算的排在最后
+)
+
+// TraitService 定义藏品属性与稀有度服务接口
+type TraitService struct{}
+
+// NewTraitService 创建一个新的TraitService实例
+func NewTraitService() *TraitService {
+	return &TraitService{}
+}
+
+// TraitInput 属性键值对
+type TraitInput struct {
+	TraitType string `json:"trait_type"`
+	Value     string `json:"value"`
+}
+
+// TraitFilter 属性筛选条件：同一属性类型的多个值之间为“或”，不同属性类型之间为“且”
+type TraitFilter struct {
+	TraitType string
+	Values    []string
+}
+
+// TraitStat 集合内某个属性值的分布
+type TraitStat struct {
+	TraitType string  `json:"trait_type"`
+	Value     string  `json:"value"`
+	Count     int     `json:"count"`     // 拥有该属性值的实例数
+	Frequency float64 `json:"frequency"` // 占集合内实例总数的比例
+}
+
+// InstanceTraits 实例的生效属性及稀有度
+type InstanceTraits struct {
+	AssetInstanceID uint64      `json:"asset_instance_id"`
+	AssetID         uint64      `json:"asset_id"`
+	CollectionID    uint64      `json:"collection_id"`
+	InstanceNo      int         `json:"instance_no"`
+	RarityScore     float64     `json:"rarity_score"`
+	RarityRank      int         `json:"rarity_rank"`
+	Traits          []TraitStat `json:"traits"`
+}
+
+// rarityInput 计算稀有度所需的实例数据
+type rarityInput struct {
+	InstanceID uint64
+	Traits     map[string]string
+}
+
+// rarityResult 实例的稀有度得分和排名
+type rarityResult struct {
+	Score float64
+	Rank  int
+}
+
+// EnsureSchema 为已有数据库补建属性表和实例稀有度字段（由 cmd/migrate 调用）
+func (s *TraitService) EnsureSchema() error {
+	m := database.DB.Migrator()
+	if !m.HasTable(&models.AssetTrait{}) {
+		if err := m.CreateTable(&models.AssetTrait{}); err != nil {
+			return err
+		}
+	}
+	for _, field := range []string{"RarityScore", "RarityRank"} {
+		if !m.HasColumn(&models.AssetInstance{}, field) {
+			if err := m.AddColumn(&models.AssetInstance{}, field); err != nil {
+				return err
+			}
+		}
+	}
+	if !m.HasIndex(&models.AssetInstance{}, "RarityRank") {
+		return m.CreateIndex(&models.AssetInstance{}, "RarityRank")
+	}
+	return nil
+}
+
+// SetAssetTraits 替换藏品级属性（管理员），并重算所在集合的稀有度
+func (s *TraitService) SetAssetTraits(assetID uint64, traits []TraitInput) ([]models.AssetTrait, error) {
+	traits, err := normalizeTraitInputs(traits)
+	if err != nil {
+		return nil, err
+	}
+
+	var asset models.Asset
+	if err := database.DB.First(&asset, assetID).Error; err != nil {
+		return nil, errors.New("藏品不存在")
+	}
+
+	records := make([]models.AssetTrait, 0, len(traits))
+	for _, trait := range traits {
+		records = append(records, models.AssetTrait{AssetID: assetID, TraitType: trait.TraitType, Value: trait.Value})
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("asset_id = ? AND asset_instance_id IS NULL", assetID).Delete(&models.AssetTrait{}).Error; err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		return tx.Create(&records).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.RecomputeCollectionRarity(asset.CollectionID); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// SetInstanceTraits 替换实例级属性（管理员），同类型的实例属性覆盖藏品级属性，并重算所在集合的稀有度
+func (s *TraitService) SetInstanceTraits(instanceID uint64, traits []TraitInput) ([]models.AssetTrait, error) {
+	traits, err := normalizeTraitInputs(traits)
+	if err != nil {
+		return nil, err
+	}
+
+	var instance models.AssetInstance
+	if err := database.DB.Preload("Asset").First(&instance, instanceID).Error; err != nil || instance.Asset == nil {
+		return nil, errors.New("藏品实例不存在")
+	}
+
+	records := make([]models.AssetTrait, 0, len(traits))
+	for _, trait := range traits {
+		records = append(records, models.AssetTrait{
+			AssetID:         instance.AssetID,
+			AssetInstanceID: &instance.ID,
+			TraitType:       trait.TraitType,
+			Value:           trait.Value,
+		})
+	}
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("asset_instance_id = ?", instanceID).Delete(&models.AssetTrait{}).Error; err != nil {
+			return err
+		}
+		if len(records) == 0 {
+			return nil
+		}
+		return tx.Create(&records).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.RecomputeCollectionRarity(instance.Asset.CollectionID); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// RecomputeCollectionRarity 重算集合内全部未销毁实例的稀有度得分和排名
+// 集合内没有任何属性时，实例的得分和排名均重置为0
+func (s *TraitService) RecomputeCollectionRarity(collectionID uint64) error {
+	inputs, err := s.loadCollectionTraits(collectionID)
+	if err != nil {
+		return err
+	}
+	results := computeRarity(inputs)
+
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, input := range inputs {
+			result := results[input.InstanceID]
+			if err := tx.Model(&models.AssetInstance{}).Where("id = ?", input.InstanceID).
+				UpdateColumns(map[string]interface{}{"rarity_score": result.Score, "rarity_rank": result.Rank}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// RecomputeStaleRarity 重算存在未计算排名实例（如新铸造）的集合，供后台任务定时调用
+func (s *TraitService) RecomputeStaleRarity() error {
+	var collectionIDs []uint64
+	if err := database.DB.Table("asset_instances").
+		Joins("JOIN assets ON assets.id = asset_instances.asset_id").
+		Where("asset_instances.rarity_rank = 0 AND asset_instances.status <> ? AND asset_instances.deleted_at IS NULL", "burned").
+		Where("EXISTS (SELECT 1 FROM asset_traits JOIN assets ta ON ta.id = asset_traits.asset_id WHERE ta.collection_id = assets.collection_id)").
+		Distinct().Pluck("assets.collection_id", &collectionIDs).Error; err != nil {
+		return err
+	}
+
+	for _, collectionID := range collectionIDs {
+		if err := s.RecomputeCollectionRarity(collectionID); err != nil {
+			return fmt.Errorf("集合 %d 稀有度计算失败: %w", collectionID, err)
+		}
+	}
+	return nil
+}
+
+// GetCollectionTraits 获取集合内各属性值的分布，用于属性筛选
+func (s *TraitService) GetCollectionTraits(collectionID uint64) ([]TraitStat, error) {
+	var collection models.Collection
+	if err := database.DB.Where("status = ?", "active").First(&collection, collectionID).Error; err != nil {
+		return nil, errors.New("集合不存在")
+	}
+
+	inputs, err := s.loadCollectionTraits(collectionID)
+	if err != nil {
+		return nil, err
+	}
+	counts := countTraits(inputs)
+
+	stats := make([]TraitStat, 0, len(counts))
+	for key, count := range counts {
+		stats = append(stats, TraitStat{
+			TraitType: key.TraitType,
+			Value:     key.Value,
+			Count:     count,
+			Frequency: float64(count) / float64(len(inputs)),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].TraitType != stats[j].TraitType {
+			return stats[i].TraitType < stats[j].TraitType
+		}
+		if stats[i].Count != stats[j].Count {
+			return stats[i].Count < stats[j].Count
+		}
+		return stats[i].Value < stats[j].Value
+	})
+	return stats, nil
+}
+
+// GetInstanceTraits 获取实例的生效属性（含集合内占比）及稀有度
+func (s *TraitService) GetInstanceTraits(instanceID uint64) (*InstanceTraits, error) {
+	var instance models.AssetInstance
+	if err := database.DB.Preload("Asset").Where("status <> ?", "burned").First(&instance, instanceID).Error; err != nil || instance.Asset == nil {
+		return nil, errors.New("藏品实例不存在")
+	}
+
+	inputs, err := s.loadCollectionTraits(instance.Asset.CollectionID)
+	if err != nil {
+		return nil, err
+	}
+	counts := countTraits(inputs)
+
+	result := &InstanceTraits{
+		AssetInstanceID: instance.ID,
+		AssetID:         instance.AssetID,
+		CollectionID:    instance.Asset.CollectionID,
+		InstanceNo:      instance.InstanceNo,
+		RarityScore:     instance.RarityScore,
+		RarityRank:      instance.RarityRank,
+		Traits:          []TraitStat{},
+	}
+	for _, input := range inputs {
+		if input.InstanceID != instance.ID {
+			continue
+		}
+		for traitType, value := range input.Traits {
+			count := counts[TraitInput{TraitType: traitType, Value: value}]
+			result.Traits = append(result.Traits, TraitStat{
+				TraitType: traitType,
+				Value:     value,
+				Count:     count,
+				Frequency: float64(count) / float64(len(inputs)),
+			})
+		}
+	}
+	sort.Slice(result.Traits, func(i, j int) bool { return result.Traits[i].TraitType < result.Traits[j].TraitType })
+	return result, nil
+}
+
+// ListCollectionInstances 获取集合内未销毁的实例，支持属性筛选和稀有度排序
+func (s *TraitService) ListCollectionInstances(collectionID uint64, filters []TraitFilter, sortBy string, page, pageSize int) ([]models.AssetInstance, int64, error) {
+	var instances []models.AssetInstance
+	var total int64
+
+	query := database.DB.Model(&models.AssetInstance{}).
+		Where("asset_instances.status <> ?", "burned").
+		Where("asset_instances.asset_id IN (?)", database.DB.Model(&models.Asset{}).Select("id").Where("collection_id = ?", collectionID))
+	query = ApplyTraitFilters(query, filters)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	if err := query.Preload("Asset").Order(instanceOrder(sortBy)).Offset(offset).Limit(pageSize).Find(&instances).Error; err != nil {
+		return nil, 0, err
+	}
+	return instances, total, nil
+}
+
+// loadCollectionTraits 加载集合内全部未销毁实例及其生效属性
+func (s *TraitService) loadCollectionTraits(collectionID uint64) ([]rarityInput, error) {
+	var instances []models.AssetInstance
+	if err := database.DB.Select("asset_instances.id", "asset_instances.asset_id").
+		Joins("JOIN assets ON assets.id = asset_instances.asset_id").
+		Where("assets.collection_id = ? AND assets.deleted_at IS NULL AND asset_instances.status <> ?", collectionID, "burned").
+		Order("asset_instances.id asc").Find(&instances).Error; err != nil {
+		return nil, err
+	}
+	if len(instances) == 0 {
+		return []rarityInput{}, nil
+	}
+
+	var traits []models.AssetTrait
+	if err := database.DB.Joins("JOIN assets ON assets.id = asset_traits.asset_id").
+		Where("assets.collection_id = ?", collectionID).Find(&traits).Error; err != nil {
+		return nil, err
+	}
+
+	return mergeInstanceTraits(instances, traits), nil
+}
+
+// ParseTraitFilters 解析 trait=类型:值 形式的筛选参数，同一类型的多个值合并为“或”条件
+func ParseTraitFilters(params []string) ([]TraitFilter, error) {
+	var filters []TraitFilter
+	index := make(map[string]int)
+	for _, param := range params {
+		if strings.TrimSpace(param) == "" {
+			continue
+		}
+		traitType, value, ok := strings.Cut(param, ":")
+		traitType, value = strings.TrimSpace(traitType), strings.TrimSpace(value)
+		if !ok || traitType == "" || value == "" {
+			return nil, fmt.Errorf("属性筛选格式错误: %s，应为 类型:值", param)
+		}
+		if i, exists := index[traitType]; exists {
+			filters[i].Values = append(filters[i].Values, value)
+			continue
+		}
+		index[traitType] = len(filters)
+		filters = append(filters, TraitFilter{TraitType: traitType, Values: []string{value}})
+	}
+	return filters, nil
+}
+
+// ApplyTraitFilters 为包含 asset_instances 表的查询追加属性筛选条件
+// 实例命中条件：实例级属性匹配，或藏品级属性匹配且该类型未被实例级属性覆盖
+func ApplyTraitFilters(query *gorm.DB, filters []TraitFilter) *gorm.DB {
+	for _, filter := range filters {
+		query = query.Where(`(EXISTS (SELECT 1 FROM asset_traits it WHERE it.asset_instance_id = asset_instances.id AND it.trait_type = ? AND it.value IN ?)
+			OR (EXISTS (SELECT 1 FROM asset_traits atr WHERE atr.asset_id = asset_instances.asset_id AND atr.asset_instance_id IS NULL AND atr.trait_type = ? AND atr.value IN ?)
+				AND NOT EXISTS (SELECT 1 FROM asset_traits ot WHERE ot.asset_instance_id = asset_instances.id AND ot.trait_type = ?)))`,
+			filter.TraitType, filter.Values, filter.TraitType, filter.Values, filter.TraitType)
+	}
+	return query
+}
+
+// instanceOrder 实例列表排序子句
+func instanceOrder(sortBy string) string {
+	if sortBy == InstanceSortRarity {
+		return "asset_instances.rarity_rank = 0, asset_instances.rarity_rank asc, asset_instances.id asc"
+	}
+	return "asset_instances.asset_id asc, asset_instances.instance_no asc"
+}
+
+// normalizeTraitInputs 校验属性列表：类型和值不能为空、不超过长度限制，同一类型不能重复
+func normalizeTraitInputs(traits []TraitInput) ([]TraitInput, error) {
+	if len(traits) > maxTraitsPerTarget {
+		return nil, fmt.Errorf("属性数量不能超过%d个", maxTraitsPerTarget)
+	}
+	seen := make(map[string]bool, len(traits))
+	result := make([]TraitInput, 0, len(traits))
+	for _, trait := range traits {
+		traitType := strings.TrimSpace(trait.TraitType)
+		value := strings.TrimSpace(trait.Value)
+		if traitType == "" || value == "" {
+			return nil, errors.New("属性类型和值不能为空")
+		}
+		if utf8.RuneCountInString(traitType) > 50 || utf8.RuneCountInString(value) > 100 {
+			return nil, errors.New("属性类型不能超过50字，属性值不能超过100字")
+		}
+		if strings.Contains(traitType, ":") {
+			return nil, errors.New("属性类型不能包含冒号")
+		}
+		if seen[traitType] {
+			return nil, fmt.Errorf("属性类型重复: %s", traitType)
+		}
+		seen[traitType] = true
+		result = append(result, TraitInput{TraitType: traitType, Value: value})
+	}
+	return result, nil
+}
+
+// mergeInstanceTraits 合并藏品级和实例级属性，得到每个实例的生效属性
+func mergeInstanceTraits(instances []models.AssetInstance, traits []models.AssetTrait) []rarityInput {
+	assetTraits := make(map[uint64]map[string]string)
+	instanceTraits := make(map[uint64]map[string]string)
+	for _, trait := range traits {
+		target, key := assetTraits, trait.AssetID
+		if trait.AssetInstanceID != nil {
+			target, key = instanceTraits, *trait.AssetInstanceID
+		}
+		if target[key] == nil {
+			target[key] = make(map[string]string)
+		}
+		target[key][trait.TraitType] = trait.Value
+	}
+
+	inputs := make([]rarityInput, 0, len(instances))
+	for _, instance := range instances {
+		merged := make(map[string]string)
+		for traitType, value := range assetTraits[instance.AssetID] {
+			merged[traitType] = value
+		}
+		for traitType, value := range instanceTraits[instance.ID] {
+			merged[traitType] = value
+		}
+		inputs = append(inputs, rarityInput{InstanceID: instance.ID, Traits: merged})
+	}
+	return inputs
+}
+
+// countTraits 统计每个属性值在实例中出现的次数
+func countTraits(inputs []rarityInput) map[TraitInput]int {
+	counts := make(map[TraitInput]int)
+	for _, input := range inputs {
+		for traitType, value := range input.Traits {
+			counts[TraitInput{TraitType: traitType, Value: value}]++
+		}
+	}
+	return counts
+}
+
+// computeRarity 计算统计稀有度：得分为各属性值出现频率倒数之和（实例总数 / 拥有该属性值的实例数），
+// 得分越高越稀有；得分相同的实例排名相同（如 1,2,2,4）。集合内没有任何属性时全部返回0
+func computeRarity(inputs []rarityInput) map[uint64]rarityResult {
+	results := make(map[uint64]rarityResult, len(inputs))
+	counts := countTraits(inputs)
+	if len(counts) == 0 {
+		for _, input := range inputs {
+			results[input.InstanceID] = rarityResult{}
+		}
+		return results
+	}
+
+	total := float64(len(inputs))
+	type scored struct {
+		id    uint64
+		score float64
+	}
+	ranked := make([]scored, 0, len(inputs))
+	for _, input := range inputs {
+		score := 0.0
+		for traitType, value := range input.Traits {
+			score += total / float64(counts[TraitInput{TraitType: traitType, Value: value}])
+		}
+		// 属性遍历顺序不固定，保留6位小数避免浮点误差导致同分不同名次
+		ranked = append(ranked, scored{id: input.InstanceID, score: math.Round(score*1e6) / 1e6})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].id < ranked[j].id
+	})
+
+	for i, item := range ranked {
+		rank := i + 1
+		if i > 0 && item.score == ranked[i-1].score {
+			rank = results[ranked[i-1].id].Rank
+		}
+		results[item.id] = rarityResult{Score: item.score, Rank: rank}
+	}
+	return results
+}
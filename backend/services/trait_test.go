@@ -0,0 +1,89 @@
+package services
+
+import (
+	"testing"
+
+	"hoho-miniapp/backend/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestParseTraitFilters 测试属性筛选参数解析，同类型的多个值合并
+func TestParseTraitFilters(t *testing.T) {
+	filters, err := ParseTraitFilters([]string{"背景:金色", " 编号 : #1 ", "背景:银色", ""})
+	assert.NoError(t, err)
+	assert.Equal(t, []TraitFilter{
+		{TraitType: "背景", Values: []string{"金色", "银色"}},
+		{TraitType: "编号", Values: []string{"#1"}},
+	}, filters)
+
+	// 值中允许出现冒号
+	filters, err = ParseTraitFilters([]string{"时间:12:00"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"12:00"}, filters[0].Values)
+
+	_, err = ParseTraitFilters([]string{"背景"})
+	assert.Error(t, err)
+	_, err = ParseTraitFilters([]string{":金色"})
+	assert.Error(t, err)
+}
+
+// TestNormalizeTraitInputs 测试属性校验
+func TestNormalizeTraitInputs(t *testing.T) {
+	traits, err := normalizeTraitInputs([]TraitInput{{TraitType: " 背景 ", Value: " 金色 "}})
+	assert.NoError(t, err)
+	assert.Equal(t, []TraitInput{{TraitType: "背景", Value: "金色"}}, traits)
+
+	_, err = normalizeTraitInputs([]TraitInput{{TraitType: "背景", Value: ""}})
+	assert.Error(t, err)
+	_, err = normalizeTraitInputs([]TraitInput{{TraitType: "背景", Value: "金色"}, {TraitType: "背景", Value: "银色"}})
+	assert.Error(t, err)
+	_, err = normalizeTraitInputs([]TraitInput{{TraitType: "a:b", Value: "金色"}})
+	assert.Error(t, err)
+}
+
+// TestMergeInstanceTraits 测试实例级属性覆盖同类型的藏品级属性
+func TestMergeInstanceTraits(t *testing.T) {
+	special := uint64(2)
+	instances := []models.AssetInstance{{ID: 1, AssetID: 10}, {ID: 2, AssetID: 10}, {ID: 3, AssetID: 11}}
+	traits := []models.AssetTrait{
+		{AssetID: 10, TraitType: "背景", Value: "蓝色"},
+		{AssetID: 10, TraitType: "表情", Value: "微笑"},
+		{AssetID: 10, AssetInstanceID: &special, TraitType: "背景", Value: "金色"},
+		{AssetID: 10, AssetInstanceID: &special, TraitType: "编号", Value: "#1"},
+	}
+
+	inputs := mergeInstanceTraits(instances, traits)
+	assert.Len(t, inputs, 3)
+	assert.Equal(t, map[string]string{"背景": "蓝色", "表情": "微笑"}, inputs[0].Traits)
+	assert.Equal(t, map[string]string{"背景": "金色", "表情": "微笑", "编号": "#1"}, inputs[1].Traits)
+	assert.Empty(t, inputs[2].Traits)
+}
+
+// TestComputeRarity 测试稀有度得分与并列排名
+func TestComputeRarity(t *testing.T) {
+	inputs := []rarityInput{
+		{InstanceID: 1, Traits: map[string]string{"背景": "蓝色"}},
+		{InstanceID: 2, Traits: map[string]string{"背景": "蓝色"}},
+		{InstanceID: 3, Traits: map[string]string{"背景": "蓝色"}},
+		{InstanceID: 4, Traits: map[string]string{"背景": "金色", "编号": "#1"}},
+	}
+	results := computeRarity(inputs)
+
+	// 金色 4/1 + 编号 4/1 = 8；蓝色 4/3
+	assert.Equal(t, rarityResult{Score: 8, Rank: 1}, results[4])
+	assert.InDelta(t, 4.0/3, results[1].Score, 1e-6)
+	for _, id := range []uint64{1, 2, 3} {
+		assert.Equal(t, 2, results[id].Rank)
+	}
+
+	// 集合内没有属性时不计算排名
+	results = computeRarity([]rarityInput{{InstanceID: 1, Traits: map[string]string{}}})
+	assert.Equal(t, rarityResult{}, results[1])
+}
+
+// TestInstanceOrder 测试实例排序子句
+func TestInstanceOrder(t *testing.T) {
+	assert.Contains(t, instanceOrder(InstanceSortRarity), "rarity_rank = 0")
+	assert.Contains(t, instanceOrder(""), "instance_no asc")
+}
@@ -53,7 +53,18 @@ func (s *UserService) Register(phone, password string) (*models.User, string, er
 	// 4. 开启事务
 	err = database.DB.Transaction(func(tx *gorm.DB) error {
 		// 4.1. 创建用户
-		if err := tx.Create(&user).Error; err != nil {
+		// 并发注册同一手机号时，UID未被占用的唯一索引冲突不会重试，在此确认是否为手机号冲突
+		if err := createWithRetry(tx, &user, "uid", func() { user.UID = utils.GenerateUID() }); err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				var count int64
+				if err := tx.Model(&models.User{}).Unscoped().Where("phone = ?", phone).Count(&count).Error; err != nil {
+					return err
+				}
+				if count > 0 {
+					return errors.New("手机号已被注册")
+				}
+				return errors.New("生成用户UID失败，请重试")
+			}
 			return err
 		}
 
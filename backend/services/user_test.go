@@ -2,7 +2,8 @@ package services
 
 import (
 	"testing"
-	"time"
+
+	"hoho-miniapp/backend/utils"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -142,23 +143,19 @@ func TestLoginUser(t *testing.T) {
 func TestGenerateUID(t *testing.T) {
 	// 生成多个UID，检查格式
 	uids := make(map[string]bool)
-	
-	for i := 0; i < 100; i++ {
-		uid := generateUID()
-		
-		// 检查长度
-		assert.Len(t, uid, 10, "UID长度应该是10位")
-		
+
+	for i := 0; i < 10000; i++ {
+		uid := utils.GenerateUID()
+
+		// 检查长度：U + 13位ID + 1位校验字符
+		assert.Len(t, uid, 15, "UID长度应该是15位")
+
 		// 检查唯一性
 		assert.False(t, uids[uid], "UID应该是唯一的")
 		uids[uid] = true
-		
-		// 检查格式（只包含数字和大写字母）
-		for _, char := range uid {
-			assert.True(t, 
-				(char >= '0' && char <= '9') || (char >= 'A' && char <= 'Z'),
-				"UID应该只包含数字和大写字母")
-		}
+
+		// 检查格式和校验字符
+		assert.True(t, utils.ValidateUID(uid), "UID校验字符应该正确")
 	}
 }
 
@@ -214,16 +211,6 @@ func TestHashPassword(t *testing.T) {
 
 // 辅助函数
 
-func generateUID() string {
-	// 简化的UID生成逻辑
-	const charset = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	b := make([]byte, 10)
-	for i := range b {
-		b[i] = charset[time.Now().UnixNano()%int64(len(charset))]
-	}
-	return string(b)
-}
-
 func validatePhone(phone string) bool {
 	if len(phone) != 11 {
 		return false
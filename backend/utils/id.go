@@ -0,0 +1,178 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 雪花ID布局：41位毫秒时间戳（自 idEpoch 起）+ 10位节点号 + 12位序号
+const (
+	idEpoch   int64 = 1704067200000 // 2024-01-01 00:00:00 UTC
+	nodeBits        = 10
+	seqBits         = 12
+	MaxNodeID       = 1<<nodeBits - 1
+	maxSeq          = 1<<seqBits - 1
+)
+
+// crockfordAlphabet Crockford Base32字符表，去掉了易混淆的 I L O U
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// decimalAlphabet 十进制字符表
+const decimalAlphabet = "0123456789"
+
+// base32IDLength 63位雪花ID编码为Base32后的固定长度
+const base32IDLength = 13
+
+var (
+	newUIDPattern    = regexp.MustCompile(`^U[0-9A-HJKMNP-TV-Z]{14}$`)
+	legacyUIDPattern = regexp.MustCompile(`^U[0-9]{1,10}$`)
+)
+
+// IDGenerator 雪花ID生成器，同一节点内单调递增且不重复
+// 时钟回拨时沿用上次的时间戳继续分配序号，不阻塞等待
+type IDGenerator struct {
+	mu     sync.Mutex
+	nodeID int64
+	lastMs int64
+	seq    int64
+}
+
+// NewIDGenerator 创建雪花ID生成器，nodeID 取值 0-1023，多实例部署时每个实例必须不同
+func NewIDGenerator(nodeID int64) (*IDGenerator, error) {
+	if nodeID < 0 || nodeID > MaxNodeID {
+		return nil, fmt.Errorf("节点号必须在0-%d之间", MaxNodeID)
+	}
+	return &IDGenerator{nodeID: nodeID}, nil
+}
+
+// Next 生成下一个ID
+func (g *IDGenerator) Next() int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := time.Now().UnixMilli() - idEpoch
+	if now > g.lastMs {
+		g.lastMs = now
+		g.seq = 0
+	} else {
+		g.seq++
+		if g.seq > maxSeq {
+			// 当前毫秒序号用尽，借用下一毫秒
+			g.lastMs++
+			g.seq = 0
+		}
+	}
+	return g.lastMs<<(nodeBits+seqBits) | g.nodeID<<seqBits | g.seq
+}
+
+var (
+	defaultGeneratorMu sync.RWMutex
+	defaultGenerator   = &IDGenerator{}
+)
+
+// InitIDGenerator 使用配置的节点号初始化全局ID生成器，应在启动时调用一次
+func InitIDGenerator(nodeID int64) error {
+	g, err := NewIDGenerator(nodeID)
+	if err != nil {
+		return err
+	}
+	defaultGeneratorMu.Lock()
+	defaultGenerator = g
+	defaultGeneratorMu.Unlock()
+	return nil
+}
+
+// NextID 使用全局生成器生成雪花ID
+func NextID() int64 {
+	defaultGeneratorMu.RLock()
+	g := defaultGenerator
+	defaultGeneratorMu.RUnlock()
+	return g.Next()
+}
+
+// GenerateUID 生成用户UID，格式：U + 13位Base32雪花ID + 1位校验字符
+func GenerateUID() string {
+	return "U" + withCheckChar(encodeBase32(NextID()), crockfordAlphabet)
+}
+
+// ValidateUID 校验UID格式及校验字符，兼容旧版纯数字UID
+func ValidateUID(uid string) bool {
+	if legacyUIDPattern.MatchString(uid) {
+		return true
+	}
+	return newUIDPattern.MatchString(uid) && validCheckChar(uid[1:], crockfordAlphabet)
+}
+
+// GenerateTokenID 生成唯一的TokenID，模拟链上ID
+// 格式：藏品ID-实例编号-13位Base32雪花ID+1位校验字符
+func GenerateTokenID(assetID uint64, instanceNo uint64) string {
+	return fmt.Sprintf("%d-%d-%s", assetID, instanceNo, withCheckChar(encodeBase32(NextID()), crockfordAlphabet))
+}
+
+// GenerateOrderNo 生成订单号，格式：前缀 + 19位十进制雪花ID + 1位Luhn校验数字
+func GenerateOrderNo(prefix string) string {
+	return prefix + withCheckChar(fmt.Sprintf("%019d", NextID()), decimalAlphabet)
+}
+
+// ValidateOrderNo 校验订单号的前缀、长度和校验数字，用于客服查单等人工输入场景
+func ValidateOrderNo(orderNo, prefix string) bool {
+	body := strings.TrimPrefix(orderNo, prefix)
+	if body == orderNo || len(body) != 20 {
+		return false
+	}
+	if _, err := strconv.ParseUint(body, 10, 64); err != nil {
+		return false
+	}
+	return validCheckChar(body, decimalAlphabet)
+}
+
+// encodeBase32 将非负ID编码为定长Crockford Base32字符串
+func encodeBase32(id int64) string {
+	b := make([]byte, base32IDLength)
+	for i := base32IDLength - 1; i >= 0; i-- {
+		b[i] = crockfordAlphabet[id&31]
+		id >>= 5
+	}
+	return string(b)
+}
+
+// luhnCheckIndex Luhn mod N 算法计算校验字符在字符表中的下标，可发现所有单字符错误和绝大多数相邻交换
+func luhnCheckIndex(s, alphabet string) int {
+	n := len(alphabet)
+	sum := 0
+	factor := 2
+	for i := len(s) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(alphabet, s[i])
+		addend = addend/n + addend%n
+		sum += addend
+		if factor == 2 {
+			factor = 1
+		} else {
+			factor = 2
+		}
+	}
+	return (n - sum%n) % n
+}
+
+// withCheckChar 在字符串末尾追加校验字符
+func withCheckChar(s, alphabet string) string {
+	return s + string(alphabet[luhnCheckIndex(s, alphabet)])
+}
+
+// validCheckChar 校验末位校验字符，要求所有字符都在字符表内
+func validCheckChar(s, alphabet string) bool {
+	if len(s) < 2 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if strings.IndexByte(alphabet, s[i]) < 0 {
+			return false
+		}
+	}
+	body := s[:len(s)-1]
+	return alphabet[luhnCheckIndex(body, alphabet)] == s[len(s)-1]
+}
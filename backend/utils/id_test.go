@@ -0,0 +1,98 @@
+package utils
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestIDGeneratorUnique 测试并发生成的ID唯一且单节点内递增
+func TestIDGeneratorUnique(t *testing.T) {
+	g, err := NewIDGenerator(7)
+	assert.NoError(t, err)
+
+	const workers, perWorker = 8, 5000
+	results := make([][]int64, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				results[w] = append(results[w], g.Next())
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	seen := make(map[int64]bool, workers*perWorker)
+	for _, ids := range results {
+		for i, id := range ids {
+			assert.False(t, seen[id], "ID重复: %d", id)
+			seen[id] = true
+			assert.Equal(t, int64(7), id>>seqBits&MaxNodeID)
+			if i > 0 {
+				assert.Greater(t, id, ids[i-1])
+			}
+		}
+	}
+}
+
+// TestNewIDGeneratorNodeRange 测试节点号范围校验
+func TestNewIDGeneratorNodeRange(t *testing.T) {
+	_, err := NewIDGenerator(-1)
+	assert.Error(t, err)
+	_, err = NewIDGenerator(MaxNodeID + 1)
+	assert.Error(t, err)
+	_, err = NewIDGenerator(MaxNodeID)
+	assert.NoError(t, err)
+}
+
+// TestValidateUID 测试UID校验字符能发现输错和相邻交换
+func TestValidateUID(t *testing.T) {
+	uid := GenerateUID()
+	assert.True(t, ValidateUID(uid))
+	assert.True(t, ValidateUID("U1234561234"), "兼容旧版UID")
+
+	// 替换任意一位
+	for i := 1; i < len(uid); i++ {
+		c := "0"
+		if uid[i] == '0' {
+			c = "1"
+		}
+		assert.False(t, ValidateUID(uid[:i]+c+uid[i+1:]), "第%d位输错应被发现", i)
+	}
+	assert.False(t, ValidateUID(strings.ToLower(uid)))
+	assert.False(t, ValidateUID("U"+uid[2:]))
+}
+
+// TestOrderNo 测试订单号格式和Luhn校验
+func TestOrderNo(t *testing.T) {
+	orderNo := GenerateOrderNo("PS")
+	assert.Len(t, orderNo, 22)
+	assert.True(t, ValidateOrderNo(orderNo, "PS"))
+	assert.False(t, ValidateOrderNo(orderNo, "RD"))
+
+	body := []byte(orderNo)
+	body[10], body[11] = body[11], body[10]
+	if body[10] != body[11] {
+		assert.False(t, ValidateOrderNo(string(body), "PS"), "相邻交换应被发现")
+	}
+
+	// 经典Luhn样例：7992739871 的校验位为 3
+	assert.Equal(t, "79927398713", withCheckChar("7992739871", decimalAlphabet))
+}
+
+// TestGenerateTokenID 测试TokenID格式
+func TestGenerateTokenID(t *testing.T) {
+	tokenID := GenerateTokenID(12, 3)
+	parts := strings.Split(tokenID, "-")
+	assert.Len(t, parts, 3)
+	assert.Equal(t, "12", parts[0])
+	assert.Equal(t, "3", parts[1])
+	assert.Len(t, parts[2], base32IDLength+1)
+	assert.True(t, validCheckChar(parts[2], crockfordAlphabet))
+	assert.NotEqual(t, tokenID, GenerateTokenID(12, 3))
+}